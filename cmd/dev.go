@@ -20,6 +20,8 @@ func newDevCmd() *cobra.Command {
 	var variablesFile string
 	var interval string
 	var ttyFlag bool
+	var lockTimeout string
+	var parallelism int
 
 	devCmd := &cobra.Command{
 		Use:   "dev",
@@ -29,7 +31,7 @@ func newDevCmd() *cobra.Command {
 		jumppad dev ./
 `,
 		Args:         cobra.ArbitraryArgs,
-		RunE:         newDevCmdFunc(&variables, &variablesFile, &interval, &ttyFlag),
+		RunE:         newDevCmdFunc(&variables, &variablesFile, &interval, &ttyFlag, &lockTimeout, &parallelism),
 		SilenceUsage: true,
 	}
 
@@ -37,11 +39,14 @@ func newDevCmd() *cobra.Command {
 	devCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
 	devCmd.Flags().StringVarP(&interval, "interval", "", "5s", "Interval to check for changes. E.g. --interval=5s")
 	devCmd.Flags().BoolVarP(&ttyFlag, "disable-tty", "", false, "Enable/disable output to TTY")
+	addWorkspaceFlag(devCmd)
+	addLockTimeoutFlag(devCmd, &lockTimeout)
+	devCmd.Flags().IntVarP(&parallelism, "parallelism", "", jumppad.DefaultParallelism, "Maximum number of resources to create concurrently, 0 removes the limit. E.g. --parallelism=4")
 
 	return devCmd
 }
 
-func newDevCmdFunc(variables *[]string, variablesFile, interval *string, ttyFlag *bool) func(cmd *cobra.Command, args []string) error {
+func newDevCmdFunc(variables *[]string, variablesFile, interval *string, ttyFlag *bool, lockTimeout *string, parallelism *int) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		// create the output view
 		var v view.View
@@ -58,7 +63,11 @@ func newDevCmdFunc(variables *[]string, variablesFile, interval *string, ttyFlag
 			}
 		}
 
-		engineClients, _ := clients.GenerateClients(v.Logger())
+		engineClients, err := clients.GenerateClients(v.Logger())
+		if err != nil {
+			return err
+		}
+
 		engine, err := createEngine(v.Logger(), engineClients)
 		if err != nil {
 			return fmt.Errorf("unable to create engine: %s", err)
@@ -72,6 +81,14 @@ func newDevCmdFunc(variables *[]string, variablesFile, interval *string, ttyFlag
 			return fmt.Errorf("invalid duration %s, please specify a duration using go syntax, e.g. 5s, 1m", *interval)
 		}
 
+		lt, err := time.ParseDuration(*lockTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid lock timeout %s, please specify a duration using go syntax, e.g. 30s, 1m", *lockTimeout)
+		}
+
+		engine.SetLockTimeout(lt)
+		engine.SetParallelism(*parallelism)
+
 		// set the source
 		src := ""
 		if len(args) == 1 {
@@ -128,6 +145,15 @@ func newDevCmdFunc(variables *[]string, variablesFile, interval *string, ttyFlag
 			}
 		}
 
+		// show the progress of the resources and publish it to the API server
+		// for IDE integrations, rendering and publishing use separate
+		// subscriptions so that a slow API server does not delay the view
+		stopProgress := handleEvents(engine, v.ShowEvent)
+		defer stopProgress()
+
+		stopPublish := handleEvents(engine, eventPublisher(eventsAPIAddr(), v.Logger()))
+		defer stopPublish()
+
 		// start the
 		go doUpdates(v, engine, src, vars, *variablesFile, d)
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/connector"
@@ -15,6 +16,8 @@ import (
 
 func newDestroyCmd(cc connector.Connector, l logger.Logger) *cobra.Command {
 	var force bool
+	var lockTimeout string
+	var targets []string
 
 	downCmd := &cobra.Command{
 		Use:     "down",
@@ -22,7 +25,12 @@ func newDestroyCmd(cc connector.Connector, l logger.Logger) *cobra.Command {
 		Long:    "Remove all resources in the current state",
 		Example: `jumppad down`,
 		Run: func(cmd *cobra.Command, args []string) {
-			engineClients, _ := clients.GenerateClients(l)
+			engineClients, err := clients.GenerateClients(l)
+			if err != nil {
+				l.Error("Unable to create clients", "error", err)
+				return
+			}
+
 			engineClients.ContainerTasks.SetForce(force)
 
 			engine, err := createEngine(l, engineClients)
@@ -31,6 +39,20 @@ func newDestroyCmd(cc connector.Connector, l logger.Logger) *cobra.Command {
 				return
 			}
 
+			lt, err := time.ParseDuration(lockTimeout)
+			if err != nil {
+				l.Error("Invalid lock timeout, please specify a duration using go syntax, e.g. 30s, 1m", "lock-timeout", lockTimeout)
+				return
+			}
+
+			engine.SetLockTimeout(lt)
+
+			err = engine.SetTargets(targets)
+			if err != nil {
+				l.Error("Invalid target", "error", err)
+				return
+			}
+
 			logger := createLogger()
 
 			done := make(chan os.Signal, 1)
@@ -57,8 +79,22 @@ func newDestroyCmd(cc connector.Connector, l logger.Logger) *cobra.Command {
 				return
 			}
 
-			// clean up the data folders
+			// other resources remain when targeting, do not clean up
+			if len(targets) > 0 {
+				return
+			}
+
+			// clean up the data folders for the workspace
 			os.RemoveAll(utils.DataFolder("", os.ModePerm))
+
+			// the connector and the library and temp folders are shared by
+			// all workspaces, only remove them when no other workspace has
+			// resources
+			if utils.OtherWorkspacesHaveState() {
+				logger.Debug("Other workspaces have resources, not stopping the jumppad daemon")
+				return
+			}
+
 			os.RemoveAll(utils.LibraryFolder("", os.ModePerm))
 			os.RemoveAll(utils.JumppadTemp())
 
@@ -73,6 +109,9 @@ func newDestroyCmd(cc connector.Connector, l logger.Logger) *cobra.Command {
 	}
 
 	downCmd.Flags().BoolVarP(&force, "force", "", false, "When set to true Jumppad will not wait for containers to exit gracefully and will ignore errors")
+	addWorkspaceFlag(downCmd)
+	addLockTimeoutFlag(downCmd, &lockTimeout)
+	downCmd.Flags().StringSliceVarP(&targets, "target", "", nil, "Only destroy the given resource and the resources that depend on it, e.g. --target resource.container.api. Can be specified multiple times")
 
 	return downCmd
 }
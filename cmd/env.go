@@ -91,6 +91,7 @@ func newEnvCmd() *cobra.Command {
 		SilenceUsage: true,
 	}
 
+	addWorkspaceFlag(envCmd)
 	envCmd.Flags().BoolVarP(&unset, "unset", "", false, "When set to true jumppad will print unset commands for environment variables defined by the blueprint")
 	return envCmd
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/connector"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+)
+
+// eventsAPIAddr returns the address of the API server endpoint that the
+// engine events are published to
+func eventsAPIAddr() string {
+	return fmt.Sprintf("http://localhost%s/events", connector.DefaultConnectorOptions().APIBind)
+}
+
+// handleEvents subscribes to the engine events and calls handler for each
+// event in a separate goroutine so that a slow handler does not delay other
+// subscribers, the returned function removes the subscription and blocks
+// until the handler has processed all the queued events
+func handleEvents(e jumppad.Engine, handler func(jumppad.Event)) func() {
+	events, unsubscribe := e.Subscribe()
+	done := make(chan struct{})
+
+	go func() {
+		for ev := range events {
+			handler(ev)
+		}
+
+		close(done)
+	}()
+
+	return func() {
+		unsubscribe()
+		<-done
+	}
+}
+
+// eventPublisher returns an event handler that sends the engine events to the
+// API server so that IDE integrations can follow the progress of the resources
+func eventPublisher(addr string, l logger.Logger) func(jumppad.Event) {
+	hc := &http.Client{Timeout: 2 * time.Second}
+
+	return func(ev jumppad.Event) {
+		d, err := json.Marshal(ev)
+		if err != nil {
+			l.Debug("Unable to marshal event", "error", err)
+			return
+		}
+
+		resp, err := hc.Post(addr, "application/json", bytes.NewReader(d))
+		if err != nil {
+			l.Debug("Unable to publish event to API server", "error", err)
+			return
+		}
+
+		resp.Body.Close()
+	}
+}
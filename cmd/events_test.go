@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	enginemocks "github.com/jumppad-labs/jumppad/pkg/jumppad/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func setupEventsEngine(events chan jumppad.Event) *enginemocks.Engine {
+	me := &enginemocks.Engine{}
+	me.On("Subscribe").Return((<-chan jumppad.Event)(events), func() { close(events) })
+
+	return me
+}
+
+func TestHandleEventsStopWaitsForQueuedEvents(t *testing.T) {
+	events := make(chan jumppad.Event, 10)
+	me := setupEventsEngine(events)
+
+	received := []jumppad.EventType{}
+	stop := handleEvents(me, func(ev jumppad.Event) {
+		received = append(received, ev.Type)
+	})
+
+	events <- jumppad.Event{Type: jumppad.EventResourceCreated}
+	events <- jumppad.Event{Type: jumppad.EventApplyFinished}
+
+	stop()
+
+	require.Equal(t, []jumppad.EventType{jumppad.EventResourceCreated, jumppad.EventApplyFinished}, received)
+}
+
+func TestEventPublisherPostsEventToAPIServer(t *testing.T) {
+	mu := sync.Mutex{}
+	received := []jumppad.Event{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ev := jumppad.Event{}
+		json.NewDecoder(r.Body).Decode(&ev)
+
+		mu.Lock()
+		received = append(received, ev)
+		mu.Unlock()
+
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	p := eventPublisher(ts.URL, logger.NewTestLogger(t))
+	p(jumppad.Event{Type: jumppad.EventResourceCreated, Resource: "resource.container.app"})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, received, 1)
+	require.Equal(t, "resource.container.app", received[0].Resource)
+}
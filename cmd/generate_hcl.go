@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jumppad-labs/jumppad/pkg/config/compose"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newGenerateHCLCommand() *cobra.Command {
+	var from string
+	var output string
+
+	generateHCLCmd := &cobra.Command{
+		Use:   "hcl",
+		Short: "Generate jumppad configuration from a Docker Compose file",
+		Long: `Generate jumppad configuration from a Docker Compose file, services are converted to
+container resources and networks to network resources. Any part of the compose file that
+can not be converted is reported as a warning.`,
+		Example: `  # write the configuration to stdout
+  jumppad generate hcl --from docker-compose.yml
+
+  # write the configuration to a file
+  jumppad generate hcl --from docker-compose.yml --output ./app/main.hcl`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !utils.IsComposeFile(from) {
+				return fmt.Errorf("%s is not a compose file, the file must exist and have the extension .yml or .yaml", from)
+			}
+
+			// paths on the host are written relative to the output so that the
+			// generated configuration can be committed with the compose file
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				dir, err = filepath.Abs(filepath.Dir(output))
+				if err != nil {
+					return err
+				}
+			}
+
+			d, warnings, err := compose.Convert(from, dir)
+			if err != nil {
+				return fmt.Errorf("unable to convert compose file: %s", err)
+			}
+
+			for _, w := range warnings {
+				cmd.PrintErrln("Warning:", w)
+			}
+
+			if output == "" {
+				cmd.Print(string(d))
+				return nil
+			}
+
+			err = os.MkdirAll(dir, os.ModePerm)
+			if err != nil {
+				return fmt.Errorf("unable to create output folder: %s", err)
+			}
+
+			err = os.WriteFile(output, d, 0644)
+			if err != nil {
+				return fmt.Errorf("unable to write configuration: %s", err)
+			}
+
+			cmd.Printf("Configuration written to %s\n", output)
+
+			return nil
+		},
+	}
+
+	generateHCLCmd.Flags().StringVarP(&from, "from", "", "", "Docker Compose file to generate the configuration from")
+	generateHCLCmd.Flags().StringVarP(&output, "output", "o", "", "File to write the configuration to, defaults to stdout")
+	generateHCLCmd.MarkFlagRequired("from")
+
+	return generateHCLCmd
+}
@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -16,9 +16,7 @@ import (
 	hcltypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/spf13/cobra"
 
-	dcontainer "github.com/docker/docker/api/types/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container"
-	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/cache"
 	ct "github.com/jumppad-labs/jumppad/pkg/config/resources/container"
@@ -27,7 +25,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
 
-func newLogCmd(dc container.Docker, stdout, stderr io.Writer) *cobra.Command {
+func newLogCmd(ct container.ContainerTasks, stdout, stderr io.Writer) *cobra.Command {
 	logCmd := &cobra.Command{
 		Use:     "logs [resource]",
 		Short:   "Tails logs for running jumppad resources",
@@ -42,9 +40,11 @@ func newLogCmd(dc container.Docker, stdout, stderr io.Writer) *cobra.Command {
 	`,
 		Args:              cobra.ArbitraryArgs,
 		ValidArgsFunction: getResources,
-		RunE:              newLogCmdFunc(dc, stdout, stderr),
+		RunE:              newLogCmdFunc(ct, stdout, stderr),
 	}
 
+	addWorkspaceFlag(logCmd)
+
 	return logCmd
 }
 
@@ -67,7 +67,7 @@ func getResources(cmd *cobra.Command, args []string, complete string) ([]string,
 	return loggable, cobra.ShellCompDirectiveNoFileComp
 }
 
-func newLogCmdFunc(dc container.Docker, stdout, stderr io.Writer) func(cmd *cobra.Command, args []string) error {
+func newLogCmdFunc(ct container.ContainerTasks, stdout, stderr io.Writer) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		log := createLogger()
 		sigs := make(chan os.Signal, 1)
@@ -96,29 +96,23 @@ func newLogCmdFunc(dc container.Docker, stdout, stderr io.Writer) func(cmd *cobr
 			}
 		}
 
-		ctx := context.Background()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
 		for _, r := range loggable {
-			rc, err := dc.ContainerLogs(
-				ctx,
-				r,
-				dcontainer.LogsOptions{
-					ShowStdout: true,
-					ShowStderr: true,
-					Follow:     true,
-					Tail:       "40",
-				},
-			)
-
-			if err == nil {
-				waitGroup.Add(1)
-				go func(rc io.ReadCloser, name string, c color.Attribute, log logger.Logger) {
-					writeLogOutput(rc, stdout, stderr, name, c, log)
-					waitGroup.Done()
-				}(rc, r, getRandomColor(), log)
-			} else {
-				log.Error("Unable to get logs for container", "error", err)
-			}
+			waitGroup.Add(1)
+			go func(name string, c color.Attribute) {
+				defer waitGroup.Done()
+
+				prefix := strings.TrimSuffix(name, utils.LocalTLD)
+				sout := newLogWriter(stdout, prefix, c)
+				serr := newLogWriter(stderr, prefix, c)
+
+				err := ct.FollowContainerLogs(ctx, name, 40, sout, serr)
+				if err != nil && ctx.Err() == nil {
+					log.Error("Unable to get logs for container", "name", name, "error", err)
+				}
+			}(r, getRandomColor())
 		}
 
 		// send an interrupt when the waitGroup is done
@@ -159,9 +153,19 @@ func getFQDNForResource(r hcltypes.Resource) []string {
 
 	switch r.Metadata().Type {
 	case ct.TypeContainer:
-		fqdns = append(fqdns, utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type))
+		fqdn := utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)
+
+		// add any init containers before the container
+		for _, ic := range r.(*ct.Container).InitContainers {
+			fqdns = append(fqdns, ct.InitContainerName(ic.Name, fqdn))
+		}
+
+		fqdns = append(fqdns, fqdn)
 	case k8s.TypeK8sCluster:
 		fqdns = append(fqdns, fmt.Sprintf("%s.%s", "server", utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)))
+
+		// add the agent nodes
+		fqdns = append(fqdns, r.(*k8s.Cluster).AgentContainerNames...)
 	case nomad.TypeNomadCluster:
 		fqdns = append(fqdns, fmt.Sprintf("%s.%s", "server", utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)))
 
@@ -171,7 +175,14 @@ func getFQDNForResource(r hcltypes.Resource) []string {
 			fqdns = append(fqdns, fmt.Sprintf("%d.%s.%s", n+1, "client", utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)))
 		}
 	case ct.TypeSidecar:
-		fallthrough
+		fqdn := utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)
+
+		// add any init containers before the sidecar
+		for _, ic := range r.(*ct.Sidecar).InitContainers {
+			fqdns = append(fqdns, ct.InitContainerName(ic.Name, fqdn))
+		}
+
+		fqdns = append(fqdns, fqdn)
 	case cache.TypeImageCache:
 		fqdns = append(fqdns, utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type))
 	}
@@ -183,34 +194,32 @@ func getRandomColor() color.Attribute {
 	return termColors[rand.Intn(len(termColors)-1)]
 }
 
-func writeLogOutput(rc io.ReadCloser, stdout, stderr io.Writer, name string, c color.Attribute, log logger.Logger) {
-	hdr := make([]byte, 8)
-	colorWriter := color.New(c)
+// logWriter writes each line of the container logs prefixed with the
+// name of the container
+type logWriter struct {
+	w      io.Writer
+	prefix string
+	color  *color.Color
+	buf    []byte
+}
 
-	for {
-		_, err := rc.Read(hdr)
-		if err != nil {
-			log.Error("Unable to read from log stream", "name", name, "error", err)
-			return
-		}
+func newLogWriter(w io.Writer, name string, c color.Attribute) *logWriter {
+	return &logWriter{w: w, prefix: fmt.Sprintf("[%s]   ", name), color: color.New(c)}
+}
 
-		var w io.Writer
-		switch hdr[0] {
-		case 1:
-			w = stdout
-		default:
-			w = stderr
-		}
+// Write buffers the output and writes each complete line
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
 
-		count := binary.BigEndian.Uint32(hdr[4:])
-		dat := make([]byte, count)
-		_, err = rc.Read(dat)
-		if err != nil {
-			log.Error("Unable to read from log stream", "name", name, "error", err)
-			return
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
 		}
 
-		name = strings.TrimSuffix(name, utils.LocalTLD)
-		colorWriter.Fprintf(w, "[%s]   %s", name, string(dat))
+		l.color.Fprintf(l.w, "%s%s", l.prefix, string(l.buf[:i+1]))
+		l.buf = l.buf[i+1:]
 	}
+
+	return len(p), nil
 }
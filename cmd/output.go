@@ -52,3 +52,7 @@ var outputCmd = &cobra.Command{
 		fmt.Printf("%s", string(d))
 	},
 }
+
+func init() {
+	addWorkspaceFlag(outputCmd)
+}
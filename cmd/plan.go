@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hokaccha/go-prettyjson"
+	"github.com/jumppad-labs/jumppad/pkg/clients/getter"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// planChangesExitCode is returned when --detailed-exitcode is set and
+// applying the configuration would make changes
+const planChangesExitCode = 2
+
+func newPlanCmd(e jumppad.Engine, bp getter.Getter) *cobra.Command {
+	var variables []string
+	var variablesFile string
+	var jsonOutput bool
+	var detailedExitCode bool
+
+	planCmd := &cobra.Command{
+		Use:   "plan [file] | [directory]",
+		Short: "Show the changes that up would make to the resources",
+		Long: `Show the changes that up would make to the resources
+
+Each resource is listed with the action that will be taken, for resources
+that have changed the difference between the state and the configuration
+is shown. No resources are created or destroyed and the state is not modified.
+
+When --detailed-exitcode is set the command exits with:
+  0 - no changes
+  1 - error
+  2 - changes pending`,
+		Example: `
+  # Show the plan for the configuration in the current folder
+  jumppad plan
+
+  # Output the plan as JSON and fail when there are changes
+  jumppad plan --json --detailed-exitcode ./my-stack
+	`,
+		Args:         cobra.ArbitraryArgs,
+		RunE:         newPlanCmdFunc(e, bp, &variables, &variablesFile, &jsonOutput, &detailedExitCode, os.Exit),
+		SilenceUsage: true,
+	}
+
+	planCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
+	planCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+	planCmd.Flags().BoolVarP(&jsonOutput, "json", "", false, "Output the plan as JSON")
+	planCmd.Flags().BoolVarP(&detailedExitCode, "detailed-exitcode", "", false, "Exit with status 2 when there are changes pending")
+	addWorkspaceFlag(planCmd)
+
+	return planCmd
+}
+
+func newPlanCmdFunc(e jumppad.Engine, bp getter.Getter, variables *[]string, variablesFile *string, jsonOutput, detailedExitCode *bool, exit func(int)) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		// parse the vars into a map
+		vars := map[string]string{}
+		for _, v := range *variables {
+			// if the variable is wrapped in single quotes remove them
+			v = strings.TrimPrefix(v, "'")
+			v = strings.TrimSuffix(v, "'")
+
+			parts := strings.Split(v, "=")
+			if len(parts) >= 2 {
+				vars[parts[0]] = strings.Join(parts[1:], "=")
+			}
+		}
+
+		// check the variables file exists
+		if *variablesFile != "" {
+			if _, err := os.Stat(*variablesFile); err != nil {
+				return fmt.Errorf("variables file %s, does not exist", *variablesFile)
+			}
+		}
+
+		dst := "./"
+		if len(args) == 1 && args[0] != "." {
+			dst = args[0]
+		}
+
+		if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) && !utils.IsComposeFile(dst) {
+			// fetch the remote blueprint
+			err := bp.Get(dst, utils.BlueprintLocalFolder(dst))
+			if err != nil {
+				return fmt.Errorf("unable to retrieve blueprint: %s", err)
+			}
+
+			dst = utils.BlueprintLocalFolder(dst)
+		}
+
+		plan, err := e.Plan(dst, vars, *variablesFile)
+		if err != nil {
+			return err
+		}
+
+		if *jsonOutput {
+			s, err := prettyjson.Marshal(plan)
+			if err != nil {
+				return fmt.Errorf("unable to output plan as JSON: %s", err)
+			}
+
+			cmd.Println(string(s))
+		} else {
+			printPlan(cmd, plan)
+		}
+
+		if *detailedExitCode && plan.HasChanges() {
+			exit(planChangesExitCode)
+		}
+
+		return nil
+	}
+}
+
+func printPlan(cmd *cobra.Command, plan *jumppad.Plan) {
+	if !plan.HasChanges() {
+		cmd.Println("No changes, the resources match the configuration")
+		return
+	}
+
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case jumppad.PlanActionCreate:
+			cmd.Printf("%s%s\n", greenIcon.Render("+"), whiteText.Render(c.ID))
+		case jumppad.PlanActionRecreate:
+			cmd.Printf("%s%s %s\n", yellowIcon.Render("±"), whiteText.Render(c.ID), grayText.Render("(recreate)"))
+		case jumppad.PlanActionRefresh, jumppad.PlanActionUpdate:
+			cmd.Printf("%s%s %s\n", yellowIcon.Render("~"), whiteText.Render(c.ID), grayText.Render(fmt.Sprintf("(%s)", c.Action)))
+
+			for _, a := range c.Attributes {
+				cmd.Printf("    %s %s: %v => %v\n", grayText.Render("└─"), a.Path, planValue(a.Old), planValue(a.New))
+			}
+		case jumppad.PlanActionDestroy:
+			cmd.Printf("%s%s\n", redIcon.Render("-"), whiteText.Render(c.ID))
+		case jumppad.PlanActionDisable:
+			cmd.Printf("%s%s %s\n", grayIcon.Render("-"), grayText.Render(c.ID), grayText.Render("(disable)"))
+		case jumppad.PlanActionNoChange:
+			cmd.Printf("%s%s %s\n", grayIcon.Render("="), grayText.Render(c.ID), grayText.Render("(no change)"))
+		}
+	}
+
+	cmd.Println()
+	cmd.Println(whiteText.Render(fmt.Sprintf(
+		"Plan: %d to create, %d to recreate, %d to update, %d to refresh, %d to destroy, %d to disable",
+		plan.Count(jumppad.PlanActionCreate),
+		plan.Count(jumppad.PlanActionRecreate),
+		plan.Count(jumppad.PlanActionUpdate),
+		plan.Count(jumppad.PlanActionRefresh),
+		plan.Count(jumppad.PlanActionDestroy),
+		plan.Count(jumppad.PlanActionDisable),
+	)))
+}
+
+// planValue formats an attribute value, missing values are shown as null
+func planValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", t)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	gettermock "github.com/jumppad-labs/jumppad/pkg/clients/getter/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	enginemocks "github.com/jumppad-labs/jumppad/pkg/jumppad/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testPlan = &jumppad.Plan{
+	Changes: []jumppad.ResourceChange{
+		{ID: "resource.container.api", Action: jumppad.PlanActionCreate},
+		{
+			ID:     "resource.container.db",
+			Action: jumppad.PlanActionRefresh,
+			Attributes: []jumppad.AttributeChange{
+				{Path: "image.name", Old: "postgres:14", New: "postgres:15"},
+			},
+		},
+		{ID: "resource.network.old", Action: jumppad.PlanActionDestroy},
+	},
+}
+
+func setupPlan(t *testing.T, plan *jumppad.Plan, jsonOutput, detailed bool) (*cobra.Command, *bytes.Buffer, *int) {
+	me := &enginemocks.Engine{}
+	me.On("Plan", mock.Anything, mock.Anything, mock.Anything).Return(plan, nil)
+
+	mg := &gettermock.Getter{}
+	mg.On("Get", mock.Anything, mock.Anything).Return(nil)
+
+	exitCode := -1
+	vars := []string{}
+	varsFile := ""
+
+	c := &cobra.Command{
+		RunE: newPlanCmdFunc(me, mg, &vars, &varsFile, &jsonOutput, &detailed, func(code int) { exitCode = code }),
+	}
+
+	out := bytes.NewBufferString("")
+	c.SetOut(out)
+	c.SetArgs([]string{"../examples/single_file"})
+
+	return c, out, &exitCode
+}
+
+func TestPlanPrintsChanges(t *testing.T) {
+	c, out, exitCode := setupPlan(t, testPlan, false, false)
+
+	err := c.Execute()
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), "resource.container.api")
+	require.Contains(t, out.String(), `image.name: "postgres:14" => "postgres:15"`)
+	require.Contains(t, out.String(), "Plan: 1 to create, 0 to recreate, 0 to update, 1 to refresh, 1 to destroy, 0 to disable")
+	require.Equal(t, -1, *exitCode)
+}
+
+func TestPlanOutputsJSON(t *testing.T) {
+	c, out, _ := setupPlan(t, testPlan, true, false)
+
+	err := c.Execute()
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), `"action"`)
+	require.Contains(t, out.String(), `"refresh"`)
+}
+
+func TestPlanWithDetailedExitCodeExitsWhenChangesPending(t *testing.T) {
+	c, _, exitCode := setupPlan(t, testPlan, false, true)
+
+	err := c.Execute()
+	require.NoError(t, err)
+
+	require.Equal(t, planChangesExitCode, *exitCode)
+}
+
+func TestPlanWithDetailedExitCodeDoesNotExitWhenNoChanges(t *testing.T) {
+	c, out, exitCode := setupPlan(t, &jumppad.Plan{}, false, true)
+
+	err := c.Execute()
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), "No changes")
+	require.Equal(t, -1, *exitCode)
+}
+
+func TestPlanWithOnlyUnchangedResourcesDoesNotExit(t *testing.T) {
+	plan := &jumppad.Plan{
+		Changes: []jumppad.ResourceChange{
+			{ID: "resource.helm.consul", Action: jumppad.PlanActionNoChange},
+		},
+	}
+
+	c, out, exitCode := setupPlan(t, plan, false, true)
+
+	err := c.Execute()
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), "No changes")
+	require.Equal(t, -1, *exitCode)
+}
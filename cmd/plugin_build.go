@@ -11,6 +11,7 @@ import (
 	"github.com/jumppad-labs/hclconfig"
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/build"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	cp "github.com/otiai10/copy"
@@ -62,6 +63,7 @@ var pluginCmd = &cobra.Command{
 		}
 
 		l := createLogger()
+		// only the getter is used, the container runtime is not required
 		engineClients, _ := clients.GenerateClients(l)
 
 		// create a temp output folder
@@ -168,9 +170,11 @@ var pluginCmd = &cobra.Command{
 
 		prov := &build.Provider{}
 		prov.Init(&build.Build{
-			ResourceBase: types.ResourceBase{
-				Meta: types.Meta{
-					Name: "jumppad",
+			ResourceOptions: config.ResourceOptions{
+				ResourceBase: types.ResourceBase{
+					Meta: types.Meta{
+						Name: "jumppad",
+					},
 				},
 			},
 			Container: build.BuildContainer{
@@ -1,13 +1,10 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path"
 
-	"github.com/docker/docker/api/types/filters"
-	dimage "github.com/docker/docker/api/types/image"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/images"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
@@ -15,7 +12,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func newPurgeCmd(dt container.Docker, il images.ImageLog, l logger.Logger) *cobra.Command {
+func newPurgeCmd(ct container.ContainerTasks, il images.ImageLog, l logger.Logger) *cobra.Command {
 	purgeCmd := &cobra.Command{
 		Use:   "purge",
 		Short: "Purges Docker images, Helm charts, and Blueprints downloaded by jumppad",
@@ -24,60 +21,29 @@ func newPurgeCmd(dt container.Docker, il images.ImageLog, l logger.Logger) *cobr
   jumppad purge
 	`,
 		Args:         cobra.ArbitraryArgs,
-		RunE:         newPurgeCmdFunc(dt, il, l),
+		RunE:         newPurgeCmdFunc(ct, il, l),
 		SilenceUsage: true,
 	}
 
 	return purgeCmd
 }
 
-func newPurgeCmdFunc(dt container.Docker, il images.ImageLog, l logger.Logger) func(cmd *cobra.Command, args []string) error {
+func newPurgeCmdFunc(ct container.ContainerTasks, il images.ImageLog, l logger.Logger) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		images, _ := il.Read(images.ImageTypeDocker)
-
 		bHasError := false
 
-		for _, i := range images {
-			l.Info("Removing image", "image", i)
-
-			_, err := dt.ImageRemove(context.Background(), i, dimage.RemoveOptions{Force: true, PruneChildren: true})
-			if err != nil {
-				l.Error("Unable to delete", "image", i, "error", err)
-			}
-		}
-		il.Clear()
-
-		// Remove any images which have been built
-		filter := filters.NewArgs()
-		filter.Add("reference", "jumppad.dev/localcache/*")
-
-		// check if the image already exists, if so do not rebuild unless force
-		sum, err := dt.ImageList(context.Background(), dimage.ListOptions{Filters: filter})
-		if err != nil {
-			l.Error("Unable to check image cache", "error", err)
-			bHasError = true
-		}
-
-		for _, i := range sum {
-			l.Info("Removing image", "image", i.ID)
-
-			_, err := dt.ImageRemove(context.Background(), i.ID, dimage.RemoveOptions{Force: true, PruneChildren: true})
-			if err != nil {
-				l.Error("Unable to delete", "image", i.ID, "error", err)
-				bHasError = true
-			}
-		}
-
-		l.Info("Removing Docker image cache")
-		err = dt.VolumeRemove(context.Background(), utils.FQDNVolumeName("images"), true)
-		if err != nil {
-			l.Error("Unable to remove cached image volume", "error", err)
+		// the cached folders are removed even when the container runtime is
+		// not available
+		if ct != nil {
+			bHasError = !purgeImages(ct, il, l)
+		} else {
+			l.Error("Unable to remove images, the container runtime is not available")
 			bHasError = true
 		}
 
 		hcp := utils.BlueprintLocalFolder("")
 		l.Info("Removing cached blueprints", "path", hcp)
-		err = os.RemoveAll(hcp)
+		err := os.RemoveAll(hcp)
 		if err != nil {
 			l.Error("Unable to remove cached blueprints", "error", err)
 			bHasError = true
@@ -131,3 +97,72 @@ func newPurgeCmdFunc(dt container.Docker, il images.ImageLog, l logger.Logger) f
 		return nil
 	}
 }
+
+// purgeImages removes the images pulled and built by jumppad, the image
+// cache volumes and the volumes bind mounts were synced to, returns false when any of the images could not be removed
+func purgeImages(ct container.ContainerTasks, il images.ImageLog, l logger.Logger) bool {
+	ok := true
+
+	images, _ := il.Read(images.ImageTypeDocker)
+	for _, i := range images {
+		l.Info("Removing image", "image", i)
+
+		err := ct.RemoveImage(i)
+		if err != nil {
+			l.Error("Unable to delete", "image", i, "error", err)
+		}
+	}
+	il.Clear()
+
+	// Remove any images which have been built
+	ids, err := ct.FindImagesInLocalRegistry(utils.BuildImagePrefix + "/*")
+	if err != nil {
+		l.Error("Unable to check image cache", "error", err)
+		ok = false
+	}
+
+	for _, i := range ids {
+		l.Info("Removing image", "image", i)
+
+		err := ct.RemoveImage(i)
+		if err != nil {
+			l.Error("Unable to delete", "image", i, "error", err)
+			ok = false
+		}
+	}
+
+	// each workspace has its own image cache volume
+	l.Info("Removing image cache")
+	vols, err := ct.FindVolumes("images*")
+	if err != nil {
+		l.Error("Unable to list cached image volumes", "error", err)
+		ok = false
+	}
+
+	for _, v := range vols {
+		err := ct.RemoveVolume(v)
+		if err != nil {
+			l.Error("Unable to remove cached image volume", "volume", v, "error", err)
+			ok = false
+		}
+	}
+
+	// remove the volumes that bind mounts were synced to for remote engines
+	vols, err = ct.FindVolumes("bind.*")
+	if err != nil {
+		l.Error("Unable to list synced volumes", "error", err)
+		ok = false
+	}
+
+	for _, v := range vols {
+		l.Info("Removing synced volume", "volume", v)
+
+		err := ct.RemoveVolume(v)
+		if err != nil {
+			l.Error("Unable to remove synced volume", "volume", v, "error", err)
+			ok = false
+		}
+	}
+
+	return ok
+}
@@ -74,7 +74,11 @@ func newPushCmd(ct container.ContainerTasks, l logger.Logger) *cobra.Command {
 }
 
 func pushK8sCluster(image string, c *k8s.Cluster, log logger.Logger, force bool) error {
-	cli, _ := clients.GenerateClients(log)
+	cli, err := clients.GenerateClients(log)
+	if err != nil {
+		return err
+	}
+
 	p := config.NewProviders(cli)
 	cl := p.GetProvider(c).(*k8s.ClusterProvider)
 
@@ -96,14 +100,18 @@ func pushK8sCluster(image string, c *k8s.Cluster, log logger.Logger, force bool)
 }
 
 func pushNomadCluster(image string, c *nomad.NomadCluster, log logger.Logger, force bool) error {
-	cli, _ := clients.GenerateClients(log)
+	cli, err := clients.GenerateClients(log)
+	if err != nil {
+		return err
+	}
+
 	p := config.NewProviders(cli)
 	cl := p.GetProvider(c).(*nomad.ClusterProvider)
 
 	// get the id of the cluster
 
 	log.Info("Pushing to container", "ref", c.Meta.ID, "image", image)
-	err := cl.ImportLocalDockerImages([]types.Image{{Name: strings.Trim(image, " ")}}, force)
+	err = cl.ImportLocalDockerImages([]types.Image{{Name: strings.Trim(image, " ")}}, force)
 	if err != nil {
 		return fmt.Errorf("error pushing image: %w ", err)
 	}
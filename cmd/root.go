@@ -53,7 +53,9 @@ func Execute(v, c, d string) error {
 	// setup dependencies
 	l := createLogger()
 
-	engineClients, _ := clients.GenerateClients(l)
+	// the clients are still returned when the container runtime is not
+	// available, commands which need the runtime return the error
+	engineClients, clientsErr := clients.GenerateClients(l)
 
 	engine, _ := createEngine(l, engineClients)
 
@@ -61,18 +63,35 @@ func Execute(v, c, d string) error {
 	rootCmd.AddCommand(outputCmd)
 	rootCmd.AddCommand(newDevCmd())
 	rootCmd.AddCommand(newEnvCmd())
-	rootCmd.AddCommand(newRunCmd(engine, engineClients.ContainerTasks, engineClients.Getter, engineClients.HTTP, engineClients.System, engineClients.Connector, l))
+	rootCmd.AddCommand(requireContainerRuntime(newRunCmd(engine, engineClients.ContainerTasks, engineClients.Getter, engineClients.HTTP, engineClients.System, engineClients.Connector, l), clientsErr))
 	rootCmd.AddCommand(newTestCmd())
 	rootCmd.AddCommand(newDestroyCmd(engineClients.Connector, l))
 	rootCmd.AddCommand(statusCmd)
-	rootCmd.AddCommand(newPurgeCmd(engineClients.Docker, engineClients.ImageLog, l))
+	rootCmd.AddCommand(newPurgeCmd(engineClients.ContainerTasks, engineClients.ImageLog, l))
 	rootCmd.AddCommand(taintCmd)
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(uninstallCmd)
-	rootCmd.AddCommand(newPushCmd(engineClients.ContainerTasks, l))
-	rootCmd.AddCommand(newLogCmd(engineClients.Docker, os.Stdout, os.Stderr), completionCmd)
+	rootCmd.AddCommand(requireContainerRuntime(newPushCmd(engineClients.ContainerTasks, l), clientsErr))
+	rootCmd.AddCommand(requireContainerRuntime(newLogCmd(engineClients.ContainerTasks, os.Stdout, os.Stderr), clientsErr), completionCmd)
 	rootCmd.AddCommand(changelogCmd)
 
+	// add the workspace commands
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(newWorkspaceListCmd())
+	workspaceCmd.AddCommand(newWorkspaceSelectCmd())
+	workspaceCmd.AddCommand(newWorkspaceDeleteCmd())
+
+	// add the snapshot commands
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(requireContainerRuntime(newSnapshotSaveCmd(engineClients.ContainerTasks), clientsErr))
+	snapshotCmd.AddCommand(requireContainerRuntime(newSnapshotRestoreCmd(engineClients.ContainerTasks), clientsErr))
+	snapshotCmd.AddCommand(newSnapshotListCmd())
+	snapshotCmd.AddCommand(requireContainerRuntime(newSnapshotDeleteCmd(engineClients.ContainerTasks), clientsErr))
+
+	// add the state commands
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(newStateUnlockCmd())
+
 	// add the server commands
 	rootCmd.AddCommand(connectorCmd)
 	connectorCmd.AddCommand(newConnectorRunCommand())
@@ -82,12 +101,16 @@ func Execute(v, c, d string) error {
 	// add the generate command
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.AddCommand(newGenerateReadmeCommand(engine))
+	generateCmd.AddCommand(newGenerateHCLCommand())
 
 	// add the plugin commands
 	rootCmd.AddCommand(pluginCmd)
 
 	// add the validate command
-	rootCmd.AddCommand(newValidateCmd(engine, engineClients.Getter))
+	rootCmd.AddCommand(newValidateCmd(engine, engineClients.ContainerTasks, engineClients.Getter))
+
+	// add the plan command
+	rootCmd.AddCommand(newPlanCmd(engine, engineClients.Getter))
 
 	// add the fmt command
 	rootCmd.AddCommand(newFormatCmd())
@@ -97,6 +120,12 @@ func Execute(v, c, d string) error {
 	// set a pre run function to show the changelog
 	rootCmd.PersistentFlags().Bool("non-interactive", false, "Run in non-interactive mode")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// set the workspace before any state is read
+		err := setWorkspaceFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
 		ni, _ := cmd.Flags().GetBool("non-interactive")
 		if ni {
 			return nil
@@ -107,7 +136,7 @@ func Execute(v, c, d string) error {
 		// replace """ with ``` in changelog
 		changes = strings.ReplaceAll(changes, `"""`, "```")
 
-		err := cl.Show(changes, changesVersion, false)
+		err = cl.Show(changes, changesVersion, false)
 		if err != nil {
 			showErr(err)
 			return err
@@ -134,6 +163,19 @@ func Execute(v, c, d string) error {
 	return err
 }
 
+// requireContainerRuntime returns the error creating the container runtime
+// client before the command runs, commands using the client would otherwise
+// panic when the runtime is not available
+func requireContainerRuntime(c *cobra.Command, err error) *cobra.Command {
+	if err != nil {
+		c.PreRunE = func(cmd *cobra.Command, args []string) error {
+			return err
+		}
+	}
+
+	return c
+}
+
 func showErr(err error) {
 	fmt.Println("")
 	fmt.Println(err)
@@ -0,0 +1,15 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore snapshots of the running resources",
+	Long: `Save and restore snapshots of the running resources
+
+A snapshot commits the filesystem of every container, sidecar and cluster node
+to an image and exports the volumes they use. Restoring a snapshot recreates
+the containers and volumes and resets the state without applying the blueprint.
+
+Snapshots are stored per workspace and are only supported by the Docker runtime.`,
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotDeleteCmd(ct container.ContainerTasks) *cobra.Command {
+	deleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a snapshot and the images it uses",
+		Long:  `Delete a snapshot and the images it uses`,
+		Example: `
+  jumppad snapshot delete exercise-2
+	`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := jumppad.DeleteSnapshot(ct, args[0])
+			if err != nil {
+				return fmt.Errorf("unable to delete snapshot: %s", err)
+			}
+
+			fmt.Printf("Deleted snapshot %s\n", args[0])
+
+			return nil
+		},
+	}
+
+	addWorkspaceFlag(deleteCmd)
+
+	return deleteCmd
+}
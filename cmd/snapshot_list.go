@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the snapshots for the workspace",
+		Long:         `List the snapshots for the workspace`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshots, err := jumppad.ListSnapshots()
+			if err != nil {
+				return err
+			}
+
+			for _, s := range snapshots {
+				fmt.Printf("%-30s %s\n", s.Name, s.Created.Format(time.RFC1123))
+			}
+
+			return nil
+		},
+	}
+
+	addWorkspaceFlag(listCmd)
+
+	return listCmd
+}
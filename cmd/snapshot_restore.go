@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotRestoreCmd(ct container.ContainerTasks) *cobra.Command {
+	var lockTimeout string
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [name]",
+		Short: "Restore a snapshot of the resources",
+		Long: `Restore a snapshot of the resources
+
+The containers and volumes in the snapshot are replaced with the contents at the
+time the snapshot was taken and the state is reset. Resources created after the
+snapshot was taken are not removed.`,
+		Example: `
+  jumppad snapshot restore exercise-2
+	`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lt, err := time.ParseDuration(lockTimeout)
+			if err != nil {
+				return fmt.Errorf("invalid lock timeout, please specify a duration using go syntax, e.g. 30s, 1m")
+			}
+
+			lock, err := config.LockState(lt)
+			if err != nil {
+				return fmt.Errorf("unable to lock state: %s", err)
+			}
+			defer lock.Unlock()
+
+			s, err := jumppad.RestoreSnapshot(ct, args[0])
+			if err != nil {
+				return fmt.Errorf("unable to restore snapshot: %s", err)
+			}
+
+			fmt.Printf("Restored snapshot %s taken at %s\n", s.Name, s.Created.Format(time.RFC1123))
+
+			return nil
+		},
+	}
+
+	addWorkspaceFlag(restoreCmd)
+	addLockTimeoutFlag(restoreCmd, &lockTimeout)
+
+	return restoreCmd
+}
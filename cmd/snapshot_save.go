@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotSaveCmd(ct container.ContainerTasks) *cobra.Command {
+	var lockTimeout string
+
+	saveCmd := &cobra.Command{
+		Use:   "save [name]",
+		Short: "Save a snapshot of the running resources",
+		Long: `Save a snapshot of the running resources
+
+Containers are paused while their filesystem is committed, an existing snapshot
+with the same name is replaced.`,
+		Example: `
+  jumppad snapshot save exercise-2
+	`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lt, err := time.ParseDuration(lockTimeout)
+			if err != nil {
+				return fmt.Errorf("invalid lock timeout, please specify a duration using go syntax, e.g. 30s, 1m")
+			}
+
+			lock, err := config.LockState(lt)
+			if err != nil {
+				return fmt.Errorf("unable to lock state: %s", err)
+			}
+			defer lock.Unlock()
+
+			s, err := jumppad.SaveSnapshot(ct, args[0])
+			if err != nil {
+				return fmt.Errorf("unable to save snapshot: %s", err)
+			}
+
+			fmt.Printf("Saved snapshot %s with %d containers and %d volumes\n", s.Name, len(s.Containers), len(s.Volumes))
+
+			return nil
+		},
+	}
+
+	addWorkspaceFlag(saveCmd)
+	addLockTimeoutFlag(saveCmd, &lockTimeout)
+
+	return saveCmd
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+// defaultLockTimeout is the time commands wait for the state lock by default,
+// commands fail immediately when the state is locked by another process
+const defaultLockTimeout = "0s"
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage the jumppad state",
+	Long:  `Manage the state that jumppad uses to track the resources it has created`,
+}
+
+// addLockTimeoutFlag adds the --lock-timeout flag to a command that modifies
+// the state, all commands share the same default
+func addLockTimeoutFlag(cmd *cobra.Command, lockTimeout *string) {
+	cmd.Flags().StringVarP(lockTimeout, "lock-timeout", "", defaultLockTimeout, "Duration to wait for the state lock when it is held by another process. E.g. --lock-timeout=1m")
+}
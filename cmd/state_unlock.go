@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newStateUnlockCmd() *cobra.Command {
+	unlockCmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Removes the lock from the state",
+		Long: `Removes the lock from the state
+
+The state is locked while jumppad modifies resources, should jumppad exit
+unexpectedly the lock may not be released, this command removes the lock
+regardless of the process that holds it.
+
+Only remove the lock when you are sure that no other process is using the state.`,
+		Example: `
+  jumppad state unlock
+	`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, err := config.StateLockInfo()
+			if err != nil {
+				return fmt.Errorf("unable to read state lock: %s", err)
+			}
+
+			if info != nil {
+				fmt.Printf("Removing lock held by: %s\n", info)
+			}
+
+			err = config.ForceUnlockState()
+			if err != nil {
+				return fmt.Errorf("unable to unlock state: %s", err)
+			}
+
+			fmt.Println("State unlocked")
+
+			return nil
+		},
+	}
+
+	return unlockCmd
+}
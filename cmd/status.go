@@ -2,17 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/hokaccha/go-prettyjson"
+	"github.com/jumppad-labs/hclconfig"
 	"github.com/jumppad-labs/hclconfig/resources"
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/cache"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/nomad"
 	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -40,8 +47,12 @@ var resourceType string
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show the status of the current resources",
-	Long:  `Show the status of the current resources`,
-	Args:  cobra.NoArgs,
+	Long: `Show the status of the current resources
+
+The health of containers and sidecars with a health_check is shown with the
+status. Exec checks are run periodically by the container engine, HTTP and
+TCP checks are run from the host each time status is run.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		// load the resources from state
 
@@ -52,14 +63,26 @@ var statusCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// health checks are run quietly, failures are shown in the status
+		engineClients, _ := clients.GenerateClients(logger.NewLogger(io.Discard, logger.LogLevelInfo))
+		health := resourceHealth(cfg, engineClients)
+
 		if jsonFlag {
+			for id, h := range health {
+				r, _ := cfg.FindResource(id)
+				r.Metadata().Properties[constants.PropertyHealth] = h
+			}
+
 			s, err := prettyjson.Marshal(cfg)
 			if err != nil {
 				fmt.Println("Unable to output state as JSON", err)
 				os.Exit(1)
 			}
 
-			fmt.Println(string(s))
+			// generated secrets like cluster tokens are stored in the state
+			// but are not shown
+			config.RegisterSensitiveValues(cfg.Resources)
+			fmt.Println(secrets.Redact(string(s)))
 		} else {
 			// fmt.Println()
 			// fmt.Printf("%-13s %-60s %s\n", "STATUS", "RESOURCE", "FQDN")
@@ -109,7 +132,7 @@ var statusCmd = &cobra.Command{
 
 					switch r.Metadata().Type {
 					case nomad.TypeNomadCluster:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
+						fmt.Printf("%s %s%s\n", status, r.Metadata().ID, attemptsText(r))
 						fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(fmt.Sprintf("%s.%s", "server", utils.FQDN(r.Metadata().Name, r.Metadata().Module, string(r.Metadata().Type)))))
 
 						// add the client nodes
@@ -118,18 +141,20 @@ var statusCmd = &cobra.Command{
 							fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(fmt.Sprintf("%d.%s.%s", n+1, "client", utils.FQDN(r.Metadata().Name, r.Metadata().Module, string(r.Metadata().Type)))))
 						}
 					case k8s.TypeK8sCluster:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
+						fmt.Printf("%s %s%s\n", status, r.Metadata().ID, attemptsText(r))
 						fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(fmt.Sprintf("%s.%s", "server", utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type))))
-					case container.TypeContainer:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
-						fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(utils.FQDN(r.Metadata().Name, r.Metadata().Module, string(r.Metadata().Type))))
-					case container.TypeSidecar:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
-						fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(utils.FQDN(r.Metadata().Name, r.Metadata().Module, string(r.Metadata().Type))))
+
+						// add the agent nodes
+						for _, a := range r.(*k8s.Cluster).AgentContainerNames {
+							fmt.Printf("    %s %s\n", grayText.Render("└─"), whiteText.Render(a))
+						}
+					case container.TypeContainer, container.TypeSidecar:
+						fmt.Printf("%s %s%s\n", status, r.Metadata().ID, attemptsText(r))
+						fmt.Printf("    %s %s%s\n", grayText.Render("└─"), whiteText.Render(utils.FQDN(r.Metadata().Name, r.Metadata().Module, string(r.Metadata().Type))), healthText(health[r.Metadata().ID]))
 					case cache.TypeImageCache:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
+						fmt.Printf("%s %s%s\n", status, r.Metadata().ID, attemptsText(r))
 					default:
-						fmt.Printf("%s %s\n", status, r.Metadata().ID)
+						fmt.Printf("%s %s%s\n", status, r.Metadata().ID, attemptsText(r))
 					}
 				}
 			}
@@ -152,7 +177,74 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// attemptsText returns the number of attempts the last create or refresh
+// needed when the resource was retried
+func attemptsText(r types.Resource) string {
+	n := 0
+	switch a := r.Metadata().Properties[constants.PropertyAttempts].(type) {
+	case int:
+		n = a
+	case float64:
+		n = int(a)
+	}
+
+	if n < 2 {
+		return ""
+	}
+
+	return grayText.Render(fmt.Sprintf(" (%d attempts)", n))
+}
+
+// resourceHealth returns the current health of the created containers and
+// sidecars that have health checks, keyed by resource id
+func resourceHealth(cfg *hclconfig.Config, cl *clients.Clients) map[string]*container.Health {
+	health := map[string]*container.Health{}
+	if cl == nil || cl.ContainerTasks == nil {
+		return health
+	}
+
+	for _, r := range cfg.Resources {
+		if r.GetDisabled() || r.Metadata().Properties[constants.PropertyStatus] != constants.StatusCreated {
+			continue
+		}
+
+		var hc *healthcheck.HealthCheckContainer
+		switch c := r.(type) {
+		case *container.Container:
+			hc = c.HealthCheck
+		case *container.Sidecar:
+			hc = c.HealthCheck
+		default:
+			continue
+		}
+
+		fqdn := utils.FQDN(r.Metadata().Name, r.Metadata().Module, r.Metadata().Type)
+		if h := container.ContainerHealth(cl.ContainerTasks, cl.HTTP, fqdn, hc, 2*time.Second); h != nil {
+			health[r.Metadata().ID] = h
+		}
+	}
+
+	return health
+}
+
+// healthText returns the formatted health of a container
+func healthText(h *container.Health) string {
+	if h == nil {
+		return ""
+	}
+
+	switch h.Status {
+	case container.HealthHealthy:
+		return " " + greenIcon.Render(h.Status)
+	case container.HealthStarting:
+		return " " + yellowIcon.Render(h.Status)
+	}
+
+	return " " + redIcon.Render(h.Status) + grayText.Render(" ("+h.Message+")")
+}
+
 func init() {
 	statusCmd.Flags().BoolVarP(&jsonFlag, "json", "", false, "Output the status as JSON")
 	statusCmd.Flags().StringVarP(&resourceType, "type", "", "", "Resource type used to filter status list")
+	addWorkspaceFlag(statusCmd)
 }
@@ -3,13 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
-	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+var taintLockTimeout string
+
 var taintCmd = &cobra.Command{
 	Use:   "taint [resource]",
 	Short: "Taint a resource e.g. 'jumppad taint container.test'",
@@ -24,26 +26,48 @@ var taintCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		cfg, err := config.LoadState()
+		lt, err := time.ParseDuration(taintLockTimeout)
 		if err != nil {
-			fmt.Println("Unable to load statefile, do you have a running blueprint?")
+			fmt.Println("Invalid lock timeout, please specify a duration using go syntax, e.g. 30s, 1m")
+			os.Exit(1)
+		}
+
+		lock, err := config.LockState(lt)
+		if err != nil {
+			fmt.Println("Unable to lock state", err)
+			os.Exit(1)
+		}
+
+		// os.Exit does not run deferred functions so the lock must be
+		// released before exiting
+		exit := func(msg ...any) {
+			fmt.Println(msg...)
+			lock.Unlock()
 			os.Exit(1)
 		}
 
+		cfg, err := config.LoadState()
+		if err != nil {
+			exit("Unable to load statefile, do you have a running blueprint?")
+		}
+
 		r, err := cfg.FindResource(args[0])
 		if err != nil || r == nil {
-			fmt.Println("Unable to locate resource in the state", args[0])
-			os.Exit(1)
+			exit("Unable to locate resource in the state", args[0])
 		}
 
 		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusTainted
 
-		d, err := cfg.ToJSON()
+		err = config.SaveState(cfg)
 		if err != nil {
-			fmt.Println("Unable to save state", err)
-			os.Exit(1)
+			exit("Unable to save state", err)
 		}
 
-		os.WriteFile(utils.StatePath(), d, os.ModePerm)
+		lock.Unlock()
 	},
 }
+
+func init() {
+	addWorkspaceFlag(taintCmd)
+	addLockTimeoutFlag(taintCmd, &taintLockTimeout)
+}
@@ -24,8 +24,6 @@ import (
 	"github.com/cucumber/godog"
 	"github.com/cucumber/godog/colors"
 	dcontainer "github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
-	dnetwork "github.com/docker/docker/api/types/network"
 	"github.com/jumppad-labs/hclconfig/resources"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
@@ -61,6 +59,7 @@ func newTestCmd() *cobra.Command {
 	var variables []string
 	var variablesFile string
 	var tags string
+	var parallelism int
 
 	var testCmd = &cobra.Command{
 		Use:                   "test [blueprint]",
@@ -68,7 +67,7 @@ func newTestCmd() *cobra.Command {
 		Long:                  `Run functional tests for the blueprint, this command will start the jumppad blueprint `,
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ArbitraryArgs,
-		RunE:                  newTestCmdFunc(testFolder, &force, &purge, &variables, &variablesFile, &tags, &dontDestroy),
+		RunE:                  newTestCmdFunc(testFolder, &force, &purge, &variables, &variablesFile, &tags, &dontDestroy, &parallelism),
 	}
 
 	testCmd.Flags().StringVarP(&testFolder, "test-folder", "", "", "Specify the folder containing the functional tests.")
@@ -76,7 +75,9 @@ func newTestCmd() *cobra.Command {
 	testCmd.Flags().BoolVarP(&purge, "purge", "", false, "When set to true jumppad will remove any cached images or blueprints")
 	testCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
 	testCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+	testCmd.Flags().IntVarP(&parallelism, "parallelism", "", jumppad.DefaultParallelism, "Maximum number of resources to create concurrently, 0 removes the limit. E.g. --parallelism=4")
 	testCmd.Flags().StringVarP(&tags, "tags", "", "", "Test tags to run e.g. @wip, @wip,@new, when not set all tests are run")
+	addWorkspaceFlag(testCmd)
 	testCmd.Flags().BoolVarP(&dontDestroy, "dont-destroy", "", false, "When set to true, jumppad does not destroy the blueprint after executing the tests")
 
 	return testCmd
@@ -90,6 +91,7 @@ func newTestCmdFunc(
 	variablesFile *string,
 	tags *string,
 	dontDestroy *bool,
+	parallelism *int,
 ) func(cmd *cobra.Command, args []string) error {
 
 	return func(cmd *cobra.Command, args []string) error {
@@ -103,6 +105,7 @@ func newTestCmdFunc(
 			variablesFile: *variablesFile,
 			tags:          *tags,
 			dontDestroy:   dontDestroy,
+			parallelism:   parallelism,
 		}
 
 		tr.start()
@@ -128,6 +131,7 @@ type CucumberRunner struct {
 	variablesFile string
 	tags          string
 	dontDestroy   *bool
+	parallelism   *int
 }
 
 // Initialize the functional tests
@@ -176,7 +180,12 @@ func (cr *CucumberRunner) initializeSuite(ctx *godog.ScenarioContext) {
 
 		cl := logger.NewLogger(sb, logger.LogLevelDebug)
 
-		cli, _ := clients.GenerateClients(cl)
+		cli, err := clients.GenerateClients(cl)
+		if err != nil {
+			fmt.Printf("Unable to setup tests: %s\n", err)
+			return ctx, err
+		}
+
 		engine, err := createEngine(cl, cli)
 		if err != nil {
 			fmt.Printf("Unable to setup tests: %s\n", err)
@@ -189,7 +198,7 @@ func (cr *CucumberRunner) initializeSuite(ctx *godog.ScenarioContext) {
 
 		// do we need to pure the cache
 		if *cr.purge {
-			pc := newPurgeCmdFunc(cr.cli.Docker, cr.cli.ImageLog, cr.cli.Logger)
+			pc := newPurgeCmdFunc(cr.cli.ContainerTasks, cr.cli.ImageLog, cr.cli.Logger)
 			pc(cr.cmd, cr.args)
 		}
 
@@ -266,6 +275,8 @@ func (cr *CucumberRunner) iRunApplyAtPath(path string) error {
 	args := []string{absPath}
 
 	noOpen := true
+	lockTimeout := defaultLockTimeout
+	targets := []string{}
 
 	// re-use the run command
 	rc := newRunCmdFunc(
@@ -279,6 +290,9 @@ func (cr *CucumberRunner) iRunApplyAtPath(path string) error {
 		cr.force,
 		&cr.variables,
 		&cr.variablesFile,
+		&lockTimeout,
+		cr.parallelism,
+		&targets,
 		cr.l,
 	)
 
@@ -315,9 +329,11 @@ func getLookupAddress(resourceName string) (string, string, int, error) {
 
 	switch res.Metadata().Type {
 	case network.TypeNetwork:
-		return res.Metadata().Name, res.Metadata().Type, 1, nil
+		// networks are found using the resource id
+		return res.Metadata().ID, res.Metadata().Type, 1, nil
 	case k8s.TypeK8sCluster:
-		return res.(*k8s.Cluster).ContainerName, res.Metadata().Type, 1, nil
+		cl := res.(*k8s.Cluster)
+		return cl.ContainerName, res.Metadata().Type, len(cl.AgentContainerNames) + 1, nil
 	case nomad.TypeNomadCluster:
 		cl := res.(*nomad.NomadCluster)
 		return cl.ServerContainerName, res.Metadata().Type, cl.ClientNodes + 1, nil
@@ -375,22 +391,28 @@ func (cr *CucumberRunner) thereShouldBeAResourceRunningCalled(id string) error {
 
 	// we need to check this a number of times to make sure it is not just a slow starting container
 	for i := 0; i < 100; i++ {
-		args := filters.NewArgs()
-		args.Add("name", id)
-		opts := dcontainer.ListOptions{Filters: args, All: true}
-
-		cl, err := cr.cli.Docker.ContainerList(context.Background(), opts)
+		ids, err := cr.cli.ContainerTasks.FindContainerIDs(id)
 		if err != nil {
 			return err
 		}
 
-		for _, c := range cl {
+		for _, cid := range ids {
+			info, err := cr.cli.ContainerTasks.ContainerInfo(cid)
+			if err != nil {
+				return err
+			}
+
+			ci, ok := info.(dcontainer.InspectResponse)
+			if !ok || ci.State == nil {
+				continue
+			}
+
 			// check to see if the container has failed
-			if c.State == "exited" {
+			if ci.State.Status == "exited" {
 				return fmt.Errorf("container exited prematurely")
 			}
 
-			if c.State == "running" {
+			if ci.State.Status == "running" {
 				checkCount++
 			}
 		}
@@ -407,16 +429,9 @@ func (cr *CucumberRunner) thereShouldBeAResourceRunningCalled(id string) error {
 }
 
 func (cr *CucumberRunner) thereShouldBe1NetworkCalled(arg1 string) error {
-	args := filters.NewArgs()
-	args.Add("name", arg1)
-	n, err := cr.cli.Docker.NetworkList(context.Background(), dnetwork.ListOptions{Filters: args})
-
+	_, err := cr.cli.ContainerTasks.FindNetwork(arg1)
 	if err != nil {
-		return err
-	}
-
-	if len(n) != 1 {
-		return fmt.Errorf("expected 1 network called %s to be created", arg1)
+		return fmt.Errorf("expected 1 network called %s to be created: %s", arg1, err)
 	}
 
 	return nil
@@ -778,7 +793,7 @@ func (cr *CucumberRunner) getJSONPath(path, resource string) (string, error) {
 	}
 
 	id := utils.FQDN(fqdn.Resource, fqdn.Module, fqdn.Type)
-	ci, err := cr.cli.Docker.ContainerInspect(context.Background(), id)
+	ci, err := cr.cli.ContainerTasks.ContainerInfo(id)
 	if err != nil {
 		return "", err
 	}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/jumppad-labs/hclconfig/resources"
 
+	"github.com/jumppad-labs/jumppad/cmd/view"
 	"github.com/jumppad-labs/jumppad/pkg/clients/connector"
 	cclients "github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/getter"
@@ -38,6 +39,9 @@ func newRunCmd(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Getter, h
 	var force bool
 	var variables []string
 	var variablesFile string
+	var lockTimeout string
+	var parallelism int
+	var targets []string
 
 	runCmd := &cobra.Command{
 		Use:   "up [file] | [directory]",
@@ -54,7 +58,7 @@ func newRunCmd(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Getter, h
   jumppad up github.com/jumppad-labs/blueprints/kubernetes-vault
 	`,
 		Args:         cobra.ArbitraryArgs,
-		RunE:         newRunCmdFunc(e, dt, bp, hc, bc, cc, &noOpen, &force, &variables, &variablesFile, l),
+		RunE:         newRunCmdFunc(e, dt, bp, hc, bc, cc, &noOpen, &force, &variables, &variablesFile, &lockTimeout, &parallelism, &targets, l),
 		SilenceUsage: true,
 	}
 
@@ -62,15 +66,32 @@ func newRunCmd(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Getter, h
 	runCmd.Flags().BoolVarP(&force, "force-update", "", false, "When set to true Jumppad ignores cached images or files and will download all resources")
 	runCmd.Flags().StringSliceVarP(&variables, "var", "", nil, "Allows setting variables from the command line, variables are specified as a key and value, e.g --var key=value. Can be specified multiple times")
 	runCmd.Flags().StringVarP(&variablesFile, "vars-file", "", "", "Load variables from a location other than *.vars files in the blueprint folder. E.g --vars-file=./file.vars")
+	addWorkspaceFlag(runCmd)
+	addLockTimeoutFlag(runCmd, &lockTimeout)
+	runCmd.Flags().IntVarP(&parallelism, "parallelism", "", jumppad.DefaultParallelism, "Maximum number of resources to create concurrently, 0 removes the limit. E.g. --parallelism=4")
+	runCmd.Flags().StringSliceVarP(&targets, "target", "", nil, "Only create the given resource and its dependencies, e.g. --target resource.container.api. Can be specified multiple times")
 
 	return runCmd
 }
 
-func newRunCmdFunc(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Getter, hc http.HTTP, bc system.System, cc connector.Connector, noOpen *bool, force *bool, variables *[]string, variablesFile *string, l logger.Logger) func(cmd *cobra.Command, args []string) error {
+func newRunCmdFunc(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Getter, hc http.HTTP, bc system.System, cc connector.Connector, noOpen *bool, force *bool, variables *[]string, variablesFile *string, lockTimeout *string, parallelism *int, targets *[]string, l logger.Logger) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		// create the shipyard and sub folders in the users home directory
 		utils.CreateFolders()
 
+		lt, err := time.ParseDuration(*lockTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid lock timeout %s, please specify a duration using go syntax, e.g. 30s, 1m", *lockTimeout)
+		}
+
+		e.SetLockTimeout(lt)
+		e.SetParallelism(*parallelism)
+
+		err = e.SetTargets(*targets)
+		if err != nil {
+			return err
+		}
+
 		if *force {
 			bp.SetForce(true)
 			dt.SetForce(true)
@@ -139,7 +160,7 @@ func newRunCmdFunc(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Gette
 			cmd.Println("Running configuration from ", dst, " -- press ctrl c to cancel")
 			cmd.Println("")
 
-			if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) {
+			if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) && !utils.IsComposeFile(dst) {
 				// fetch the remote server from github
 				err := bp.Get(dst, utils.BlueprintLocalFolder(dst))
 				if err != nil {
@@ -175,7 +196,19 @@ func newRunCmdFunc(e jumppad.Engine, dt cclients.ContainerTasks, bp getter.Gette
 			cancel()
 		}()
 
+		// show the progress of the resources and publish it to the API server
+		// for IDE integrations, rendering and publishing use separate
+		// subscriptions so that a slow API server does not delay the output
+		stopProgress := handleEvents(e, func(ev jumppad.Event) { view.LogEvent(l, ev) })
+		stopPublish := handleEvents(e, eventPublisher(eventsAPIAddr(), l))
+
 		config, err := e.ApplyWithVariables(ctx, dst, vars, *variablesFile)
+
+		// wait for the queued events to be written before continuing so that
+		// the apply finished event is not lost when the command exits
+		stopProgress()
+		stopPublish()
+
 		if err != nil {
 			return err
 		}
@@ -320,6 +353,13 @@ func buildBrowserPath(n, p string, resourceType string, path string) string {
 	}
 
 	ty := resourceType
+	host := utils.FQDN(n, "", ty)
+
+	// the fqdn resolves to the loopback address, when the Docker host is
+	// remote the ports are published on the remote engine
+	if utils.IsRemoteDockerHost() {
+		host = utils.GetDockerIP()
+	}
 
-	return fmt.Sprintf("http://%s:%s%s", utils.FQDN(n, "", ty), p, path)
+	return fmt.Sprintf("http://%s:%s%s", host, p, path)
 }
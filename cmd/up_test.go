@@ -18,11 +18,13 @@ import (
 	httpmock "github.com/jumppad-labs/jumppad/pkg/clients/http/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	systemmock "github.com/jumppad-labs/jumppad/pkg/clients/system/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/blueprint"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/docs"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/ingress"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/nomad"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
 	enginemocks "github.com/jumppad-labs/jumppad/pkg/jumppad/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/jumppad-labs/jumppad/testutils"
@@ -89,6 +91,13 @@ func setupRun(t *testing.T) (*cobra.Command, *runMocks) {
 	mockEngine.On("ApplyWithVariables", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&hclconfig, nil)
 	mockEngine.On("GetClients", mock.Anything).Return(clients)
 	mockEngine.On("ResourceCountForType", mock.Anything).Return(0)
+	mockEngine.On("SetLockTimeout", mock.Anything)
+	mockEngine.On("SetParallelism", mock.Anything)
+	mockEngine.On("SetTargets", mock.Anything).Return(nil)
+	mockEngine.On("Subscribe").Return(func() (<-chan jumppad.Event, func()) {
+		ch := make(chan jumppad.Event)
+		return ch, func() { close(ch) }
+	})
 
 	bp := blueprint.Blueprint{}
 
@@ -121,6 +130,13 @@ func TestRunSetsForceOnClients(t *testing.T) {
 	rm.tasks.AssertCalled(t, "SetForce", true)
 }
 
+func TestRunAndDevUseSameLockTimeoutDefault(t *testing.T) {
+	rf, _ := setupRun(t)
+
+	require.Equal(t, defaultLockTimeout, rf.Flags().Lookup("lock-timeout").DefValue)
+	require.Equal(t, defaultLockTimeout, newDevCmd().Flags().Lookup("lock-timeout").DefValue)
+}
+
 func TestRunChecksForCertBundle(t *testing.T) {
 	rf, rm := setupRun(t)
 	rf.SetArgs([]string{"/tmp"})
@@ -202,6 +218,26 @@ func TestRunSetsDestinationFromArgsWhenPresent(t *testing.T) {
 	rm.engine.AssertCalled(t, "ApplyWithVariables", mock.Anything, "/tmp", mock.Anything, mock.Anything)
 }
 
+func TestRunSetsParallelismFromFlag(t *testing.T) {
+	rf, rm := setupRun(t)
+	rf.SetArgs([]string{"--parallelism=4", "/tmp"})
+
+	err := rf.Execute()
+	require.NoError(t, err)
+
+	rm.engine.AssertCalled(t, "SetParallelism", 4)
+}
+
+func TestRunSetsTargetsFromFlag(t *testing.T) {
+	rf, rm := setupRun(t)
+	rf.SetArgs([]string{"--target=resource.container.api", "--target=module.consul.resource.container.server", "/tmp"})
+
+	err := rf.Execute()
+	require.NoError(t, err)
+
+	rm.engine.AssertCalled(t, "SetTargets", []string{"resource.container.api", "module.consul.resource.container.server"})
+}
+
 func TestRunSetsVariablesFromFlag(t *testing.T) {
 	rf, rm := setupRun(t)
 	rf.SetArgs([]string{
@@ -215,7 +251,12 @@ func TestRunSetsVariablesFromFlag(t *testing.T) {
 	err := rf.Execute()
 	require.NoError(t, err)
 
-	args := rm.engine.Calls[0].Arguments[2]
+	var args interface{}
+	for _, c := range rm.engine.Calls {
+		if c.Method == "ApplyWithVariables" {
+			args = c.Arguments[2]
+		}
+	}
 
 	require.Equal(t, map[string]string{
 		"abc":  "1234",
@@ -290,16 +331,16 @@ func TestRunOpensBrowserWindowForResources(t *testing.T) {
 	testutils.RemoveOn(&rm.engine.Mock, "ApplyWithVariables")
 
 	// should open
-	d := &docs.Docs{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "docs"}}}
+	d := &docs.Docs{ResourceOptions: config.ResourceOptions{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "docs"}}}}
 	d.OpenInBrowser = true
 
 	// should open
-	i := &ingress.Ingress{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "ingress"}}}
+	i := &ingress.Ingress{ResourceOptions: config.ResourceOptions{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "ingress"}}}}
 	i.Port = 8080
 	i.OpenInBrowser = "/"
 
 	// should open
-	c := &container.Container{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "container"}}}
+	c := &container.Container{ResourceOptions: config.ResourceOptions{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "container"}}}}
 	c.Ports = []container.Port{{Host: "8080", OpenInBrowser: "https://test.container.jumppad.dev:8080"}}
 
 	// should not be opened
@@ -314,7 +355,7 @@ func TestRunOpensBrowserWindowForResources(t *testing.T) {
 	d2 := &docs.Docs{}
 
 	// should be opened
-	n1 := &nomad.NomadCluster{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "nomad_cluster"}}}
+	n1 := &nomad.NomadCluster{ResourceOptions: config.ResourceOptions{ResourceBase: hcltypes.ResourceBase{Meta: hcltypes.Meta{Name: "test", Type: "nomad_cluster"}}}}
 	n1.OpenInBrowser = true
 	n1.APIPort = 4646
 
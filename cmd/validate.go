@@ -5,13 +5,14 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/getter"
 	"github.com/jumppad-labs/jumppad/pkg/jumppad"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
-func newValidateCmd(e jumppad.Engine, bp getter.Getter) *cobra.Command {
+func newValidateCmd(e jumppad.Engine, ct container.ContainerTasks, bp getter.Getter) *cobra.Command {
 	var variables []string
 	var variablesFile string
 
@@ -30,7 +31,7 @@ func newValidateCmd(e jumppad.Engine, bp getter.Getter) *cobra.Command {
   jumppad validate github.com/jumppad-labs/blueprints/kubernetes-vault
 	`,
 		Args:         cobra.ArbitraryArgs,
-		RunE:         newValidateCmdFunc(e, bp, &variables, &variablesFile),
+		RunE:         newValidateCmdFunc(e, ct, bp, &variables, &variablesFile),
 		SilenceUsage: true,
 	}
 
@@ -40,7 +41,7 @@ func newValidateCmd(e jumppad.Engine, bp getter.Getter) *cobra.Command {
 	return validateCmd
 }
 
-func newValidateCmdFunc(e jumppad.Engine, bp getter.Getter, variables *[]string, variablesFile *string) func(cmd *cobra.Command, args []string) error {
+func newValidateCmdFunc(e jumppad.Engine, ct container.ContainerTasks, bp getter.Getter, variables *[]string, variablesFile *string) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		// create the jumppad and sub folders in the users home directory
 		utils.CreateFolders()
@@ -81,7 +82,7 @@ func newValidateCmdFunc(e jumppad.Engine, bp getter.Getter, variables *[]string,
 
 		if dst != "" {
 			cmd.Printf("Validating configuration from '%s':\n", dst)
-			if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) {
+			if !utils.IsLocalFolder(dst) && !utils.IsHCLFile(dst) && !utils.IsComposeFile(dst) {
 				// fetch the remote server from github
 				bp.SetForce(true)
 				err := bp.Get(dst, utils.BlueprintLocalFolder(dst))
@@ -93,11 +94,20 @@ func newValidateCmdFunc(e jumppad.Engine, bp getter.Getter, variables *[]string,
 			}
 		}
 
-		_, err := e.ParseConfigWithVariables(dst, vars, *variablesFile)
+		cfg, err := e.ParseConfigWithVariables(dst, vars, *variablesFile)
 		if err != nil {
 			return err
 		}
 
+		// images that have already been pulled are checked against the image
+		// policies, other images are checked when the configuration is applied
+		if ct != nil {
+			err = jumppad.CheckImagePolicies(ct, cfg)
+			if err != nil {
+				return err
+			}
+		}
+
 		cmd.Println()
 		cmd.Println("Success! The configuration is valid")
 
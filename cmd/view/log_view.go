@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
 )
 
 type LogView struct {
@@ -57,3 +59,24 @@ func (c *LogView) UpdateStatus(message string, withTimer bool) {
 
 	c.Logger().Info(message)
 }
+
+// ShowEvent logs the completion of resource operations, started events
+// are not shown
+func (c *LogView) ShowEvent(e jumppad.Event) {
+	LogEvent(c.Logger(), e)
+}
+
+// LogEvent writes the completion of resource operations to the given logger,
+// started events are not logged
+func LogEvent(l logger.Logger, e jumppad.Event) {
+	switch e.Type {
+	case jumppad.EventResourceStarted:
+		return
+	case jumppad.EventResourceFailed:
+		l.Error("Resource failed", "resource", e.Resource, "duration", e.Duration.String(), "error", e.Error)
+	case jumppad.EventApplyFinished:
+		l.Info("Apply finished", "duration", e.Duration.String(), "error", e.Error)
+	default:
+		l.Info(fmt.Sprintf("Resource %s", strings.TrimPrefix(string(e.Type), "resource_")), "resource", e.Resource, "duration", e.Duration.String())
+	}
+}
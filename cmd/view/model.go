@@ -28,6 +28,7 @@ type model struct {
 
 	viewport  viewport.Model
 	statusbar StatusModel
+	progress  ProgressModel
 	messages  []string
 	follow    bool
 	logger    logger.Logger
@@ -70,6 +71,7 @@ func initialModel() model {
 	return model{
 		messages:  []string{},
 		statusbar: status,
+		progress:  NewProgress(),
 		follow:    true,
 		left:      1,
 	}
@@ -164,6 +166,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, cmd
 
+	case EventMsg:
+		var cmd tea.Cmd
+		m.progress, cmd = m.progress.Update(msg)
+
+		return m, cmd
+
 	// we handle errors just like any other message
 	case ErrMsg:
 		//m.err = msg
@@ -193,7 +201,9 @@ func (m model) viewportView() string {
 
 func (m model) headerView() string {
 	title := "Jumppad Dev Mode"
-	return lipgloss.JoinVertical(lipgloss.Top, title, "")
+	progress := lipgloss.NewStyle().MaxWidth(m.width - m.left).Render(m.progress.View())
+
+	return lipgloss.JoinVertical(lipgloss.Top, title, progress)
 }
 
 func (m model) footerView() string {
@@ -0,0 +1,101 @@
+package view
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+)
+
+// EventMsg updates the resource progress with an event from the engine
+type EventMsg jumppad.Event
+
+// ProgressModel shows the progress of the resources being processed by the
+// engine
+type ProgressModel struct {
+	// running contains the resources currently being processed and the time
+	// processing started
+	running map[string]time.Time
+	counts  map[jumppad.EventType]int
+	last    string
+}
+
+func NewProgress() ProgressModel {
+	return ProgressModel{
+		running: map[string]time.Time{},
+		counts:  map[jumppad.EventType]int{},
+	}
+}
+
+func (m ProgressModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ProgressModel) Update(msg tea.Msg) (ProgressModel, tea.Cmd) {
+	if msg, ok := msg.(EventMsg); ok {
+		switch msg.Type {
+		case jumppad.EventResourceStarted:
+			// a new apply clears the previous counts
+			if len(m.running) == 0 && m.last != "" {
+				m.counts = map[jumppad.EventType]int{}
+				m.last = ""
+			}
+
+			m.running[msg.Resource] = msg.Time
+		case jumppad.EventApplyFinished:
+			m.running = map[string]time.Time{}
+			m.last = fmt.Sprintf("finished in %s", msg.Duration.Round(time.Second))
+
+			if msg.Error != "" {
+				m.last = fmt.Sprintf("failed after %s", msg.Duration.Round(time.Second))
+			}
+		default:
+			delete(m.running, msg.Resource)
+			m.counts[msg.Type]++
+		}
+	}
+
+	return m, nil
+}
+
+func (m ProgressModel) View() string {
+	if len(m.running) == 0 && len(m.counts) == 0 {
+		return ""
+	}
+
+	summary := fmt.Sprintf(
+		"%d created, %d refreshed, %d destroyed, %d failed",
+		m.counts[jumppad.EventResourceCreated],
+		m.counts[jumppad.EventResourceRefreshed],
+		m.counts[jumppad.EventResourceDestroyed],
+		m.counts[jumppad.EventResourceFailed],
+	)
+
+	if m.last != "" {
+		summary = fmt.Sprintf("%s, %s", summary, m.last)
+	}
+
+	ids := []string{}
+	for id := range m.running {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	running := []string{}
+	for _, id := range ids {
+		et := time.Since(m.running[id])
+		running = append(running, fmt.Sprintf("%s (%ds)", id, int(math.Round(float64(et)/float64(time.Second)))))
+	}
+
+	if len(running) > 0 {
+		summary = fmt.Sprintf("%s, in progress: %s", summary, strings.Join(running, ", "))
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("37")).Render(summary)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -60,3 +61,8 @@ func (c *TTYView) Logger() logger.Logger {
 func (c *TTYView) UpdateStatus(message string, withTimer bool) {
 	c.program.Send(StatusMsg{Message: message, ShowElapsed: withTimer})
 }
+
+// ShowEvent updates the progress of the resources with the given engine event
+func (c *TTYView) ShowEvent(e jumppad.Event) {
+	c.program.Send(EventMsg(e))
+}
@@ -1,6 +1,9 @@
 package view
 
-import "github.com/jumppad-labs/jumppad/pkg/clients/logger"
+import (
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad"
+)
 
 type View interface {
 
@@ -14,4 +17,7 @@ type View interface {
 	// the elapsed time that the the status has been shown for will also
 	// be displayed
 	UpdateStatus(message string, withTimer bool)
+
+	// ShowEvent updates the progress of the resources with the given engine event
+	ShowEvent(e jumppad.Event)
 }
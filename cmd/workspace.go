@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage workspaces",
+	Long: `Manage workspaces
+
+Workspaces allow multiple blueprints to run at the same time, each workspace
+has its own state, data folders, and resource names. The workspace for a single
+command can be set with the --workspace flag or the JUMPPAD_WORKSPACE environment
+variable.
+
+Networks are shared by all workspaces, blueprints that run at the same time
+must use unique network names and subnets.`,
+}
+
+// addWorkspaceFlag adds the --workspace flag to a command, the flag is
+// applied by the root command before the command runs
+func addWorkspaceFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("workspace", "", "", "Workspace to use for this command, defaults to the selected workspace. E.g. --workspace=nomad")
+}
+
+// setWorkspaceFromFlags sets the workspace for the current process when the
+// --workspace flag has been specified, otherwise the workspace set with
+// JUMPPAD_WORKSPACE is validated
+func setWorkspaceFromFlags(cmd *cobra.Command) error {
+	f := cmd.Flags().Lookup("workspace")
+	if f == nil || f.Value.String() == "" {
+		// the workspace can also be set with the environment variable
+		return utils.ValidateWorkspaceEnv()
+	}
+
+	ws := f.Value.String()
+	err := utils.ValidateWorkspaceName(ws)
+	if err != nil {
+		return fmt.Errorf("invalid workspace '%s': %s", ws, err)
+	}
+
+	return os.Setenv(utils.WorkspaceEnvVar, ws)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceDeleteCmd() *cobra.Command {
+	var force bool
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a workspace",
+		Long: `Delete a workspace and its data folders
+
+A workspace that contains resources can not be deleted, run 'jumppad down --workspace [name]'
+first to remove the resources, or use --force to delete the workspace and leave
+the resources running.`,
+		Example: `
+  jumppad workspace delete nomad
+	`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			err := utils.ValidateWorkspaceName(name)
+			if err != nil {
+				return err
+			}
+
+			if name == utils.DefaultWorkspace {
+				return fmt.Errorf("the default workspace can not be deleted")
+			}
+
+			if name == utils.Workspace() {
+				return fmt.Errorf("unable to delete the current workspace, select a different workspace first")
+			}
+
+			dir := utils.WorkspaceHome(name)
+			if _, err := os.Stat(dir); err != nil {
+				return fmt.Errorf("workspace '%s' does not exist", name)
+			}
+
+			if !force && utils.WorkspaceHasState(name) {
+				return fmt.Errorf("workspace '%s' contains resources, run 'jumppad down --workspace %s' before deleting the workspace", name, name)
+			}
+
+			err = os.RemoveAll(dir)
+			if err != nil {
+				return fmt.Errorf("unable to delete workspace '%s': %s", name, err)
+			}
+
+			fmt.Printf("Deleted workspace '%s'\n", name)
+
+			return nil
+		},
+	}
+
+	deleteCmd.Flags().BoolVarP(&force, "force", "", false, "Delete the workspace even when it contains resources")
+
+	return deleteCmd
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "list",
+		Short:        "List the workspaces",
+		Long:         `List the workspaces, the current workspace is marked with an *`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ws, err := utils.ListWorkspaces()
+			if err != nil {
+				return err
+			}
+
+			current := utils.Workspace()
+			for _, w := range ws {
+				if w == current {
+					fmt.Printf("* %s\n", w)
+					continue
+				}
+
+				fmt.Printf("  %s\n", w)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newWorkspaceSelectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "select [name]",
+		Short: "Select the workspace used by subsequent commands",
+		Long: `Select the workspace used by subsequent commands,
+the workspace is created if it does not exist`,
+		Example: `
+  jumppad workspace select nomad
+	`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := utils.SelectWorkspace(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Switched to workspace '%s'\n", args[0])
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,3 @@
+module "compose" {
+  source = compose("${dir()}/docker-compose.yml")
+}
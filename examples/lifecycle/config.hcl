@@ -0,0 +1,16 @@
+resource "network" "onprem" {
+  subnet = "10.6.0.0/16"
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = ["subnet"]
+  }
+}
+
+resource "random_password" "cloud" {
+  length = 16
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
@@ -0,0 +1,9 @@
+resource "network" "flaky" {
+  subnet = "10.8.0.0/16"
+
+  retry {
+    attempts        = 3
+    initial_backoff = "1ms"
+    max_backoff     = "2ms"
+  }
+}
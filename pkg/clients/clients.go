@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients/command"
@@ -34,7 +35,9 @@ type Clients struct {
 	TarGz          *tar.TarGz
 }
 
-// GenerateClients creates the various clients for creating and destroying resources
+// GenerateClients creates the various clients for creating and destroying resources.
+// An error is returned along with the clients when the client for the
+// container runtime can not be created, in this case ContainerTasks is nil
 func GenerateClients(l logger.Logger) (*Clients, error) {
 	dc, _ := container.NewDocker()
 
@@ -56,12 +59,14 @@ func GenerateClients(l logger.Logger) (*Clients, error) {
 
 	tgz := &tar.TarGz{}
 
-	ct, _ := container.NewDockerTasks(dc, il, tgz, l)
+	// the container tasks use the runtime selected by JUMPPAD_CONTAINER_RUNTIME
+	// Docker is the default and also supports Podman
+	ct, ctErr := container.NewContainerTasks(utils.ContainerRuntime(), il, tgz, l)
 
 	co := connector.DefaultConnectorOptions()
 	cc := connector.NewConnector(co)
 
-	return &Clients{
+	c := &Clients{
 		ContainerTasks: ct,
 		Docker:         dc,
 		Kubernetes:     kc,
@@ -75,5 +80,11 @@ func GenerateClients(l logger.Logger) (*Clients, error) {
 		ImageLog:       il,
 		Connector:      cc,
 		TarGz:          tgz,
-	}, nil
+	}
+
+	if ctErr != nil {
+		return c, fmt.Errorf("unable to create client for container runtime %s: %w", utils.ContainerRuntime(), ctErr)
+	}
+
+	return c, nil
 }
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"context"
 	"io"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
@@ -27,6 +28,10 @@ type ContainerTasks interface {
 	ContainerInfo(id string) (interface{}, error)
 	// RemoveContainer stops and removes a running container
 	RemoveContainer(id string, force bool) error
+	// WaitForContainer blocks until the container exits and returns the exit code,
+	// an error is returned when the container does not exit within the timeout
+	// in seconds
+	WaitForContainer(id string, timeout int) (int, error)
 	// BuildContainer builds a container based on the given configuration
 	// If a cached image already exists Build will noop
 	// When force is specified BuildContainer will rebuild the container regardless of cached images
@@ -37,6 +42,10 @@ type ContainerTasks interface {
 	CreateVolume(name string) (id string, err error)
 	// RemoveVolume removes a volume with the given name
 	RemoveVolume(name string) error
+	// FindVolumes returns the names of the volumes that match the given name,
+	// the name can contain wildcards. The returned names can be passed to
+	// RemoveVolume
+	FindVolumes(name string) ([]string, error)
 	// FindImageInLocalRegistry returns the unique identifier for an image specified by the given
 	// tag in the local registry. If no image is found the function returns an
 	// empty id and no error
@@ -56,6 +65,9 @@ type ContainerTasks interface {
 	// If the force parameter is set then PullImage will pull regardless of the image already
 	// being cached locally.
 	PullImage(image types.Image, force bool) error
+	// InspectImage returns the metadata for the image with the given name or id
+	// in the local registry, an error is returned when the image does not exist
+	InspectImage(name string) (*types.ImageInfo, error)
 	// PushImage pushes an image to the registry
 	PushImage(image types.Image) error
 	// FindContainerIDs returns the Container IDs for the given container name
@@ -66,6 +78,10 @@ type ContainerTasks interface {
 	// io.ReadCloser.
 	// Returns an error if the container is not running
 	ContainerLogs(id string, stdOut, stdErr bool) (io.ReadCloser, error)
+	// FollowContainerLogs writes the last lines of the container logs and
+	// any new output to stdOut and stdErr, it blocks until the container
+	// stops or the context is cancelled
+	FollowContainerLogs(ctx context.Context, id string, lines int, stdOut, stdErr io.Writer) error
 	// CopyFromContainer allows the copying of a file from a container
 	CopyFromContainer(id, src, dst string) error
 	// CopyToContainer allows a file to be copied into a container
@@ -73,6 +89,9 @@ type ContainerTasks interface {
 	// CreateFileInContainer creates a file with the given contents and name in the container containerID and
 	// stores it in the container at the directory path.
 	CreateFileInContainer(containerID, contents, filename, path string) error
+	// CopyFilesToContainer writes the files to the container, any missing
+	// parent directories are created and the mode of the file is set
+	CopyFilesToContainer(containerID string, files []types.File) error
 	// CopyLocaDockerImageToVolume copies the docker images to the docker volume as a
 	// compressed archive.
 	// the path in the docker volume where the archive is created is returned
@@ -102,6 +121,8 @@ type ContainerTasks interface {
 	ListNetworks(id string) []types.NetworkAttachment
 	// FindNetwork returns a network using the unique resource id
 	FindNetwork(id string) (types.NetworkAttachment, error)
+	// NetworkTasks manages the networks used by the network resource
+	NetworkTasks
 
 	// CreateShell in the running container and attach
 	CreateShell(id string, command []string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error
@@ -109,6 +130,18 @@ type ContainerTasks interface {
 	// TagImage tags an image with the given tag
 	TagImage(source, destination string) error
 
+	// SaveContainer commits the filesystem of the container to the image and
+	// returns a snapshot that can be used to recreate the container
+	SaveContainer(id, image string) (*types.ContainerSnapshot, error)
+	// RestoreContainer recreates and starts the container from the snapshot,
+	// any existing container with the same name is removed
+	RestoreContainer(snapshot *types.ContainerSnapshot) (string, error)
+	// ExportVolume writes the contents of the volume to w as a tar archive
+	ExportVolume(name string, w io.Writer) error
+	// ImportVolume replaces the contents of the volume with the tar archive
+	// read from r, the volume is created if it does not exist
+	ImportVolume(name string, r io.Reader) error
+
 	// Returns basic information related to the Docker Engine
 	EngineInfo() *types.EngineInfo
 }
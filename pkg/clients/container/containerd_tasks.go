@@ -0,0 +1,1438 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
+	dtypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/images"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	ctar "github.com/jumppad-labs/jumppad/pkg/clients/tar"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+var _ ContainerTasks = &ContainerdTasks{}
+
+// ContainerdTasks is a concrete implementation of ContainerTasks which uses
+// containerd through the nerdctl CLI
+type ContainerdTasks struct {
+	storageDriver string
+	memory        int
+	cpu           int
+	c             Nerdctl
+	il            images.ImageLog
+	l             logger.Logger
+	tg            *ctar.TarGz
+	force         bool
+	defaultWait   time.Duration
+}
+
+// NewContainerdTasks creates a ContainerdTasks with the given nerdctl client
+func NewContainerdTasks(c Nerdctl, il images.ImageLog, tg *ctar.TarGz, l logger.Logger) (*ContainerdTasks, error) {
+	ct := &ContainerdTasks{c: c, il: il, tg: tg, l: l, defaultWait: 1 * time.Second}
+
+	out, err := ct.run("info", "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("error checking containerd info, error: %s", err)
+	}
+
+	info := system.Info{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse containerd info: %s", err)
+	}
+
+	ct.storageDriver = storageDriverForSnapshotter(info.Driver)
+	ct.cpu = info.NCPU
+	ct.memory = int(info.MemTotal)
+
+	return ct, nil
+}
+
+func (c *ContainerdTasks) EngineInfo() *dtypes.EngineInfo {
+	return &dtypes.EngineInfo{StorageDriver: c.storageDriver, EngineType: dtypes.EngineTypeContainerd, CPU: c.cpu, Memory: c.memory}
+}
+
+// SetForce sets a global override for the ContainerdTasks, when set to true
+// Images will always be pulled from remote registries
+// Containers will be destroyed immediately and not wait for graceful shutdown
+func (c *ContainerdTasks) SetForce(force bool) {
+	c.force = force
+}
+
+// CreateContainer creates and starts a new container for the given configuration
+func (c *ContainerdTasks) CreateContainer(cc *dtypes.Container) (string, error) {
+	c.l.Debug("Creating containerd Container", "ref", cc.Name)
+
+	cc.Image.Name = makeImageCanonical(cc.Image.Name)
+
+	_, err := parsePlatform(cc.Image.Platform)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"create", "--name", cc.Name, "--tty", "--interactive"}
+
+	if cc.Image.Platform != "" {
+		args = append(args, "--platform", cc.Image.Platform)
+	}
+
+	// sort the environment and labels so the arguments are stable
+	for _, k := range sortedKeys(cc.Environment) {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", k, cc.Environment[k]))
+	}
+
+	for _, k := range sortedKeys(cc.Labels) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, cc.Labels[k]))
+	}
+
+	if cc.RunAs != nil {
+		args = append(args, "--user", fmt.Sprintf("%s:%s", cc.RunAs.User, cc.RunAs.Group))
+	}
+
+	for _, d := range cc.DNS {
+		args = append(args, "--dns", d)
+	}
+
+	if cc.MaxRestartCount > 0 {
+		args = append(args, "--restart", fmt.Sprintf("on-failure:%d", cc.MaxRestartCount))
+	} else if cc.MaxRestartCount == -1 {
+		args = append(args, "--restart", "always")
+	}
+
+	if cc.Capabilities != nil {
+		for _, ca := range cc.Capabilities.Add {
+			args = append(args, "--cap-add", ca)
+		}
+
+		for _, cd := range cc.Capabilities.Drop {
+			args = append(args, "--cap-drop", cd)
+		}
+	}
+
+	if cc.Resources != nil {
+		// memory is specified in megabytes
+		if cc.Resources.Memory > 0 {
+			args = append(args, "--memory", fmt.Sprintf("%dm", cc.Resources.Memory))
+		}
+
+		// cpu is specified in millicores
+		if cc.Resources.CPU > 0 {
+			args = append(args, "--cpus", fmt.Sprintf("%.3f", float64(cc.Resources.CPU)/1000))
+		}
+
+		if len(cc.Resources.CPUPin) > 0 {
+			cpuPin := make([]string, len(cc.Resources.CPUPin))
+			for i, v := range cc.Resources.CPUPin {
+				cpuPin[i] = fmt.Sprintf("%d", v)
+			}
+
+			args = append(args, "--cpuset-cpus", strings.Join(cpuPin, ","))
+		}
+
+		if cc.Resources.GPU != nil {
+			gpus := "all"
+			if len(cc.Resources.GPU.DeviceIDs) > 0 {
+				gpus = fmt.Sprintf("device=%s", strings.Join(cc.Resources.GPU.DeviceIDs, ","))
+			}
+
+			args = append(args, "--gpus", gpus)
+		}
+	}
+
+	for _, vc := range cc.Volumes {
+		va, err := c.volumeArgs(cc.Name, vc)
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, va...)
+	}
+
+	for _, p := range cc.Ports {
+		if p.Protocol == "" {
+			p.Protocol = "tcp"
+		}
+
+		if p.Host == "" {
+			args = append(args, "--publish", fmt.Sprintf("%s/%s", p.Local, p.Protocol))
+			continue
+		}
+
+		args = append(args, "--publish", fmt.Sprintf("0.0.0.0:%s:%s/%s", p.Host, p.Local, p.Protocol))
+	}
+
+	// validate the port ranges, only ranges with enable host are published
+	_, err = createPublishedPortRanges(cc.PortRanges)
+	if err != nil {
+		return "", fmt.Errorf("unable to attach to container network, invalid port range: %w", err)
+	}
+
+	for _, p := range cc.PortRanges {
+		if p.EnableHost {
+			args = append(args, "--publish", fmt.Sprintf("0.0.0.0:%s:%s/%s", p.Range, p.Range, p.Protocol))
+		}
+	}
+
+	if cc.Privileged {
+		args = append(args, "--privileged", "--cgroupns", "host")
+	}
+
+	for _, u := range cc.Ulimits {
+		args = append(args, "--ulimit", fmt.Sprintf("%s=%d:%d", u.Name, u.Soft, u.Hard))
+	}
+
+	for _, k := range sortedKeys(cc.Sysctls) {
+		args = append(args, "--sysctl", fmt.Sprintf("%s=%s", k, cc.Sysctls[k]))
+	}
+
+	for _, h := range sortedKeys(cc.ExtraHosts) {
+		args = append(args, "--add-host", fmt.Sprintf("%s:%s", h, cc.ExtraHosts[h]))
+	}
+
+	// shm size is specified in megabytes
+	if cc.ShmSize > 0 {
+		args = append(args, "--shm-size", fmt.Sprintf("%dm", cc.ShmSize))
+	}
+
+	if cc.StopSignal != "" {
+		args = append(args, "--stop-signal", cc.StopSignal)
+	}
+
+	if cc.StopTimeout > 0 {
+		args = append(args, "--stop-timeout", fmt.Sprintf("%d", cc.StopTimeout))
+	}
+
+	// nerdctl only supports shell health checks, CMD tests are joined
+	if hc := cc.HealthCheck; hc != nil && len(hc.Test) > 1 {
+		args = append(args,
+			"--health-cmd", strings.Join(hc.Test[1:], " "),
+			"--health-interval", hc.Interval.String(),
+			"--health-timeout", hc.Timeout.String(),
+			"--health-start-period", hc.StartPeriod.String(),
+			"--health-retries", fmt.Sprintf("%d", hc.Retries),
+		)
+	}
+
+	// attach the networks, containerd does not support connecting networks
+	// to a created container so all networks are set on create
+	ipv6Enabled := false
+	containerNetwork := false
+	for _, n := range cc.Networks {
+		if n.IsContainer {
+			c.l.Debug("Attaching as sidecar", "ref", cc.Name, "container", n.ID)
+
+			args = append(args, "--network", fmt.Sprintf("container:%s", n.ID))
+			containerNetwork = true
+			continue
+		}
+
+		net, err := c.FindNetwork(n.ID)
+		if err != nil {
+			return "", fmt.Errorf("unable to create container network does not exist: %s", err)
+		}
+
+		if net.IPv6Enabled {
+			ipv6Enabled = true
+		}
+
+		args = append(args, "--network", net.Name)
+
+		if n.IPAddress != "" {
+			c.l.Debug("Assigning static ip address", "ref", cc.Name, "network", net.Name, "ip_address", n.IPAddress)
+			args = append(args, "--ip", n.IPAddress)
+		}
+
+		if len(n.Aliases) > 0 {
+			c.l.Debug("Network aliases are not supported by containerd, ignoring", "ref", cc.Name, "network", net.Name, "aliases", n.Aliases)
+		}
+	}
+
+	// when using container networking can not use a hostname
+	if !containerNetwork {
+		args = append(args, "--hostname", cc.Name)
+	}
+
+	// disable ipv6 networking unless the user has set the sysctl
+	if _, ok := cc.Sysctls["net.ipv6.conf.all.disable_ipv6"]; !ipv6Enabled && !ok {
+		args = append(args, "--sysctl", "net.ipv6.conf.all.disable_ipv6=1")
+	}
+
+	// containerd only accepts a single entrypoint, any additional parts are
+	// passed as the first arguments of the command
+	command := cc.Command
+	if len(cc.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", cc.Entrypoint[0])
+		command = append(append([]string{}, cc.Entrypoint[1:]...), cc.Command...)
+	}
+
+	args = append(args, cc.Image.Name)
+	args = append(args, command...)
+
+	id, err := c.run(args...)
+	if err != nil {
+		return "", err
+	}
+
+	// write any files before the container starts
+	err = c.CopyFilesToContainer(id, cc.Files)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = c.run("start", id)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// ContainerInfo returns the Docker compatible container info
+func (c *ContainerdTasks) ContainerInfo(id string) (interface{}, error) {
+	info, err := c.inspectContainer(id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read information about containerd container %s: %w", id, err)
+	}
+
+	return info, nil
+}
+
+// RemoveContainer with the given id
+func (c *ContainerdTasks) RemoveContainer(id string, force bool) error {
+	// try and shutdown graceful only if we are not forcing
+	if !force && !c.force {
+		_, err := c.run("stop", "--time", fmt.Sprintf("%d", c.stopTimeout(id)), id)
+		if err == nil {
+			c.l.Debug("Container stopped gracefully, removing", "container", id)
+
+			_, err = c.run("rm", "--volumes", id)
+			if err == nil {
+				return nil
+			}
+		}
+
+		c.l.Debug("Unable to stop container gracefully, trying force", "container", id, "error", err)
+	}
+
+	c.l.Debug("Forcefully remove", "container", id)
+
+	_, err := c.run("rm", "--force", "--volumes", id)
+	return err
+}
+
+// BuildContainer builds an image from the build context using BuildKit
+func (c *ContainerdTasks) BuildContainer(config *dtypes.Build, force bool) (string, error) {
+	// get the checksum for the id
+	cs, err := utils.HashDir(config.Context, config.Ignore...)
+	if err != nil {
+		return "", err
+	}
+
+	// strip the prefix and grab the first 8chars for the id
+	cs, _ = utils.ReplaceNonURIChars(cs[3:11])
+
+	suffix, err := platformTag(config.Platforms)
+	if err != nil {
+		return "", err
+	}
+
+	cs += suffix
+
+	imageWithId := fmt.Sprintf("jumppad.dev/localcache/%s:%s", config.Name, strings.ToLower(cs))
+	imageWithId = makeImageCanonical(imageWithId)
+
+	// check if the image already exists, if so do not rebuild unless force
+	if !force && !c.force {
+		ids, err := c.FindImagesInLocalRegistry(imageWithId)
+		if err != nil {
+			return "", err
+		}
+
+		if len(ids) > 0 {
+			c.l.Debug("Image exists in local cache, skip build", "image", imageWithId)
+
+			return imageWithId, nil
+		}
+	}
+
+	if config.DockerFile == "" {
+		config.DockerFile = "./Dockerfile"
+	}
+
+	c.l.Debug("Building image", "id", imageWithId, "args", config.Args, "platforms", config.Platforms)
+
+	// copy the build context to a temporary folder removing any ignored files
+	// as BuildKit reads the context directly from disk
+	dir, err := os.MkdirTemp(utils.JumppadTemp(), "build")
+	if err != nil {
+		return "", fmt.Errorf("unable to create temporary directory for build context: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	err = c.tg.Create(&buf, &ctar.TarGzOptions{OmitRoot: true, ZipContents: true}, []string{config.Context}, config.Ignore...)
+	if err != nil {
+		return "", fmt.Errorf("unable to create build context: %w", err)
+	}
+
+	err = c.tg.Extract(&buf, true, dir)
+	if err != nil {
+		return "", fmt.Errorf("unable to create build context: %w", err)
+	}
+
+	args := []string{"build", "--tag", imageWithId, "--file", filepath.Join(dir, config.DockerFile)}
+
+	for _, k := range sortedKeys(config.Args) {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, config.Args[k]))
+	}
+
+	// BuildKit builds a multi-platform image when more than one platform is set
+	if len(config.Platforms) > 0 {
+		args = append(args, "--platform", strings.Join(config.Platforms, ","))
+	}
+
+	args = append(args, dir)
+
+	out := c.l.StandardWriter()
+	err = c.c.Execute(context.Background(), nil, out, out, args...)
+	if err != nil {
+		return "", fmt.Errorf("unable to build image: %w", err)
+	}
+
+	return imageWithId, nil
+}
+
+// CreateVolume creates a volume for a cluster
+// if the volume exists performs no action
+// returns the volume name and an error if unsuccessful
+func (c *ContainerdTasks) CreateVolume(name string) (string, error) {
+	vn := utils.FQDNVolumeName(name)
+
+	vols, err := c.runLines("volume", "ls", "--quiet")
+	if err != nil {
+		return "", fmt.Errorf("unable to list volume '%s': %w", vn, err)
+	}
+
+	for _, v := range vols {
+		if v == vn {
+			c.l.Debug("Volume exists", "ref", name, "name", vn)
+			return vn, nil
+		}
+	}
+
+	c.l.Debug("Create Volume", "ref", name, "name", vn)
+
+	_, err = c.run("volume", "create", vn)
+	if err != nil {
+		return "", fmt.Errorf("failed to create volume '%s': %w", vn, err)
+	}
+
+	return vn, nil
+}
+
+// RemoveVolume deletes the volume associated with a cluster
+func (c *ContainerdTasks) RemoveVolume(name string) error {
+	vn := utils.FQDNVolumeName(name)
+	c.l.Debug("Deleting Volume", "ref", name, "name", vn)
+
+	_, err := c.run("volume", "rm", "--force", vn)
+	return err
+}
+
+// FindVolumes returns the names of the volumes that match the given name,
+// the name can contain wildcards
+func (c *ContainerdTasks) FindVolumes(name string) ([]string, error) {
+	lines, err := c.runLines("volume", "ls", "--quiet")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list volumes: %w", err)
+	}
+
+	vols := []string{}
+	for _, l := range lines {
+		if n, ok := volumeRef(l, name); ok {
+			vols = append(vols, n)
+		}
+	}
+
+	return vols, nil
+}
+
+// FindImageInLocalRegistry returns the id for an image in the local registry that
+// matches the given tag. If no image is found an empty string is returned.
+func (c *ContainerdTasks) FindImageInLocalRegistry(image dtypes.Image) (string, error) {
+	ids, err := c.FindImagesInLocalRegistry(image.Name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	return "", nil
+}
+
+// FindImagesInLocalRegistry returns the ids of the images in the local
+// registry that match the filter, a filter without a tag matches all tags
+func (c *ContainerdTasks) FindImagesInLocalRegistry(filter string) ([]string, error) {
+	lines, err := c.runLines("images", "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list images in local containerd cache: %w", err)
+	}
+
+	ids := []string{}
+	for _, l := range lines {
+		i := struct {
+			ID         string
+			Repository string
+			Tag        string
+		}{}
+
+		err := json.Unmarshal([]byte(l), &i)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse image list: %w", err)
+		}
+
+		if imageMatchesFilter(i.Repository, i.Tag, filter) && !contains(ids, i.ID) {
+			ids = append(ids, i.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return ids, nil
+}
+
+// PullImage pulls an image from a remote repo
+func (c *ContainerdTasks) PullImage(img dtypes.Image, force bool) error {
+	// if image is local, do not try to pull jumppad.dev/localcache
+	if strings.HasPrefix(img.Name, utils.BuildImagePrefix) {
+		return nil
+	}
+
+	in := makeImageCanonical(img.Name)
+
+	// only pull if image is not in current registry so check to see if the image is present
+	// if force then skip this check
+	if !force && !c.force {
+		id, err := c.FindImageInLocalRegistry(img)
+		if err != nil {
+			return err
+		}
+
+		// found the image do nothing, unless a different platform is required
+		if id != "" && c.imageMatchesPlatform(id, img.Platform) {
+			return nil
+		}
+	}
+
+	if img.Username != "" && img.Password != "" {
+		err := c.login(in, img.Username, img.Password)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.l.Debug("Pulling image", "image", in, "platform", img.Platform)
+
+	args := []string{"pull"}
+	if img.Platform != "" {
+		args = append(args, "--platform", img.Platform)
+	}
+
+	args = append(args, in)
+
+	out := c.l.StandardWriter()
+	err := c.c.Execute(context.Background(), nil, out, out, args...)
+	if err != nil {
+		return fmt.Errorf("error pulling image: %w", err)
+	}
+
+	// update the image log
+	err = c.il.Log(in, images.ImageTypeDocker)
+	if err != nil {
+		c.l.Error("Unable to add image name to cache", "error", err)
+	}
+
+	return nil
+}
+
+// InspectImage returns the metadata for the image in the local registry
+func (c *ContainerdTasks) InspectImage(name string) (*dtypes.ImageInfo, error) {
+	out, err := c.run("image", "inspect", "--mode", "dockercompat", name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect image %s: %w", name, err)
+	}
+
+	info := []image.InspectResponse{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse image details: %w", err)
+	}
+
+	if len(info) == 0 {
+		return nil, fmt.Errorf("image %s not found", name)
+	}
+
+	return newImageInfo(info[0]), nil
+}
+
+// PushImage pushes an image to the registry
+func (c *ContainerdTasks) PushImage(img dtypes.Image) error {
+	if img.Username != "" && img.Password != "" {
+		err := c.login(img.Name, img.Username, img.Password)
+		if err != nil {
+			return err
+		}
+	}
+
+	out := c.l.StandardWriter()
+	err := c.c.Execute(context.Background(), nil, out, out, "push", img.Name)
+	if err != nil {
+		return fmt.Errorf("error pushing image: %w", err)
+	}
+
+	return nil
+}
+
+// FindContainerIDs returns the Container IDs for the given identifier
+func (c *ContainerdTasks) FindContainerIDs(fqdn string) ([]string, error) {
+	lines, err := c.runLines("ps", "--all", "--no-trunc", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, l := range lines {
+		co := struct {
+			ID    string
+			Names string
+		}{}
+
+		err := json.Unmarshal([]byte(l), &co)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse container list: %w", err)
+		}
+
+		if co.Names == fqdn {
+			ids = append(ids, co.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return ids, nil
+}
+
+// RemoveImage removes the image with the given id from the local registry
+func (c *ContainerdTasks) RemoveImage(id string) error {
+	_, err := c.run("rmi", "--force", id)
+	return err
+}
+
+// ContainerLogs streams the logs for the container to the returned io.ReadCloser
+func (c *ContainerdTasks) ContainerLogs(id string, stdOut, stdErr bool) (io.ReadCloser, error) {
+	r, w := io.Pipe()
+
+	var sout, serr io.Writer = io.Discard, io.Discard
+	if stdOut {
+		sout = w
+	}
+
+	if stdErr {
+		serr = w
+	}
+
+	go func() {
+		w.CloseWithError(c.c.Execute(context.Background(), nil, sout, serr, "logs", id))
+	}()
+
+	return r, nil
+}
+
+// FollowContainerLogs writes the last lines of the container logs and any new
+// output to stdOut and stdErr until the container stops or ctx is cancelled
+func (c *ContainerdTasks) FollowContainerLogs(ctx context.Context, id string, lines int, stdOut, stdErr io.Writer) error {
+	return c.c.Execute(ctx, nil, stdOut, stdErr, "logs", "--follow", "--tail", strconv.Itoa(lines), id)
+}
+
+// CopyFromContainer copies a file or directory from a container
+func (c *ContainerdTasks) CopyFromContainer(id, src, dst string) error {
+	c.l.Debug("Copying file from", "id", id, "src", src, "dst", dst)
+
+	// make sure the destination does not exist
+	if _, err := os.Stat(dst); err == nil {
+		err = os.RemoveAll(dst)
+		if err != nil {
+			return fmt.Errorf("unable to remove destination file or directory: %s", err)
+		}
+	}
+
+	_, err := c.run("cp", fmt.Sprintf("%s:%s", id, src), dst)
+	if err != nil {
+		return fmt.Errorf("unable to copy '%s' from container '%s': %w", src, id, err)
+	}
+
+	return nil
+}
+
+// CopyFileToContainer copies the file at path filename to the container containerID and
+// stores it in the container at the directory path.
+func (c *ContainerdTasks) CopyFileToContainer(containerID, filename, dir string) error {
+	dst := path.Join(filepath.ToSlash(dir), filepath.Base(filename))
+
+	_, err := c.run("cp", filename, fmt.Sprintf("%s:%s", containerID, dst))
+	if err != nil {
+		return fmt.Errorf("unable to copy file to container: %w", err)
+	}
+
+	return nil
+}
+
+// CreateFileInContainer creates a file with the given contents and name in the container containerID and
+// stores it in the container at the directory path.
+func (c *ContainerdTasks) CreateFileInContainer(containerID, contents, filename, dir string) error {
+	tmpFile := filepath.Join(utils.JumppadTemp(), filename)
+
+	err := os.WriteFile(tmpFile, []byte(contents), 0755)
+	if err != nil {
+		return fmt.Errorf("unable to write contents to temporary file: %w", err)
+	}
+
+	defer os.Remove(tmpFile)
+
+	return c.CopyFileToContainer(containerID, tmpFile, dir)
+}
+
+// CopyFilesToContainer writes the files to the container
+func (c *ContainerdTasks) CopyFilesToContainer(containerID string, files []dtypes.File) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	dir, err := os.MkdirTemp(utils.JumppadTemp(), "files*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary directory for files: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	for i, f := range files {
+		tmpFile := filepath.Join(dir, fmt.Sprintf("%d", i))
+
+		err := os.WriteFile(tmpFile, []byte(f.Content), f.Mode.Perm())
+		if err != nil {
+			return fmt.Errorf("unable to write contents to temporary file: %w", err)
+		}
+
+		// set the mode explicitly as WriteFile applies the umask
+		err = os.Chmod(tmpFile, f.Mode.Perm())
+		if err != nil {
+			return fmt.Errorf("unable to set mode for temporary file: %w", err)
+		}
+
+		_, err = c.run("cp", tmpFile, fmt.Sprintf("%s:%s", containerID, f.Destination))
+		if err != nil {
+			return fmt.Errorf("unable to copy file %s to container: %w", f.Destination, err)
+		}
+	}
+
+	return nil
+}
+
+// CopyLocalDockerImagesToVolume writes multiple images to a volume as archives
+// returns the filenames of the archives and an error if one occurred
+func (c *ContainerdTasks) CopyLocalDockerImagesToVolume(images []string, volume string, force bool) ([]string, error) {
+	c.l.Debug("Writing images to volume", "images", images, "volume", volume)
+
+	// make sure this operation runs sequentially as we do not want to update the same volume at the same time
+	importMutex.Lock()
+	defer importMutex.Unlock()
+
+	for _, i := range images {
+		id, err := c.FindImageInLocalRegistry(dtypes.Image{Name: i})
+		if err != nil {
+			return nil, err
+		}
+
+		if id == "" {
+			return nil, fmt.Errorf("unable to find image '%s' in the local containerd cache, please pull the image before attempting to copy to a volume", i)
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	savedImages := []string{}
+	for _, i := range images {
+		c.l.Debug("Copying image to container", "image", i)
+
+		imageFile := path.Join(tmpDir, base64.StdEncoding.EncodeToString([]byte(i)))
+
+		_, err := c.run("save", "--output", imageFile, makeImageCanonical(i))
+		if err != nil {
+			return nil, fmt.Errorf("unable to save images: %w", err)
+		}
+
+		savedImages = append(savedImages, imageFile)
+	}
+
+	return c.CopyFilesToVolume(volume, savedImages, "/images", force)
+}
+
+// CopyFilesToVolume copies the files to the path in a volume
+// returns the names of the stored files
+func (c *ContainerdTasks) CopyFilesToVolume(volumeID string, filenames []string, dir string, force bool) ([]string, error) {
+	// make sure we have the alpine image needed to copy
+	err := c.PullImage(dtypes.Image{Name: "alpine:latest"}, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable pull 'alpine:latest' needed to copy files to volume: %w", err)
+	}
+
+	// create a dummy container to import to volume
+	name := fmt.Sprintf("%d", time.Now().UnixNano())
+	name = name[len(name)-8:]
+	cc := &dtypes.Container{}
+	cc.Name = fmt.Sprintf("%s-import", name)
+
+	cc.Image = &dtypes.Image{Name: "alpine:latest"}
+	cc.Volumes = []dtypes.Volume{
+		{
+			Source:      volumeID,
+			Destination: "/cache",
+			Type:        "volume",
+		},
+	}
+	cc.Command = []string{"tail", "-f", "/dev/null"}
+
+	tmpID, err := c.CreateContainer(cc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create dummy container for importing files: %w", err)
+	}
+	defer c.RemoveContainer(tmpID, true)
+
+	err = c.waitForRunning(tmpID)
+	if err != nil {
+		return nil, err
+	}
+
+	// create the directory paths ensure unix paths for containers
+	destPath := filepath.ToSlash(filepath.Join("/cache", dir))
+	_, err = c.ExecuteCommand(tmpID, []string{"mkdir", "-p", destPath}, nil, "/", "", "", 300, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create destination path '%s' in volume: %w", destPath, err)
+	}
+
+	imported := []string{}
+	for _, f := range filenames {
+		name := filepath.Base(f)
+		destFile := fmt.Sprintf("%s/%s", destPath, name)
+
+		// check if the file exists if we are not doing a forced update
+		if !c.force && !force {
+			_, err := c.ExecuteCommand(tmpID, []string{"find", destFile}, nil, "/", "", "", 300, nil)
+			if err == nil {
+				c.l.Debug("File already cached", "name", name, "path", dir)
+				imported = append(imported, destFile)
+				continue
+			}
+		}
+
+		err = c.CopyFileToContainer(tmpID, f, destPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to copy file %s to container: %w", f, err)
+		}
+
+		imported = append(imported, destFile)
+	}
+
+	return imported, nil
+}
+
+// ExecuteCommand allows the execution of commands in a running container
+// id is the id of the container to execute the command in
+// command is a slice of strings to execute
+// writer [optional] will be used to write any output from the command execution.
+func (c *ContainerdTasks) ExecuteCommand(id string, command []string, env []string, workingDir string, user, group string, timeout int, writer io.Writer) (int, error) {
+	args := []string{"exec"}
+
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+
+	if workingDir != "" {
+		args = append(args, "--workdir", workingDir)
+	}
+
+	if user != "" && group != "" {
+		args = append(args, "--user", fmt.Sprintf("%s:%s", user, group))
+	} else if user != "" {
+		args = append(args, "--user", user)
+	}
+
+	args = append(args, id)
+	args = append(args, command...)
+
+	if writer == nil {
+		writer = io.Discard
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	err := c.c.Execute(ctx, nil, writer, writer, args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return defaultExitCode, fmt.Errorf("timeout waiting for container exec to complete")
+		}
+
+		code := exitCode(err)
+		return code, fmt.Errorf("container exec failed with exit code %d", code)
+	}
+
+	return 0, nil
+}
+
+// ExecuteScript allows the execution of a script in a running container
+// id is the id of the container to execute the command in
+// contents is the contents of the script to execute
+// writer [optional] will be used to write any output from the command execution.
+func (c *ContainerdTasks) ExecuteScript(id string, contents string, env []string, workingDir string, user, group string, timeout int, writer io.Writer) (int, error) {
+	// ensure we only have unix line ending in ths script
+	contents = strings.Replace(contents, "\r\n", "\n", -1)
+
+	err := c.CreateFileInContainer(id, contents, "script.sh", "/tmp")
+	if err != nil {
+		return defaultExitCode, fmt.Errorf("unable to create script in container: %w", err)
+	}
+
+	return c.ExecuteCommand(id, []string{"sh", "/tmp/script.sh"}, env, workingDir, user, group, timeout, writer)
+}
+
+// AttachNetwork is not supported by containerd, networks can only be
+// attached when the container is created
+func (c *ContainerdTasks) AttachNetwork(net, containerID string, aliases []string, ipAddress string) error {
+	return fmt.Errorf("unable to attach container %s to network %s, containerd does not support attaching networks to existing containers", containerID, net)
+}
+
+// DetachNetwork is a no-op for containerd, networks are released when the
+// container is removed
+func (c *ContainerdTasks) DetachNetwork(network, containerID string) error {
+	c.l.Debug("Networks are detached when the container is removed, skipping detach", "id", containerID, "network", network)
+
+	return nil
+}
+
+// ListNetworks lists the networks a container is attached to
+func (c *ContainerdTasks) ListNetworks(id string) []dtypes.NetworkAttachment {
+	info, err := c.inspectContainer(id)
+	if err != nil || info.NetworkSettings == nil {
+		return []dtypes.NetworkAttachment{}
+	}
+
+	nets, _ := c.NetworkList(context.Background(), "")
+
+	attachments := []dtypes.NetworkAttachment{}
+	for _, n := range nets {
+		for k, es := range info.NetworkSettings.Networks {
+			if k != n.Name || es == nil {
+				continue
+			}
+
+			attachments = append(attachments, dtypes.NetworkAttachment{
+				ID:        n.Labels["id"],
+				Name:      n.Name,
+				IPAddress: es.IPAddress,
+			})
+		}
+	}
+
+	return attachments
+}
+
+// FindNetwork returns a network using the unique resource id
+func (c *ContainerdTasks) FindNetwork(id string) (dtypes.NetworkAttachment, error) {
+	nets, err := c.NetworkList(context.Background(), "")
+	if err != nil {
+		return dtypes.NetworkAttachment{}, err
+	}
+
+	for _, n := range nets {
+		if n.Labels["id"] == id {
+			na := dtypes.NetworkAttachment{
+				ID:          n.ID,
+				Name:        n.Name,
+				IPv6Enabled: n.EnableIPv6,
+			}
+
+			if len(n.IPAM.Config) > 0 {
+				na.Subnet = n.IPAM.Config[0].Subnet
+			}
+
+			return na, nil
+		}
+	}
+
+	return dtypes.NetworkAttachment{}, fmt.Errorf("a network with the label id: %s, was not found", id)
+}
+
+// NetworkCreate creates a new network with the given name
+func (c *ContainerdTasks) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) error {
+	args := []string{"network", "create"}
+
+	if options.Driver != "" {
+		args = append(args, "--driver", options.Driver)
+	}
+
+	if options.IPAM != nil {
+		for _, ic := range options.IPAM.Config {
+			args = append(args, "--subnet", ic.Subnet)
+		}
+	}
+
+	if options.EnableIPv6 != nil && *options.EnableIPv6 {
+		args = append(args, "--ipv6")
+	}
+
+	for _, k := range sortedKeys(options.Labels) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, options.Labels[k]))
+	}
+
+	args = append(args, name)
+
+	_, err := c.runContext(ctx, args...)
+	return err
+}
+
+// NetworkRemove removes the network with the given name
+func (c *ContainerdTasks) NetworkRemove(ctx context.Context, name string) error {
+	_, err := c.runContext(ctx, "network", "rm", name)
+	return err
+}
+
+// NetworkList returns the networks matching the name, when name is empty
+// all networks are returned
+func (c *ContainerdTasks) NetworkList(ctx context.Context, name string) ([]network.Summary, error) {
+	lines, err := c.runLines("network", "ls", "--format", "{{.Name}}")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, l := range lines {
+		if name == "" || l == name {
+			names = append(names, l)
+		}
+	}
+
+	if len(names) == 0 {
+		return []network.Summary{}, nil
+	}
+
+	out, err := c.runContext(ctx, append([]string{"network", "inspect", "--mode", "dockercompat"}, names...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	nets := []network.Summary{}
+	err = json.Unmarshal([]byte(out), &nets)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse network details: %w", err)
+	}
+
+	return nets, nil
+}
+
+// CreateShell creates an interactive shell inside a container
+func (c *ContainerdTasks) CreateShell(id string, command []string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error {
+	args := append([]string{"exec", "--interactive", "--tty", "--workdir", "/", id}, command...)
+
+	err := c.c.Execute(context.Background(), stdin, stdout, stderr, args...)
+	if err != nil {
+		return fmt.Errorf("container exec failed with exit code %d", exitCode(err))
+	}
+
+	return nil
+}
+
+// TagImage tags an image with the given tag
+func (c *ContainerdTasks) TagImage(source, destination string) error {
+	_, err := c.run("tag", source, destination)
+	return err
+}
+
+// errSnapshotsNotSupported is returned by the snapshot methods, nerdctl does not
+// support committing containers with the original configuration
+var errSnapshotsNotSupported = errors.New("snapshots are not supported by the containerd runtime")
+
+// SaveContainer is not supported by the containerd runtime
+func (c *ContainerdTasks) SaveContainer(id, image string) (*dtypes.ContainerSnapshot, error) {
+	return nil, errSnapshotsNotSupported
+}
+
+// RestoreContainer is not supported by the containerd runtime
+func (c *ContainerdTasks) RestoreContainer(snapshot *dtypes.ContainerSnapshot) (string, error) {
+	return "", errSnapshotsNotSupported
+}
+
+// ExportVolume is not supported by the containerd runtime
+func (c *ContainerdTasks) ExportVolume(name string, w io.Writer) error {
+	return errSnapshotsNotSupported
+}
+
+// ImportVolume is not supported by the containerd runtime
+func (c *ContainerdTasks) ImportVolume(name string, r io.Reader) error {
+	return errSnapshotsNotSupported
+}
+
+// volumeArgs returns the mount arguments for a volume, bind mount sources
+// are created when they do not exist
+func (c *ContainerdTasks) volumeArgs(name string, vc dtypes.Volume) ([]string, error) {
+	t := vc.Type
+	if t == "" {
+		t = "bind"
+	}
+
+	if t == "bind" {
+		_, err := os.Stat(vc.Source)
+		if err != nil {
+			c.l.Debug("Creating directory for container volume", "ref", name, "directory", vc.Source, "volume", vc.Destination)
+
+			err := os.MkdirAll(vc.Source, os.ModePerm)
+			if err != nil {
+				return nil, fmt.Errorf("source for Volume %s does not exist, error creating directory: %w", vc.Source, err)
+			}
+		}
+	}
+
+	if vc.SelinuxRelabel != "" && vc.BindPropagationNonRecursive {
+		return nil, errors.New("cannot apply selinux relabeling and non-recursive bind mounts with containerd")
+	}
+
+	// selinux relabeling is only supported with volume syntax
+	if t == "bind" && vc.SelinuxRelabel != "" {
+		options := []string{}
+		if vc.ReadOnly {
+			options = append(options, "ro")
+		}
+
+		if vc.BindPropagation != "" {
+			options = append(options, vc.BindPropagation)
+		}
+
+		if vc.SelinuxRelabel == "shared" {
+			options = append(options, "z")
+		} else if vc.SelinuxRelabel == "private" {
+			options = append(options, "Z")
+		}
+
+		return []string{"--volume", fmt.Sprintf("%s:%s:%s", vc.Source, vc.Destination, strings.Join(options, ","))}, nil
+	}
+
+	mount := []string{fmt.Sprintf("type=%s", t)}
+	if t != "tmpfs" {
+		mount = append(mount, fmt.Sprintf("source=%s", vc.Source))
+	}
+
+	mount = append(mount, fmt.Sprintf("target=%s", vc.Destination))
+
+	if t == "tmpfs" {
+		if vc.TmpfsSize > 0 {
+			mount = append(mount, fmt.Sprintf("tmpfs-size=%dm", vc.TmpfsSize))
+		}
+
+		if vc.TmpfsMode != "" {
+			mount = append(mount, fmt.Sprintf("tmpfs-mode=%s", vc.TmpfsMode))
+		}
+	}
+
+	if vc.ReadOnly {
+		mount = append(mount, "readonly")
+	}
+
+	if t == "bind" {
+		bp := vc.BindPropagation
+		if bp == "" {
+			bp = "rprivate"
+		}
+
+		mount = append(mount, fmt.Sprintf("bind-propagation=%s", bp))
+
+		if vc.BindPropagationNonRecursive {
+			mount = append(mount, "bind-nonrecursive=true")
+		}
+	}
+
+	return []string{"--mount", strings.Join(mount, ",")}, nil
+}
+
+// waitForRunning waits for the container to be running for two consecutive
+// checks
+func (c *ContainerdTasks) waitForRunning(id string) error {
+	successCount := 0
+	failCount := 0
+
+	var startError error
+	for {
+		i, err := c.inspectContainer(id)
+
+		switch {
+		case err == nil && i.State != nil && i.State.Running:
+			successCount++
+
+		case err != nil:
+			startError = err
+			fallthrough
+
+		default:
+			failCount++
+		}
+
+		if successCount == 2 {
+			return nil
+		}
+
+		if failCount == 5 {
+			c.l.Error("Timeout waiting for container to start", "ref", id, "error", startError)
+			return fmt.Errorf("timeout waiting for container to start: %w", startError)
+		}
+
+		time.Sleep(c.defaultWait)
+	}
+}
+
+// inspectContainer returns the Docker compatible details for a container
+func (c *ContainerdTasks) inspectContainer(id string) (container.InspectResponse, error) {
+	out, err := c.run("container", "inspect", "--mode", "dockercompat", id)
+	if err != nil {
+		return container.InspectResponse{}, err
+	}
+
+	info := []container.InspectResponse{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return container.InspectResponse{}, fmt.Errorf("unable to parse container details: %w", err)
+	}
+
+	if len(info) == 0 {
+		return container.InspectResponse{}, fmt.Errorf("container %s not found", id)
+	}
+
+	return info[0], nil
+}
+
+// WaitForContainer blocks until the container exits and returns the exit code
+func (c *ContainerdTasks) WaitForContainer(id string, timeout int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	out, err := c.runContext(ctx, "wait", id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return defaultExitCode, fmt.Errorf("timeout waiting for container %s to exit after %d seconds", id, timeout)
+		}
+
+		return defaultExitCode, fmt.Errorf("unable to wait for container %s: %w", id, err)
+	}
+
+	code, err := strconv.Atoi(out)
+	if err != nil {
+		return defaultExitCode, fmt.Errorf("unable to parse exit code for container %s: %w", id, err)
+	}
+
+	return code, nil
+}
+
+// stopTimeout returns the stop timeout configured for the container, when the
+// container does not have a timeout the default of 30 seconds is used
+func (c *ContainerdTasks) stopTimeout(id string) int {
+	timeout := 30
+
+	info, err := c.inspectContainer(id)
+	if err == nil && info.Config != nil && info.Config.StopTimeout != nil {
+		timeout = *info.Config.StopTimeout
+	}
+
+	return timeout
+}
+
+// imageMatchesPlatform returns true when the image with the given id in the
+// local registry was built for the platform or when no platform is required
+func (c *ContainerdTasks) imageMatchesPlatform(id, platform string) bool {
+	p, err := parsePlatform(platform)
+	if err != nil || p == nil {
+		return true
+	}
+
+	out, err := c.run("image", "inspect", "--mode", "dockercompat", id)
+	if err != nil {
+		c.l.Debug("Unable to inspect image", "id", id, "error", err)
+		return false
+	}
+
+	info := []image.InspectResponse{}
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil || len(info) == 0 {
+		return false
+	}
+
+	if info[0].Os != p.OS || info[0].Architecture != p.Architecture {
+		return false
+	}
+
+	return p.Variant == "" || info[0].Variant == p.Variant
+}
+
+// login authenticates nerdctl with the registry for the image
+func (c *ContainerdTasks) login(img, username, password string) error {
+	ref, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return fmt.Errorf("error parsing image name: %w", err)
+	}
+
+	domain := reference.Domain(ref)
+
+	var stderr bytes.Buffer
+	err = c.c.Execute(context.Background(), strings.NewReader(password), io.Discard, &stderr, "login", "--username", username, "--password-stdin", domain)
+	if err != nil {
+		return fmt.Errorf("unable to login to registry %s: %s: %w", domain, strings.TrimSpace(stderr.String()), err)
+	}
+
+	return nil
+}
+
+// run executes nerdctl and returns the trimmed output
+func (c *ContainerdTasks) run(args ...string) (string, error) {
+	return c.runContext(context.Background(), args...)
+}
+
+func (c *ContainerdTasks) runContext(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	err := c.c.Execute(ctx, nil, &stdout, &stderr, args...)
+	if err != nil {
+		return "", fmt.Errorf("nerdctl %s failed: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// runLines executes nerdctl and returns the non empty lines of the output
+func (c *ContainerdTasks) runLines(args ...string) ([]string, error) {
+	out, err := c.run(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		if l := strings.TrimSpace(s.Text()); l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines, nil
+}
+
+// imageMatchesFilter returns true when the image repository and tag match
+// the filter, names are normalized so consul:1.6.1 matches
+// docker.io/library/consul:1.6.1. Filters containing a wildcard such as
+// jumppad.dev/localcache/* are matched against the repository in the same way
+// as the Docker reference filter
+func imageMatchesFilter(repository, tag, filter string) bool {
+	if strings.Contains(filter, "*") {
+		ok, _ := path.Match(filter, repository)
+		return ok
+	}
+
+	f, err := reference.ParseNormalizedNamed(filter)
+	if err != nil {
+		return false
+	}
+
+	i, err := reference.ParseNormalizedNamed(repository)
+	if err != nil {
+		return false
+	}
+
+	if f.Name() != i.Name() {
+		return false
+	}
+
+	if ft, ok := f.(reference.Tagged); ok {
+		return ft.Tag() == tag
+	}
+
+	return true
+}
+
+// storageDriverForSnapshotter converts a containerd snapshotter to the
+// equivalent Docker storage driver
+func storageDriverForSnapshotter(snapshotter string) string {
+	switch snapshotter {
+	case "overlayfs":
+		return StorageDriverOverlay2
+	case "native":
+		return StorageDriverVFS
+	}
+
+	return snapshotter
+}
+
+// exitCode returns the exit code of a failed nerdctl command
+func exitCode(err error) int {
+	var ee interface{ ExitCode() int }
+	if errors.As(err, &ee) {
+		return ee.ExitCode()
+	}
+
+	return defaultExitCode
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
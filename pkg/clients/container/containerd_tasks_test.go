@@ -0,0 +1,322 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	dtypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	imocks "github.com/jumppad-labs/jumppad/pkg/clients/images/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/clients/tar"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitError) ExitCode() int { return int(e) }
+
+// testContainerdSetup creates ContainerdTasks with a mock nerdctl, outputs
+// contains the stdout returned for commands starting with the key
+func testContainerdSetup(t *testing.T, outputs map[string]string, errs map[string]error) (*mocks.Nerdctl, *ContainerdTasks, *imocks.ImageLog) {
+	if _, ok := outputs["info"]; !ok {
+		outputs["info"] = `{"Driver":"overlayfs","NCPU":4,"MemTotal":8000000000}`
+	}
+
+	mn := &mocks.Nerdctl{}
+	mn.On("Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			cmd := strings.Join(args.Get(4).([]string), " ")
+			for k, v := range outputs {
+				if strings.HasPrefix(cmd, k) {
+					args.Get(2).(io.Writer).Write([]byte(v))
+				}
+			}
+		}).
+		Return(func(_ context.Context, _ io.Reader, _, _ io.Writer, args ...string) error {
+			cmd := strings.Join(args, " ")
+			for k, v := range errs {
+				if strings.HasPrefix(cmd, k) {
+					return v
+				}
+			}
+
+			return nil
+		})
+
+	mil := &imocks.ImageLog{}
+	mil.On("Log", mock.Anything, mock.Anything).Return(nil)
+
+	ct, err := NewContainerdTasks(mn, mil, &tar.TarGz{}, logger.NewTestLogger(t))
+	assert.NoError(t, err)
+
+	return mn, ct, mil
+}
+
+// getNerdctlCalls returns the arguments for the nerdctl commands that start with cmd
+func getNerdctlCalls(mn *mocks.Nerdctl, cmd string) [][]string {
+	calls := [][]string{}
+	for _, c := range mn.Calls {
+		args := c.Arguments.Get(4).([]string)
+		if len(args) > 0 && args[0] == cmd {
+			calls = append(calls, args)
+		}
+	}
+
+	return calls
+}
+
+func TestContainerdEngineInfoFromNerdctlInfo(t *testing.T) {
+	_, ct, _ := testContainerdSetup(t, map[string]string{}, nil)
+
+	ei := ct.EngineInfo()
+	assert.Equal(t, dtypes.EngineTypeContainerd, ei.EngineType)
+	assert.Equal(t, StorageDriverOverlay2, ei.StorageDriver)
+	assert.Equal(t, 4, ei.CPU)
+}
+
+func TestContainerdCreatesAndStartsContainer(t *testing.T) {
+	outputs := map[string]string{
+		"create":          "abc123",
+		"network ls":      "onprem",
+		"network inspect": `[{"Name":"onprem","Id":"123","Labels":{"id":"resource.network.onprem"},"IPAM":{"Config":[{"Subnet":"10.5.0.0/16"}]}}]`,
+	}
+
+	mn, ct, _ := testContainerdSetup(t, outputs, nil)
+
+	cc := &dtypes.Container{
+		Name:        "consul.container.local.jmpd.in",
+		Image:       &dtypes.Image{Name: "consul:1.6.1"},
+		Entrypoint:  []string{"/bin/sh", "-c"},
+		Command:     []string{"consul", "agent"},
+		Environment: map[string]string{"b": "2", "a": "1"},
+		Ports:       []dtypes.Port{{Local: "8500", Host: "18500"}},
+		Networks:    []dtypes.NetworkAttachment{{ID: "resource.network.onprem", IPAddress: "10.5.0.2"}},
+	}
+
+	id, err := ct.CreateContainer(cc)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", id)
+
+	args := strings.Join(getNerdctlCalls(mn, "create")[0], " ")
+	assert.Contains(t, args, "--name consul.container.local.jmpd.in")
+	assert.Contains(t, args, "--env a=1 --env b=2")
+	assert.Contains(t, args, "--publish 0.0.0.0:18500:8500/tcp")
+	assert.Contains(t, args, "--network onprem --ip 10.5.0.2")
+	assert.Contains(t, args, "--entrypoint /bin/sh docker.io/library/consul:1.6.1 -c consul agent")
+
+	assert.Equal(t, []string{"start", "abc123"}, getNerdctlCalls(mn, "start")[0])
+}
+
+func TestContainerdCreateReturnsErrorWhenNetworkNotFound(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{}, nil)
+
+	cc := &dtypes.Container{
+		Name:     "consul.container.local.jmpd.in",
+		Image:    &dtypes.Image{Name: "consul:1.6.1"},
+		Networks: []dtypes.NetworkAttachment{{ID: "resource.network.missing"}},
+	}
+
+	_, err := ct.CreateContainer(cc)
+	assert.Error(t, err)
+	assert.Len(t, getNerdctlCalls(mn, "create"), 0)
+}
+
+func TestContainerdFindsImagesWithNormalizedNames(t *testing.T) {
+	outputs := map[string]string{
+		"images": `{"ID":"abc","Repository":"consul","Tag":"1.6.1"}
+{"ID":"def","Repository":"docker.io/library/consul","Tag":"1.7.0"}
+{"ID":"ghi","Repository":"<none>","Tag":"<none>"}`,
+	}
+
+	_, ct, _ := testContainerdSetup(t, outputs, nil)
+
+	ids, err := ct.FindImagesInLocalRegistry("docker.io/library/consul:1.6.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, ids)
+
+	ids, err = ct.FindImagesInLocalRegistry("consul")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc", "def"}, ids)
+
+	ids, err = ct.FindImagesInLocalRegistry("nomad")
+	assert.NoError(t, err)
+	assert.Nil(t, ids)
+}
+
+func TestContainerdFindsImagesWithWildcardFilter(t *testing.T) {
+	outputs := map[string]string{
+		"images": `{"ID":"abc","Repository":"jumppad.dev/localcache/app","Tag":"d41d8cd9"}
+{"ID":"def","Repository":"consul","Tag":"1.6.1"}`,
+	}
+
+	_, ct, _ := testContainerdSetup(t, outputs, nil)
+
+	ids, err := ct.FindImagesInLocalRegistry("jumppad.dev/localcache/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, ids)
+}
+
+func TestContainerdDoesNotPullImageWhenCached(t *testing.T) {
+	outputs := map[string]string{
+		"images": `{"ID":"abc","Repository":"consul","Tag":"1.6.1"}`,
+	}
+
+	mn, ct, mil := testContainerdSetup(t, outputs, nil)
+
+	err := ct.PullImage(dtypes.Image{Name: "consul:1.6.1"}, false)
+	assert.NoError(t, err)
+
+	assert.Len(t, getNerdctlCalls(mn, "pull"), 0)
+	mil.AssertNotCalled(t, "Log", mock.Anything, mock.Anything)
+}
+
+func TestContainerdPullsImageWithPlatform(t *testing.T) {
+	mn, ct, mil := testContainerdSetup(t, map[string]string{}, nil)
+
+	err := ct.PullImage(dtypes.Image{Name: "consul:1.6.1", Platform: "linux/arm64"}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"pull", "--platform", "linux/arm64", "docker.io/library/consul:1.6.1"}, getNerdctlCalls(mn, "pull")[0])
+	mil.AssertCalled(t, "Log", "docker.io/library/consul:1.6.1", mock.Anything)
+}
+
+func TestContainerdBuildsImageWithMultiplePlatforms(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{}, nil)
+
+	b := &dtypes.Build{Name: "test", Context: "../../../examples/build/src", Platforms: []string{"linux/amd64", "linux/arm64"}}
+	name, err := ct.BuildContainer(b, false)
+	assert.NoError(t, err)
+	assert.Contains(t, name, "-linux-amd64_linux-arm64")
+
+	args := getNerdctlCalls(mn, "build")[0]
+	assert.Contains(t, strings.Join(args, " "), "--platform linux/amd64,linux/arm64")
+}
+
+func TestContainerdPullsImageWithCredentials(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{}, nil)
+
+	err := ct.PullImage(dtypes.Image{Name: "ghcr.io/jumppad/consul:1.6.1", Username: "nic", Password: "secret"}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"login", "--username", "nic", "--password-stdin", "ghcr.io"}, getNerdctlCalls(mn, "login")[0])
+	assert.Len(t, getNerdctlCalls(mn, "pull"), 1)
+}
+
+func TestContainerdExecuteCommandReturnsExitCode(t *testing.T) {
+	_, ct, _ := testContainerdSetup(t, map[string]string{}, map[string]error{"exec": exitError(3)})
+
+	code, err := ct.ExecuteCommand("abc", []string{"ls"}, nil, "/", "", "", 30, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 3, code)
+}
+
+func TestContainerdFindsContainerIDsWithExactName(t *testing.T) {
+	outputs := map[string]string{
+		"ps": `{"ID":"abc","Names":"consul.container.local.jmpd.in"}
+{"ID":"def","Names":"consul.container.local.jmpd.in.old"}`,
+	}
+
+	_, ct, _ := testContainerdSetup(t, outputs, nil)
+
+	ids, err := ct.FindContainerIDs("consul.container.local.jmpd.in")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, ids)
+}
+
+func TestNewContainerTasksReturnsErrorForUnknownRuntime(t *testing.T) {
+	_, err := NewContainerTasks("rkt", nil, nil, logger.NewTestLogger(t))
+	assert.Error(t, err)
+}
+
+func TestContainerdCreatesContainerWithLimitsAndStopSettings(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{"create": "abc123"}, nil)
+
+	cc := &dtypes.Container{
+		Name:        "postgres.container.local.jmpd.in",
+		Image:       &dtypes.Image{Name: "postgres:16"},
+		Ulimits:     []dtypes.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}},
+		Sysctls:     map[string]string{"net.ipv6.conf.all.disable_ipv6": "0"},
+		ExtraHosts:  map[string]string{"db.local": "10.0.0.2"},
+		ShmSize:     256,
+		StopSignal:  "SIGINT",
+		StopTimeout: 120,
+		Volumes:     []dtypes.Volume{{Destination: "/tmp", Type: "tmpfs", TmpfsSize: 64, TmpfsMode: "1777"}},
+	}
+
+	_, err := ct.CreateContainer(cc)
+	assert.NoError(t, err)
+
+	args := strings.Join(getNerdctlCalls(mn, "create")[0], " ")
+	assert.Contains(t, args, "--ulimit nofile=1024:2048")
+	assert.Contains(t, args, "--sysctl net.ipv6.conf.all.disable_ipv6=0")
+	assert.NotContains(t, args, "disable_ipv6=1")
+	assert.Contains(t, args, "--add-host db.local:10.0.0.2")
+	assert.Contains(t, args, "--shm-size 256m")
+	assert.Contains(t, args, "--stop-signal SIGINT --stop-timeout 120")
+	assert.Contains(t, args, "--mount type=tmpfs,target=/tmp,tmpfs-size=64m,tmpfs-mode=1777")
+}
+
+func TestContainerdWaitForContainerReturnsExitCode(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{"wait": "3"}, nil)
+
+	code, err := ct.WaitForContainer("abc", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, []string{"wait", "abc"}, getNerdctlCalls(mn, "wait")[0])
+}
+
+func TestContainerdCopiesFilesBeforeStart(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{"create": "abc123"}, nil)
+
+	cc := &dtypes.Container{
+		Name:  "consul.container.local.jmpd.in",
+		Image: &dtypes.Image{Name: "consul:1.6.1"},
+		Files: []dtypes.File{{Destination: "/etc/consul/config.hcl", Content: "server = true", Mode: 0600}},
+	}
+
+	_, err := ct.CreateContainer(cc)
+	assert.NoError(t, err)
+
+	cp := getNerdctlCalls(mn, "cp")
+	assert.Len(t, cp, 1)
+	assert.Equal(t, "abc123:/etc/consul/config.hcl", cp[0][2])
+
+	// files must be written before the container is started
+	order := []string{}
+	for _, c := range mn.Calls {
+		cmd := c.Arguments.Get(4).([]string)[0]
+		if cmd == "cp" || cmd == "start" {
+			order = append(order, cmd)
+		}
+	}
+
+	assert.Equal(t, []string{"cp", "start"}, order)
+}
+
+func TestContainerdFindsVolumesWithWildcard(t *testing.T) {
+	_, ct, _ := testContainerdSetup(t, map[string]string{
+		"volume ls": "bind.dev.abc123.volume.jmpd.in\nimages.volume.jmpd.in\n",
+	}, nil)
+
+	vols, err := ct.FindVolumes("bind.*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bind.dev.abc123"}, vols)
+}
+
+func TestContainerdFollowsContainerLogs(t *testing.T) {
+	mn, ct, _ := testContainerdSetup(t, map[string]string{"logs": "hello\n"}, nil)
+
+	out := &bytes.Buffer{}
+	err := ct.FollowContainerLogs(context.Background(), "app", 40, out, io.Discard)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "hello\n", out.String())
+	assert.Equal(t, [][]string{{"logs", "--follow", "--tail", "40", "app"}}, getNerdctlCalls(mn, "logs"))
+}
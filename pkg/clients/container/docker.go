@@ -2,8 +2,12 @@ package container
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"os"
+	"strings"
 
+	"github.com/docker/cli/cli/connhelper"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/checkpoint"
 	"github.com/docker/docker/api/types/container"
@@ -39,6 +43,8 @@ type Docker interface {
 	ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error)
 	ContainerExecResize(ctx context.Context, execID string, config container.ResizeOptions) error
 	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	ContainerCommit(ctx context.Context, containerID string, options container.CommitOptions) (container.CommitResponse, error)
 
 	CheckpointCreate(ctx context.Context, container string, options checkpoint.CreateOptions) error
 	CheckpointList(ctx context.Context, container string, options checkpoint.ListOptions) ([]checkpoint.Summary, error)
@@ -60,6 +66,7 @@ type Docker interface {
 
 	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
 	ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (image.InspectResponse, []byte, error)
 	ImageSave(ctx context.Context, imageIDs []string, saveOpts ...client.ImageSaveOption) (io.ReadCloser, error)
 	ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error)
@@ -71,9 +78,26 @@ type Docker interface {
 	Info(ctx context.Context) (system.Info, error)
 }
 
-// NewDocker creates a new Docker client
+// NewDocker creates a new Docker client, the host and TLS configuration are
+// read from the DOCKER_HOST, DOCKER_TLS_VERIFY and DOCKER_CERT_PATH
+// environment variables. ssh:// hosts are accessed using the ssh client
 func NewDocker() (Docker, error) {
-	cli, err := client.NewClientWithOpts(client.WithHostFromEnv(), client.WithVersion("1.41"))
+	opts := []client.Opt{
+		client.WithHostFromEnv(),
+		client.WithTLSClientConfigFromEnv(),
+		client.WithVersion("1.41"),
+	}
+
+	if dh := os.Getenv("DOCKER_HOST"); strings.HasPrefix(dh, "ssh://") {
+		helper, err := connhelper.GetConnectionHelper(dh)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create ssh connection to Docker host %s: %w", dh, err)
+		}
+
+		opts = append(opts, client.WithHost(helper.Host), client.WithDialContext(helper.Dialer))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -4,7 +4,9 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -26,8 +28,11 @@ import (
 	"github.com/docker/docker/api/types/network"
 	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 	dtypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/images"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
@@ -36,6 +41,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/moby/sys/signal"
 	"github.com/moby/term"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 const (
@@ -55,14 +61,18 @@ const defaultExitCode = 254
 type DockerTasks struct {
 	engineType    string
 	storageDriver string
-	memory        int
-	cpu           int
-	c             Docker
-	il            images.ImageLog
-	l             logger.Logger
-	tg            *ctar.TarGz
-	force         bool
-	defaultWait   time.Duration
+	// containerdStore is true when the engine uses the containerd image
+	// store which can hold multi-platform images
+	containerdStore bool
+	memory          int
+	cpu             int
+	c               Docker
+	il              images.ImageLog
+	l               logger.Logger
+	tg              *ctar.TarGz
+	force           bool
+	defaultWait     time.Duration
+	remote          bool
 }
 
 // NewDockerTasks creates a DockerTasks with the given Docker client
@@ -91,7 +101,14 @@ func NewDockerTasks(c Docker, il images.ImageLog, tg *ctar.TarGz, l logger.Logge
 		return nil, fmt.Errorf("error checking server storage driver, error: %s", err)
 	}
 
-	return &DockerTasks{engineType: t, storageDriver: info.Driver, c: c, il: il, tg: tg, l: l, defaultWait: 1 * time.Second, cpu: info.NCPU, memory: int(info.MemTotal)}, nil
+	containerdStore := false
+	for _, ds := range info.DriverStatus {
+		if len(ds) == 2 && ds[0] == "driver-type" && ds[1] == "io.containerd.snapshotter.v1" {
+			containerdStore = true
+		}
+	}
+
+	return &DockerTasks{engineType: t, storageDriver: info.Driver, containerdStore: containerdStore, c: c, il: il, tg: tg, l: l, defaultWait: 1 * time.Second, cpu: info.NCPU, memory: int(info.MemTotal), remote: utils.IsRemoteDockerHost()}, nil
 }
 
 func (d *DockerTasks) EngineInfo() *dtypes.EngineInfo {
@@ -113,6 +130,11 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 	// default docker.io registry
 	c.Image.Name = makeImageCanonical(c.Image.Name)
 
+	platform, err := parsePlatform(c.Image.Platform)
+	if err != nil {
+		return "", err
+	}
+
 	// create a unique name based on service network [container].[network].shipyard
 	// attach to networks
 	// - networkRef
@@ -146,6 +168,21 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 		Tty:          true,
 		OpenStdin:    true,
 		User:         user,
+		StopSignal:   c.StopSignal,
+	}
+
+	if c.StopTimeout > 0 {
+		dc.StopTimeout = &c.StopTimeout
+	}
+
+	if c.HealthCheck != nil {
+		dc.Healthcheck = &container.HealthConfig{
+			Test:        c.HealthCheck.Test,
+			Interval:    c.HealthCheck.Interval,
+			Timeout:     c.HealthCheck.Timeout,
+			StartPeriod: c.HealthCheck.StartPeriod,
+			Retries:     c.HealthCheck.Retries,
+		}
 	}
 
 	// create the host and network configs
@@ -201,7 +238,19 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 		}
 	}
 
-	// Add GPU details
+	for _, u := range c.Ulimits {
+		hc.Ulimits = append(hc.Ulimits, &units.Ulimit{Name: u.Name, Soft: int64(u.Soft), Hard: int64(u.Hard)})
+	}
+
+	// shm size is specified in megabytes, docker uses bytes
+	if c.ShmSize > 0 {
+		hc.ShmSize = int64(c.ShmSize) * 1024 * 1024
+	}
+
+	// add any additional entries to /etc/hosts, sorted so the config is stable
+	for _, h := range sortedKeys(c.ExtraHosts) {
+		hc.ExtraHosts = append(hc.ExtraHosts, fmt.Sprintf("%s:%s", h, c.ExtraHosts[h]))
+	}
 
 	// by default the container should NOT be attached to a network
 	nc.EndpointsConfig = make(map[string]*network.EndpointSettings)
@@ -209,6 +258,7 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 	// Create mounts
 	mounts := make([]mount.Mount, 0)
 	volumes := []string{}
+	files := append([]dtypes.File{}, c.Files...)
 
 	for _, vc := range c.Volumes {
 		// default mount type to bind
@@ -224,6 +274,24 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 			t = mount.TypeTmpfs
 		}
 
+		// a remote engine does not share the local filesystem, the source
+		// is synced to a managed volume or copied to the container when a file
+		if t == mount.TypeBind && d.remote && !remoteHostPaths[vc.Source] {
+			vol, file, err := d.syncBindMount(c.Image.Name, platform, vc)
+			if err != nil {
+				return "", err
+			}
+
+			if file != nil {
+				files = append(files, *file)
+				continue
+			}
+
+			t = mount.TypeVolume
+			vc.Source = vol
+			vc.SelinuxRelabel = ""
+		}
+
 		bp := mount.PropagationRPrivate
 		switch vc.BindPropagation {
 		case "shared":
@@ -258,6 +326,14 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 			bindOptions = &mount.BindOptions{Propagation: bp, NonRecursive: vc.BindPropagationNonRecursive}
 		}
 
+		var tmpfsOptions *mount.TmpfsOptions
+		if t == mount.TypeTmpfs && (vc.TmpfsSize > 0 || vc.TmpfsMode != "") {
+			tmpfsOptions, err = createTmpfsOptions(vc)
+			if err != nil {
+				return "", err
+			}
+		}
+
 		if vc.SelinuxRelabel != "" && vc.BindPropagationNonRecursive {
 			return "", errors.New("cannot apply selinux relabeling and non-recursive bind mounts with docker")
 		}
@@ -279,11 +355,12 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 			volumes = append(volumes, fmt.Sprintf("%s:%s:%s", vc.Source, vc.Destination, strings.Join(options, ",")))
 		} else {
 			mounts = append(mounts, mount.Mount{
-				Type:        t,
-				Source:      vc.Source,
-				Target:      vc.Destination,
-				ReadOnly:    vc.ReadOnly,
-				BindOptions: bindOptions,
+				Type:         t,
+				Source:       vc.Source,
+				Target:       vc.Destination,
+				ReadOnly:     vc.ReadOnly,
+				BindOptions:  bindOptions,
+				TmpfsOptions: tmpfsOptions,
 			})
 		}
 
@@ -345,7 +422,16 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 		hc.Sysctls = map[string]string{"net.ipv6.conf.all.disable_ipv6": "1"}
 	}
 
-	cont, err := d.c.ContainerCreate(context.Background(), dc, hc, nc, nil, c.Name)
+	// add any user defined sysctls, these take precedence over the defaults
+	if len(c.Sysctls) > 0 && hc.Sysctls == nil {
+		hc.Sysctls = map[string]string{}
+	}
+
+	for k, v := range c.Sysctls {
+		hc.Sysctls[k] = v
+	}
+
+	cont, err := d.c.ContainerCreate(context.Background(), dc, hc, nc, platform, c.Name)
 	if err != nil {
 		return "", err
 	}
@@ -397,6 +483,12 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 		}
 	}
 
+	// write any files before the container starts
+	err = d.CopyFilesToContainer(cont.ID, files)
+	if err != nil {
+		return "", err
+	}
+
 	err = d.c.ContainerStart(context.Background(), cont.ID, container.StartOptions{})
 	if err != nil {
 		return "", err
@@ -405,6 +497,99 @@ func (d *DockerTasks) CreateContainer(c *dtypes.Container) (string, error) {
 	return cont.ID, nil
 }
 
+// remoteHostPaths are the bind mount sources that refer to the remote host
+// so are not synced, the Docker socket and the kernel modules mounted by the
+// kind driver
+var remoteHostPaths = map[string]bool{
+	"/var/run/docker.sock": true,
+	"/lib/modules":         true,
+}
+
+// bindVolumePrefix returns the prefix for the names of the volumes that bind
+// mounts are synced to for the current workspace
+func bindVolumePrefix() string {
+	return fmt.Sprintf("bind.%s.", utils.Workspace())
+}
+
+// syncBindMount copies the source of a bind mount to the remote engine. When the
+// source is a directory the contents are copied to a managed volume which is
+// returned, the volume name is derived from the path and the contents of the
+// source so the volume is shared by all containers that mount the same source
+// and a new volume is created when the source changes. Volumes are removed
+// with the last container that uses them.
+// When the source is a file it is returned so it can be written to the
+// container. Changes to the source are not synced until the container is
+// recreated
+func (d *DockerTasks) syncBindMount(img string, platform *specs.Platform, vc dtypes.Volume) (string, *dtypes.File, error) {
+	fi, err := os.Stat(vc.Source)
+	if err != nil {
+		d.l.Debug("Creating directory for container volume", "directory", vc.Source, "volume", vc.Destination)
+		// source does not exist, create the source as a directory
+		err := os.MkdirAll(vc.Source, os.ModePerm)
+		if err != nil {
+			return "", nil, fmt.Errorf("source for Volume %s does not exist, error creating directory: %w", vc.Source, err)
+		}
+
+		fi, err = os.Stat(vc.Source)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !fi.IsDir() {
+		content, err := os.ReadFile(vc.Source)
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to read source for Volume %s: %w", vc.Source, err)
+		}
+
+		return "", &dtypes.File{Destination: vc.Destination, Content: string(content), Mode: fi.Mode().Perm()}, nil
+	}
+
+	dh, err := utils.HashDir(vc.Source)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create checksum for source %s: %w", vc.Source, err)
+	}
+
+	hash := sha256.Sum256([]byte(vc.Source + dh))
+	vol, err := d.CreateVolume(fmt.Sprintf("%s%x", bindVolumePrefix(), hash[:6]))
+	if err != nil {
+		return "", nil, err
+	}
+
+	d.l.Debug("Syncing bind mount to remote volume", "source", vc.Source, "volume", vol)
+
+	// create a container that is never started to copy the source to the
+	// volume, the image of the container being created is used as it has
+	// already been pulled
+	cont, err := d.c.ContainerCreate(
+		context.Background(),
+		&container.Config{Image: img, Entrypoint: []string{"true"}},
+		&container.HostConfig{Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: vol, Target: "/sync"}}},
+		nil,
+		platform,
+		"",
+	)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create container to sync volume %s: %w", vol, err)
+	}
+
+	defer d.c.ContainerRemove(context.Background(), cont.ID, container.RemoveOptions{Force: true})
+
+	buf := &bytes.Buffer{}
+	err = d.tg.Create(buf, &ctar.TarGzOptions{OmitRoot: true}, []string{vc.Source})
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create archive for source %s: %w", vc.Source, err)
+	}
+
+	err = d.c.CopyToContainer(context.Background(), cont.ID, "/sync", buf, container.CopyToContainerOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to copy source %s to volume %s: %w", vc.Source, vol, err)
+	}
+
+	return vol, nil, nil
+}
+
 // ContainerInfo returns the Docker container info
 func (d *DockerTasks) ContainerInfo(id string) (interface{}, error) {
 	cj, err := d.c.ContainerInspect(context.Background(), id)
@@ -490,13 +675,13 @@ func (d *DockerTasks) PullImage(img dtypes.Image, force bool) error {
 			return err
 		}
 
-		// found the image do nothing
-		if id != "" {
+		// found the image do nothing, unless a different platform is required
+		if id != "" && d.imageMatchesPlatform(id, img.Platform) {
 			return nil
 		}
 	}
 
-	ipo := image.PullOptions{}
+	ipo := image.PullOptions{Platform: img.Platform}
 
 	// if the username and password is not null make an authenticated
 	// image pull
@@ -504,7 +689,7 @@ func (d *DockerTasks) PullImage(img dtypes.Image, force bool) error {
 		ipo.RegistryAuth = createRegistryAuth(img.Username, img.Password)
 	}
 
-	d.l.Debug("Pulling image", "image", in)
+	d.l.Debug("Pulling image", "image", in, "platform", img.Platform)
 
 	out, err := d.c.ImagePull(context.Background(), in, ipo)
 	if err != nil {
@@ -523,6 +708,16 @@ func (d *DockerTasks) PullImage(img dtypes.Image, force bool) error {
 	return nil
 }
 
+// InspectImage returns the metadata for the image in the local registry
+func (d *DockerTasks) InspectImage(name string) (*dtypes.ImageInfo, error) {
+	info, _, err := d.c.ImageInspectWithRaw(context.Background(), name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect image %s: %w", name, err)
+	}
+
+	return newImageInfo(info), nil
+}
+
 func (d *DockerTasks) PushImage(img dtypes.Image) error {
 	ipo := image.PushOptions{}
 	// if the username and password is not null make an authenticated
@@ -596,11 +791,31 @@ func (d *DockerTasks) FindContainerIDs(fqdn string) ([]string, error) {
 
 // RemoveContainer with the given id
 func (d *DockerTasks) RemoveContainer(id string, force bool) error {
+	vols := d.syncedVolumes(id)
+
+	err := d.removeContainer(id, force)
+	if err != nil {
+		return err
+	}
+
+	// volumes still used by other containers can not be removed, these are
+	// removed with the last container
+	for _, v := range vols {
+		err := d.c.VolumeRemove(context.Background(), v, false)
+		if err != nil {
+			d.l.Debug("Unable to remove synced volume", "container", id, "volume", v, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DockerTasks) removeContainer(id string, force bool) error {
 	var err error
 
 	// try and shutdown graceful only if we are not forcing
 	if !force && !d.force {
-		timeout := 30
+		timeout := d.stopTimeout(id)
 		err = d.c.ContainerStop(context.Background(), id, container.StopOptions{Timeout: &timeout})
 		if err == nil {
 			d.l.Debug("Container stopped gracefully, removing", "container", id)
@@ -619,6 +834,65 @@ func (d *DockerTasks) RemoveContainer(id string, force bool) error {
 	return d.c.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true, RemoveVolumes: true})
 }
 
+// syncedVolumes returns the names of the volumes mounted by the container
+// that bind mounts have been synced to, these are only created for remote
+// engines
+func (d *DockerTasks) syncedVolumes(id string) []string {
+	if !d.remote {
+		return nil
+	}
+
+	info, err := d.c.ContainerInspect(context.Background(), id)
+	if err != nil {
+		return nil
+	}
+
+	vols := []string{}
+	for _, m := range info.Mounts {
+		if m.Type == mount.TypeVolume && strings.HasPrefix(m.Name, bindVolumePrefix()) {
+			vols = append(vols, m.Name)
+		}
+	}
+
+	return vols
+}
+
+// WaitForContainer blocks until the container exits and returns the exit code
+func (d *DockerTasks) WaitForContainer(id string, timeout int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	rc, ec := d.c.ContainerWait(ctx, id, container.WaitConditionNotRunning)
+
+	select {
+	case r := <-rc:
+		if r.Error != nil {
+			return int(r.StatusCode), fmt.Errorf("unable to wait for container %s: %s", id, r.Error.Message)
+		}
+
+		return int(r.StatusCode), nil
+	case err := <-ec:
+		if ctx.Err() != nil {
+			return defaultExitCode, fmt.Errorf("timeout waiting for container %s to exit after %d seconds", id, timeout)
+		}
+
+		return defaultExitCode, fmt.Errorf("unable to wait for container %s: %w", id, err)
+	}
+}
+
+// stopTimeout returns the stop timeout configured for the container, when the
+// container does not have a timeout the default of 30 seconds is used
+func (d *DockerTasks) stopTimeout(id string) int {
+	timeout := 30
+
+	info, err := d.c.ContainerInspect(context.Background(), id)
+	if err == nil && info.Config != nil && info.Config.StopTimeout != nil {
+		timeout = *info.Config.StopTimeout
+	}
+
+	return timeout
+}
+
 func (d *DockerTasks) RemoveImage(id string) error {
 	_, err := d.c.ImageRemove(context.Background(), id, image.RemoveOptions{Force: true})
 
@@ -635,6 +909,21 @@ func (d *DockerTasks) BuildContainer(config *dtypes.Build, force bool) (string,
 	// strip the prefix and grab the first 8chars for the id
 	cs, _ = utils.ReplaceNonURIChars(cs[3:11])
 
+	// images built for a specific platform are tagged with the platform so
+	// that builds for different platforms do not replace each other
+	suffix, err := platformTag(config.Platforms)
+	if err != nil {
+		return "", err
+	}
+
+	cs += suffix
+
+	// the image store used by the classic Docker engine can only hold a
+	// single platform for an image
+	if len(config.Platforms) > 1 && !d.containerdStore {
+		return "", fmt.Errorf("building an image for multiple platforms requires the containerd image store to be enabled for the Docker engine, see https://docs.docker.com/engine/storage/containerd/")
+	}
+
 	// create the fully qualified name using the checksum for the content
 	imageWithId := fmt.Sprintf("jumppad.dev/localcache/%s:%s", config.Name, strings.ToLower(cs))
 	imageWithId = makeImageCanonical(imageWithId)
@@ -669,7 +958,7 @@ func (d *DockerTasks) BuildContainer(config *dtypes.Build, force bool) (string,
 		buildArgs[k] = &v
 	}
 
-	d.l.Debug("Building image", "id", imageWithId, "args", config.Args)
+	d.l.Debug("Building image", "id", imageWithId, "args", config.Args, "platforms", config.Platforms)
 
 	// tar the build context folder and send to the server
 	buildOpts := types.ImageBuildOptions{
@@ -677,6 +966,13 @@ func (d *DockerTasks) BuildContainer(config *dtypes.Build, force bool) (string,
 		Tags:       []string{imageWithId},
 		Remove:     true,
 		BuildArgs:  buildArgs,
+		Platform:   strings.Join(config.Platforms, ","),
+	}
+
+	// multi-platform images are built with BuildKit which stores the image
+	// for each platform and the manifest list in the image store
+	if len(config.Platforms) > 1 {
+		buildOpts.Version = types.BuilderBuildKit
 	}
 
 	var buf bytes.Buffer
@@ -743,11 +1039,48 @@ func (d *DockerTasks) RemoveVolume(name string) error {
 	return d.c.VolumeRemove(context.Background(), vn, true)
 }
 
+// FindVolumes returns the names of the volumes that match the given name,
+// the name can contain wildcards
+func (d *DockerTasks) FindVolumes(name string) ([]string, error) {
+	ops, err := d.c.VolumeList(context.Background(), volume.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list docker volumes: %w", err)
+	}
+
+	vols := []string{}
+	for _, v := range ops.Volumes {
+		if n, ok := volumeRef(v.Name, name); ok {
+			vols = append(vols, n)
+		}
+	}
+
+	return vols, nil
+}
+
 // ContainerLogs streams the logs for the container to the returned io.ReadCloser
 func (d *DockerTasks) ContainerLogs(id string, stdOut, stdErr bool) (io.ReadCloser, error) {
 	return d.c.ContainerLogs(context.Background(), id, container.LogsOptions{ShowStderr: stdErr, ShowStdout: stdOut})
 }
 
+// FollowContainerLogs writes the last lines of the container logs and any new
+// output to stdOut and stdErr until the container stops or ctx is cancelled
+func (d *DockerTasks) FollowContainerLogs(ctx context.Context, id string, lines int, stdOut, stdErr io.Writer) error {
+	rc, err := d.c.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// the log stream multiplexes stdout and stderr
+	_, err = stdcopy.StdCopy(stdOut, stdErr, rc)
+	return err
+}
+
 // CopyFromContainer copies a file from a container
 func (d *DockerTasks) CopyFromContainer(id, src, dst string) error {
 	d.l.Debug("Copying file from", "id", id, "src", src, "dst", dst)
@@ -1063,6 +1396,50 @@ func (d *DockerTasks) CopyFileToContainer(containerID, filename, path string) er
 	return nil
 }
 
+// CopyFilesToContainer writes the files to the container, the files are
+// written as a single tar archive extracted at the root of the container, any
+// missing parent directories are created
+func (d *DockerTasks) CopyFilesToContainer(containerID string, files []dtypes.File) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	ta := tar.NewWriter(buf)
+
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:     strings.TrimPrefix(path.Clean(f.Destination), "/"),
+			Mode:     int64(f.Mode.Perm()),
+			Size:     int64(len(f.Content)),
+			ModTime:  time.Now(),
+			Typeflag: tar.TypeReg,
+		}
+
+		err := ta.WriteHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("unable to write tar header for file %s: %w", f.Destination, err)
+		}
+
+		_, err = ta.Write([]byte(f.Content))
+		if err != nil {
+			return fmt.Errorf("unable to write file %s to tar: %w", f.Destination, err)
+		}
+	}
+
+	err := ta.Close()
+	if err != nil {
+		return fmt.Errorf("unable to create tar archive for files: %w", err)
+	}
+
+	err = d.c.CopyToContainer(context.Background(), containerID, "/", buf, container.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to copy files to container: %w", err)
+	}
+
+	return nil
+}
+
 // ExecuteCommand allows the execution of commands in a running docker container
 // id is the id of the container to execute the command in
 // command is a slice of strings to execute
@@ -1372,16 +1749,301 @@ func (d *DockerTasks) FindNetwork(id string) (dtypes.NetworkAttachment, error) {
 	return dtypes.NetworkAttachment{}, fmt.Errorf("a network with the label id: %s, was not found", id)
 }
 
+// NetworkCreate creates a new network with the given name
+func (d *DockerTasks) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) error {
+	_, err := d.c.NetworkCreate(ctx, name, options)
+	return err
+}
+
+// NetworkRemove removes the network with the given name
+func (d *DockerTasks) NetworkRemove(ctx context.Context, name string) error {
+	return d.c.NetworkRemove(ctx, name)
+}
+
+// NetworkList returns the networks matching the name, when name is empty
+// all networks are returned
+func (d *DockerTasks) NetworkList(ctx context.Context, name string) ([]network.Summary, error) {
+	args := filters.NewArgs()
+	if name != "" {
+		args.Add("name", name)
+	}
+
+	return d.c.NetworkList(ctx, network.ListOptions{Filters: args})
+}
+
 func (d *DockerTasks) TagImage(source, destination string) error {
 	return d.c.ImageTag(context.Background(), source, destination)
 }
 
+// SaveContainer commits the filesystem of the container to the given image,
+// the container is paused while the commit is taken. The returned snapshot
+// contains the inspected configuration of the container which is used to
+// recreate the container with the same settings and networks
+func (d *DockerTasks) SaveContainer(id, image string) (*dtypes.ContainerSnapshot, error) {
+	d.l.Debug("Saving container", "id", id, "image", image)
+
+	info, err := d.c.ContainerInspect(context.Background(), id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read information about Docker container %s: %w", id, err)
+	}
+
+	_, err = d.c.ContainerCommit(context.Background(), id, container.CommitOptions{Reference: image, Pause: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to commit container %s to image %s: %w", id, image, err)
+	}
+
+	// sidecars reference the container they share a network with by id, the id
+	// changes when the container is recreated so reference by name
+	if info.HostConfig != nil && info.HostConfig.NetworkMode.IsContainer() {
+		target, err := d.c.ContainerInspect(context.Background(), info.HostConfig.NetworkMode.ConnectedContainer())
+		if err != nil {
+			return nil, fmt.Errorf("unable to read information about the network container for %s: %w", id, err)
+		}
+
+		info.HostConfig.NetworkMode = container.NetworkMode(fmt.Sprintf("container:%s", strings.TrimPrefix(target.Name, "/")))
+	}
+
+	volumes := []string{}
+	for _, m := range info.Mounts {
+		if m.Type == mount.TypeVolume && m.Name != "" {
+			volumes = append(volumes, m.Name)
+		}
+	}
+
+	config, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize configuration for container %s: %w", id, err)
+	}
+
+	return &dtypes.ContainerSnapshot{
+		Name:    strings.TrimPrefix(info.Name, "/"),
+		Image:   image,
+		Volumes: volumes,
+		Config:  config,
+	}, nil
+}
+
+// RestoreContainer recreates the container from the image and configuration
+// in the snapshot, any existing container with the same name is removed. The
+// container is attached to the networks it was connected to when saved with
+// the same aliases and addresses and then started
+func (d *DockerTasks) RestoreContainer(s *dtypes.ContainerSnapshot) (string, error) {
+	d.l.Debug("Restoring container", "name", s.Name, "image", s.Image)
+
+	info := container.InspectResponse{}
+	err := json.Unmarshal(s.Config, &info)
+	if err != nil {
+		return "", fmt.Errorf("unable to read configuration for container %s: %w", s.Name, err)
+	}
+
+	if info.Config == nil || info.HostConfig == nil {
+		return "", fmt.Errorf("snapshot for container %s does not contain the container configuration", s.Name)
+	}
+
+	ids, err := d.FindContainerIDs(s.Name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, id := range ids {
+		err := d.RemoveContainer(id, true)
+		if err != nil {
+			return "", fmt.Errorf("unable to remove existing container %s: %w", s.Name, err)
+		}
+	}
+
+	dc := info.Config
+	dc.Image = s.Image
+
+	hc := info.HostConfig
+
+	// custom networks are attached after the container has been created
+	networks := map[string]*network.EndpointSettings{}
+	if !hc.NetworkMode.IsContainer() {
+		hc.NetworkMode = ""
+
+		if info.NetworkSettings != nil {
+			networks = info.NetworkSettings.Networks
+		}
+	}
+
+	cont, err := d.c.ContainerCreate(context.Background(), dc, hc, nil, nil, s.Name)
+	if err != nil {
+		return "", fmt.Errorf("unable to create container %s: %w", s.Name, err)
+	}
+
+	if len(networks) > 0 {
+		created, err := d.c.ContainerInspect(context.Background(), cont.ID)
+		if err != nil {
+			return "", fmt.Errorf("unable to remove container from the default network: %w", err)
+		}
+
+		defaultNets := []string{}
+		for k := range created.NetworkSettings.Networks {
+			if _, ok := networks[k]; !ok {
+				defaultNets = append(defaultNets, k)
+			}
+		}
+
+		// the short id of the original container is added as an alias by the
+		// engine, this is not valid for the new container
+		shortID := info.ID
+		if len(shortID) > 12 {
+			shortID = shortID[:12]
+		}
+
+		for name, ep := range networks {
+			if _, ok := created.NetworkSettings.Networks[name]; ok {
+				continue
+			}
+
+			aliases := []string{}
+			for _, a := range ep.Aliases {
+				if a != shortID {
+					aliases = append(aliases, a)
+				}
+			}
+
+			ip := ""
+			if ep.IPAMConfig != nil {
+				ip = ep.IPAMConfig.IPv4Address
+			}
+
+			err := d.AttachNetwork(name, cont.ID, aliases, ip)
+			if err != nil {
+				return "", fmt.Errorf("unable to connect container %s to network %s: %w", s.Name, name, err)
+			}
+		}
+
+		for _, n := range defaultNets {
+			err := d.c.NetworkDisconnect(context.Background(), n, cont.ID, true)
+			if err != nil {
+				d.l.Warn("Unable to remove container from the network", "name", n, "ref", s.Name, "error", err)
+			}
+		}
+	}
+
+	err = d.c.ContainerStart(context.Background(), cont.ID, container.StartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to start container %s: %w", s.Name, err)
+	}
+
+	return cont.ID, nil
+}
+
+// snapshotVolumeImage is the image used to mount volumes when exporting and
+// importing the contents of volumes, the container is never started so the
+// versioned connector image that jumppad already uses for clusters is reused
+// rather than pulling an additional image
+const snapshotVolumeImage = "ghcr.io/jumppad-labs/connector:v0.4.0"
+
+// ExportVolume writes the contents of the volume with the given name to w as
+// a tar archive
+func (d *DockerTasks) ExportVolume(name string, w io.Writer) error {
+	d.l.Debug("Exporting volume", "name", name)
+
+	id, err := d.createVolumeContainer(name)
+	if err != nil {
+		return err
+	}
+
+	defer d.c.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+
+	reader, _, err := d.c.CopyFromContainer(context.Background(), id, "/volume")
+	if err != nil {
+		return fmt.Errorf("unable to read contents of volume %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(w, reader)
+	if err != nil {
+		return fmt.Errorf("unable to write contents of volume %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ImportVolume replaces the volume with the given name with a new volume that
+// contains the tar archive read from r
+func (d *DockerTasks) ImportVolume(name string, r io.Reader) error {
+	d.l.Debug("Importing volume", "name", name)
+
+	err := d.c.VolumeRemove(context.Background(), name, true)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("unable to remove existing volume %s: %w", name, err)
+	}
+
+	_, err = d.c.VolumeCreate(context.Background(), volume.CreateOptions{Name: name, Driver: "local", DriverOpts: map[string]string{}})
+	if err != nil {
+		return fmt.Errorf("failed to create volume '%s': %w", name, err)
+	}
+
+	id, err := d.createVolumeContainer(name)
+	if err != nil {
+		return err
+	}
+
+	defer d.c.ContainerRemove(context.Background(), id, container.RemoveOptions{Force: true})
+
+	err = d.c.CopyToContainer(context.Background(), id, "/", r, container.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to write contents of volume %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// createVolumeContainer creates a container that is never started with the
+// volume mounted at /volume, this allows the contents of the volume to be read
+// and written with the copy API. Archives are rooted at the volume directory
+// so that an exported archive can be copied back to the root of the container
+func (d *DockerTasks) createVolumeContainer(name string) (string, error) {
+	err := d.PullImage(dtypes.Image{Name: snapshotVolumeImage}, false)
+	if err != nil {
+		return "", err
+	}
+
+	cont, err := d.c.ContainerCreate(
+		context.Background(),
+		&container.Config{Image: snapshotVolumeImage, Entrypoint: []string{"true"}},
+		&container.HostConfig{Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: name, Target: "/volume"}}},
+		nil,
+		nil,
+		"",
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("unable to create container for volume %s: %w", name, err)
+	}
+
+	return cont.ID, nil
+}
+
 // publishedPorts defines a Docker published port
 type publishedPorts struct {
 	ExposedPorts map[nat.Port]struct{}
 	PortBindings map[nat.Port][]nat.PortBinding
 }
 
+// createTmpfsOptions converts the tmpfs size and mode of a volume to Docker
+// TmpfsOptions, size is specified in megabytes and mode in octal
+func createTmpfsOptions(vc dtypes.Volume) (*mount.TmpfsOptions, error) {
+	opts := &mount.TmpfsOptions{
+		SizeBytes: int64(vc.TmpfsSize) * 1024 * 1024,
+	}
+
+	if vc.TmpfsMode != "" {
+		mode, err := strconv.ParseUint(vc.TmpfsMode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tmpfs mode %s for volume %s, mode must be specified in octal: %w", vc.TmpfsMode, vc.Destination, err)
+		}
+
+		opts.Mode = os.FileMode(mode)
+	}
+
+	return opts, nil
+}
+
 // createPublishedPorts converts a list of config.Port to Docker publishedPorts type
 func createPublishedPorts(ps []dtypes.Port) publishedPorts {
 	pp := publishedPorts{
@@ -1469,6 +2131,85 @@ func createRegistryAuth(username, password string) string {
 	return ec
 }
 
+// platformTag returns the suffix added to the tag of an image built for the
+// given platforms e.g. -linux-amd64_linux-arm64, an error is returned when
+// a platform is invalid
+func platformTag(platforms []string) (string, error) {
+	if len(platforms) == 0 {
+		return "", nil
+	}
+
+	tags := []string{}
+	for _, p := range platforms {
+		_, err := parsePlatform(p)
+		if err != nil {
+			return "", err
+		}
+
+		tags = append(tags, strings.ReplaceAll(p, "/", "-"))
+	}
+
+	return "-" + strings.Join(tags, "_"), nil
+}
+
+// parsePlatform converts a platform in the format os/arch[/variant] e.g.
+// linux/arm64/v8 into an OCI platform, nil is returned for an empty platform
+func parsePlatform(platform string) (*specs.Platform, error) {
+	if platform == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %s, platforms must be specified as os/arch[/variant] e.g. linux/arm64", platform)
+	}
+
+	p := &specs.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+
+	return p, nil
+}
+
+// imageMatchesPlatform returns true when the image with the given id in the
+// local registry was built for the platform or when no platform is required
+func (d *DockerTasks) imageMatchesPlatform(id, platform string) bool {
+	p, err := parsePlatform(platform)
+	if err != nil || p == nil {
+		return true
+	}
+
+	info, _, err := d.c.ImageInspectWithRaw(context.Background(), id)
+	if err != nil {
+		d.l.Debug("Unable to inspect image", "id", id, "error", err)
+		return false
+	}
+
+	if info.Os != p.OS || info.Architecture != p.Architecture {
+		return false
+	}
+
+	return p.Variant == "" || info.Variant == p.Variant
+}
+
+// newImageInfo converts the engine image details to image info, the created
+// time is zero when it can not be parsed
+func newImageInfo(info image.InspectResponse) *dtypes.ImageInfo {
+	ii := &dtypes.ImageInfo{
+		ID:          info.ID,
+		RepoDigests: info.RepoDigests,
+	}
+
+	ii.Created, _ = time.Parse(time.RFC3339Nano, info.Created)
+
+	if info.Config != nil {
+		ii.User = info.Config.User
+	}
+
+	return ii
+}
+
 // makeImageCanonical makes sure the image reference uses full canonical name i.e.
 // consul:1.6.1 -> docker.io/library/consul:1.6.1
 func makeImageCanonical(image string) string {
@@ -1598,3 +2339,15 @@ func copy(src, dst string) (int64, error) {
 	nBytes, err := io.Copy(destination, source)
 	return nBytes, err
 }
+
+// volumeRef returns the name used to create the volume with the given fully
+// qualified name and if the name matches the pattern, see FQDNVolumeName
+func volumeRef(fqdn, pattern string) (string, bool) {
+	ref, ok := strings.CutSuffix(fqdn, ".volume."+utils.LocalTLD)
+	if !ok {
+		return "", false
+	}
+
+	m, _ := path.Match(pattern, ref)
+	return ref, m
+}
@@ -91,3 +91,60 @@ func TestBuildListsImagesAndBuildsWhenNotExistsCustomDockerfile(t *testing.T) {
 	params := testutils.GetCalls(&md.Mock, "ImageBuild")[0].Arguments[2].(types.ImageBuildOptions)
 	assert.Equal(t, "./Docker/Dockerfile", params.Dockerfile)
 }
+
+func TestBuildSetsPlatform(t *testing.T) {
+	md, dt := testBuildSetup(t)
+	testutils.RemoveOn(&md.Mock, "ImageList")
+	md.On("ImageList", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	b := &dtypes.Build{Name: "test", Context: "../../../examples/build/src", Platforms: []string{"linux/arm64"}}
+
+	in, err := dt.BuildContainer(b, false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, in, "-linux-arm64")
+
+	params := testutils.GetCalls(&md.Mock, "ImageBuild")[0].Arguments[2].(types.ImageBuildOptions)
+	assert.Equal(t, "linux/arm64", params.Platform)
+}
+
+func TestBuildWithMultiplePlatformsUsesBuildKit(t *testing.T) {
+	md, dt := testBuildSetup(t)
+	testutils.RemoveOn(&md.Mock, "ImageList")
+	md.On("ImageList", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	dt.containerdStore = true
+
+	b := &dtypes.Build{Name: "test", Context: "../../../examples/build/src", Platforms: []string{"linux/amd64", "linux/arm64"}}
+
+	in, err := dt.BuildContainer(b, false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, in, "-linux-amd64_linux-arm64")
+
+	params := testutils.GetCalls(&md.Mock, "ImageBuild")[0].Arguments[2].(types.ImageBuildOptions)
+	assert.Equal(t, "linux/amd64,linux/arm64", params.Platform)
+	assert.Equal(t, types.BuilderBuildKit, params.Version)
+}
+
+func TestBuildWithMultiplePlatformsReturnsErrorWithoutContainerdStore(t *testing.T) {
+	md, dt := testBuildSetup(t)
+	testutils.RemoveOn(&md.Mock, "ImageList")
+	md.On("ImageList", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	b := &dtypes.Build{Name: "test", Context: "../../../examples/build/src", Platforms: []string{"linux/amd64", "linux/arm64"}}
+
+	_, err := dt.BuildContainer(b, false)
+
+	assert.ErrorContains(t, err, "containerd image store")
+	md.AssertNotCalled(t, "ImageBuild", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNewDockerTasksDetectsContainerdStore(t *testing.T) {
+	mk := &mocks.Docker{}
+	mk.On("ServerVersion", mock.Anything).Return(types.Version{}, nil)
+	mk.On("Info", mock.Anything).Return(system.Info{Driver: "overlayfs", DriverStatus: [][2]string{{"driver-type", "io.containerd.snapshotter.v1"}}}, nil)
+
+	dt, err := NewDockerTasks(mk, nil, &tar.TarGz{}, logger.NewTestLogger(t))
+	assert.NoError(t, err)
+	assert.True(t, dt.containerdStore)
+}
@@ -1,10 +1,12 @@
 package container
 
 import (
+	gotar "archive/tar"
 	"fmt"
 	"io"
 
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -25,6 +27,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/mohae/deepcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/mock"
 	assert "github.com/stretchr/testify/require"
 )
@@ -606,3 +609,245 @@ func TestContainerLabels(t *testing.T) {
 	assert.Contains(t, dc.Labels, "com.example.foo")
 	assert.Equal(t, "bar", dc.Labels["com.example.foo"])
 }
+
+func TestContainerSetsPlatform(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.Image.Platform = "linux/arm64/v8"
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	p := params[4].(*specs.Platform)
+	assert.Equal(t, "linux", p.OS)
+	assert.Equal(t, "arm64", p.Architecture)
+	assert.Equal(t, "v8", p.Variant)
+}
+
+func TestContainerReturnsErrorWhenPlatformInvalid(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.Image.Platform = "arm64"
+
+	err := setupContainer(t, cc, md, mic)
+	assert.Error(t, err)
+
+	md.AssertNotCalled(t, "ContainerCreate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContainerConfiguresUlimitsSysctlsAndHosts(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.Ulimits = []dtypes.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}
+	cc.Sysctls = map[string]string{"net.core.somaxconn": "1024"}
+	cc.ExtraHosts = map[string]string{"db.local": "10.0.0.2", "api.local": "10.0.0.3"}
+	cc.ShmSize = 256
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	hc := params[2].(*container.HostConfig)
+
+	assert.Len(t, hc.Ulimits, 1)
+	assert.Equal(t, "nofile", hc.Ulimits[0].Name)
+	assert.Equal(t, int64(1024), hc.Ulimits[0].Soft)
+	assert.Equal(t, int64(2048), hc.Ulimits[0].Hard)
+	assert.Equal(t, "1024", hc.Sysctls["net.core.somaxconn"])
+	assert.Equal(t, "1", hc.Sysctls["net.ipv6.conf.all.disable_ipv6"])
+	assert.Equal(t, []string{"api.local:10.0.0.3", "db.local:10.0.0.2"}, hc.ExtraHosts)
+	assert.Equal(t, int64(256*1024*1024), hc.ShmSize)
+}
+
+func TestContainerConfiguresStopSignalAndTimeout(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.StopSignal = "SIGINT"
+	cc.StopTimeout = 120
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	dc := params[1].(*container.Config)
+
+	assert.Equal(t, "SIGINT", dc.StopSignal)
+	assert.Equal(t, 120, *dc.StopTimeout)
+}
+
+func TestContainerSetsTmpfsOptionsForVolumeTypeTmpfs(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.Volumes[0].Type = "tmpfs"
+	cc.Volumes[0].TmpfsSize = 64
+	cc.Volumes[0].TmpfsMode = "1777"
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	hc := params[2].(*container.HostConfig)
+
+	assert.Len(t, hc.Mounts, 1)
+	assert.Equal(t, mount.TypeTmpfs, hc.Mounts[0].Type)
+	assert.Equal(t, int64(64*1024*1024), hc.Mounts[0].TmpfsOptions.SizeBytes)
+	assert.Equal(t, os.FileMode(01777), hc.Mounts[0].TmpfsOptions.Mode)
+}
+
+func TestContainerConfiguresHealthCheck(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.HealthCheck = &dtypes.HealthCheck{
+		Test:        []string{"CMD-SHELL", "pg_isready"},
+		Interval:    10 * time.Second,
+		Timeout:     5 * time.Second,
+		StartPeriod: 30 * time.Second,
+		Retries:     3,
+	}
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	dc := params[1].(*container.Config)
+
+	assert.Equal(t, []string{"CMD-SHELL", "pg_isready"}, dc.Healthcheck.Test)
+	assert.Equal(t, 10*time.Second, dc.Healthcheck.Interval)
+	assert.Equal(t, 5*time.Second, dc.Healthcheck.Timeout)
+	assert.Equal(t, 30*time.Second, dc.Healthcheck.StartPeriod)
+	assert.Equal(t, 3, dc.Healthcheck.Retries)
+}
+
+func TestContainerCopiesFilesBeforeStart(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+	cc.Files = []dtypes.File{
+		{Destination: "/etc/app/config.hcl", Content: "port = 8080", Mode: 0600},
+	}
+
+	files := map[string]*gotar.Header{}
+	content := map[string]string{}
+
+	md.On("CopyToContainer", mock.Anything, "test", "/", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			tr := gotar.NewReader(args.Get(3).(io.Reader))
+			for {
+				hdr, err := tr.Next()
+				if err != nil {
+					break
+				}
+
+				d, _ := io.ReadAll(tr)
+				files[hdr.Name] = hdr
+				content[hdr.Name] = string(d)
+			}
+		}).
+		Return(nil)
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(0600), files["etc/app/config.hcl"].Mode)
+	assert.Equal(t, "port = 8080", content["etc/app/config.hcl"])
+
+	// files must be written before the container is started
+	order := []string{}
+	for _, c := range md.Calls {
+		if c.Method == "CopyToContainer" || c.Method == "ContainerStart" {
+			order = append(order, c.Method)
+		}
+	}
+
+	assert.Equal(t, []string{"CopyToContainer", "ContainerStart"}, order)
+}
+
+func TestContainerDoesNotCopyFilesWhenNoneSet(t *testing.T) {
+	cc, md, mic := createContainerConfig()
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	md.AssertNotCalled(t, "CopyToContainer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContainerSyncsBindMountsWhenDockerHostRemote(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "ssh://nic@10.5.0.20")
+	t.Setenv(utils.WorkspaceEnvVar, "dev")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("hello"), 0644)
+
+	file := filepath.Join(t.TempDir(), "config.hcl")
+	os.WriteFile(file, []byte("port = 8080"), 0600)
+
+	cc, md, mic := createContainerConfig()
+	cc.Volumes = []dtypes.Volume{
+		{Source: dir, Destination: "/data"},
+		{Source: file, Destination: "/etc/app/config.hcl"},
+		{Source: "/var/run/docker.sock", Destination: "/var/run/docker.sock"},
+		{Source: "/lib/modules", Destination: "/lib/modules", ReadOnly: true},
+	}
+
+	md.On("CopyToContainer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	// the directory is synced to a volume for the workspace
+	md.AssertNumberOfCalls(t, "VolumeCreate", 1)
+	vc := testutils.GetCalls(&md.Mock, "VolumeCreate")[0].Arguments[1].(volume.CreateOptions)
+	assert.True(t, strings.HasPrefix(vc.Name, "bind.dev."))
+
+	copies := testutils.GetCalls(&md.Mock, "CopyToContainer")
+	assert.Equal(t, "/sync", copies[0].Arguments[2])
+
+	// the file is copied to the container
+	assert.Equal(t, "/", copies[1].Arguments[2])
+
+	creates := testutils.GetCalls(&md.Mock, "ContainerCreate")
+	assert.Len(t, creates, 2)
+
+	hc := creates[1].Arguments[2].(*container.HostConfig)
+	assert.Len(t, hc.Mounts, 3)
+	assert.Equal(t, mount.TypeVolume, hc.Mounts[0].Type)
+	assert.Equal(t, "test_volume", hc.Mounts[0].Source)
+	assert.Equal(t, "/data", hc.Mounts[0].Target)
+
+	// the docker socket and kernel modules refer to the remote host
+	assert.Equal(t, mount.TypeBind, hc.Mounts[1].Type)
+	assert.Equal(t, "/var/run/docker.sock", hc.Mounts[1].Source)
+	assert.Equal(t, mount.TypeBind, hc.Mounts[2].Type)
+	assert.Equal(t, "/lib/modules", hc.Mounts[2].Source)
+}
+
+func TestContainerSyncsChangedBindMountToNewVolume(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "ssh://nic@10.5.0.20")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("hello"), 0644)
+
+	cc, md, mic := createContainerConfig()
+	cc.Volumes = []dtypes.Volume{{Source: dir, Destination: "/data"}}
+
+	md.On("CopyToContainer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("updated"), 0644)
+
+	err = setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	vc := testutils.GetCalls(&md.Mock, "VolumeCreate")
+	assert.Len(t, vc, 2)
+	assert.NotEqual(t, vc[0].Arguments[1].(volume.CreateOptions).Name, vc[1].Arguments[1].(volume.CreateOptions).Name)
+}
+
+func TestContainerDoesNotSyncBindMountsWhenDockerHostLocal(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+
+	cc, md, mic := createContainerConfig()
+
+	err := setupContainer(t, cc, md, mic)
+	assert.NoError(t, err)
+
+	md.AssertNotCalled(t, "VolumeCreate", mock.Anything, mock.Anything)
+
+	hc := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments[2].(*container.HostConfig)
+	assert.Equal(t, mount.TypeBind, hc.Mounts[0].Type)
+}
@@ -2,12 +2,15 @@ package container
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"testing"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	imocks "github.com/jumppad-labs/jumppad/pkg/clients/images/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
@@ -34,3 +37,28 @@ func TestContainerLogsCalled(t *testing.T) {
 	assert.NotNil(t, rc)
 	assert.Error(t, err)
 }
+
+func TestFollowContainerLogsWritesDemultiplexedStreams(t *testing.T) {
+	stream := &bytes.Buffer{}
+	stdcopy.NewStdWriter(stream, stdcopy.Stdout).Write([]byte("out\n"))
+	stdcopy.NewStdWriter(stream, stdcopy.Stderr).Write([]byte("err\n"))
+
+	md := &mocks.Docker{}
+	md.On("ServerVersion", mock.Anything).Return(types.Version{}, nil)
+	md.On("Info", mock.Anything).Return(system.Info{Driver: StorageDriverOverlay2}, nil)
+	md.On("ContainerLogs", mock.Anything, mock.Anything, mock.Anything).Return(io.NopCloser(stream), nil)
+
+	dt, _ := NewDockerTasks(md, &imocks.ImageLog{}, &tar.TarGz{}, logger.NewTestLogger(t))
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err := dt.FollowContainerLogs(context.Background(), "123", 40, stdout, stderr)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+
+	opts := md.Calls[len(md.Calls)-1].Arguments.Get(2).(container.LogsOptions)
+	assert.True(t, opts.Follow)
+	assert.Equal(t, "40", opts.Tail)
+}
@@ -6,18 +6,23 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/system"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	imocks "github.com/jumppad-labs/jumppad/pkg/clients/images/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	"github.com/jumppad-labs/jumppad/pkg/clients/tar"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
 )
 
 func setupRemoveTests(t *testing.T) (*DockerTasks, *mocks.Docker) {
 	md := &mocks.Docker{}
 	md.On("ServerVersion", mock.Anything).Return(types.Version{}, nil)
 	md.On("Info", mock.Anything).Return(system.Info{Driver: StorageDriverOverlay2}, nil)
+	md.On("ContainerInspect", mock.Anything, mock.Anything).Return(container.InspectResponse{Config: &container.Config{}}, nil)
 
 	mic := &imocks.ImageLog{}
 	dt, _ := NewDockerTasks(md, mic, &tar.TarGz{}, logger.NewTestLogger(t))
@@ -69,3 +74,80 @@ func TestContainerRemoveFailsCallsRemoveForcefully(t *testing.T) {
 
 	md.AssertNumberOfCalls(t, "ContainerRemove", 1)
 }
+
+func TestContainerRemoveUsesDefaultStopTimeout(t *testing.T) {
+	dt, md := setupRemoveTests(t)
+	md.On("ContainerRemove", mock.Anything, "test", mock.Anything).Return(nil)
+	md.On("ContainerStop", mock.Anything, "test", mock.Anything).Return(nil)
+
+	dt.RemoveContainer("test", false)
+
+	opts := testutils.GetCalls(&md.Mock, "ContainerStop")[0].Arguments[2].(container.StopOptions)
+	assert.Equal(t, 30, *opts.Timeout)
+}
+
+func TestContainerRemoveUsesContainerStopTimeout(t *testing.T) {
+	dt, md := setupRemoveTests(t)
+	testutils.RemoveOn(&md.Mock, "ContainerInspect")
+
+	timeout := 120
+	md.On("ContainerInspect", mock.Anything, "test").Return(container.InspectResponse{Config: &container.Config{StopTimeout: &timeout}}, nil)
+	md.On("ContainerRemove", mock.Anything, "test", mock.Anything).Return(nil)
+	md.On("ContainerStop", mock.Anything, "test", mock.Anything).Return(nil)
+
+	dt.RemoveContainer("test", false)
+
+	opts := testutils.GetCalls(&md.Mock, "ContainerStop")[0].Arguments[2].(container.StopOptions)
+	assert.Equal(t, 120, *opts.Timeout)
+}
+
+func TestContainerRemoveCallsRemoveForcefullyWhenGlobalForceSet(t *testing.T) {
+	dt, md := setupRemoveTests(t)
+	md.On("ContainerRemove", mock.Anything, "test", container.RemoveOptions{Force: true, RemoveVolumes: true}).Return(nil)
+
+	dt.SetForce(true)
+	dt.RemoveContainer("test", false)
+
+	md.AssertNotCalled(t, "ContainerStop", mock.Anything, mock.Anything, mock.Anything)
+	md.AssertNumberOfCalls(t, "ContainerRemove", 1)
+}
+
+func TestContainerRemoveRemovesSyncedVolumesWhenDockerHostRemote(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "ssh://nic@10.5.0.20")
+	t.Setenv(utils.WorkspaceEnvVar, "dev")
+
+	dt, md := setupRemoveTests(t)
+	testutils.RemoveOn(&md.Mock, "ContainerInspect")
+	md.On("ContainerInspect", mock.Anything, "test").Return(container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{},
+		Config:            &container.Config{},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeVolume, Name: "bind.dev.abc123.volume.jmpd.in"},
+			{Type: mount.TypeVolume, Name: "bind.other.abc123.volume.jmpd.in"},
+			{Type: mount.TypeVolume, Name: "images.volume.jmpd.in"},
+			{Type: mount.TypeBind, Source: "/var/run/docker.sock"},
+		},
+	}, nil)
+	md.On("ContainerStop", mock.Anything, "test", mock.Anything).Return(nil)
+	md.On("ContainerRemove", mock.Anything, "test", mock.Anything).Return(nil)
+	md.On("VolumeRemove", mock.Anything, mock.Anything, false).Return(fmt.Errorf("volume in use"))
+
+	err := dt.RemoveContainer("test", false)
+	assert.NoError(t, err)
+
+	md.AssertNumberOfCalls(t, "VolumeRemove", 1)
+	md.AssertCalled(t, "VolumeRemove", mock.Anything, "bind.dev.abc123.volume.jmpd.in", false)
+}
+
+func TestContainerRemoveDoesNotRemoveVolumesWhenDockerHostLocal(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+
+	dt, md := setupRemoveTests(t)
+	md.On("ContainerStop", mock.Anything, "test", mock.Anything).Return(nil)
+	md.On("ContainerRemove", mock.Anything, "test", mock.Anything).Return(nil)
+
+	err := dt.RemoveContainer("test", false)
+	assert.NoError(t, err)
+
+	md.AssertNotCalled(t, "VolumeRemove", mock.Anything, mock.Anything, mock.Anything)
+}
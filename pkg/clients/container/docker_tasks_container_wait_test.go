@@ -0,0 +1,33 @@
+package container
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestWaitForContainerReturnsExitCode(t *testing.T) {
+	dt, md := setupRemoveTests(t)
+
+	rc := make(chan container.WaitResponse, 1)
+	rc <- container.WaitResponse{StatusCode: 3}
+	md.On("ContainerWait", mock.Anything, "test", container.WaitConditionNotRunning).Return((<-chan container.WaitResponse)(rc), (<-chan error)(make(chan error)))
+
+	code, err := dt.WaitForContainer("test", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, code)
+}
+
+func TestWaitForContainerReturnsErrorOnWaitError(t *testing.T) {
+	dt, md := setupRemoveTests(t)
+
+	ec := make(chan error, 1)
+	ec <- fmt.Errorf("boom")
+	md.On("ContainerWait", mock.Anything, "test", container.WaitConditionNotRunning).Return((<-chan container.WaitResponse)(make(chan container.WaitResponse)), (<-chan error)(ec))
+
+	_, err := dt.WaitForContainer("test", 30)
+	assert.Error(t, err)
+}
@@ -132,3 +132,38 @@ func TestPullImageAlwaysWhenForce(t *testing.T) {
 	md.AssertCalled(t, "ImagePull", mock.Anything, mock.Anything, mock.Anything)
 	mic.AssertCalled(t, "Log", mock.Anything, mock.Anything)
 }
+
+func TestPullImageWithPlatformWhenNOTCached(t *testing.T) {
+	cc, md, mic := createImagePullConfig()
+	cc.Platform = "linux/arm64"
+
+	setupImagePull(t, cc, md, mic, false)
+
+	md.AssertCalled(t, "ImagePull", mock.Anything, makeImageCanonical(cc.Name), image.PullOptions{Platform: "linux/arm64"})
+}
+
+func TestDoNotPullImageWhenCachedForPlatform(t *testing.T) {
+	cc, md, mic := createImagePullConfig()
+	cc.Platform = "linux/arm64"
+
+	testutils.RemoveOn(&md.Mock, "ImageList")
+	md.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{{ID: "abc"}}, nil)
+	md.On("ImageInspectWithRaw", mock.Anything, "abc").Return(image.InspectResponse{Os: "linux", Architecture: "arm64"}, nil, nil)
+
+	setupImagePull(t, cc, md, mic, false)
+
+	md.AssertNotCalled(t, "ImagePull", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPullImageWhenCachedForDifferentPlatform(t *testing.T) {
+	cc, md, mic := createImagePullConfig()
+	cc.Platform = "linux/arm64"
+
+	testutils.RemoveOn(&md.Mock, "ImageList")
+	md.On("ImageList", mock.Anything, mock.Anything).Return([]image.Summary{{ID: "abc"}}, nil)
+	md.On("ImageInspectWithRaw", mock.Anything, "abc").Return(image.InspectResponse{Os: "linux", Architecture: "amd64"}, nil, nil)
+
+	setupImagePull(t, cc, md, mic, false)
+
+	md.AssertCalled(t, "ImagePull", mock.Anything, makeImageCanonical(cc.Name), image.PullOptions{Platform: "linux/arm64"})
+}
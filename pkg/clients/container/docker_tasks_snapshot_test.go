@@ -0,0 +1,146 @@
+package container
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	dtypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/clients/tar"
+	"github.com/jumppad-labs/jumppad/testutils"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupSnapshotMocks(t *testing.T, info container.InspectResponse) (*mocks.Docker, *DockerTasks) {
+	md, mic := setupContainerMocks()
+
+	testutils.RemoveOn(&md.Mock, "ContainerInspect")
+	md.On("ContainerInspect", mock.Anything, "abc").Return(info, nil)
+	md.On("ContainerInspect", mock.Anything, "app").Return(container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{Name: "/app.container.local.jmpd.in"}}, nil)
+	md.On("ContainerInspect", mock.Anything, mock.Anything).Return(container.InspectResponse{NetworkSettings: &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{"bridge": nil}}}, nil)
+	md.On("ContainerCommit", mock.Anything, mock.Anything, mock.Anything).Return(container.CommitResponse{ID: "sha256:123"}, nil)
+	md.On("ContainerList", mock.Anything, mock.Anything).Return(nil, nil)
+	md.On("CopyToContainer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	dt, err := NewDockerTasks(md, mic, &tar.TarGz{}, logger.NewTestLogger(t))
+	assert.NoError(t, err)
+
+	return md, dt
+}
+
+func testSnapshotInfo() container.InspectResponse {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:         "abcdef1234567890",
+			Name:       "/consul.container.local.jmpd.in",
+			HostConfig: &container.HostConfig{NetworkMode: "default"},
+		},
+		Config: &container.Config{Image: "consul:1.6.1", Env: []string{"a=1"}},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeVolume, Name: "images.volume.jmpd.in", Destination: "/cache"},
+			{Type: mount.TypeBind, Source: "/tmp", Destination: "/data"},
+		},
+		NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"onprem": {
+					Aliases:    []string{"consul", "abcdef123456"},
+					IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.5.0.2"},
+				},
+			},
+		},
+	}
+}
+
+func TestSaveContainerCommitsContainerToImage(t *testing.T) {
+	md, dt := setupSnapshotMocks(t, testSnapshotInfo())
+
+	s, err := dt.SaveContainer("abc", "jumppad-snapshot/lab:consul.container.local.jmpd.in")
+	assert.NoError(t, err)
+
+	opts := testutils.GetCalls(&md.Mock, "ContainerCommit")[0].Arguments.Get(2).(container.CommitOptions)
+	assert.Equal(t, "jumppad-snapshot/lab:consul.container.local.jmpd.in", opts.Reference)
+	assert.True(t, opts.Pause)
+
+	assert.Equal(t, "consul.container.local.jmpd.in", s.Name)
+	assert.Equal(t, "jumppad-snapshot/lab:consul.container.local.jmpd.in", s.Image)
+	assert.Equal(t, []string{"images.volume.jmpd.in"}, s.Volumes)
+}
+
+func TestSaveContainerReferencesNetworkContainerByName(t *testing.T) {
+	info := testSnapshotInfo()
+	info.HostConfig.NetworkMode = "container:app"
+
+	_, dt := setupSnapshotMocks(t, info)
+
+	s, err := dt.SaveContainer("abc", "jumppad-snapshot/lab:consul.container.local.jmpd.in")
+	assert.NoError(t, err)
+
+	saved := container.InspectResponse{}
+	err = json.Unmarshal(s.Config, &saved)
+	assert.NoError(t, err)
+
+	assert.Equal(t, container.NetworkMode("container:app.container.local.jmpd.in"), saved.HostConfig.NetworkMode)
+}
+
+func TestRestoreContainerCreatesContainerWithNetworks(t *testing.T) {
+	config, _ := json.Marshal(testSnapshotInfo())
+	md, dt := setupSnapshotMocks(t, container.InspectResponse{})
+
+	testutils.RemoveOn(&md.Mock, "ContainerList")
+	md.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{{ID: "old"}}, nil)
+
+	_, err := dt.RestoreContainer(&dtypes.ContainerSnapshot{
+		Name:   "consul.container.local.jmpd.in",
+		Image:  "jumppad-snapshot/lab:consul.container.local.jmpd.in",
+		Config: config,
+	})
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "ContainerRemove", mock.Anything, "old", mock.Anything)
+
+	params := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	assert.Equal(t, "jumppad-snapshot/lab:consul.container.local.jmpd.in", params.Get(1).(*container.Config).Image)
+	assert.Equal(t, []string{"a=1"}, params.Get(1).(*container.Config).Env)
+	assert.Equal(t, "consul.container.local.jmpd.in", params.Get(5))
+
+	// the alias for the short id of the original container must be removed
+	connect := testutils.GetCalls(&md.Mock, "NetworkConnect")[0].Arguments
+	assert.Equal(t, "onprem", connect.Get(1))
+	assert.Equal(t, []string{"consul"}, connect.Get(3).(*network.EndpointSettings).Aliases)
+	assert.Equal(t, "10.5.0.2", connect.Get(3).(*network.EndpointSettings).IPAMConfig.IPv4Address)
+
+	md.AssertCalled(t, "NetworkDisconnect", mock.Anything, "bridge", "test", true)
+	md.AssertCalled(t, "ContainerStart", mock.Anything, "test", mock.Anything)
+}
+
+func TestImportVolumeReplacesVolume(t *testing.T) {
+	md, dt := setupSnapshotMocks(t, container.InspectResponse{})
+
+	err := dt.ImportVolume("images.volume.jmpd.in", strings.NewReader("archive"))
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "VolumeRemove", mock.Anything, "images.volume.jmpd.in", true)
+
+	opts := testutils.GetCalls(&md.Mock, "VolumeCreate")[0].Arguments.Get(1).(volume.CreateOptions)
+	assert.Equal(t, "images.volume.jmpd.in", opts.Name)
+
+	// the volume is mounted with the versioned connector image pulled through
+	// the normal image pull
+	md.AssertCalled(t, "ImagePull", mock.Anything, makeImageCanonical("ghcr.io/jumppad-labs/connector:v0.4.0"), mock.Anything)
+
+	cc := testutils.GetCalls(&md.Mock, "ContainerCreate")[0].Arguments
+	assert.Equal(t, "ghcr.io/jumppad-labs/connector:v0.4.0", cc.Get(1).(*container.Config).Image)
+
+	hc := cc.Get(2).(*container.HostConfig)
+	assert.Equal(t, "images.volume.jmpd.in", hc.Mounts[0].Source)
+
+	md.AssertCalled(t, "CopyToContainer", mock.Anything, "test", "/", mock.Anything, mock.Anything)
+	md.AssertCalled(t, "ContainerRemove", mock.Anything, "test", mock.Anything)
+}
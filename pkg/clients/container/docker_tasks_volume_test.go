@@ -65,3 +65,19 @@ func TestRemoveVolumeRemotesSuccesfully(t *testing.T) {
 
 	md.AssertCalled(t, "VolumeRemove", mock.Anything, "test.volume.jmpd.in", true)
 }
+
+func TestFindVolumesReturnsMatchingVolumes(t *testing.T) {
+	_, md, mic := createContainerConfig()
+	p, _ := NewDockerTasks(md, mic, &tar.TarGz{}, logger.NewTestLogger(t))
+
+	testutils.RemoveOn(&md.Mock, "VolumeList")
+	md.On("VolumeList", mock.Anything, volume.ListOptions{}).Return(volume.ListResponse{Volumes: []*volume.Volume{
+		{Name: "bind.dev.abc123.volume.jmpd.in"},
+		{Name: "images.volume.jmpd.in"},
+		{Name: "bind.other"},
+	}}, nil)
+
+	vols, err := p.FindVolumes("bind.*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bind.dev.abc123"}, vols)
+}
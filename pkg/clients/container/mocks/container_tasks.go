@@ -3,8 +3,11 @@
 package mocks
 
 import (
+	context "context"
 	io "io"
 
+	network "github.com/docker/docker/api/types/network"
+
 	types "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -186,6 +189,24 @@ func (_m *ContainerTasks) CopyFromContainer(id string, src string, dst string) e
 	return r0
 }
 
+// CopyFilesToContainer provides a mock function with given fields: containerID, files
+func (_m *ContainerTasks) CopyFilesToContainer(containerID string, files []types.File) error {
+	ret := _m.Called(containerID, files)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyFilesToContainer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []types.File) error); ok {
+		r0 = rf(containerID, files)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CopyLocalDockerImagesToVolume provides a mock function with given fields: images, volume, force
 func (_m *ContainerTasks) CopyLocalDockerImagesToVolume(images []string, volume string, force bool) ([]string, error) {
 	ret := _m.Called(images, volume, force)
@@ -402,6 +423,24 @@ func (_m *ContainerTasks) ExecuteScript(id string, contents string, env []string
 	return r0, r1
 }
 
+// ExportVolume provides a mock function with given fields: name, w
+func (_m *ContainerTasks) ExportVolume(name string, w io.Writer) error {
+	ret := _m.Called(name, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportVolume")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, io.Writer) error); ok {
+		r0 = rf(name, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindContainerIDs provides a mock function with given fields: containerName
 func (_m *ContainerTasks) FindContainerIDs(containerName string) ([]string, error) {
 	ret := _m.Called(containerName)
@@ -518,6 +557,102 @@ func (_m *ContainerTasks) FindNetwork(id string) (types.NetworkAttachment, error
 	return r0, r1
 }
 
+// FindVolumes provides a mock function with given fields: name
+func (_m *ContainerTasks) FindVolumes(name string) ([]string, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindVolumes")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FollowContainerLogs provides a mock function with given fields: ctx, id, lines, stdOut, stdErr
+func (_m *ContainerTasks) FollowContainerLogs(ctx context.Context, id string, lines int, stdOut io.Writer, stdErr io.Writer) error {
+	ret := _m.Called(ctx, id, lines, stdOut, stdErr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FollowContainerLogs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, io.Writer, io.Writer) error); ok {
+		r0 = rf(ctx, id, lines, stdOut, stdErr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ImportVolume provides a mock function with given fields: name, r
+func (_m *ContainerTasks) ImportVolume(name string, r io.Reader) error {
+	ret := _m.Called(name, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportVolume")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, io.Reader) error); ok {
+		r0 = rf(name, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InspectImage provides a mock function with given fields: name
+func (_m *ContainerTasks) InspectImage(name string) (*types.ImageInfo, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InspectImage")
+	}
+
+	var r0 *types.ImageInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*types.ImageInfo, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *types.ImageInfo); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ImageInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListNetworks provides a mock function with given fields: id
 func (_m *ContainerTasks) ListNetworks(id string) []types.NetworkAttachment {
 	ret := _m.Called(id)
@@ -538,6 +673,72 @@ func (_m *ContainerTasks) ListNetworks(id string) []types.NetworkAttachment {
 	return r0
 }
 
+// NetworkCreate provides a mock function with given fields: ctx, name, options
+func (_m *ContainerTasks) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) error {
+	ret := _m.Called(ctx, name, options)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NetworkCreate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, network.CreateOptions) error); ok {
+		r0 = rf(ctx, name, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NetworkList provides a mock function with given fields: ctx, name
+func (_m *ContainerTasks) NetworkList(ctx context.Context, name string) ([]network.Summary, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NetworkList")
+	}
+
+	var r0 []network.Summary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]network.Summary, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []network.Summary); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]network.Summary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NetworkRemove provides a mock function with given fields: ctx, name
+func (_m *ContainerTasks) NetworkRemove(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NetworkRemove")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PullImage provides a mock function with given fields: image, force
 func (_m *ContainerTasks) PullImage(image types.Image, force bool) error {
 	ret := _m.Called(image, force)
@@ -628,6 +829,64 @@ func (_m *ContainerTasks) RemoveVolume(name string) error {
 	return r0
 }
 
+// RestoreContainer provides a mock function with given fields: snapshot
+func (_m *ContainerTasks) RestoreContainer(snapshot *types.ContainerSnapshot) (string, error) {
+	ret := _m.Called(snapshot)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreContainer")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*types.ContainerSnapshot) (string, error)); ok {
+		return rf(snapshot)
+	}
+	if rf, ok := ret.Get(0).(func(*types.ContainerSnapshot) string); ok {
+		r0 = rf(snapshot)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(*types.ContainerSnapshot) error); ok {
+		r1 = rf(snapshot)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveContainer provides a mock function with given fields: id, image
+func (_m *ContainerTasks) SaveContainer(id string, image string) (*types.ContainerSnapshot, error) {
+	ret := _m.Called(id, image)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveContainer")
+	}
+
+	var r0 *types.ContainerSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*types.ContainerSnapshot, error)); ok {
+		return rf(id, image)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *types.ContainerSnapshot); ok {
+		r0 = rf(id, image)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.ContainerSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(id, image)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetForce provides a mock function with given fields: _a0
 func (_m *ContainerTasks) SetForce(_a0 bool) {
 	_m.Called(_a0)
@@ -651,6 +910,34 @@ func (_m *ContainerTasks) TagImage(source string, destination string) error {
 	return r0
 }
 
+// WaitForContainer provides a mock function with given fields: id, timeout
+func (_m *ContainerTasks) WaitForContainer(id string, timeout int) (int, error) {
+	ret := _m.Called(id, timeout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitForContainer")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) (int, error)); ok {
+		return rf(id, timeout)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) int); ok {
+		r0 = rf(id, timeout)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(id, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewContainerTasks creates a new instance of ContainerTasks. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewContainerTasks(t interface {
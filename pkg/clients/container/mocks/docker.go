@@ -82,6 +82,34 @@ func (_m *Docker) CheckpointList(ctx context.Context, _a1 string, options checkp
 	return r0, r1
 }
 
+// ContainerCommit provides a mock function with given fields: ctx, containerID, options
+func (_m *Docker) ContainerCommit(ctx context.Context, containerID string, options typescontainer.CommitOptions) (typescontainer.CommitResponse, error) {
+	ret := _m.Called(ctx, containerID, options)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContainerCommit")
+	}
+
+	var r0 typescontainer.CommitResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, typescontainer.CommitOptions) (typescontainer.CommitResponse, error)); ok {
+		return rf(ctx, containerID, options)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, typescontainer.CommitOptions) typescontainer.CommitResponse); ok {
+		r0 = rf(ctx, containerID, options)
+	} else {
+		r0 = ret.Get(0).(typescontainer.CommitResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, typescontainer.CommitOptions) error); ok {
+		r1 = rf(ctx, containerID, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ContainerCreate provides a mock function with given fields: ctx, config, hostConfig, networkingConfig, platform, containerName
 func (_m *Docker) ContainerCreate(ctx context.Context, config *typescontainer.Config, hostConfig *typescontainer.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (typescontainer.CreateResponse, error) {
 	ret := _m.Called(ctx, config, hostConfig, networkingConfig, platform, containerName)
@@ -372,6 +400,38 @@ func (_m *Docker) ContainerStop(ctx context.Context, containerID string, options
 	return r0
 }
 
+// ContainerWait provides a mock function with given fields: ctx, containerID, condition
+func (_m *Docker) ContainerWait(ctx context.Context, containerID string, condition typescontainer.WaitCondition) (<-chan typescontainer.WaitResponse, <-chan error) {
+	ret := _m.Called(ctx, containerID, condition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContainerWait")
+	}
+
+	var r0 <-chan typescontainer.WaitResponse
+	var r1 <-chan error
+	if rf, ok := ret.Get(0).(func(context.Context, string, typescontainer.WaitCondition) (<-chan typescontainer.WaitResponse, <-chan error)); ok {
+		return rf(ctx, containerID, condition)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, typescontainer.WaitCondition) <-chan typescontainer.WaitResponse); ok {
+		r0 = rf(ctx, containerID, condition)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan typescontainer.WaitResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, typescontainer.WaitCondition) <-chan error); ok {
+		r1 = rf(ctx, containerID, condition)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(<-chan error)
+		}
+	}
+
+	return r0, r1
+}
+
 // CopyFromContainer provides a mock function with given fields: ctx, containerID, srcPath
 func (_m *Docker) CopyFromContainer(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, typescontainer.PathStat, error) {
 	ret := _m.Called(ctx, containerID, srcPath)
@@ -455,6 +515,43 @@ func (_m *Docker) ImageBuild(ctx context.Context, buildContext io.Reader, option
 	return r0, r1
 }
 
+// ImageInspectWithRaw provides a mock function with given fields: ctx, imageID
+func (_m *Docker) ImageInspectWithRaw(ctx context.Context, imageID string) (image.InspectResponse, []byte, error) {
+	ret := _m.Called(ctx, imageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImageInspectWithRaw")
+	}
+
+	var r0 image.InspectResponse
+	var r1 []byte
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (image.InspectResponse, []byte, error)); ok {
+		return rf(ctx, imageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) image.InspectResponse); ok {
+		r0 = rf(ctx, imageID)
+	} else {
+		r0 = ret.Get(0).(image.InspectResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) []byte); ok {
+		r1 = rf(ctx, imageID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, imageID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // ImageList provides a mock function with given fields: ctx, options
 func (_m *Docker) ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
 	ret := _m.Called(ctx, options)
@@ -0,0 +1,48 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Nerdctl defines an interface for the nerdctl CLI which is used to manage
+// containers, images, volumes and networks with containerd.
+// nerdctl supports rootless containerd and uses the CONTAINERD_ADDRESS and
+// CONTAINERD_NAMESPACE environment variables to select the containerd instance
+//
+//go:generate mockery --name Nerdctl --filename nerdctl.go --unroll-variadic=false
+type Nerdctl interface {
+	// Execute runs nerdctl with the given arguments, stdin is optional.
+	// When the command exits with a non zero code the returned error
+	// implements ExitCode() int
+	Execute(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
+}
+
+// NerdctlImpl is a concrete implementation of Nerdctl which executes the
+// nerdctl binary
+type NerdctlImpl struct {
+	path string
+}
+
+// NewNerdctl creates a new Nerdctl client using the nerdctl binary found in
+// the path
+func NewNerdctl() (Nerdctl, error) {
+	p, err := exec.LookPath("nerdctl")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find nerdctl, please check that nerdctl is installed and in your path: %w", err)
+	}
+
+	return &NerdctlImpl{path: p}, nil
+}
+
+// Execute runs nerdctl with the given arguments
+func (n *NerdctlImpl) Execute(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, n.path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,66 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/jumppad-labs/jumppad/pkg/clients/images"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	ctar "github.com/jumppad-labs/jumppad/pkg/clients/tar"
+)
+
+const (
+	// RuntimeDocker manages containers using the Docker Engine API, Podman is
+	// also supported through its Docker compatible socket
+	RuntimeDocker = "docker"
+	// RuntimePodman is an alias for RuntimeDocker
+	RuntimePodman = "podman"
+	// RuntimeContainerd manages containers with containerd using nerdctl
+	RuntimeContainerd = "containerd"
+)
+
+// NetworkTasks defines the methods that each container runtime implements to
+// create, remove and list networks
+type NetworkTasks interface {
+	// NetworkCreate creates a new network with the given name
+	NetworkCreate(ctx context.Context, name string, options network.CreateOptions) error
+	// NetworkRemove removes the network with the given name
+	NetworkRemove(ctx context.Context, name string) error
+	// NetworkList returns the networks matching the name, when name is
+	// empty all networks are returned
+	NetworkList(ctx context.Context, name string) ([]network.Summary, error)
+}
+
+// NewContainerTasks creates the ContainerTasks for the given runtime
+func NewContainerTasks(runtime string, il images.ImageLog, tg *ctar.TarGz, l logger.Logger) (ContainerTasks, error) {
+	switch runtime {
+	case "", RuntimeDocker, RuntimePodman:
+		dc, err := NewDocker()
+		if err != nil {
+			return nil, err
+		}
+
+		dt, err := NewDockerTasks(dc, il, tg, l)
+		if err != nil {
+			return nil, err
+		}
+
+		return dt, nil
+
+	case RuntimeContainerd:
+		nc, err := NewNerdctl()
+		if err != nil {
+			return nil, err
+		}
+
+		ct, err := NewContainerdTasks(nc, il, tg, l)
+		if err != nil {
+			return nil, err
+		}
+
+		return ct, nil
+	}
+
+	return nil, fmt.Errorf("unknown container runtime %s, supported runtimes are %s, %s and %s", runtime, RuntimeDocker, RuntimePodman, RuntimeContainerd)
+}
@@ -4,7 +4,7 @@ type EngineInfo struct {
 	// StorageDriver used by the engine, overlay, devicemapper, etc
 	StorageDriver string
 
-	// EngineType, docker, podman, not found
+	// EngineType, docker, podman, containerd, not found
 	EngineType string
 
 	// EngineType, docker, podman, not found
@@ -15,7 +15,9 @@ type EngineInfo struct {
 const (
 	EngineTypeDocker = "docker"
 	EngineTypePodman = "podman"
-	EngineNotFound   = "not found"
+	// EngineTypeContainerd is containerd managed using the nerdctl CLI
+	EngineTypeContainerd = "containerd"
+	EngineNotFound       = "not found"
 )
 
 const (
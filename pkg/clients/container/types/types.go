@@ -1,5 +1,11 @@
 package types
 
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
 type Container struct {
 	Name            string
 	Networks        []NetworkAttachment
@@ -15,12 +21,41 @@ type Container struct {
 	Privileged      bool
 	Capabilities    *Capabilities
 	MaxRestartCount int
+	Ulimits         []Ulimit
+	Sysctls         map[string]string
+	ExtraHosts      map[string]string // hostname to ip address mappings added to /etc/hosts
+	ShmSize         int               // size of /dev/shm in MB
+	StopSignal      string            // signal used to stop the container, defaults to SIGTERM
+	StopTimeout     int               // seconds to wait for the container to stop before killing it
 
 	// resource constraints
 	Resources *Resources
 
 	// User block for mapping the user id and group id inside the container
 	RunAs *User
+
+	// HealthCheck is run by the container engine for the life of the container
+	HealthCheck *HealthCheck
+
+	// Files are written to the container before it is started
+	Files []File
+}
+
+// File is written to a container
+type File struct {
+	Destination string      // absolute path of the file in the container
+	Content     string      // content of the file
+	Mode        os.FileMode // permissions of the file
+}
+
+// HealthCheck defines a test that the container engine runs periodically to
+// determine the health of the container
+type HealthCheck struct {
+	Test        []string      // test to run, the first element is CMD or CMD-SHELL
+	Interval    time.Duration // time between running the test
+	Timeout     time.Duration // time to wait for a single test to complete
+	StartPeriod time.Duration // time for the container to start before failures are counted
+	Retries     int           // consecutive failures needed to mark the container unhealthy
 }
 
 type User struct {
@@ -40,6 +75,13 @@ type NetworkAttachment struct {
 	IPv6Enabled bool
 }
 
+// Ulimit sets the soft and hard limit for a resource
+type Ulimit struct {
+	Name string
+	Soft int
+	Hard int
+}
+
 type Capabilities struct {
 	Add  []string
 	Drop []string
@@ -67,6 +109,8 @@ type Volume struct {
 	BindPropagation             string
 	BindPropagationNonRecursive bool
 	SelinuxRelabel              string
+	TmpfsSize                   int    // size of a tmpfs mount in MB
+	TmpfsMode                   string // file mode of a tmpfs mount in octal
 }
 
 // Port is a port mapping
@@ -94,6 +138,17 @@ type Image struct {
 	Username string
 	// Password is the Docker registry password to use for private repositories
 	Password string
+	// Platform is the os and architecture of the image e.g. linux/arm64, when
+	// empty the platform of the Docker engine is used
+	Platform string
+}
+
+// ImageInfo contains the metadata for an image in the local registry
+type ImageInfo struct {
+	ID          string
+	Created     time.Time // time the image was built
+	User        string    // default user for containers created from the image, empty for root
+	RepoDigests []string  // digests of the image in the registries it was pulled from
 }
 
 type Build struct {
@@ -102,4 +157,17 @@ type Build struct {
 	Context    string            // Context to copy to the build process
 	Ignore     []string          // globbed list of files to ignore in the context, same as .dockerignore
 	Args       map[string]string // Arguments to pass to the build process
+	// Platforms to build the image for e.g. linux/arm64, when more than one
+	// platform is set a multi-platform image is built. Defaults to the
+	// platform of the Docker engine
+	Platforms []string
+}
+
+// ContainerSnapshot contains the details needed to recreate a container from
+// an image committed from the container
+type ContainerSnapshot struct {
+	Name    string          `json:"name"`              // name of the container
+	Image   string          `json:"image"`             // image containing the committed filesystem
+	Volumes []string        `json:"volumes,omitempty"` // names of the volumes mounted by the container
+	Config  json.RawMessage `json:"config"`            // engine specific configuration for the container
 }
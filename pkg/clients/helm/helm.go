@@ -32,6 +32,9 @@ type Helm interface {
 	// CreateFromRepository creates a Helm install from a repository
 	Create(kubeConfig, name, namespace string, createNamespace bool, skipCRDs bool, chart, version, valuesPath string, valuesString map[string]string) error
 
+	// Upgrade an existing Helm install in place with the given chart and values
+	Upgrade(kubeConfig, name, namespace string, skipCRDs bool, chart, version, valuesPath string, valuesString map[string]string) error
+
 	// Destroy the given chart
 	Destroy(kubeConfig, name, namespace string) error
 
@@ -77,14 +80,9 @@ func NewHelm(l logger.Logger) Helm {
 
 func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bool, skipCRDs bool, chart, version, valuesPath string, valuesString map[string]string) error {
 	// set the kube client for Helm
-	s := kube.GetConfig(kubeConfig, "default", namespace)
-	cfg := &action.Configuration{}
-	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
-		h.log.Debug("Helm debug", "name", name, "chart", chart, "message", fmt.Sprintf(format, v...))
-	})
-
+	cfg, err := h.actionConfig(kubeConfig, name, namespace, chart)
 	if err != nil {
-		return fmt.Errorf("unable to initialize Helm: %w", err)
+		return err
 	}
 
 	client := action.NewInstall(cfg)
@@ -93,16 +91,77 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 	client.CreateNamespace = createNamespace
 	client.SkipCRDs = skipCRDs
 
-	settings := h.getSettings()
-	settings.Debug = true
-
 	h.log.Debug("Creating chart from config", "release_name", name, "chart", chart)
 	cpa := client.ChartPathOptions
 	cpa.Version = version
 
-	cp, err := cpa.LocateChart(chart, &settings)
+	chartRequested, vals, err := h.loadChart(&cpa, client.DependencyUpdate, name, chart, valuesPath, valuesString)
 	if err != nil {
-		return fmt.Errorf("error locating chart: %w", err)
+		return err
+	}
+
+	h.log.Debug("Run chart", "ref", name)
+	_, err = client.Run(chartRequested, vals)
+	if err != nil {
+		return fmt.Errorf("error running chart: %w", err)
+	}
+
+	return nil
+}
+
+// Upgrade updates an installed Helm chart with the given chart and values
+// without uninstalling the release
+func (h *HelmImpl) Upgrade(kubeConfig, name, namespace string, skipCRDs bool, chart, version, valuesPath string, valuesString map[string]string) error {
+	cfg, err := h.actionConfig(kubeConfig, name, namespace, chart)
+	if err != nil {
+		return err
+	}
+
+	client := action.NewUpgrade(cfg)
+	client.Namespace = namespace
+	client.SkipCRDs = skipCRDs
+
+	h.log.Debug("Upgrading chart from config", "release_name", name, "chart", chart)
+	cpa := client.ChartPathOptions
+	cpa.Version = version
+
+	chartRequested, vals, err := h.loadChart(&cpa, client.DependencyUpdate, name, chart, valuesPath, valuesString)
+	if err != nil {
+		return err
+	}
+
+	h.log.Debug("Run chart upgrade", "ref", name)
+	_, err = client.Run(name, chartRequested, vals)
+	if err != nil {
+		return fmt.Errorf("error upgrading chart: %w", err)
+	}
+
+	return nil
+}
+
+// actionConfig creates the Helm configuration for the given cluster
+func (h *HelmImpl) actionConfig(kubeConfig, name, namespace, chart string) (*action.Configuration, error) {
+	s := kube.GetConfig(kubeConfig, "default", namespace)
+	cfg := &action.Configuration{}
+	err := cfg.Init(s, namespace, "", func(format string, v ...interface{}) {
+		h.log.Debug("Helm debug", "name", name, "chart", chart, "message", fmt.Sprintf(format, v...))
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize Helm: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// loadChart locates, loads and validates the chart and merges the values
+func (h *HelmImpl) loadChart(cpa *action.ChartPathOptions, dependencyUpdate bool, name, chartRef, valuesPath string, valuesString map[string]string) (*chart.Chart, map[string]interface{}, error) {
+	settings := h.getSettings()
+	settings.Debug = true
+
+	cp, err := cpa.LocateChart(chartRef, &settings)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error locating chart: %w", err)
 	}
 
 	p := getter.All(&settings)
@@ -121,7 +180,7 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 
 	vals, err := vo.MergeValues(p)
 	if err != nil {
-		return fmt.Errorf("error merging Helm values: %w", err)
+		return nil, nil, fmt.Errorf("error merging Helm values: %w", err)
 	}
 
 	h.log.Debug("Using Values", "ref", name, "values", vals)
@@ -129,22 +188,22 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 	h.log.Debug("Loading chart", "ref", name, "path", cp)
 	chartRequested, err := loader.Load(cp)
 	if err != nil {
-		return fmt.Errorf("error loading chart: %w", err)
+		return nil, nil, fmt.Errorf("error loading chart: %w", err)
 	}
 
 	if err := checkIfInstallable(chartRequested); err != nil {
-		return fmt.Errorf("chart is not installable: %w", err)
+		return nil, nil, fmt.Errorf("chart is not installable: %w", err)
 	}
 
 	if req := chartRequested.Metadata.Dependencies; req != nil {
 		h.log.Debug("Checking chart dependencies", "deps", req)
 
 		if err := action.CheckDependencies(chartRequested, req); err != nil {
-			if client.DependencyUpdate {
+			if dependencyUpdate {
 				man := &downloader.Manager{
 					Out:              h.log.StandardWriter(),
 					ChartPath:        cp,
-					Keyring:          client.ChartPathOptions.Keyring,
+					Keyring:          cpa.Keyring,
 					SkipUpdate:       false,
 					Getters:          p,
 					RepositoryConfig: settings.RepositoryConfig,
@@ -152,14 +211,14 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 					Debug:            h.log.IsDebug(),
 				}
 				if err := man.Update(); err != nil {
-					return err
+					return nil, nil, err
 				}
 
 				if chartRequested, err = loader.Load(cp); err != nil {
-					return fmt.Errorf("failed reloading chart after repo update: %w", err)
+					return nil, nil, fmt.Errorf("failed reloading chart after repo update: %w", err)
 				}
 			} else {
-				return err
+				return nil, nil, err
 			}
 		}
 	}
@@ -167,16 +226,10 @@ func (h *HelmImpl) Create(kubeConfig, name, namespace string, createNamespace bo
 	h.log.Debug("Validate chart", "ref", name)
 	err = chartRequested.Validate()
 	if err != nil {
-		return fmt.Errorf("error validating chart: %w", err)
-	}
-
-	h.log.Debug("Run chart", "ref", name)
-	_, err = client.Run(chartRequested, vals)
-	if err != nil {
-		return fmt.Errorf("error running chart: %w", err)
+		return nil, nil, fmt.Errorf("error validating chart: %w", err)
 	}
 
-	return nil
+	return chartRequested, vals, nil
 }
 
 func checkIfInstallable(ch *chart.Chart) error {
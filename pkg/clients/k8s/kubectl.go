@@ -1,21 +1,32 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	"helm.sh/helm/v3/pkg/kube"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 // Kubernetes defines an interface for a Kuberenetes client
@@ -24,10 +35,66 @@ type Kubernetes interface {
 	GetPods(string) (*v1.PodList, error)
 	HealthCheckPods(ctx context.Context, selectors []string, timeout time.Duration) error
 	Apply(files []string, waitUntilReady bool) error
+	ApplyConfig(files []string, opts ApplyOptions) ([]Object, error)
 	Delete(files []string) error
+	DeleteConfig(files []string, opts ApplyOptions) error
+	DeleteObjects(objects []Object) error
 	GetPodLogs(ctx context.Context, podName, nameSpace string) (io.ReadCloser, error)
 }
 
+// fieldManager is the field manager for objects applied with server-side
+// apply
+const fieldManager = "jumppad"
+
+// ApplyOptions control how Kubernetes config is applied and deleted
+type ApplyOptions struct {
+	// Namespace for objects that do not specify a namespace, when empty the
+	// default namespace is used
+	Namespace string
+	// ServerSide applies the objects using server-side apply, otherwise
+	// existing objects are updated with a merge patch
+	ServerSide bool
+	// WaitUntilReady blocks until all the objects are ready
+	WaitUntilReady bool
+}
+
+// Object identifies a Kubernetes object that has been applied
+type Object struct {
+	Group     string
+	Version   string
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Source is the file or directory in the applied paths that contains
+	// the object
+	Source string
+}
+
+// String returns the object in the format [group/]version/kind/namespace/name
+func (o Object) String() string {
+	gv := o.Version
+	if o.Group != "" {
+		gv = fmt.Sprintf("%s/%s", o.Group, o.Version)
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s", gv, o.Kind, o.Namespace, o.Name)
+}
+
+// ParseObject parses an object in the format returned by Object.String
+func ParseObject(s string) (Object, error) {
+	parts := strings.Split(s, "/")
+
+	switch len(parts) {
+	case 4:
+		return Object{Version: parts[0], Kind: parts[1], Namespace: parts[2], Name: parts[3]}, nil
+	case 5:
+		return Object{Group: parts[0], Version: parts[1], Kind: parts[2], Namespace: parts[3], Name: parts[4]}, nil
+	}
+
+	return Object{}, fmt.Errorf("invalid object %s, the object must be in the format [group/]version/kind/namespace/name", s)
+}
+
 // KubernetesImpl is a concrete implementation of a Kubernetes client
 type KubernetesImpl struct {
 	clientset  *kubernetes.Clientset
@@ -113,41 +180,66 @@ func (k *KubernetesImpl) GetPods(selector string) (*v1.PodList, error) {
 // Apply Kubernetes YAML files at path
 // if waitUntilReady is true then the client will block until all resources have been created
 func (k *KubernetesImpl) Apply(files []string, waitUntilReady bool) error {
-	allFiles, err := buildFileList(files)
+	_, err := k.ApplyConfig(files, ApplyOptions{WaitUntilReady: waitUntilReady})
+	return err
+}
+
+// ApplyConfig applies the Kubernetes YAML files and kustomize directories at
+// path and returns the objects that were applied
+func (k *KubernetesImpl) ApplyConfig(files []string, opts ApplyOptions) ([]Object, error) {
+	manifests, err := buildManifests(files)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s := kube.GetConfig(k.configPath, "default", "default")
-	kc := kube.New(s)
+	kc := k.kubeClient(opts.Namespace)
+
+	objects := []Object{}
 
 	// process the files
-	for _, f := range allFiles {
-		k.l.Debug("Applying Kubernetes config", "file", f)
-		err := applyFile(f, waitUntilReady, kc)
+	for _, m := range manifests {
+		k.l.Debug("Applying Kubernetes config", "file", m.path, "namespace", opts.Namespace, "server_side", opts.ServerSide)
+		r, err := applyManifest(m, opts, kc)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		for _, i := range r {
+			gvk := i.Mapping.GroupVersionKind
+			objects = append(objects, Object{
+				Group:     gvk.Group,
+				Version:   gvk.Version,
+				Kind:      gvk.Kind,
+				Namespace: i.Namespace,
+				Name:      i.Name,
+				Source:    m.source,
+			})
 		}
 	}
 
-	return nil
+	return objects, nil
 }
 
 // Delete Kuberentes YAML files at path
 func (k *KubernetesImpl) Delete(files []string) error {
-	allFiles, err := buildFileList(files)
+	return k.DeleteConfig(files, ApplyOptions{})
+}
+
+// DeleteConfig deletes the objects in the Kubernetes YAML files and kustomize
+// directories at path
+func (k *KubernetesImpl) DeleteConfig(files []string, opts ApplyOptions) error {
+	manifests, err := buildManifests(files)
 	if err != nil {
 		return err
 	}
 
-	s := kube.GetConfig(k.configPath, "default", "default")
-	kc := kube.New(s)
+	kc := k.kubeClient(opts.Namespace)
 
 	// process the files
-	for _, f := range allFiles {
-		k.l.Debug("Removing Kubernetes config", "file", f)
+	for _, m := range manifests {
+		k.l.Debug("Removing Kubernetes config", "file", m.path)
 
-		err := deleteFile(f, kc)
+		err := deleteManifest(m, kc)
 		if err != nil {
 			return err
 		}
@@ -156,6 +248,56 @@ func (k *KubernetesImpl) Delete(files []string) error {
 	return nil
 }
 
+// DeleteObjects deletes the given objects, objects that do not exist are
+// ignored
+func (k *KubernetesImpl) DeleteObjects(objects []Object) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	// build a manifest containing the identity of each object so that the
+	// objects can be resolved the same way as config files
+	docs := []string{}
+	for _, o := range objects {
+		k.l.Debug("Removing Kubernetes object", "object", o.String())
+
+		apiVersion := o.Version
+		if o.Group != "" {
+			apiVersion = fmt.Sprintf("%s/%s", o.Group, o.Version)
+		}
+
+		meta := map[string]string{"name": o.Name}
+		if o.Namespace != "" {
+			meta["namespace"] = o.Namespace
+		}
+
+		d, err := json.Marshal(map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       o.Kind,
+			"metadata":   meta,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, string(d))
+	}
+
+	return deleteManifest(manifest{path: "inventory", data: []byte(strings.Join(docs, "\n---\n"))}, k.kubeClient(""))
+}
+
+// kubeClient returns a client for applying config with the given default
+// namespace
+func (k *KubernetesImpl) kubeClient(namespace string) *kube.Client {
+	if namespace == "" {
+		namespace = v1.NamespaceDefault
+	}
+
+	s := kube.GetConfig(k.configPath, "default", namespace)
+	return kube.New(s)
+}
+
 // HealthCheckPods uses the given selector to check that all pods are started
 // and running.
 // selectors are checked sequentially
@@ -231,6 +373,78 @@ func (k *KubernetesImpl) healthCheckSingle(ctx context.Context, selector string,
 	return nil
 }
 
+// manifest is the content of a config file or a rendered kustomize directory
+type manifest struct {
+	// path of the file or the kustomize directory
+	path string
+	// source is the path that was passed to the client
+	source string
+	data   []byte
+}
+
+// buildManifests reads the config files at the paths, kustomize directories
+// are rendered, other directories return all the yaml files in the directory
+func buildManifests(files []string) ([]manifest, error) {
+	manifests := []manifest{}
+
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if fi.IsDir() && isKustomization(f) {
+			data, err := renderKustomization(f)
+			if err != nil {
+				return nil, err
+			}
+
+			manifests = append(manifests, manifest{path: f, source: f, data: data})
+			continue
+		}
+
+		paths, err := buildFileList([]string{f})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range paths {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read file: %w", err)
+			}
+
+			manifests = append(manifests, manifest{path: p, source: f, data: data})
+		}
+	}
+
+	return manifests, nil
+}
+
+// isKustomization returns true when the directory contains a kustomization
+func isKustomization(dir string) bool {
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(dir, n)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderKustomization builds the kustomization in the directory and returns
+// the resulting yaml
+func renderKustomization(dir string) ([]byte, error) {
+	kz := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	rm, err := kz.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build kustomization %s: %w", dir, err)
+	}
+
+	return rm.AsYaml()
+}
+
 func buildFileList(files []string) ([]string, error) {
 	allFiles := make([]string, 0)
 
@@ -263,38 +477,98 @@ func buildFileList(files []string) ([]string, error) {
 	return allFiles, nil
 }
 
-func applyFile(path string, waitUntilReady bool, kc *kube.Client) error {
-	f, err := os.Open(path)
+func applyManifest(m manifest, opts ApplyOptions, kc *kube.Client) (kube.ResourceList, error) {
+	r, err := kc.Build(bytes.NewReader(m.data), true)
 	if err != nil {
-		return fmt.Errorf("unable to open file: %w", err)
+		return nil, fmt.Errorf("unable to build resources for file %s: %w", m.path, err)
 	}
-	defer f.Close()
 
-	r, err := kc.Build(f, true)
-	if err != nil {
-		return fmt.Errorf("unable to build resources for file %s: %w", path, err)
+	if opts.ServerSide {
+		err = serverSideApply(r)
+	} else {
+		err = clientSideApply(r)
 	}
 
-	_, err = kc.Create(r)
 	if err != nil {
-		return fmt.Errorf("unable to create resources for file %s: %w", path, err)
+		return nil, fmt.Errorf("unable to apply resources for file %s: %w", m.path, err)
 	}
 
-	if waitUntilReady {
-		return kc.WatchUntilReady(r, 30*time.Second)
+	if opts.WaitUntilReady {
+		return r, kc.WatchUntilReady(r, 30*time.Second)
 	}
 
-	return nil
+	return r, nil
 }
 
-func deleteFile(path string, kc *kube.Client) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+// serverSideApply applies the resources using server-side apply, conflicts
+// with other field managers are overwritten
+func serverSideApply(r kube.ResourceList) error {
+	force := true
+
+	return r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+		if err != nil {
+			return fmt.Errorf("unable to encode %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		obj, err := resource.NewHelper(info.Client, info.Mapping).
+			WithFieldManager(fieldManager).
+			Patch(info.Namespace, info.Name, types.ApplyPatchType, data, &metav1.PatchOptions{Force: &force})
+
+		if err != nil {
+			return fmt.Errorf("unable to apply %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		return info.Refresh(obj, true)
+	})
+}
+
+// clientSideApply creates the resources that do not exist, resources that
+// already exist are updated in place with a merge patch containing the
+// fields in the manifest. Fields removed from the manifest are not removed
+// from existing resources, use server-side apply to remove them
+func clientSideApply(r kube.ResourceList) error {
+	return r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(fieldManager)
+
+		_, err = helper.Get(info.Namespace, info.Name)
+		if apierrors.IsNotFound(err) {
+			obj, err := helper.Create(info.Namespace, true, info.Object)
+			if err != nil {
+				return fmt.Errorf("unable to create %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+			}
+
+			return info.Refresh(obj, true)
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to get %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+		if err != nil {
+			return fmt.Errorf("unable to encode %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		obj, err := helper.Patch(info.Namespace, info.Name, types.MergePatchType, data, nil)
+		if err != nil {
+			return fmt.Errorf("unable to update %s %s: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		return info.Refresh(obj, true)
+	})
+}
 
-	r, err := kc.Build(f, false)
+func deleteManifest(m manifest, kc *kube.Client) error {
+	r, err := kc.Build(bytes.NewReader(m.data), false)
 	if err != nil {
 		return err
 	}
@@ -302,7 +576,7 @@ func deleteFile(path string, kc *kube.Client) error {
 	_, errs := kc.Delete(r)
 	if errs != nil {
 		//TODO need to handle this better
-		return fmt.Errorf("error deleting configuration for file %s: %v", path, errs)
+		return fmt.Errorf("error deleting configuration for file %s: %v", m.path, errs)
 	}
 
 	return nil
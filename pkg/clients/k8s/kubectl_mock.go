@@ -42,12 +42,34 @@ func (m *MockKubernetes) Apply(files []string, waitUntilReady bool) error {
 	return args.Error(0)
 }
 
+func (m *MockKubernetes) ApplyConfig(files []string, opts ApplyOptions) ([]Object, error) {
+	args := m.Called(files, opts)
+
+	if o, ok := args.Get(0).([]Object); ok {
+		return o, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (m *MockKubernetes) Delete(files []string) error {
 	args := m.Called(files)
 
 	return args.Error(0)
 }
 
+func (m *MockKubernetes) DeleteConfig(files []string, opts ApplyOptions) error {
+	args := m.Called(files, opts)
+
+	return args.Error(0)
+}
+
+func (m *MockKubernetes) DeleteObjects(objects []Object) error {
+	args := m.Called(objects)
+
+	return args.Error(0)
+}
+
 func (m *MockKubernetes) HealthCheckPods(ctx context.Context, selectors []string, timeout time.Duration) error {
 	args := m.Called(ctx, selectors, timeout)
 
@@ -1,7 +1,20 @@
 package k8s
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+
+	openapi_v2 "github.com/google/gnostic-models/openapiv2"
+	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 // TODO: implement these tests
@@ -13,3 +26,194 @@ func TestKubernetesSetConfigCompletesWithoutError(t *testing.T) {
 func TestApply(t *testing.T) {
 	t.Skip()
 }
+
+func TestObjectStringAndParseRoundTrip(t *testing.T) {
+	for _, o := range []Object{
+		{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "config"},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "apps", Name: "web"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole", Name: "reader"},
+	} {
+		p, err := ParseObject(o.String())
+		require.NoError(t, err)
+		require.Equal(t, o, p)
+	}
+}
+
+func TestParseObjectReturnsErrorForInvalidObject(t *testing.T) {
+	_, err := ParseObject("v1/ConfigMap")
+	require.Error(t, err)
+}
+
+func TestBuildManifestsRendersKustomization(t *testing.T) {
+	d := t.TempDir()
+	kd := filepath.Join(d, "kustomize")
+	os.MkdirAll(kd, 0755)
+
+	os.WriteFile(filepath.Join(kd, "kustomization.yaml"), []byte(`
+namePrefix: dev-
+resources:
+- config.yaml
+`), 0644)
+	os.WriteFile(filepath.Join(kd, "config.yaml"), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`), 0644)
+
+	f := filepath.Join(d, "file.yaml")
+	os.WriteFile(f, []byte("kind: Namespace"), 0644)
+
+	m, err := buildManifests([]string{kd, f})
+	require.NoError(t, err)
+	require.Len(t, m, 2)
+
+	require.Equal(t, kd, m[0].source)
+	require.Contains(t, string(m[0].data), "name: dev-config")
+
+	require.Equal(t, f, m[1].source)
+	require.Equal(t, "kind: Namespace", string(m[1].data))
+}
+
+// setupFakeAPIServer starts a Kubernetes API server that serves config maps,
+// the config map named existing exists and can not be created again. The body
+// of each request is recorded by "METHOD path"
+func setupFakeAPIServer(t *testing.T) (*KubernetesImpl, map[string]string) {
+	requests := map[string]string{}
+	mutex := sync.Mutex{}
+
+	// manifests are validated against the OpenAPI schema
+	doc, err := openapi_v2.ParseDocument([]byte(configMapSchema))
+	require.NoError(t, err)
+
+	schema, err := proto.Marshal(doc)
+	require.NoError(t, err)
+
+	existing := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"existing","namespace":"default","resourceVersion":"1"},"data":{"port":"8080"}}`
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mutex.Lock()
+		requests[r.Method+" "+r.URL.Path] = string(body)
+		mutex.Unlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /openapi/v2":
+			rw.Header().Set("Content-Type", "application/octet-stream")
+			rw.Write(schema)
+		case "GET /api":
+			fmt.Fprint(rw, `{"kind":"APIVersions","versions":["v1"]}`)
+		case "GET /apis":
+			fmt.Fprint(rw, `{"kind":"APIGroupList","apiVersion":"v1","groups":[]}`)
+		case "GET /api/v1":
+			fmt.Fprint(rw, `{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"configmaps","singularName":"configmap","namespaced":true,"kind":"ConfigMap","verbs":["create","delete","get","list","patch","update"]}]}`)
+		case "GET /api/v1/namespaces/default/configmaps/existing":
+			fmt.Fprint(rw, existing)
+		case "PATCH /api/v1/namespaces/default/configmaps/existing":
+			fmt.Fprint(rw, existing)
+		case "GET /api/v1/namespaces/default/configmaps/new":
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(rw, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		case "POST /api/v1/namespaces/default/configmaps":
+			if strings.Contains(string(body), `"name":"existing"`) {
+				rw.WriteHeader(http.StatusConflict)
+				fmt.Fprint(rw, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"AlreadyExists","code":409}`)
+				return
+			}
+
+			rw.WriteHeader(http.StatusCreated)
+			rw.Write(body)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(rw, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	// discovery information is cached in the home folder
+	t.Setenv("HOME", t.TempDir())
+
+	kc := filepath.Join(t.TempDir(), "kubeconfig.yaml")
+	os.WriteFile(kc, []byte(fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+  name: default
+contexts:
+- context:
+    cluster: default
+    user: default
+  name: default
+current-context: default
+users:
+- name: default
+  user: {}
+`, s.URL)), 0644)
+
+	return &KubernetesImpl{configPath: kc, l: logger.NewTestLogger(t)}, requests
+}
+
+func TestApplyConfigPatchesExistingObjects(t *testing.T) {
+	k, requests := setupFakeAPIServer(t)
+
+	f := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(f, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+data:
+  port: "9090"
+`), 0644)
+
+	objects, err := k.ApplyConfig([]string{f}, ApplyOptions{})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	require.NotContains(t, requests, "POST /api/v1/namespaces/default/configmaps")
+	require.Contains(t, requests["PATCH /api/v1/namespaces/default/configmaps/existing"], `"port":"9090"`)
+}
+
+func TestApplyConfigCreatesNewObjects(t *testing.T) {
+	k, requests := setupFakeAPIServer(t)
+
+	f := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(f, []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: new
+data:
+  port: "9090"
+`), 0644)
+
+	_, err := k.ApplyConfig([]string{f}, ApplyOptions{})
+	require.NoError(t, err)
+
+	require.Contains(t, requests["POST /api/v1/namespaces/default/configmaps"], `"name":"new"`)
+	require.NotContains(t, requests, "PATCH /api/v1/namespaces/default/configmaps/new")
+}
+
+// configMapSchema is the OpenAPI schema used to validate config maps
+var configMapSchema = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.core.v1.ConfigMap": {
+      "type": "object",
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "ConfigMap", "version": "v1"}],
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"type": "object"},
+        "data": {"type": "object", "additionalProperties": {"type": "string"}}
+      }
+    }
+  }
+}`
@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/hashicorp/go-hclog"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
 	"github.com/muesli/termenv"
 )
 
@@ -59,8 +60,10 @@ type CharmLogger struct {
 	level    string
 }
 
+// NewLogger creates a new logger, values registered with the secrets package
+// are redacted from the output
 func NewLogger(w io.Writer, level string) Logger {
-	l := log.New(w)
+	l := log.New(secrets.NewRedactWriter(w))
 	ll, err := log.ParseLevel(level)
 	if err != nil {
 		ll = log.InfoLevel
@@ -73,7 +76,7 @@ func NewLogger(w io.Writer, level string) Logger {
 // NewTTYLogger creates a new logger with full TTY colors
 func NewTTYLogger(w io.Writer, level string) Logger {
 	r := lipgloss.NewRenderer(w, termenv.WithColorCache(true), termenv.WithTTY(true))
-	l := log.NewWithOptions(w, log.Options{
+	l := log.NewWithOptions(secrets.NewRedactWriter(w), log.Options{
 		Level:           log.InfoLevel,
 		ReportTimestamp: false,
 		Renderer:        r,
@@ -84,7 +87,7 @@ func NewTTYLogger(w io.Writer, level string) Logger {
 
 func (l *CharmLogger) SetOutput(w io.Writer) {
 	l.writer = w
-	l.internal.SetOutput(w)
+	l.internal.SetOutput(secrets.NewRedactWriter(w))
 }
 
 func (l *CharmLogger) Output() io.Writer {
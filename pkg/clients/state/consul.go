@@ -0,0 +1,198 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConsulConfig defines the configuration for the Consul KV backend
+type ConsulConfig struct {
+	// Address of the Consul server including scheme, e.g. http://localhost:8500
+	Address    string `json:"address"`
+	Path       string `json:"path"`
+	Token      string `json:"token,omitempty"`
+	Datacenter string `json:"datacenter,omitempty"`
+}
+
+// Consul stores the state as a value at a Consul KV path.
+// Note: Consul limits values to 512KB.
+type Consul struct {
+	config ConsulConfig
+	client *http.Client
+}
+
+// NewConsul creates a new Consul backend
+func NewConsul(c ConsulConfig) *Consul {
+	return &Consul{c, &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (c *Consul) Read() ([]byte, error) {
+	resp, err := c.do(http.MethodGet, c.config.Path, url.Values{"raw": []string{"true"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrStateNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d reading state from Consul path %s", resp.StatusCode, c.config.Path)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Consul) Write(d []byte) error {
+	resp, err := c.do(http.MethodPut, c.config.Path, nil, d)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d writing state to Consul path %s: %s", resp.StatusCode, c.config.Path, string(b))
+	}
+
+	return nil
+}
+
+func (c *Consul) Delete() error {
+	resp, err := c.do(http.MethodDelete, c.config.Path, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d deleting state from Consul path %s", resp.StatusCode, c.config.Path)
+	}
+
+	return nil
+}
+
+func (c *Consul) String() string {
+	return c.keyAddress(c.config.Path)
+}
+
+// Lock uses a check-and-set with an index of 0 to create the lock key, this
+// only succeeds when the key does not exist
+func (c *Consul) Lock(info *LockInfo) error {
+	d, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to serialize lock info: %s", err)
+	}
+
+	resp, err := c.do(http.MethodPut, c.lockPath(), url.Values{"cas": []string{"0"}}, d)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d creating lock at Consul path %s", resp.StatusCode, c.lockPath())
+	}
+
+	// consul returns the result of the cas operation as the body
+	b, _ := io.ReadAll(resp.Body)
+	if strings.TrimSpace(string(b)) != "true" {
+		current, _ := c.LockInfo()
+		return &LockedError{current}
+	}
+
+	return nil
+}
+
+func (c *Consul) Unlock(id string) error {
+	locked, err := checkLockOwner(c, id)
+	if err != nil || !locked {
+		return err
+	}
+
+	resp, err := c.do(http.MethodDelete, c.lockPath(), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d removing lock at Consul path %s", resp.StatusCode, c.lockPath())
+	}
+
+	return nil
+}
+
+func (c *Consul) LockInfo() (*LockInfo, error) {
+	resp, err := c.do(http.MethodGet, c.lockPath(), url.Values{"raw": []string{"true"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d reading lock from Consul path %s", resp.StatusCode, c.lockPath())
+	}
+
+	d, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalLockInfo(d)
+}
+
+func (c *Consul) lockPath() string {
+	return c.config.Path + ".lock"
+}
+
+func (c *Consul) keyAddress(key string) string {
+	return fmt.Sprintf("%s/v1/kv/%s", strings.TrimSuffix(c.config.Address, "/"), strings.TrimPrefix(key, "/"))
+}
+
+func (c *Consul) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	if c.config.Datacenter != "" {
+		query.Set("dc", c.config.Datacenter)
+	}
+
+	addr := c.keyAddress(key)
+	if len(query) > 0 {
+		addr = addr + "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, addr, r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request for %s: %s", addr, err)
+	}
+
+	if c.config.Token != "" {
+		req.Header.Set("X-Consul-Token", c.config.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to contact Consul at %s: %s", c.config.Address, err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,35 @@
+package state
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsulReadWithNoStateReturnsNotFound(t *testing.T) {
+	s, _ := testSetupStateServer(t)
+	c := NewConsul(ConsulConfig{Address: s.URL, Path: "jumppad/state"})
+
+	_, err := c.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestConsulWriteAndReadUsesKVPath(t *testing.T) {
+	s, reqs := testSetupStateServer(t)
+	c := NewConsul(ConsulConfig{Address: s.URL, Path: "jumppad/state", Token: "abc", Datacenter: "dc2"})
+
+	err := c.Write([]byte(`{"resources":[]}`))
+	require.NoError(t, err)
+
+	d, err := c.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{"resources":[]}`, string(d))
+
+	require.Equal(t, http.MethodPut, (*reqs)[0].Method)
+	require.Equal(t, "/v1/kv/jumppad/state", (*reqs)[0].URL.Path)
+	require.Equal(t, "dc2", (*reqs)[0].URL.Query().Get("dc"))
+	require.Equal(t, "abc", (*reqs)[0].Header.Get("X-Consul-Token"))
+
+	require.Equal(t, "true", (*reqs)[1].URL.Query().Get("raw"))
+}
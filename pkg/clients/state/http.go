@@ -0,0 +1,208 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPConfig defines the configuration for the HTTP backend
+type HTTPConfig struct {
+	// Address is the URL used to GET, POST and DELETE the state
+	Address string `json:"address"`
+	// UpdateMethod is the HTTP method used to write the state, defaults to POST
+	UpdateMethod string `json:"update_method,omitempty"`
+	// LockAddress is the URL used to lock the state, when not set locking
+	// is disabled
+	LockAddress string `json:"lock_address,omitempty"`
+	// LockMethod is the HTTP method used to lock the state, defaults to LOCK
+	LockMethod string `json:"lock_method,omitempty"`
+	// UnlockAddress is the URL used to unlock the state, defaults to LockAddress
+	UnlockAddress string `json:"unlock_address,omitempty"`
+	// UnlockMethod is the HTTP method used to unlock the state, defaults to UNLOCK
+	UnlockMethod string            `json:"unlock_method,omitempty"`
+	Username     string            `json:"username,omitempty"`
+	Password     string            `json:"password,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+}
+
+// HTTP stores the state using a simple REST endpoint, the state is
+// read with GET, written with POST (or UpdateMethod), and removed with
+// DELETE. Any 404 response when reading is treated as no state.
+//
+// Locking follows the same convention as the Terraform HTTP backend, the lock
+// info is sent to the LockAddress using the LockMethod, a 409 or 423 response
+// indicates that the state is locked by another process.
+type HTTP struct {
+	config HTTPConfig
+	client *http.Client
+}
+
+// NewHTTP creates a new HTTP backend
+func NewHTTP(c HTTPConfig) *HTTP {
+	if c.UpdateMethod == "" {
+		c.UpdateMethod = http.MethodPost
+	}
+
+	if c.LockMethod == "" {
+		c.LockMethod = "LOCK"
+	}
+
+	if c.UnlockAddress == "" {
+		c.UnlockAddress = c.LockAddress
+	}
+
+	if c.UnlockMethod == "" {
+		c.UnlockMethod = "UNLOCK"
+	}
+
+	return &HTTP{c, &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (h *HTTP) Read() ([]byte, error) {
+	resp, err := h.do(http.MethodGet, h.config.Address, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent {
+		return nil, ErrStateNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d reading state from %s", resp.StatusCode, h.config.Address)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (h *HTTP) Write(d []byte) error {
+	resp, err := h.do(h.config.UpdateMethod, h.config.Address, d)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d writing state to %s", resp.StatusCode, h.config.Address)
+	}
+
+	return nil
+}
+
+func (h *HTTP) Delete() error {
+	resp, err := h.do(http.MethodDelete, h.config.Address, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d deleting state from %s", resp.StatusCode, h.config.Address)
+	}
+
+	return nil
+}
+
+func (h *HTTP) String() string {
+	return h.config.Address
+}
+
+func (h *HTTP) Lock(info *LockInfo) error {
+	if h.config.LockAddress == "" {
+		return nil
+	}
+
+	d, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to serialize lock info: %s", err)
+	}
+
+	resp, err := h.do(h.config.LockMethod, h.config.LockAddress, d)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict, http.StatusLocked:
+		// the server can optionally return the current lock holder
+		b, _ := io.ReadAll(resp.Body)
+		current, _ := unmarshalLockInfo(b)
+		return &LockedError{current}
+	}
+
+	return fmt.Errorf("unexpected status code %d locking state at %s", resp.StatusCode, h.config.LockAddress)
+}
+
+// Unlock sends the lock id to the server, it is the responsibility of the
+// server to check the lock owner
+func (h *HTTP) Unlock(id string) error {
+	if h.config.UnlockAddress == "" {
+		return nil
+	}
+
+	d, err := json.Marshal(&LockInfo{ID: id})
+	if err != nil {
+		return fmt.Errorf("unable to serialize lock info: %s", err)
+	}
+
+	resp, err := h.do(h.config.UnlockMethod, h.config.UnlockAddress, d)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d unlocking state at %s", resp.StatusCode, h.config.UnlockAddress)
+	}
+
+	return nil
+}
+
+// LockInfo is not supported by the HTTP backend, the lock holder is only
+// returned when a lock fails
+func (h *HTTP) LockInfo() (*LockInfo, error) {
+	return nil, nil
+}
+
+func (h *HTTP) do(method, address string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, address, r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request for %s: %s", address, err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for k, v := range h.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if h.config.Username != "" {
+		req.SetBasicAuth(h.config.Username, h.config.Password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to contact state backend %s: %s", address, err)
+	}
+
+	return resp, nil
+}
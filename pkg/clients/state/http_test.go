@@ -0,0 +1,91 @@
+package state
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSetupStateServer creates a simple in memory server that stores
+// the body for any write and returns it on GET
+func testSetupStateServer(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	reqs := &[]*http.Request{}
+	var state []byte
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		*reqs = append(*reqs, r)
+
+		switch r.Method {
+		case http.MethodGet:
+			if state == nil {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			rw.Write(state)
+		case http.MethodDelete:
+			state = nil
+		default:
+			state, _ = io.ReadAll(r.Body)
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s, reqs
+}
+
+func TestHTTPReadWithNoStateReturnsNotFound(t *testing.T) {
+	s, _ := testSetupStateServer(t)
+	h := NewHTTP(HTTPConfig{Address: s.URL})
+
+	_, err := h.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestHTTPWriteAndReadReturnsState(t *testing.T) {
+	s, reqs := testSetupStateServer(t)
+	h := NewHTTP(HTTPConfig{Address: s.URL, Username: "nic", Password: "secret", Headers: map[string]string{"X-Test": "abc"}})
+
+	err := h.Write([]byte(`{"resources":[]}`))
+	require.NoError(t, err)
+
+	d, err := h.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{"resources":[]}`, string(d))
+
+	require.Equal(t, http.MethodPost, (*reqs)[0].Method)
+	require.Equal(t, "abc", (*reqs)[0].Header.Get("X-Test"))
+
+	u, p, ok := (*reqs)[0].BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "nic", u)
+	require.Equal(t, "secret", p)
+}
+
+func TestHTTPWriteUsesUpdateMethod(t *testing.T) {
+	s, reqs := testSetupStateServer(t)
+	h := NewHTTP(HTTPConfig{Address: s.URL, UpdateMethod: http.MethodPut})
+
+	err := h.Write([]byte(`{}`))
+	require.NoError(t, err)
+
+	require.Equal(t, http.MethodPut, (*reqs)[0].Method)
+}
+
+func TestHTTPDeleteRemovesState(t *testing.T) {
+	s, _ := testSetupStateServer(t)
+	h := NewHTTP(HTTPConfig{Address: s.URL})
+
+	err := h.Write([]byte(`{}`))
+	require.NoError(t, err)
+
+	err = h.Delete()
+	require.NoError(t, err)
+
+	_, err = h.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalConfig defines the configuration for the local file backend
+type LocalConfig struct {
+	Path string `json:"path"`
+}
+
+// Local stores the state in a file on the local filesystem
+type Local struct {
+	path string
+}
+
+// NewLocal creates a new Local backend that writes the state to the given path
+func NewLocal(path string) *Local {
+	return &Local{path}
+}
+
+func (l *Local) Read() ([]byte, error) {
+	d, err := os.ReadFile(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrStateNotFound
+	}
+
+	return d, err
+}
+
+func (l *Local) Write(d []byte) error {
+	dir := filepath.Dir(l.path)
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create directory for state file '%s', error: %s", dir, err)
+	}
+
+	err = os.WriteFile(l.path, d, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to write state file '%s', error: %s", l.path, err)
+	}
+
+	return nil
+}
+
+func (l *Local) Delete() error {
+	err := os.Remove(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *Local) String() string {
+	return l.path
+}
+
+func (l *Local) Lock(info *LockInfo) error {
+	dir := filepath.Dir(l.path)
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create directory for state lock '%s', error: %s", dir, err)
+	}
+
+	d, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to serialize lock info: %s", err)
+	}
+
+	// O_EXCL ensures that only a single process can create the lock file
+	f, err := os.OpenFile(l.lockPath(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		current, _ := l.LockInfo()
+		return &LockedError{current}
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to create state lock '%s', error: %s", l.lockPath(), err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(d)
+	return err
+}
+
+func (l *Local) Unlock(id string) error {
+	locked, err := checkLockOwner(l, id)
+	if err != nil || !locked {
+		return err
+	}
+
+	err = os.Remove(l.lockPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *Local) LockInfo() (*LockInfo, error) {
+	d, err := os.ReadFile(l.lockPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalLockInfo(d)
+}
+
+func (l *Local) lockPath() string {
+	return l.path + ".lock"
+}
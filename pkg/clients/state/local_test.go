@@ -0,0 +1,41 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalReadWithNoStateReturnsNotFound(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+
+	_, err := l.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestLocalWriteCreatesFolderAndReads(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "sub", "state.json"))
+
+	err := l.Write([]byte(`{"resources":[]}`))
+	require.NoError(t, err)
+
+	d, err := l.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{"resources":[]}`, string(d))
+}
+
+func TestLocalDeleteRemovesStateAndIgnoresMissing(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "state.json")
+	l := NewLocal(p)
+
+	err := l.Write([]byte(`{}`))
+	require.NoError(t, err)
+
+	err = l.Delete()
+	require.NoError(t, err)
+	require.NoFileExists(t, p)
+
+	err = l.Delete()
+	require.NoError(t, err)
+}
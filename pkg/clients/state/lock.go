@@ -0,0 +1,123 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LockInfo contains the details of the process that holds the state lock
+type LockInfo struct {
+	ID      string    `json:"id"`
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Created time.Time `json:"created"`
+}
+
+// NewLockInfo creates the lock info for the current process
+func NewLockInfo() *LockInfo {
+	hn, _ := os.Hostname()
+
+	return &LockInfo{
+		ID:      uuid.New().String(),
+		PID:     os.Getpid(),
+		Host:    hn,
+		Command: strings.Join(os.Args, " "),
+		Created: time.Now().UTC(),
+	}
+}
+
+func (l *LockInfo) String() string {
+	return fmt.Sprintf("ID: %s, PID: %d, Host: %s, Command: '%s', Created: %s", l.ID, l.PID, l.Host, l.Command, l.Created.Format(time.RFC3339))
+}
+
+// LockedError is returned when the state is locked by another process
+type LockedError struct {
+	// Info contains the details of the current lock holder, Info can be nil
+	// when the backend is unable to report the lock holder
+	Info *LockInfo
+}
+
+func (e *LockedError) Error() string {
+	if e.Info == nil {
+		return "state is locked by another process"
+	}
+
+	return fmt.Sprintf("state is locked by another process, %s", e.Info)
+}
+
+// Locker defines an interface for backends that can prevent concurrent
+// modification of the state using an advisory lock
+type Locker interface {
+	// Lock attempts to acquire the lock for the given owner, when the lock
+	// is held by another owner a *LockedError is returned
+	Lock(info *LockInfo) error
+
+	// Unlock releases the lock held by the owner with the given id, when id is
+	// empty the lock is removed regardless of the owner
+	Unlock(id string) error
+
+	// LockInfo returns the details of the current lock holder, nil is
+	// returned when the state is not locked
+	LockInfo() (*LockInfo, error)
+}
+
+// AcquireLock attempts to lock the state, if the state is locked by another
+// process AcquireLock retries until the timeout elapses
+func AcquireLock(l Locker, info *LockInfo, timeout time.Duration, backoff time.Duration) error {
+	st := time.Now()
+
+	for {
+		err := l.Lock(info)
+		if err == nil {
+			return nil
+		}
+
+		var le *LockedError
+		if !errors.As(err, &le) {
+			return fmt.Errorf("unable to lock state: %s", err)
+		}
+
+		if time.Since(st) >= timeout {
+			return err
+		}
+
+		time.Sleep(backoff)
+	}
+}
+
+// checkLockOwner returns true when the state is locked, an error is returned
+// when the lock is not held by the owner with the given id, an empty id
+// matches any owner
+func checkLockOwner(l Locker, id string) (bool, error) {
+	current, err := l.LockInfo()
+	if err != nil {
+		return false, err
+	}
+
+	if current == nil {
+		return false, nil
+	}
+
+	if id != "" && current.ID != id {
+		return false, fmt.Errorf("unable to unlock state, lock is held by a different owner, %s", current)
+	}
+
+	return true, nil
+}
+
+func unmarshalLockInfo(d []byte) (*LockInfo, error) {
+	li := &LockInfo{}
+	err := json.Unmarshal(d, li)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lock info: %s", err)
+	}
+
+	return li, nil
+}
@@ -0,0 +1,160 @@
+package state
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLockWhenUnlockedAcquiresLock(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+	info := NewLockInfo()
+
+	err := l.Lock(info)
+	require.NoError(t, err)
+
+	current, err := l.LockInfo()
+	require.NoError(t, err)
+	require.Equal(t, info.ID, current.ID)
+}
+
+func TestLocalLockWhenLockedReturnsLockedError(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+	info := NewLockInfo()
+
+	err := l.Lock(info)
+	require.NoError(t, err)
+
+	err = l.Lock(NewLockInfo())
+
+	le := &LockedError{}
+	require.ErrorAs(t, err, &le)
+	require.Equal(t, info.ID, le.Info.ID)
+}
+
+func TestLocalUnlockWithDifferentOwnerReturnsError(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+
+	err := l.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	err = l.Unlock("other")
+	require.Error(t, err)
+
+	current, err := l.LockInfo()
+	require.NoError(t, err)
+	require.NotNil(t, current)
+}
+
+func TestLocalUnlockWithEmptyIDRemovesLock(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+
+	err := l.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	err = l.Unlock("")
+	require.NoError(t, err)
+
+	current, err := l.LockInfo()
+	require.NoError(t, err)
+	require.Nil(t, current)
+}
+
+func TestAcquireLockWhenLockedRetriesUntilTimeout(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+
+	err := l.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	st := time.Now()
+	err = AcquireLock(l, NewLockInfo(), 50*time.Millisecond, 10*time.Millisecond)
+
+	le := &LockedError{}
+	require.ErrorAs(t, err, &le)
+	require.GreaterOrEqual(t, time.Since(st), 50*time.Millisecond)
+}
+
+func TestAcquireLockWhenReleasedAcquiresLock(t *testing.T) {
+	l := NewLocal(filepath.Join(t.TempDir(), "state.json"))
+	first := NewLockInfo()
+
+	err := l.Lock(first)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.Unlock(first.ID)
+	}()
+
+	second := NewLockInfo()
+	err = AcquireLock(l, second, 1*time.Second, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	current, err := l.LockInfo()
+	require.NoError(t, err)
+	require.Equal(t, second.ID, current.ID)
+}
+
+func TestHTTPLockWhenServerReturnsConflictReturnsLockedError(t *testing.T) {
+	reqs := []*http.Request{}
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r)
+		rw.WriteHeader(http.StatusConflict)
+		rw.Write([]byte(`{"id":"abc","pid":12}`))
+	}))
+	t.Cleanup(s.Close)
+
+	h := NewHTTP(HTTPConfig{Address: s.URL, LockAddress: s.URL + "/lock"})
+
+	err := h.Lock(NewLockInfo())
+
+	le := &LockedError{}
+	require.ErrorAs(t, err, &le)
+	require.Equal(t, "abc", le.Info.ID)
+	require.Equal(t, "LOCK", reqs[0].Method)
+	require.Equal(t, "/lock", reqs[0].URL.Path)
+}
+
+func TestHTTPLockWithNoLockAddressDoesNothing(t *testing.T) {
+	s, reqs := testSetupStateServer(t)
+	h := NewHTTP(HTTPConfig{Address: s.URL})
+
+	err := h.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	err = h.Unlock("")
+	require.NoError(t, err)
+
+	require.Len(t, *reqs, 0)
+}
+
+func TestConsulLockUsesCheckAndSet(t *testing.T) {
+	reqs := []*http.Request{}
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		reqs = append(reqs, r)
+
+		if r.Method == http.MethodGet {
+			rw.Write([]byte(`{"id":"abc"}`))
+			return
+		}
+
+		rw.Write([]byte("false"))
+	}))
+	t.Cleanup(s.Close)
+
+	c := NewConsul(ConsulConfig{Address: s.URL, Path: "jumppad/state"})
+
+	err := c.Lock(NewLockInfo())
+
+	le := &LockedError{}
+	require.ErrorAs(t, err, &le)
+	require.Equal(t, "abc", le.Info.ID)
+
+	require.Equal(t, http.MethodPut, reqs[0].Method)
+	require.Equal(t, "/v1/kv/jumppad/state.lock", reqs[0].URL.Path)
+	require.Equal(t, "0", reqs[0].URL.Query().Get("cas"))
+}
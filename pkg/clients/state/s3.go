@@ -0,0 +1,207 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Config defines the configuration for an S3 compatible backend
+type S3Config struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Region string `json:"region,omitempty"`
+	// Endpoint allows S3 compatible stores such as MinIO to be used,
+	// when set path style addressing is used
+	Endpoint string `json:"endpoint,omitempty"`
+	// Profile is the name of the shared credentials profile, when AccessKey
+	// is not set credentials are read using the default AWS credential chain
+	Profile   string `json:"profile,omitempty"`
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+}
+
+// S3 stores the state as an object in an S3 compatible object store
+type S3 struct {
+	config S3Config
+	client *s3.S3
+}
+
+// NewS3 creates a new S3 backend
+func NewS3(c S3Config) (*S3, error) {
+	if c.Bucket == "" || c.Key == "" {
+		return nil, fmt.Errorf("s3 state backend requires both bucket and key")
+	}
+
+	ac := aws.NewConfig()
+	if c.Region != "" {
+		ac = ac.WithRegion(c.Region)
+	} else {
+		ac = ac.WithRegion("us-east-1")
+	}
+
+	if c.Endpoint != "" {
+		ac = ac.WithEndpoint(c.Endpoint).WithS3ForcePathStyle(true)
+	}
+
+	if c.AccessKey != "" {
+		ac = ac.WithCredentials(credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, ""))
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *ac,
+		Profile:           c.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create session for s3 state backend: %s", err)
+	}
+
+	return &S3{c, s3.New(sess)}, nil
+}
+
+func (s *S3) Read() ([]byte, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.config.Key),
+	})
+
+	if isS3NotFound(err) {
+		return nil, ErrStateNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read state from %s: %s", s, err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+func (s *S3) Write(d []byte) error {
+	_, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.config.Bucket),
+		Key:         aws.String(s.config.Key),
+		Body:        bytes.NewReader(d),
+		ContentType: aws.String("application/json"),
+	})
+
+	if err != nil {
+		return fmt.Errorf("unable to write state to %s: %s", s, err)
+	}
+
+	return nil
+}
+
+func (s *S3) Delete() error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.config.Key),
+	})
+
+	if err != nil && !isS3NotFound(err) {
+		return fmt.Errorf("unable to delete state from %s: %s", s, err)
+	}
+
+	return nil
+}
+
+func (s *S3) String() string {
+	return fmt.Sprintf("s3://%s/%s", s.config.Bucket, s.config.Key)
+}
+
+func isS3NotFound(err error) bool {
+	var ae awserr.Error
+	if errors.As(err, &ae) {
+		return ae.Code() == s3.ErrCodeNoSuchKey || ae.Code() == "NotFound"
+	}
+
+	return false
+}
+
+// Lock creates the lock object using a conditional write, S3 rejects the write
+// with a 412 when the object already exists
+func (s *S3) Lock(info *LockInfo) error {
+	d, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("unable to serialize lock info: %s", err)
+	}
+
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(s.config.Bucket),
+		Key:         aws.String(s.lockKey()),
+		Body:        bytes.NewReader(d),
+		ContentType: aws.String("application/json"),
+	})
+
+	// PutObjectInput does not expose If-None-Match so set the header directly
+	req.HTTPRequest.Header.Set("If-None-Match", "*")
+
+	err = req.Send()
+	if err != nil {
+		var rf awserr.RequestFailure
+		if errors.As(err, &rf) && (rf.StatusCode() == http.StatusPreconditionFailed || rf.StatusCode() == http.StatusConflict) {
+			current, _ := s.LockInfo()
+			return &LockedError{current}
+		}
+
+		return fmt.Errorf("unable to create lock s3://%s/%s: %s", s.config.Bucket, s.lockKey(), err)
+	}
+
+	return nil
+}
+
+func (s *S3) Unlock(id string) error {
+	locked, err := checkLockOwner(s, id)
+	if err != nil || !locked {
+		return err
+	}
+
+	_, err = s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.lockKey()),
+	})
+
+	if err != nil && !isS3NotFound(err) {
+		return fmt.Errorf("unable to remove lock s3://%s/%s: %s", s.config.Bucket, s.lockKey(), err)
+	}
+
+	return nil
+}
+
+func (s *S3) LockInfo() (*LockInfo, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.lockKey()),
+	})
+
+	if isS3NotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to read lock s3://%s/%s: %s", s.config.Bucket, s.lockKey(), err)
+	}
+	defer out.Body.Close()
+
+	d, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalLockInfo(d)
+}
+
+func (s *S3) lockKey() string {
+	return s.config.Key + ".lock"
+}
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSetupS3Server creates a fake S3 endpoint that stores objects in memory
+// using path style addressing, conditional writes with If-None-Match are
+// rejected when the object exists
+func testSetupS3Server(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	reqs := &[]*http.Request{}
+	objects := map[string][]byte{}
+	mutex := sync.Mutex{}
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		*reqs = append(*reqs, r)
+
+		switch r.Method {
+		case http.MethodGet:
+			d, ok := objects[r.URL.Path]
+			if !ok {
+				rw.Header().Set("Content-Type", "application/xml")
+				rw.WriteHeader(http.StatusNotFound)
+				rw.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+				return
+			}
+
+			rw.Write(d)
+		case http.MethodPut:
+			if _, ok := objects[r.URL.Path]; ok && r.Header.Get("If-None-Match") == "*" {
+				rw.Header().Set("Content-Type", "application/xml")
+				rw.WriteHeader(http.StatusPreconditionFailed)
+				rw.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`))
+				return
+			}
+
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s, reqs
+}
+
+func testSetupS3(t *testing.T) (*S3, *[]*http.Request) {
+	s, reqs := testSetupS3Server(t)
+
+	b, err := NewS3(S3Config{
+		Bucket:    "jumppad",
+		Key:       "dev/state.json",
+		Endpoint:  s.URL,
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	require.NoError(t, err)
+
+	return b, reqs
+}
+
+func TestNewS3WithoutBucketReturnsError(t *testing.T) {
+	_, err := NewS3(S3Config{Key: "state.json"})
+	require.Error(t, err)
+}
+
+func TestS3ReadWithNoStateReturnsNotFound(t *testing.T) {
+	b, _ := testSetupS3(t)
+
+	_, err := b.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestS3WriteAndReadReturnsState(t *testing.T) {
+	b, reqs := testSetupS3(t)
+
+	err := b.Write([]byte(`{"resources":[]}`))
+	require.NoError(t, err)
+
+	d, err := b.Read()
+	require.NoError(t, err)
+	require.Equal(t, `{"resources":[]}`, string(d))
+
+	// path style addressing is used for custom endpoints
+	require.Equal(t, http.MethodPut, (*reqs)[0].Method)
+	require.Equal(t, "/jumppad/dev/state.json", (*reqs)[0].URL.Path)
+}
+
+func TestS3DeleteRemovesState(t *testing.T) {
+	b, _ := testSetupS3(t)
+
+	err := b.Write([]byte(`{"resources":[]}`))
+	require.NoError(t, err)
+
+	err = b.Delete()
+	require.NoError(t, err)
+
+	_, err = b.Read()
+	require.ErrorIs(t, err, ErrStateNotFound)
+}
+
+func TestS3LockUsesConditionalWrite(t *testing.T) {
+	b, reqs := testSetupS3(t)
+
+	err := b.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	require.Equal(t, http.MethodPut, (*reqs)[0].Method)
+	require.Equal(t, "/jumppad/dev/state.json.lock", (*reqs)[0].URL.Path)
+	require.Equal(t, "*", (*reqs)[0].Header.Get("If-None-Match"))
+}
+
+func TestS3LockWhenLockedReturnsLockedError(t *testing.T) {
+	b, _ := testSetupS3(t)
+
+	owner := NewLockInfo()
+	err := b.Lock(owner)
+	require.NoError(t, err)
+
+	err = b.Lock(NewLockInfo())
+
+	le := &LockedError{}
+	require.ErrorAs(t, err, &le)
+	require.Equal(t, owner.ID, le.Info.ID)
+}
+
+func TestS3UnlockRemovesLock(t *testing.T) {
+	b, _ := testSetupS3(t)
+
+	info := NewLockInfo()
+	err := b.Lock(info)
+	require.NoError(t, err)
+
+	err = b.Unlock(info.ID)
+	require.NoError(t, err)
+
+	li, err := b.LockInfo()
+	require.NoError(t, err)
+	require.Nil(t, li)
+
+	// the lock can be acquired again once released
+	err = b.Lock(NewLockInfo())
+	require.NoError(t, err)
+}
+
+func TestS3UnlockWithDifferentOwnerReturnsError(t *testing.T) {
+	b, _ := testSetupS3(t)
+
+	err := b.Lock(NewLockInfo())
+	require.NoError(t, err)
+
+	err = b.Unlock("other")
+	require.Error(t, err)
+
+	li, err := b.LockInfo()
+	require.NoError(t, err)
+	require.NotNil(t, li)
+}
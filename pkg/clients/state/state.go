@@ -0,0 +1,163 @@
+package state
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ErrStateNotFound is returned by a Backend when no state has been written
+var ErrStateNotFound = fmt.Errorf("state not found")
+
+const (
+	// BackendLocal stores the state in a file on the local machine
+	BackendLocal = "local"
+	// BackendS3 stores the state as an object in an S3 compatible store
+	BackendS3 = "s3"
+	// BackendHTTP stores the state using a simple REST endpoint
+	BackendHTTP = "http"
+	// BackendConsul stores the state in a Consul KV path
+	BackendConsul = "consul"
+)
+
+// Backend defines an interface for a location where the jumppad state
+// is persisted. Backends only deal with the raw serialized state, parsing
+// is the responsibility of the caller.
+type Backend interface {
+	// Read returns the raw state, when the state does not exist
+	// ErrStateNotFound is returned
+	Read() ([]byte, error)
+
+	// Write persists the raw state, overwriting any existing state
+	Write(d []byte) error
+
+	// Delete removes the state, deleting state that does not exist is
+	// not an error
+	Delete() error
+
+	// String returns a human readable description of the state location
+	String() string
+
+	Locker
+}
+
+// Config defines the configuration for a state backend. Config is serializable
+// so that the selected backend can be persisted between jumppad commands.
+type Config struct {
+	Type   string        `json:"type"`
+	Local  *LocalConfig  `json:"local,omitempty"`
+	S3     *S3Config     `json:"s3,omitempty"`
+	HTTP   *HTTPConfig   `json:"http,omitempty"`
+	Consul *ConsulConfig `json:"consul,omitempty"`
+}
+
+// New creates a Backend from the given config
+func New(c *Config) (Backend, error) {
+	if c == nil {
+		return nil, fmt.Errorf("state backend config is nil")
+	}
+
+	switch c.Type {
+	case BackendLocal:
+		if c.Local == nil {
+			return nil, fmt.Errorf("local state backend requires a configuration block")
+		}
+
+		return NewLocal(c.Local.Path), nil
+	case BackendS3:
+		if c.S3 == nil {
+			return nil, fmt.Errorf("s3 state backend requires a configuration block")
+		}
+
+		return NewS3(*c.S3)
+	case BackendHTTP:
+		if c.HTTP == nil {
+			return nil, fmt.Errorf("http state backend requires a configuration block")
+		}
+
+		return NewHTTP(*c.HTTP), nil
+	case BackendConsul:
+		if c.Consul == nil {
+			return nil, fmt.Errorf("consul state backend requires a configuration block")
+		}
+
+		return NewConsul(*c.Consul), nil
+	}
+
+	return nil, fmt.Errorf("unknown state backend type '%s', valid types are %s, %s, %s, %s", c.Type, BackendLocal, BackendS3, BackendHTTP, BackendConsul)
+}
+
+// ParseURL creates a backend Config from a URL, this is used to configure
+// the backend using an environment variable.
+//
+//	/path/to/state.json
+//	file:///path/to/state.json
+//	s3://bucket/path/state.json?region=eu-west-1&endpoint=http://localhost:9000
+//	http://state.example.com/jumppad
+//	consul://localhost:8500/jumppad/state?token=abc
+func ParseURL(s string) (*Config, error) {
+	if !strings.Contains(s, "://") {
+		p, err := filepath.Abs(s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine absolute path for state file '%s': %s", s, err)
+		}
+
+		return &Config{Type: BackendLocal, Local: &LocalConfig{Path: p}}, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse state backend url '%s': %s", s, err)
+	}
+
+	q := u.Query()
+
+	switch u.Scheme {
+	case "file":
+		return &Config{Type: BackendLocal, Local: &LocalConfig{Path: filepath.FromSlash(u.Host + u.Path)}}, nil
+
+	case "s3":
+		key := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || key == "" {
+			return nil, fmt.Errorf("s3 state backend url must be in the format s3://bucket/key")
+		}
+
+		return &Config{
+			Type: BackendS3,
+			S3: &S3Config{
+				Bucket:   u.Host,
+				Key:      key,
+				Region:   q.Get("region"),
+				Endpoint: q.Get("endpoint"),
+				Profile:  q.Get("profile"),
+			},
+		}, nil
+
+	case "http", "https":
+		return &Config{Type: BackendHTTP, HTTP: &HTTPConfig{Address: s}}, nil
+
+	case "consul":
+		path := strings.TrimPrefix(u.Path, "/")
+		if path == "" {
+			return nil, fmt.Errorf("consul state backend url must be in the format consul://host:port/path")
+		}
+
+		scheme := q.Get("scheme")
+		if scheme == "" {
+			scheme = "http"
+		}
+
+		return &Config{
+			Type: BackendConsul,
+			Consul: &ConsulConfig{
+				Address:    fmt.Sprintf("%s://%s", scheme, u.Host),
+				Path:       path,
+				Token:      q.Get("token"),
+				Datacenter: q.Get("datacenter"),
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported state backend scheme '%s'", u.Scheme)
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURLWithPathReturnsLocal(t *testing.T) {
+	c, err := ParseURL("/tmp/state.json")
+	require.NoError(t, err)
+
+	require.Equal(t, BackendLocal, c.Type)
+	require.Equal(t, "/tmp/state.json", c.Local.Path)
+}
+
+func TestParseURLWithFileReturnsLocal(t *testing.T) {
+	c, err := ParseURL("file:///tmp/state.json")
+	require.NoError(t, err)
+
+	require.Equal(t, BackendLocal, c.Type)
+	require.Equal(t, "/tmp/state.json", c.Local.Path)
+}
+
+func TestParseURLWithS3ReturnsS3(t *testing.T) {
+	c, err := ParseURL("s3://mybucket/ci/state.json?region=eu-west-1&endpoint=http://localhost:9000")
+	require.NoError(t, err)
+
+	require.Equal(t, BackendS3, c.Type)
+	require.Equal(t, "mybucket", c.S3.Bucket)
+	require.Equal(t, "ci/state.json", c.S3.Key)
+	require.Equal(t, "eu-west-1", c.S3.Region)
+	require.Equal(t, "http://localhost:9000", c.S3.Endpoint)
+}
+
+func TestParseURLWithS3NoKeyReturnsError(t *testing.T) {
+	_, err := ParseURL("s3://mybucket")
+	require.Error(t, err)
+}
+
+func TestParseURLWithHTTPReturnsHTTP(t *testing.T) {
+	c, err := ParseURL("https://state.example.com/jumppad")
+	require.NoError(t, err)
+
+	require.Equal(t, BackendHTTP, c.Type)
+	require.Equal(t, "https://state.example.com/jumppad", c.HTTP.Address)
+}
+
+func TestParseURLWithConsulReturnsConsul(t *testing.T) {
+	c, err := ParseURL("consul://localhost:8500/jumppad/state?token=abc")
+	require.NoError(t, err)
+
+	require.Equal(t, BackendConsul, c.Type)
+	require.Equal(t, "http://localhost:8500", c.Consul.Address)
+	require.Equal(t, "jumppad/state", c.Consul.Path)
+	require.Equal(t, "abc", c.Consul.Token)
+}
+
+func TestParseURLWithUnknownSchemeReturnsError(t *testing.T) {
+	_, err := ParseURL("ftp://localhost/state")
+	require.Error(t, err)
+}
+
+func TestNewWithUnknownTypeReturnsError(t *testing.T) {
+	_, err := New(&Config{Type: "foo"})
+	require.Error(t, err)
+}
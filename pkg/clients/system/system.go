@@ -13,6 +13,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
 
 const (
@@ -76,39 +77,57 @@ func (b *SystemImpl) OpenBrowser(uri string) error {
 // Preflight checks that the required software is installed and is
 // working correctly
 func (b *SystemImpl) Preflight() (string, error) {
-	dockerPass := true
-	podmanPass := true
 	gitPass := true
 	errors := ""
 	output := ""
 
-	// check docker
-
-	if b.checkDocker() != nil {
-		dockerPass = false
+	// when containerd is selected only containerd needs to be available
+	// otherwise either Docker or Podman is required
+	runtimePass := true
+	if utils.ContainerRuntime() == container.RuntimeContainerd {
+		if b.checkContainerd() != nil {
+			runtimePass = false
+			output += fmt.Sprintf(" [ %s ] Containerd\n", fmt.Sprintf(Red, " ERROR "))
+			errors += "* Unable to connect to containerd, ensure containerd and nerdctl are installed and running.\n"
+		} else {
+			output += fmt.Sprintf(" [ %s ] Containerd\n", fmt.Sprintf(Green, "  OK   "))
+		}
 	} else {
-		output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Green, "  OK   "))
-	}
+		dockerPass := true
+		podmanPass := true
 
-	if b.checkPodman() != nil {
-		podmanPass = false
-	} else {
-		output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Green, "  OK   "))
-	}
+		if b.checkDocker() != nil {
+			dockerPass = false
+		} else {
+			output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Green, "  OK   "))
+		}
 
-	if !dockerPass && podmanPass {
-		output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Yellow, "WARNING"))
-	}
+		if b.checkPodman() != nil {
+			podmanPass = false
+		} else {
+			output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Green, "  OK   "))
+		}
 
-	if dockerPass && !podmanPass {
-		output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Yellow, "WARNING"))
-	}
+		if !dockerPass && podmanPass {
+			output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Yellow, "WARNING"))
+		}
 
-	if !dockerPass && !podmanPass {
-		output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Red, " ERROR "))
-		errors += "* Unable to connect to Docker, ensure Docker is installed and running.\n"
-		output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Red, " ERROR "))
-		errors += "* Unable to connect to Podman, ensure Podman is installed and running.\n"
+		if dockerPass && !podmanPass {
+			output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Yellow, "WARNING"))
+		}
+
+		if !dockerPass && !podmanPass {
+			runtimePass = false
+			output += fmt.Sprintf(" [ %s ] Docker\n", fmt.Sprintf(Red, " ERROR "))
+			errors += "* Unable to connect to Docker, ensure Docker is installed and running.\n"
+			output += fmt.Sprintf(" [ %s ] Podman\n", fmt.Sprintf(Red, " ERROR "))
+			errors += "* Unable to connect to Podman, ensure Podman is installed and running.\n"
+
+			// containerd is available but not selected
+			if b.checkContainerd() == nil {
+				errors += "* containerd is available, set JUMPPAD_CONTAINER_RUNTIME=containerd to use it.\n"
+			}
+		}
 	}
 
 	if b.checkGit() != nil {
@@ -128,7 +147,7 @@ func (b *SystemImpl) Preflight() (string, error) {
 		}
 	}
 
-	if (!dockerPass && !podmanPass) || !gitPass {
+	if !runtimePass || !gitPass {
 		return fmt.Sprintf("%s\n\n%s", output, errors), fmt.Errorf("errors preflighting system")
 	}
 
@@ -233,6 +252,20 @@ func (b *SystemImpl) checkPodman() error {
 	return nil
 }
 
+func (b *SystemImpl) checkContainerd() error {
+	n, err := container.NewNerdctl()
+	if err != nil {
+		return err
+	}
+
+	_, err = container.NewContainerdTasks(n, nil, nil, b.logger)
+	if err != nil {
+		return fmt.Errorf("unable to connect to containerd, please check that containerd is running and CONTAINERD_ADDRESS is set for rootless installs: %w", err)
+	}
+
+	return nil
+}
+
 func (b *SystemImpl) checkGit() error {
 	_, err := exec.LookPath("git")
 	return err
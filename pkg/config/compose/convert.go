@@ -0,0 +1,616 @@
+package compose
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// Convert reads the compose file and returns the equivalent jumppad
+// configuration, services are converted to container resources and networks
+// to network resources. Paths on the host are written relative to dir, when
+// dir is empty absolute paths are used. The returned warnings describe the
+// parts of the compose file that could not be converted.
+func Convert(file, dir string) ([]byte, []string, error) {
+	p, err := Load(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, err := p.hcl(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return d, p.warnings, nil
+}
+
+// Generate converts the compose file and writes the configuration to a folder
+// in the jumppad cache, the path of the folder is returned. The folder is
+// named from the checksum of the configuration so that any change to the
+// compose file results in a new folder
+func Generate(file string) (string, []string, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	d, warnings, err := Convert(file, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	sum := sha256.Sum256(append([]byte(file), d...))
+	dir := utils.CacheFolder(filepath.Join("compose", fmt.Sprintf("%x", sum[:8])), 0755)
+
+	err = os.WriteFile(filepath.Join(dir, "main.hcl"), d, 0644)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to write configuration for compose file %s: %w", file, err)
+	}
+
+	return dir, warnings, nil
+}
+
+// defaultNetwork is the network services are attached to when they do not
+// specify any networks
+const defaultNetwork = "default"
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// resourceName returns a valid resource name for the compose name
+func resourceName(name string) string {
+	return invalidNameChars.ReplaceAllString(name, "_")
+}
+
+// hcl returns the jumppad configuration for the project, paths are written
+// relative to dir when set
+func (p *Project) hcl(dir string) ([]byte, error) {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte(fmt.Sprintf("# Generated from the compose file %s\n", filepath.Base(p.file)))},
+	})
+
+	networks, err := p.networkResources()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedKeys(networks) {
+		if networks[name] == "" {
+			continue
+		}
+
+		n := p.Networks[name]
+		if n == nil {
+			n = &Network{}
+		}
+
+		subnet := p.subnet(name)
+		if len(n.IPAM.Config) > 0 && n.IPAM.Config[0].Subnet != "" {
+			subnet = n.IPAM.Config[0].Subnet
+		}
+
+		body.AppendNewline()
+		nb := body.AppendNewBlock("resource", []string{"network", networks[name]}).Body()
+		nb.SetAttributeValue("subnet", cty.StringVal(subnet))
+
+		if n.EnableIPv6 {
+			nb.SetAttributeValue("enable_ipv6", cty.True)
+		}
+	}
+
+	for _, name := range sortedKeys(p.Services) {
+		err := p.writeService(body, name, p.Services[name], networks, dir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert service %s: %w", name, err)
+		}
+	}
+
+	return hclwrite.Format(f.Bytes()), nil
+}
+
+// networkResources returns the resource names for the networks used by the
+// services keyed by the compose network name, external networks are not
+// managed by jumppad and have an empty resource name
+func (p *Project) networkResources() (map[string]string, error) {
+	networks := map[string]string{}
+
+	for _, sn := range sortedKeys(p.Services) {
+		s := p.Services[sn]
+
+		names := sortedKeys(s.Networks)
+		if len(names) == 0 && s.NetworkMode == "" {
+			names = []string{defaultNetwork}
+		}
+
+		for _, name := range names {
+			n, ok := p.Networks[name]
+			if !ok && name != defaultNetwork {
+				return nil, fmt.Errorf("service %s refers to undefined network %s", sn, name)
+			}
+
+			if _, ok := networks[name]; ok {
+				continue
+			}
+
+			switch {
+			case n != nil && n.External:
+				p.warn("network %s is external, external networks are not supported and services will not be attached", name)
+				networks[name] = ""
+			case n != nil && n.Name != "":
+				networks[name] = resourceName(n.Name)
+			default:
+				networks[name] = resourceName(fmt.Sprintf("%s_%s", p.Name, name))
+			}
+		}
+	}
+
+	return networks, nil
+}
+
+// subnet returns a subnet for a network that does not specify one, the subnet
+// is derived from the project and network name so that it is stable
+func (p *Project) subnet(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(p.Name + "/" + name))
+	v := h.Sum32()
+
+	return fmt.Sprintf("10.%d.%d.0/24", 128+v%64, (v>>8)%256)
+}
+
+func (p *Project) writeService(body *hclwrite.Body, name string, s *Service, networks map[string]string, dir string) error {
+	rn := resourceName(name)
+
+	if s.Image == "" && s.Build == nil {
+		return fmt.Errorf("an image or build must be specified")
+	}
+
+	if s.Build != nil {
+		ctx := s.Build.Context
+		if ctx == "" {
+			ctx = "."
+		}
+
+		body.AppendNewline()
+		bb := body.AppendNewBlock("resource", []string{"build", rn}).Body()
+		cb := bb.AppendNewBlock("container", nil).Body()
+		cb.SetAttributeValue("context", cty.StringVal(p.hostPath(ctx, dir)))
+
+		if s.Build.Dockerfile != "" {
+			cb.SetAttributeValue("dockerfile", cty.StringVal(s.Build.Dockerfile))
+		}
+
+		setStringMap(cb, "args", s.Build.Args)
+	}
+
+	body.AppendNewline()
+	cb := body.AppendNewBlock("resource", []string{"container", rn}).Body()
+
+	deps := []string{}
+	for _, d := range sortedKeys(s.DependsOn) {
+		if _, ok := p.Services[d]; !ok {
+			return fmt.Errorf("depends on undefined service %s", d)
+		}
+
+		if c := s.DependsOn[d]; c == "service_completed_successfully" {
+			p.warn("service %s: depends_on condition %s is not supported, the dependency waits for %s to be created", name, c, d)
+		}
+
+		deps = append(deps, fmt.Sprintf("resource.container.%s", resourceName(d)))
+	}
+
+	setStrings(cb, "depends_on", deps)
+
+	ib := cb.AppendNewBlock("image", nil).Body()
+	if s.Build != nil {
+		ib.SetAttributeTraversal("name", traversal("resource", "build", rn, "image"))
+	} else {
+		ib.SetAttributeValue("name", cty.StringVal(s.Image))
+	}
+
+	setStrings(cb, "entrypoint", s.Entrypoint)
+	setStrings(cb, "command", s.Command)
+	setStringMap(cb, "environment", values(s.Environment.toMap("=")))
+	setStringMap(cb, "labels", values(s.Labels.toMap("=")))
+	setStrings(cb, "dns", s.DNS)
+
+	if s.Privileged {
+		cb.SetAttributeValue("privileged", cty.True)
+	}
+
+	if s.Platform != "" {
+		cb.SetAttributeValue("platform", cty.StringVal(s.Platform))
+	}
+
+	switch {
+	case s.Restart == "always" || s.Restart == "unless-stopped":
+		cb.SetAttributeValue("max_restart_count", cty.NumberIntVal(-1))
+	case strings.HasPrefix(s.Restart, "on-failure:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(s.Restart, "on-failure:"))
+		if err != nil {
+			return fmt.Errorf("invalid restart policy %s", s.Restart)
+		}
+
+		cb.SetAttributeValue("max_restart_count", cty.NumberIntVal(int64(n)))
+	case s.Restart == "on-failure":
+		p.warn("service %s: restart policy on-failure requires a maximum retry count e.g. on-failure:3, the policy has been ignored", name)
+	}
+
+	setStringMap(cb, "sysctls", values(s.Sysctls.toMap("=")))
+	setStringMap(cb, "extra_hosts", values(s.ExtraHosts.toMap(":=")))
+
+	if s.ShmSize > 0 {
+		cb.SetAttributeValue("shm_size", cty.NumberIntVal(int64(s.ShmSize.megabytes())))
+	}
+
+	if s.StopSignal != "" {
+		cb.SetAttributeValue("stop_signal", cty.StringVal(s.StopSignal))
+	}
+
+	if s.StopGracePeriod != "" {
+		d, err := time.ParseDuration(s.StopGracePeriod)
+		if err != nil {
+			return fmt.Errorf("invalid stop_grace_period %s: %w", s.StopGracePeriod, err)
+		}
+
+		cb.SetAttributeValue("stop_timeout", cty.NumberIntVal(int64(d.Seconds())))
+	}
+
+	if s.NetworkMode != "" {
+		p.warn("service %s: network_mode is not supported and has been ignored", name)
+	}
+
+	names := sortedKeys(s.Networks)
+	if len(names) == 0 && s.NetworkMode == "" {
+		names = []string{defaultNetwork}
+	}
+
+	for _, n := range names {
+		if networks[n] == "" {
+			continue
+		}
+
+		// services can be reached using the service name on all networks
+		aliases := []string{name}
+		nb := cb.AppendNewBlock("network", nil).Body()
+		nb.SetAttributeTraversal("id", traversal("resource", "network", networks[n], "meta", "id"))
+
+		if o := s.Networks[n]; o != nil {
+			if o.IPv4Address != "" {
+				nb.SetAttributeValue("ip_address", cty.StringVal(o.IPv4Address))
+			}
+
+			aliases = append(aliases, o.Aliases...)
+		}
+
+		setStrings(nb, "aliases", aliases)
+	}
+
+	err := p.writeVolumes(cb, name, s, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range s.Ports {
+		if port.HostIP != "" {
+			p.warn("service %s: host_ip %s for port %s is not supported, the port is published on all interfaces", name, port.HostIP, port.Target)
+		}
+
+		if strings.Contains(port.Target, "-") {
+			if port.Published != "" && port.Published != port.Target {
+				p.warn("service %s: port range %s is published to %s, port ranges are published using the same ports on the host", name, port.Target, port.Published)
+			}
+
+			pb := cb.AppendNewBlock("port_range", nil).Body()
+			pb.SetAttributeValue("range", cty.StringVal(port.Target))
+			if port.Published != "" {
+				pb.SetAttributeValue("enable_host", cty.True)
+			}
+
+			setString(pb, "protocol", port.Protocol)
+			continue
+		}
+
+		pb := cb.AppendNewBlock("port", nil).Body()
+		pb.SetAttributeValue("local", cty.StringVal(port.Target))
+		setString(pb, "host", port.Published)
+		setString(pb, "protocol", port.Protocol)
+	}
+
+	if len(s.CapAdd) > 0 || len(s.CapDrop) > 0 {
+		capb := cb.AppendNewBlock("capabilities", nil).Body()
+		setStrings(capb, "add", s.CapAdd)
+		setStrings(capb, "drop", s.CapDrop)
+	}
+
+	for _, u := range sortedKeys(s.Ulimits) {
+		ub := cb.AppendNewBlock("ulimit", nil).Body()
+		ub.SetAttributeValue("name", cty.StringVal(u))
+		ub.SetAttributeValue("soft", cty.NumberIntVal(int64(s.Ulimits[u].Soft)))
+		ub.SetAttributeValue("hard", cty.NumberIntVal(int64(s.Ulimits[u].Hard)))
+	}
+
+	err = writeResources(cb, s)
+	if err != nil {
+		return err
+	}
+
+	if s.User != "" {
+		// when only the user is set the group with the same name or id is used
+		user, group, ok := strings.Cut(s.User, ":")
+		if !ok {
+			group = user
+		}
+
+		rb := cb.AppendNewBlock("run_as", nil).Body()
+		rb.SetAttributeValue("user", cty.StringVal(user))
+		rb.SetAttributeValue("group", cty.StringVal(group))
+	}
+
+	return writeHealthCheck(cb, s.HealthCheck)
+}
+
+func (p *Project) writeVolumes(cb *hclwrite.Body, name string, s *Service, dir string) error {
+	for _, v := range s.Volumes {
+		vb := hclwrite.NewBlock("volume", nil)
+
+		switch v.Type {
+		case "bind":
+			vb.Body().SetAttributeValue("source", cty.StringVal(p.hostPath(v.Source, dir)))
+			vb.Body().SetAttributeValue("destination", cty.StringVal(v.Target))
+
+			if v.Bind != nil {
+				setString(vb.Body(), "bind_propagation", v.Bind.Propagation)
+
+				switch v.Bind.SELinux {
+				case "z":
+					vb.Body().SetAttributeValue("selinux_relabel", cty.StringVal("shared"))
+				case "Z":
+					vb.Body().SetAttributeValue("selinux_relabel", cty.StringVal("private"))
+				}
+			}
+
+		case "volume":
+			// volumes without a source are anonymous volumes created by the engine
+			source := v.Source
+			if source != "" {
+				vol, ok := p.Volumes[source]
+				if !ok {
+					return fmt.Errorf("refers to undefined volume %s", source)
+				}
+
+				switch {
+				case vol != nil && vol.Name != "":
+					source = vol.Name
+				case vol == nil || !vol.External:
+					source = fmt.Sprintf("%s_%s", p.Name, source)
+				}
+			}
+
+			vb.Body().SetAttributeValue("source", cty.StringVal(source))
+			vb.Body().SetAttributeValue("destination", cty.StringVal(v.Target))
+			vb.Body().SetAttributeValue("type", cty.StringVal("volume"))
+
+		case "tmpfs":
+			writeTmpfs(vb.Body(), v.Target, v.Tmpfs)
+
+		default:
+			p.warn("service %s: volume type %s is not supported and has been ignored", name, v.Type)
+			continue
+		}
+
+		if v.ReadOnly {
+			vb.Body().SetAttributeValue("read_only", cty.True)
+		}
+
+		cb.AppendBlock(vb)
+	}
+
+	for _, t := range s.Tmpfs {
+		target, opts, _ := strings.Cut(t, ":")
+
+		o := &TmpfsOptions{}
+		for _, opt := range strings.Split(opts, ",") {
+			k, v, _ := strings.Cut(opt, "=")
+			switch k {
+			case "size":
+				err := o.Size.UnmarshalYAML(&yaml.Node{Kind: yaml.ScalarNode, Value: v})
+				if err != nil {
+					return fmt.Errorf("invalid tmpfs size %s: %w", v, err)
+				}
+			case "mode":
+				o.Mode = scalar(v)
+			}
+		}
+
+		writeTmpfs(cb.AppendNewBlock("volume", nil).Body(), target, o)
+	}
+
+	return nil
+}
+
+func writeTmpfs(b *hclwrite.Body, target string, o *TmpfsOptions) {
+	b.SetAttributeValue("source", cty.StringVal(""))
+	b.SetAttributeValue("destination", cty.StringVal(target))
+	b.SetAttributeValue("type", cty.StringVal("tmpfs"))
+
+	if o == nil {
+		return
+	}
+
+	if o.Size > 0 {
+		b.SetAttributeValue("tmpfs_size", cty.NumberIntVal(int64(o.Size.megabytes())))
+	}
+
+	setString(b, "tmpfs_mode", string(o.Mode))
+}
+
+func writeResources(cb *hclwrite.Body, s *Service) error {
+	cpus := s.CPUs
+	memory := s.MemLimit
+
+	if s.Deploy != nil {
+		if l := s.Deploy.Resources.Limits; l.CPUs != "" || l.Memory > 0 {
+			cpus = l.CPUs
+			memory = l.Memory
+		}
+	}
+
+	if cpus == "" && memory == 0 {
+		return nil
+	}
+
+	rb := cb.AppendNewBlock("resources", nil).Body()
+
+	if cpus != "" {
+		c, err := strconv.ParseFloat(string(cpus), 64)
+		if err != nil {
+			return fmt.Errorf("invalid cpus %s: %w", cpus, err)
+		}
+
+		rb.SetAttributeValue("cpu", cty.NumberIntVal(int64(c*1000)))
+	}
+
+	if memory > 0 {
+		rb.SetAttributeValue("memory", cty.NumberIntVal(int64(memory.megabytes())))
+	}
+
+	return nil
+}
+
+// writeHealthCheck converts the compose health check to an exec health check,
+// the timeout allows for the start period and all the retries to complete
+func writeHealthCheck(cb *hclwrite.Body, hc *HealthCheck) error {
+	if hc == nil || hc.Disable || len(hc.Test) == 0 || hc.Test[0] == "NONE" {
+		return nil
+	}
+
+	interval, err := parseDuration(hc.Interval, 30*time.Second)
+	if err != nil {
+		return fmt.Errorf("invalid healthcheck interval %s: %w", hc.Interval, err)
+	}
+
+	start, err := parseDuration(hc.StartPeriod, 0)
+	if err != nil {
+		return fmt.Errorf("invalid healthcheck start_period %s: %w", hc.StartPeriod, err)
+	}
+
+	retries := hc.Retries
+	if retries == 0 {
+		retries = 3
+	}
+
+	hb := cb.AppendNewBlock("health_check", nil).Body()
+	hb.SetAttributeValue("timeout", cty.StringVal((start + interval*time.Duration(retries)).String()))
+
+	eb := hb.AppendNewBlock("exec", nil).Body()
+
+	switch hc.Test[0] {
+	case "CMD":
+		setStrings(eb, "command", hc.Test[1:])
+	case "CMD-SHELL":
+		eb.SetAttributeValue("script", cty.StringVal(strings.Join(hc.Test[1:], " ")))
+	default:
+		eb.SetAttributeValue("script", cty.StringVal(strings.Join(hc.Test, " ")))
+	}
+
+	return nil
+}
+
+// hostPath returns the path for a file on the host, the path is relative to
+// dir when set
+func (p *Project) hostPath(path, dir string) string {
+	path = p.path(path)
+	if dir == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "..") {
+		rel = "./" + rel
+	}
+
+	return rel
+}
+
+func parseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+func traversal(names ...string) hcl.Traversal {
+	t := hcl.Traversal{hcl.TraverseRoot{Name: names[0]}}
+	for _, n := range names[1:] {
+		t = append(t, hcl.TraverseAttr{Name: n})
+	}
+
+	return t
+}
+
+func setString(b *hclwrite.Body, name, value string) {
+	if value != "" {
+		b.SetAttributeValue(name, cty.StringVal(value))
+	}
+}
+
+func setStrings(b *hclwrite.Body, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	vals := []cty.Value{}
+	for _, v := range values {
+		vals = append(vals, cty.StringVal(v))
+	}
+
+	b.SetAttributeValue(name, cty.ListVal(vals))
+}
+
+func setStringMap(b *hclwrite.Body, name string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	vals := map[string]cty.Value{}
+	for k, v := range values {
+		vals[k] = cty.StringVal(v)
+	}
+
+	b.SetAttributeValue(name, cty.MapVal(vals))
+}
+
+// values returns the map with nil values replaced by empty strings
+func values(m map[string]*string) map[string]string {
+	out := map[string]string{}
+	for k, v := range m {
+		out[k] = ""
+		if v != nil {
+			out[k] = *v
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,157 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testCompose = `
+name: shop
+services:
+  web:
+    build:
+      context: ./web
+      dockerfile: Dockerfile.dev
+    ports:
+      - "8080:80"
+    environment:
+      DB_HOST: db
+    volumes:
+      - ./html:/usr/share/nginx/html:ro
+      - cache:/cache
+    depends_on:
+      db:
+        condition: service_healthy
+    restart: on-failure:3
+  db:
+    image: postgres:16
+    user: postgres
+    networks:
+      backend:
+        aliases:
+          - database
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+      interval: 5s
+      retries: 5
+      start_period: 10s
+networks:
+  backend:
+    ipam:
+      config:
+        - subnet: 10.99.0.0/24
+volumes:
+  cache:
+`
+
+func TestConvertCreatesNetworks(t *testing.T) {
+	file := writeComposeFile(t, testCompose)
+
+	d, _, err := Convert(file, filepath.Dir(file))
+	require.NoError(t, err)
+
+	require.Contains(t, string(d), `resource "network" "shop_backend" {`)
+	require.Contains(t, string(d), `subnet = "10.99.0.0/24"`)
+
+	// services without networks are attached to the default network
+	require.Contains(t, string(d), `resource "network" "shop_default" {`)
+	require.Contains(t, string(d), `id      = resource.network.shop_default.meta.id`)
+}
+
+func TestConvertCreatesContainers(t *testing.T) {
+	file := writeComposeFile(t, testCompose)
+
+	d, _, err := Convert(file, filepath.Dir(file))
+	require.NoError(t, err)
+
+	hcl := string(d)
+	require.Contains(t, hcl, `resource "build" "web" {`)
+	require.Contains(t, hcl, `context    = "./web"`)
+	require.Contains(t, hcl, `name = resource.build.web.image`)
+	require.Contains(t, hcl, `depends_on = ["resource.container.db"]`)
+	require.Contains(t, hcl, `DB_HOST = "db"`)
+	require.Contains(t, hcl, `max_restart_count = 3`)
+	require.Contains(t, hcl, `source      = "./html"`)
+	require.Contains(t, hcl, `read_only   = true`)
+	require.Contains(t, hcl, `source      = "shop_cache"`)
+	require.Contains(t, hcl, `host  = "8080"`)
+
+	require.Contains(t, hcl, `resource "container" "db" {`)
+	require.Contains(t, hcl, `aliases = ["db", "database"]`)
+	require.Contains(t, hcl, `group = "postgres"`)
+}
+
+func TestConvertCreatesHealthCheck(t *testing.T) {
+	file := writeComposeFile(t, testCompose)
+
+	d, _, err := Convert(file, filepath.Dir(file))
+	require.NoError(t, err)
+
+	require.Contains(t, string(d), `timeout = "35s"`)
+	require.Contains(t, string(d), `command = ["pg_isready"]`)
+}
+
+func TestConvertWithoutDirUsesAbsolutePaths(t *testing.T) {
+	file := writeComposeFile(t, testCompose)
+
+	d, _, err := Convert(file, "")
+	require.NoError(t, err)
+
+	require.Contains(t, string(d), filepath.Join(filepath.Dir(file), "html"))
+}
+
+func TestConvertReturnsErrorForUndefinedVolume(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    volumes:
+      - data:/data
+`)
+
+	_, _, err := Convert(file, "")
+	require.ErrorContains(t, err, "undefined volume data")
+}
+
+func TestConvertWarnsForExternalNetworks(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    networks:
+      - proxy
+networks:
+  proxy:
+    external: true
+`)
+
+	d, warnings, err := Convert(file, "")
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.NotContains(t, string(d), `resource "network"`)
+}
+
+func TestGenerateWritesConfigurationToCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	file := writeComposeFile(t, testCompose)
+
+	dir, _, err := Generate(file)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, "main.hcl"))
+
+	// the folder only changes when the compose file changes
+	same, _, err := Generate(file)
+	require.NoError(t, err)
+	require.Equal(t, dir, same)
+
+	err = os.WriteFile(file, []byte(strings.Replace(testCompose, "postgres:16", "postgres:17", 1)), 0644)
+	require.NoError(t, err)
+
+	changed, _, err := Generate(file)
+	require.NoError(t, err)
+	require.NotEqual(t, dir, changed)
+}
@@ -0,0 +1,688 @@
+package compose
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/google/shlex"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a parsed Docker Compose file, only the keys that can be
+// represented by jumppad resources are read, other keys are reported as
+// warnings
+type Project struct {
+	Name     string              `yaml:"name"`
+	Services map[string]*Service `yaml:"services"`
+	Networks map[string]*Network `yaml:"networks"`
+	Volumes  map[string]*Volume  `yaml:"volumes"`
+
+	// Extensions contains the keys that are not supported
+	Extensions map[string]any `yaml:",inline"`
+
+	// file is the absolute path of the compose file
+	file string
+	// warnings contains the keys that were ignored
+	warnings []string
+}
+
+// Service defines a container in the compose file
+type Service struct {
+	Image           string             `yaml:"image"`
+	Build           *Build             `yaml:"build"`
+	Command         shellCommand       `yaml:"command"`
+	Entrypoint      shellCommand       `yaml:"entrypoint"`
+	Environment     keyValues          `yaml:"environment"`
+	EnvFile         stringOrList       `yaml:"env_file"`
+	Labels          keyValues          `yaml:"labels"`
+	Ports           []Port             `yaml:"ports"`
+	Volumes         []ServiceVolume    `yaml:"volumes"`
+	Tmpfs           stringOrList       `yaml:"tmpfs"`
+	Networks        serviceNetworks    `yaml:"networks"`
+	NetworkMode     string             `yaml:"network_mode"`
+	DependsOn       dependsOn          `yaml:"depends_on"`
+	HealthCheck     *HealthCheck       `yaml:"healthcheck"`
+	DNS             stringOrList       `yaml:"dns"`
+	Privileged      bool               `yaml:"privileged"`
+	CapAdd          []string           `yaml:"cap_add"`
+	CapDrop         []string           `yaml:"cap_drop"`
+	Ulimits         map[string]*Ulimit `yaml:"ulimits"`
+	Sysctls         keyValues          `yaml:"sysctls"`
+	ExtraHosts      keyValues          `yaml:"extra_hosts"`
+	ShmSize         byteSize           `yaml:"shm_size"`
+	StopSignal      string             `yaml:"stop_signal"`
+	StopGracePeriod string             `yaml:"stop_grace_period"`
+	User            string             `yaml:"user"`
+	Restart         string             `yaml:"restart"`
+	Platform        string             `yaml:"platform"`
+	CPUs            scalar             `yaml:"cpus"`
+	MemLimit        byteSize           `yaml:"mem_limit"`
+	Deploy          *Deploy            `yaml:"deploy"`
+
+	// Extensions contains the keys that are not supported
+	Extensions map[string]any `yaml:",inline"`
+}
+
+// Build defines how the image for a service is built
+type Build struct {
+	Context    string            `yaml:"context"`
+	Dockerfile string            `yaml:"dockerfile"`
+	Args       map[string]string `yaml:"args"`
+}
+
+// UnmarshalYAML allows the build to be specified as the context folder
+func (b *Build) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		b.Context = value.Value
+		return nil
+	}
+
+	type raw Build
+	return value.Decode((*raw)(b))
+}
+
+// Port is a port published by a service
+type Port struct {
+	Target    string `yaml:"target"`
+	Published string `yaml:"published"`
+	Protocol  string `yaml:"protocol"`
+	HostIP    string `yaml:"host_ip"`
+}
+
+// UnmarshalYAML parses the short syntax [host_ip:][published:]target[/protocol]
+// or the long syntax for a port
+func (p *Port) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		type raw Port
+		return value.Decode((*raw)(p))
+	}
+
+	s := value.Value
+	if i := strings.LastIndex(s, "/"); i > 0 {
+		p.Protocol = s[i+1:]
+		s = s[:i]
+	}
+
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		p.Target = s
+		return nil
+	}
+
+	p.Target = s[i+1:]
+	s = s[:i]
+
+	i = strings.LastIndex(s, ":")
+	if i < 0 {
+		p.Published = s
+		return nil
+	}
+
+	p.Published = s[i+1:]
+	p.HostIP = strings.Trim(s[:i], "[]")
+
+	return nil
+}
+
+// ServiceVolume is a volume, bind mount or tmpfs mounted by a service
+type ServiceVolume struct {
+	Type     string        `yaml:"type"`
+	Source   string        `yaml:"source"`
+	Target   string        `yaml:"target"`
+	ReadOnly bool          `yaml:"read_only"`
+	Bind     *BindOptions  `yaml:"bind"`
+	Tmpfs    *TmpfsOptions `yaml:"tmpfs"`
+}
+
+// BindOptions are the options for a bind mount
+type BindOptions struct {
+	Propagation string `yaml:"propagation"`
+	SELinux     string `yaml:"selinux"`
+}
+
+// TmpfsOptions are the options for a tmpfs mount
+type TmpfsOptions struct {
+	Size byteSize `yaml:"size"`
+	Mode scalar   `yaml:"mode"`
+}
+
+// UnmarshalYAML parses the short syntax [source:]target[:mode] or the long
+// syntax for a volume
+func (v *ServiceVolume) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		type raw ServiceVolume
+		return value.Decode((*raw)(v))
+	}
+
+	parts := strings.Split(value.Value, ":")
+	switch len(parts) {
+	case 1:
+		v.Type = "volume"
+		v.Target = parts[0]
+		return nil
+	case 2, 3:
+		v.Source = parts[0]
+		v.Target = parts[1]
+	default:
+		return fmt.Errorf("invalid volume %s", value.Value)
+	}
+
+	v.Type = "volume"
+	if isPath(v.Source) {
+		v.Type = "bind"
+	}
+
+	if len(parts) == 3 {
+		for _, o := range strings.Split(parts[2], ",") {
+			switch o {
+			case "ro":
+				v.ReadOnly = true
+			case "z", "Z":
+				v.Bind = &BindOptions{SELinux: o}
+			}
+		}
+	}
+
+	return nil
+}
+
+// HealthCheck is the health check for a service
+type HealthCheck struct {
+	Test        stringOrList `yaml:"test"`
+	Interval    string       `yaml:"interval"`
+	Timeout     string       `yaml:"timeout"`
+	Retries     int          `yaml:"retries"`
+	StartPeriod string       `yaml:"start_period"`
+	Disable     bool         `yaml:"disable"`
+}
+
+// Ulimit is a ulimit for a service, a single value sets the soft and hard limit
+type Ulimit struct {
+	Soft int `yaml:"soft"`
+	Hard int `yaml:"hard"`
+}
+
+// UnmarshalYAML allows a single value to be used for the soft and hard limit
+func (u *Ulimit) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		err := value.Decode(&u.Soft)
+		u.Hard = u.Soft
+		return err
+	}
+
+	type raw Ulimit
+	return value.Decode((*raw)(u))
+}
+
+// Deploy contains the resource limits for a service
+type Deploy struct {
+	Resources struct {
+		Limits struct {
+			CPUs   scalar   `yaml:"cpus"`
+			Memory byteSize `yaml:"memory"`
+		} `yaml:"limits"`
+	} `yaml:"resources"`
+}
+
+// Network is a network defined in the compose file or the options for a
+// network attached to a service
+type Network struct {
+	Name       string `yaml:"name"`
+	External   bool   `yaml:"external"`
+	EnableIPv6 bool   `yaml:"enable_ipv6"`
+	IPAM       struct {
+		Config []struct {
+			Subnet string `yaml:"subnet"`
+		} `yaml:"config"`
+	} `yaml:"ipam"`
+
+	// options when attaching a service to the network
+	Aliases     []string `yaml:"aliases"`
+	IPv4Address string   `yaml:"ipv4_address"`
+}
+
+// Volume is a named volume defined in the compose file
+type Volume struct {
+	Name     string `yaml:"name"`
+	External bool   `yaml:"external"`
+}
+
+// scalar is a value that can be specified as a string or a number
+type scalar string
+
+func (s *scalar) UnmarshalYAML(value *yaml.Node) error {
+	*s = scalar(value.Value)
+	return nil
+}
+
+// byteSize is a size in bytes specified as a number or a string with a unit
+// e.g. 64m
+type byteSize int64
+
+func (b *byteSize) UnmarshalYAML(value *yaml.Node) error {
+	v, err := units.RAMInBytes(value.Value)
+	if err != nil {
+		return fmt.Errorf("invalid size %s: %w", value.Value, err)
+	}
+
+	*b = byteSize(v)
+	return nil
+}
+
+// megabytes returns the size rounded down to the nearest megabyte
+func (b byteSize) megabytes() int {
+	return int(b / (1024 * 1024))
+}
+
+// stringOrList is a value that can be a single string or a list of strings
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*s = []string{value.Value}
+		return nil
+	}
+
+	return value.Decode((*[]string)(s))
+}
+
+// shellCommand is a command that can be a list or a string that is split
+// into arguments using shell quoting rules
+type shellCommand []string
+
+func (s *shellCommand) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		args, err := shlex.Split(value.Value)
+		*s = args
+		return err
+	}
+
+	return value.Decode((*[]string)(s))
+}
+
+// keyValues is a mapping or a list of key value pairs, list items that do
+// not contain a value are stored with a nil value
+type keyValues struct {
+	values map[string]*string
+	list   []string
+}
+
+func (k *keyValues) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&k.list)
+	}
+
+	k.values = map[string]*string{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i].Value
+		v := value.Content[i+1]
+		if v.Tag == "!!null" {
+			k.values[key] = nil
+			continue
+		}
+
+		s := v.Value
+		k.values[key] = &s
+	}
+
+	return nil
+}
+
+// toMap returns the key value pairs, list items are split on the first sep
+func (k keyValues) toMap(seps string) map[string]*string {
+	m := map[string]*string{}
+	for key, v := range k.values {
+		m[key] = v
+	}
+
+	for _, item := range k.list {
+		i := strings.IndexAny(item, seps)
+		if i < 0 {
+			m[item] = nil
+			continue
+		}
+
+		v := item[i+1:]
+		m[item[:i]] = &v
+	}
+
+	return m
+}
+
+// serviceNetworks is a list of network names or a map of network names to
+// the options for the attachment
+type serviceNetworks map[string]*Network
+
+func (s *serviceNetworks) UnmarshalYAML(value *yaml.Node) error {
+	*s = serviceNetworks{}
+
+	if value.Kind == yaml.SequenceNode {
+		names := []string{}
+		err := value.Decode(&names)
+		for _, n := range names {
+			(*s)[n] = nil
+		}
+
+		return err
+	}
+
+	return value.Decode((*map[string]*Network)(s))
+}
+
+// dependsOn is a list of services or a map of services to the condition
+type dependsOn map[string]string
+
+func (d *dependsOn) UnmarshalYAML(value *yaml.Node) error {
+	*d = dependsOn{}
+
+	if value.Kind == yaml.SequenceNode {
+		names := []string{}
+		err := value.Decode(&names)
+		for _, n := range names {
+			(*d)[n] = "service_started"
+		}
+
+		return err
+	}
+
+	conditions := map[string]struct {
+		Condition string `yaml:"condition"`
+	}{}
+
+	err := value.Decode(&conditions)
+	for k, v := range conditions {
+		(*d)[k] = v.Condition
+	}
+
+	return err
+}
+
+// Load reads the compose file, variables in the file are interpolated from
+// the environment and the .env file in the same folder as the compose file
+func Load(file string) (*Project, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read compose file %s: %w", file, err)
+	}
+
+	dotEnv, err := readEnvFile(filepath.Join(filepath.Dir(file), ".env"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	lookup := func(name string) (string, bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
+		}
+
+		v, ok := dotEnv[name]
+		return v, ok
+	}
+
+	root := &yaml.Node{}
+	err = yaml.Unmarshal(d, root)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse compose file %s: %w", file, err)
+	}
+
+	err = interpolateNode(root, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse compose file %s: %w", file, err)
+	}
+
+	p := &Project{file: file}
+	err = root.Decode(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse compose file %s: %w", file, err)
+	}
+
+	if len(p.Services) == 0 {
+		return nil, fmt.Errorf("compose file %s does not define any services", file)
+	}
+
+	if p.Name == "" {
+		p.Name = filepath.Base(filepath.Dir(file))
+	}
+
+	p.Name = strings.ToLower(p.Name)
+
+	for _, k := range sortedKeys(p.Extensions) {
+		// version is obsolete and ignored by compose
+		if k != "version" && !strings.HasPrefix(k, "x-") {
+			p.warn("%s is not supported and has been ignored", k)
+		}
+	}
+
+	for _, name := range sortedKeys(p.Services) {
+		s := p.Services[name]
+		if s == nil {
+			return nil, fmt.Errorf("service %s does not define any configuration", name)
+		}
+
+		for _, k := range sortedKeys(s.Extensions) {
+			if !strings.HasPrefix(k, "x-") {
+				p.warn("service %s: %s is not supported and has been ignored", name, k)
+			}
+		}
+
+		// values from env files are overridden by the environment key
+		env := map[string]*string{}
+		for _, f := range s.EnvFile {
+			vars, err := readEnvFile(p.path(f))
+			if err != nil {
+				return nil, fmt.Errorf("unable to read env_file for service %s: %w", name, err)
+			}
+
+			for k, v := range vars {
+				env[k] = &v
+			}
+		}
+
+		for k, v := range s.Environment.toMap("=") {
+			// variables without a value are read from the environment
+			if v == nil {
+				if lv, ok := lookup(k); ok {
+					v = &lv
+				} else {
+					continue
+				}
+			}
+
+			env[k] = v
+		}
+
+		s.Environment = keyValues{values: env}
+	}
+
+	return p, nil
+}
+
+// path returns the absolute path for a path in the compose file, relative
+// paths are resolved from the folder containing the compose file
+func (p *Project) path(path string) string {
+	if strings.HasPrefix(path, "~") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[1:])
+	}
+
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(filepath.Dir(p.file), path)
+}
+
+func (p *Project) warn(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// isPath returns true when the source of a volume is a path on the host
+// rather than a named volume
+func isPath(s string) bool {
+	return strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") || strings.HasPrefix(s, "~")
+}
+
+// readEnvFile reads a file containing KEY=VALUE lines, blank lines and lines
+// starting with # are ignored
+func readEnvFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		k, v, _ := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		v = strings.TrimSpace(v)
+		if len(v) > 1 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+
+		vars[strings.TrimSpace(k)] = v
+	}
+
+	return vars, s.Err()
+}
+
+var variableRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// interpolateNode replaces the variables in all the values of the node
+func interpolateNode(n *yaml.Node, lookup func(string) (string, bool)) error {
+	if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "$") {
+		v, err := interpolate(n.Value, lookup)
+		if err != nil {
+			return err
+		}
+
+		n.Value = v
+		return nil
+	}
+
+	for i, c := range n.Content {
+		// do not interpolate the keys of a mapping
+		if n.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+
+		err := interpolateNode(c, lookup)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// interpolate replaces $VAR and ${VAR} in the string with the value of the
+// variable, the compose default and error modifiers :-, -, :? and ? are
+// supported, $$ is replaced with a single $
+func interpolate(s string, lookup func(string) (string, bool)) (string, error) {
+	out := strings.Builder{}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		next := s[i+1]
+		switch {
+		case next == '$':
+			out.WriteByte('$')
+			i++
+
+		case next == '{':
+			end := strings.Index(s[i:], "}")
+			if end < 0 {
+				return "", fmt.Errorf("invalid variable in %s, missing closing brace", s)
+			}
+
+			expr := s[i+2 : i+end]
+			i += end
+
+			v, err := expandVariable(expr, lookup)
+			if err != nil {
+				return "", err
+			}
+
+			out.WriteString(v)
+
+		default:
+			name := variableRegex.FindString(s[i+1:])
+			if name == "" {
+				out.WriteByte('$')
+				continue
+			}
+
+			v, _ := lookup(name)
+			out.WriteString(v)
+			i += len(name)
+		}
+	}
+
+	return out.String(), nil
+}
+
+func expandVariable(expr string, lookup func(string) (string, bool)) (string, error) {
+	name := variableRegex.FindString(expr)
+	if name == "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+
+	v, ok := lookup(name)
+	op := expr[len(name):]
+
+	switch {
+	case op == "":
+		return v, nil
+	case strings.HasPrefix(op, ":-"):
+		if v == "" {
+			return op[2:], nil
+		}
+	case strings.HasPrefix(op, "-"):
+		if !ok {
+			return op[1:], nil
+		}
+	case strings.HasPrefix(op, ":?"):
+		if v == "" {
+			return "", fmt.Errorf("required variable %s is not set: %s", name, op[2:])
+		}
+	case strings.HasPrefix(op, "?"):
+		if !ok {
+			return "", fmt.Errorf("required variable %s is not set: %s", name, op[1:])
+		}
+	default:
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+
+	return v, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,129 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeComposeFile(t *testing.T, contents string) string {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "docker-compose.yml")
+
+	err := os.WriteFile(file, []byte(contents), 0644)
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestLoadInterpolatesVariables(t *testing.T) {
+	t.Setenv("COMPOSE_TEST_TAG", "1.2.3")
+
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx:${COMPOSE_TEST_TAG}
+    environment:
+      PORT: ${COMPOSE_TEST_PORT:-8080}
+      FROM_ENV_FILE: ${ENV_FILE_VALUE}
+      ESCAPED: $$HOME
+`)
+
+	err := os.WriteFile(filepath.Join(filepath.Dir(file), ".env"), []byte("ENV_FILE_VALUE=abc\n"), 0644)
+	require.NoError(t, err)
+
+	p, err := Load(file)
+	require.NoError(t, err)
+
+	s := p.Services["web"]
+	require.Equal(t, "nginx:1.2.3", s.Image)
+
+	env := values(s.Environment.toMap("="))
+	require.Equal(t, "8080", env["PORT"])
+	require.Equal(t, "abc", env["FROM_ENV_FILE"])
+	require.Equal(t, "$HOME", env["ESCAPED"])
+}
+
+func TestLoadReturnsErrorForRequiredVariable(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx:${COMPOSE_TEST_MISSING:?tag must be set}
+`)
+
+	_, err := Load(file)
+	require.ErrorContains(t, err, "tag must be set")
+}
+
+func TestLoadReturnsErrorWhenNoServices(t *testing.T) {
+	file := writeComposeFile(t, `
+networks:
+  backend:
+`)
+
+	_, err := Load(file)
+	require.Error(t, err)
+}
+
+func TestLoadParsesShortSyntax(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    command: nginx -g "daemon off;"
+    ports:
+      - "8080:80"
+      - "127.0.0.1:5353:53/udp"
+      - "9000"
+    volumes:
+      - ./html:/usr/share/nginx/html:ro
+      - data:/data
+      - /cache
+    depends_on:
+      - db
+  db:
+    image: postgres
+volumes:
+  data:
+`)
+
+	p, err := Load(file)
+	require.NoError(t, err)
+
+	s := p.Services["web"]
+	require.Equal(t, shellCommand{"nginx", "-g", "daemon off;"}, s.Command)
+
+	require.Equal(t, Port{Target: "80", Published: "8080"}, s.Ports[0])
+	require.Equal(t, Port{Target: "53", Published: "5353", Protocol: "udp", HostIP: "127.0.0.1"}, s.Ports[1])
+	require.Equal(t, Port{Target: "9000"}, s.Ports[2])
+
+	require.Equal(t, "bind", s.Volumes[0].Type)
+	require.Equal(t, "./html", s.Volumes[0].Source)
+	require.True(t, s.Volumes[0].ReadOnly)
+	require.Equal(t, "volume", s.Volumes[1].Type)
+	require.Equal(t, "data", s.Volumes[1].Source)
+	require.Equal(t, "volume", s.Volumes[2].Type)
+	require.Equal(t, "", s.Volumes[2].Source)
+
+	require.Contains(t, s.DependsOn, "db")
+}
+
+func TestLoadWarnsForUnsupportedKeys(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    configs:
+      - my_config
+    x-custom: true
+configs:
+  my_config:
+    file: ./config
+`)
+
+	p, err := Load(file)
+	require.NoError(t, err)
+	require.Len(t, p.warnings, 2)
+}
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strconv"
 
+	"github.com/jumppad-labs/jumppad/pkg/config/compose"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
 
@@ -58,3 +59,19 @@ func customHCLFuncExists(path string) (bool, error) {
 
 	return true, nil
 }
+
+// customHCLFuncCompose converts the Docker Compose file at path and returns the
+// folder containing the generated configuration, the folder can be used as the
+// source for a module
+func customHCLFuncCompose(path string) (string, error) {
+	if !utils.IsComposeFile(path) {
+		return "", fmt.Errorf("%s is not a compose file", path)
+	}
+
+	dir, _, err := compose.Generate(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to convert compose file %s: %s", path, err)
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jumppad-labs/hclconfig/types"
+)
+
+// Lifecycle defines the optional lifecycle block that can be added to any
+// resource to control how the engine handles changes to the resource
+type Lifecycle struct {
+	// PreventDestroy causes an error to be returned when the resource would be
+	// destroyed, either because it has been removed from the config, disabled,
+	// tainted or by running down
+	PreventDestroy bool `hcl:"prevent_destroy,optional" json:"prevent_destroy,omitempty"`
+	// CreateBeforeDestroy creates the replacement for a failed or tainted resource
+	// before the existing resource is destroyed, only resources that implement
+	// Replaceable can set this
+	CreateBeforeDestroy bool `hcl:"create_before_destroy,optional" json:"create_before_destroy,omitempty"`
+	// IgnoreChanges is a list of attributes, e.g. image.name, that are ignored
+	// when determining if the resource has changed, the value stored in the state
+	// is used in place of the value in the config
+	IgnoreChanges []string `hcl:"ignore_changes,optional" json:"ignore_changes,omitempty"`
+}
+
+// Replaceable is implemented by resources that can set
+// lifecycle.create_before_destroy. The replacement for these resources can
+// exist at the same time as the existing instance, other resources use a fixed
+// name, address or path that would conflict with the existing instance.
+type Replaceable interface {
+	Replaceable()
+}
+
+// ValidateLifecycle returns an error when the lifecycle block of the resource
+// sets an option that is not supported by the resource type
+func ValidateLifecycle(r types.Resource) error {
+	lc := LifecycleFromResource(r)
+	if lc == nil || !lc.CreateBeforeDestroy {
+		return nil
+	}
+
+	if _, ok := r.(Replaceable); ok {
+		return nil
+	}
+
+	return fmt.Errorf(
+		`resource "%s" can not set lifecycle.create_before_destroy, resources of type %s can not exist at the same time as their replacement`,
+		r.Metadata().ID,
+		r.Metadata().Type,
+	)
+}
+
+// Ignores returns true when the attribute at the given path, e.g. ports[0].local,
+// is covered by one of the ignore_changes entries
+func (l *Lifecycle) Ignores(path string) bool {
+	if l == nil {
+		return false
+	}
+
+	for _, i := range l.IgnoreChanges {
+		if path == i || strings.HasPrefix(path, i+".") || strings.HasPrefix(path, i+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LifecycleFromResource returns the lifecycle block for the given resource,
+// nil is returned when the resource does not define a lifecycle block
+func LifecycleFromResource(r types.Resource) *Lifecycle {
+	o := optionsFromResource(r)
+	if o == nil {
+		return nil
+	}
+
+	return o.Lifecycle
+}
@@ -0,0 +1,34 @@
+package config
+
+import "github.com/jumppad-labs/hclconfig/types"
+
+// ResourceOptions is embedded in every jumppad resource in place of
+// types.ResourceBase, it adds the optional lifecycle and retry blocks that
+// control how the engine creates, refreshes and destroys the resource.
+//
+// hcl only allows a single remain field, the hclconfig ResourceBase is
+// embedded here so that resources keep a single remain field.
+type ResourceOptions struct {
+	// embedded type holding name, etc
+	types.ResourceBase `hcl:",remain"`
+
+	Lifecycle *Lifecycle `hcl:"lifecycle,block" json:"lifecycle,omitempty"`
+	Retry     *Retry     `hcl:"retry,block" json:"retry,omitempty"`
+}
+
+// Options returns the options for the resource, the method is promoted to
+// the resources that embed ResourceOptions
+func (o *ResourceOptions) Options() *ResourceOptions {
+	return o
+}
+
+// optionsFromResource returns the options for the given resource, nil is
+// returned when the resource does not embed ResourceOptions
+func optionsFromResource(r types.Resource) *ResourceOptions {
+	o, ok := r.(interface{ Options() *ResourceOptions })
+	if !ok {
+		return nil
+	}
+
+	return o.Options()
+}
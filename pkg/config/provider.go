@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/jumppad-labs/hclconfig/types"
@@ -15,6 +16,14 @@ type Provider interface {
 	sdk.Provider
 }
 
+// Updater is an optional interface implemented by providers that can modify
+// an existing resource in place. When a provider implements Updater the engine
+// calls Update instead of Refresh when Changed reports that the resource has
+// changed.
+type Updater interface {
+	Update(ctx context.Context) error
+}
+
 // ConfigWrapper allows the provider config to be deserialized to a type
 type ConfigWrapper struct {
 	Type  string
@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/state"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// TypeStateBackend is the resource string for a StateBackend resource
+const TypeStateBackend string = "state_backend"
+
+// StateBackend defines where the state for the blueprint is stored, when
+// not specified the state is stored in a local file.
+//
+// Only a single backend block (local, s3, http, consul) can be specified.
+type StateBackend struct {
+	// embedded type holding name, etc
+	config.ResourceOptions `hcl:",remain"`
+
+	Local  *Local  `hcl:"local,block" json:"local,omitempty"`
+	S3     *S3     `hcl:"s3,block" json:"s3,omitempty"`
+	HTTP   *HTTP   `hcl:"http,block" json:"http,omitempty"`
+	Consul *Consul `hcl:"consul,block" json:"consul,omitempty"`
+}
+
+// Local stores the state in a file on the local machine
+type Local struct {
+	Path string `hcl:"path" json:"path"` // path to the state file
+}
+
+// S3 stores the state in an S3 compatible object store
+type S3 struct {
+	Bucket    string `hcl:"bucket" json:"bucket"`                            // bucket to store the state in
+	Key       string `hcl:"key" json:"key"`                                  // key of the state object
+	Region    string `hcl:"region,optional" json:"region,omitempty"`         // region for the bucket
+	Endpoint  string `hcl:"endpoint,optional" json:"endpoint,omitempty"`     // endpoint for S3 compatible stores like MinIO
+	Profile   string `hcl:"profile,optional" json:"profile,omitempty"`       // shared credentials profile
+	AccessKey string `hcl:"access_key,optional" json:"access_key,omitempty"` // access key, when not set the default AWS credential chain is used
+	SecretKey string `hcl:"secret_key,optional" json:"secret_key,omitempty"` // secret key
+}
+
+// HTTP stores the state using a REST endpoint that supports GET, POST and DELETE
+type HTTP struct {
+	Address       string            `hcl:"address" json:"address"`                                  // address of the state endpoint
+	UpdateMethod  string            `hcl:"update_method,optional" json:"update_method,omitempty"`   // method used to write the state, default POST
+	LockAddress   string            `hcl:"lock_address,optional" json:"lock_address,omitempty"`     // address used to lock the state, locking is disabled when not set
+	LockMethod    string            `hcl:"lock_method,optional" json:"lock_method,omitempty"`       // method used to lock the state, default LOCK
+	UnlockAddress string            `hcl:"unlock_address,optional" json:"unlock_address,omitempty"` // address used to unlock the state, default lock_address
+	UnlockMethod  string            `hcl:"unlock_method,optional" json:"unlock_method,omitempty"`   // method used to unlock the state, default UNLOCK
+	Username      string            `hcl:"username,optional" json:"username,omitempty"`             // username for basic auth
+	Password      string            `hcl:"password,optional" json:"password,omitempty"`             // password for basic auth
+	Headers       map[string]string `hcl:"headers,optional" json:"headers,omitempty"`               // additional headers sent with each request
+}
+
+// Consul stores the state at a Consul KV path
+type Consul struct {
+	Address    string `hcl:"address" json:"address"`                          // address of the Consul server e.g. http://localhost:8500
+	Path       string `hcl:"path" json:"path"`                                // KV path to store the state
+	Token      string `hcl:"token,optional" json:"token,omitempty"`           // ACL token
+	Datacenter string `hcl:"datacenter,optional" json:"datacenter,omitempty"` // datacenter for the KV store
+}
+
+func (b *StateBackend) Process() error {
+	count := 0
+	for _, set := range []bool{b.Local != nil, b.S3 != nil, b.HTTP != nil, b.Consul != nil} {
+		if set {
+			count++
+		}
+	}
+
+	if count != 1 {
+		return fmt.Errorf("state_backend must define exactly one of local, s3, http, or consul")
+	}
+
+	if b.Local != nil {
+		b.Local.Path = utils.EnsureAbsolute(b.Local.Path, b.Meta.File)
+	}
+
+	return nil
+}
+
+// ToClientConfig converts the resource to the config used by the state client
+func (b *StateBackend) ToClientConfig() *state.Config {
+	switch {
+	case b.Local != nil:
+		return &state.Config{
+			Type:  state.BackendLocal,
+			Local: &state.LocalConfig{Path: b.Local.Path},
+		}
+	case b.S3 != nil:
+		return &state.Config{
+			Type: state.BackendS3,
+			S3: &state.S3Config{
+				Bucket:    b.S3.Bucket,
+				Key:       b.S3.Key,
+				Region:    b.S3.Region,
+				Endpoint:  b.S3.Endpoint,
+				Profile:   b.S3.Profile,
+				AccessKey: b.S3.AccessKey,
+				SecretKey: b.S3.SecretKey,
+			},
+		}
+	case b.HTTP != nil:
+		return &state.Config{
+			Type: state.BackendHTTP,
+			HTTP: &state.HTTPConfig{
+				Address:       b.HTTP.Address,
+				UpdateMethod:  b.HTTP.UpdateMethod,
+				LockAddress:   b.HTTP.LockAddress,
+				LockMethod:    b.HTTP.LockMethod,
+				UnlockAddress: b.HTTP.UnlockAddress,
+				UnlockMethod:  b.HTTP.UnlockMethod,
+				Username:      b.HTTP.Username,
+				Password:      b.HTTP.Password,
+				Headers:       b.HTTP.Headers,
+			},
+		}
+	case b.Consul != nil:
+		return &state.Config{
+			Type: state.BackendConsul,
+			Consul: &state.ConsulConfig{
+				Address:    b.Consul.Address,
+				Path:       b.Consul.Path,
+				Token:      b.Consul.Token,
+				Datacenter: b.Consul.Datacenter,
+			},
+		}
+	}
+
+	return nil
+}
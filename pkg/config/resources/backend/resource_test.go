@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/state"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	config.RegisterResource(TypeStateBackend, &StateBackend{}, nil)
+}
+
+func TestStateBackendProcessWithNoBackendReturnsError(t *testing.T) {
+	b := &StateBackend{}
+
+	err := b.Process()
+	require.Error(t, err)
+}
+
+func TestStateBackendProcessWithMultipleBackendsReturnsError(t *testing.T) {
+	b := &StateBackend{
+		HTTP:   &HTTP{Address: "http://localhost"},
+		Consul: &Consul{Address: "http://localhost:8500", Path: "state"},
+	}
+
+	err := b.Process()
+	require.Error(t, err)
+}
+
+func TestStateBackendProcessSetsAbsoluteLocalPath(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	b := &StateBackend{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Local:           &Local{Path: "./state.json"},
+	}
+
+	err = b.Process()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(wd, "state.json"), b.Local.Path)
+}
+
+func TestStateBackendToClientConfigConvertsS3(t *testing.T) {
+	b := &StateBackend{
+		S3: &S3{Bucket: "jumppad", Key: "ci/state.json", Region: "eu-west-1", Endpoint: "http://minio:9000"},
+	}
+
+	c := b.ToClientConfig()
+	require.Equal(t, state.BackendS3, c.Type)
+	require.Equal(t, "jumppad", c.S3.Bucket)
+	require.Equal(t, "ci/state.json", c.S3.Key)
+	require.Equal(t, "http://minio:9000", c.S3.Endpoint)
+}
@@ -1,13 +1,15 @@
 package blueprint
 
-import "github.com/jumppad-labs/hclconfig/types"
+import (
+	"github.com/jumppad-labs/jumppad/pkg/config"
+)
 
 // TypeContainer is the resource string for a Container resource
 const TypeBlueprint string = "blueprint"
 
 // Blueprint defines a stack blueprint for defining yard configs
 type Blueprint struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Title        string   `hcl:"title,optional" json:"title,omitempty"`
 	Organization string   `hcl:"organization,optional" json:"organization,omitempty"`
@@ -3,11 +3,13 @@ package build
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
@@ -45,9 +47,9 @@ func (b *Provider) Create(ctx context.Context) error {
 	}
 
 	// calculate the hash
-	hash, err := utils.HashDir(b.config.Container.Context, b.config.Container.Ignore...)
+	hash, err := b.checksum()
 	if err != nil {
-		return fmt.Errorf("unable to hash directory: %w", err)
+		return err
 	}
 
 	tag, _ := utils.ReplaceNonURIChars(hash[3:11])
@@ -57,8 +59,16 @@ func (b *Provider) Create(ctx context.Context) error {
 		"context", b.config.Container.Context,
 		"dockerfile", b.config.Container.DockerFile,
 		"image", fmt.Sprintf("jumppad.dev/localcache/%s:%s", b.config.Meta.Name, tag),
+		"platforms", b.config.BuildPlatforms(),
 	)
 
+	// the base images are pulled by the build, only the references can be
+	// checked against the image policies
+	err = b.enforcePolicies(ctx)
+	if err != nil {
+		return err
+	}
+
 	force := false
 	if hash != b.config.BuildChecksum {
 		force = true
@@ -70,6 +80,7 @@ func (b *Provider) Create(ctx context.Context) error {
 		Context:    b.config.Container.Context,
 		Ignore:     b.config.Container.Ignore,
 		Args:       b.config.Container.Args,
+		Platforms:  b.config.BuildPlatforms(),
 	}
 
 	name, err := b.client.BuildContainer(build, force)
@@ -171,9 +182,9 @@ func (b *Provider) Changed() (bool, error) {
 }
 
 func (b *Provider) hasChanged() (bool, error) {
-	hash, err := utils.HashDir(b.config.Container.Context, b.config.Container.Ignore...)
+	hash, err := b.checksum()
 	if err != nil {
-		return false, fmt.Errorf("unable to hash directory: %w", err)
+		return false, err
 	}
 
 	if hash != b.config.BuildChecksum {
@@ -188,10 +199,17 @@ func (b *Provider) copyOutputs() error {
 		return nil
 	}
 
-	// start an instance of the container
+	// start an instance of the container, the files are copied from the
+	// image for the first platform of a multi-platform image
+	platform := ""
+	if p := b.config.BuildPlatforms(); len(p) > 0 {
+		platform = p[0]
+	}
+
 	c := types.Container{
 		Image: &types.Image{
-			Name: b.config.Image,
+			Name:     b.config.Image,
+			Platform: platform,
 		},
 		Entrypoint: []string{},
 		Command:    []string{"tail", "-f", "/dev/null"},
@@ -219,3 +237,39 @@ func (b *Provider) copyOutputs() error {
 
 	return nil
 }
+
+// checksum returns the hash of the build context, when platforms are set they
+// are appended to the hash so that changing the platforms triggers a rebuild
+func (b *Provider) checksum() (string, error) {
+	hash, err := utils.HashDir(b.config.Container.Context, b.config.Container.Ignore...)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash directory: %w", err)
+	}
+
+	if p := b.config.BuildPlatforms(); len(p) > 0 {
+		hash = fmt.Sprintf("%s-%s", hash, strings.Join(p, ","))
+	}
+
+	return hash, nil
+}
+
+func (b *Provider) enforcePolicies(ctx context.Context) error {
+	if len(policy.FromContext(ctx)) == 0 {
+		return nil
+	}
+
+	images, err := b.config.BaseImages()
+	if err != nil {
+		return err
+	}
+
+	for _, i := range images {
+		err := policy.EnforceReference(ctx, i)
+		if err != nil {
+			b.log.Error("Base image does not meet the image policy", "ref", b.config.Meta.ID, "image", i, "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,13 +3,17 @@ package build
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +38,7 @@ func setupProvider(t *testing.T, b *Build) (*Provider, *mocks.ContainerTasks) {
 
 func TestCreatePushesToRegistry(t *testing.T) {
 	b := &Build{
-		ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test"}}},
 		Registries: []container.Image{
 			container.Image{
 				Name: "nicholasjackson/fake:latest",
@@ -56,3 +60,27 @@ func TestCreatePushesToRegistry(t *testing.T) {
 	mc.AssertCalled(t, "PushImage", types.Image{Name: "nicholasjackson/fake:latest", Username: "", Password: ""})
 	mc.AssertCalled(t, "PushImage", types.Image{Name: "authed/fake:latest", Username: "test", Password: "password"})
 }
+
+func TestCreateWithBaseImageViolatingPolicyReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine:latest\n"), 0644)
+	require.NoError(t, err)
+
+	b := &Build{
+		ResourceOptions: config.ResourceOptions{ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test"}}},
+		Container:       BuildContainer{Context: dir},
+	}
+
+	ip := &policy.ImagePolicy{ForbidLatest: true}
+	ip.Meta.ID = "resource.image_policy.strict"
+	ctx := policy.WithPolicies(context.Background(), []*policy.ImagePolicy{ip})
+
+	p, mc := setupProvider(t, b)
+	err = p.Create(ctx)
+
+	ve := &policy.ViolationError{}
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "alpine:latest", ve.Image)
+
+	mc.AssertNotCalled(t, "BuildContainer", mock.Anything, mock.Anything)
+}
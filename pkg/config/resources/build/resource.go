@@ -1,11 +1,12 @@
 package build
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path"
+	"strings"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -16,10 +17,20 @@ const TypeBuild string = "build"
 
 type Build struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Container BuildContainer `hcl:"container,block" json:"container"`
 
+	// Platform to build the image for e.g. linux/arm64, defaults to the
+	// platform of the engine
+	Platform string `hcl:"platform,optional" json:"platform,omitempty"`
+
+	// Platforms to build a multi-platform image for e.g. ["linux/amd64",
+	// "linux/arm64"], the image and a manifest list referencing the image
+	// for each platform are stored in the local image cache. Outputs are
+	// copied from the image for the first platform.
+	Platforms []string `hcl:"platforms,optional" json:"platforms,omitempty"`
+
 	// Outputs allow files or directories to be copied from the container
 	Outputs []Output `hcl:"output,block" json:"outputs"`
 
@@ -50,6 +61,10 @@ type Output struct {
 }
 
 func (b *Build) Process() error {
+	if b.Platform != "" && len(b.Platforms) > 0 {
+		return fmt.Errorf("only one of platform or platforms can be set")
+	}
+
 	b.Container.Context = utils.EnsureAbsolute(b.Container.Context, b.Meta.File)
 
 	// check that the Dockerfile exists inside the context folder
@@ -77,3 +92,78 @@ func (b *Build) Process() error {
 
 	return nil
 }
+
+// BuildPlatforms returns the platforms the image is built for, an empty list
+// is returned when the image is built for the platform of the engine
+func (b *Build) BuildPlatforms() []string {
+	if b.Platform != "" {
+		return []string{b.Platform}
+	}
+
+	return b.Platforms
+}
+
+// BaseImages returns the images referenced by the FROM instructions in the
+// Dockerfile. Stages defined earlier in the Dockerfile and scratch are not
+// returned, build args used in the image name are replaced with the values
+// from args or the default value of the ARG instruction.
+func (b *Build) BaseImages() ([]string, error) {
+	df := b.Container.DockerFile
+	if df == "" {
+		df = "Dockerfile"
+	}
+
+	f, err := os.Open(path.Join(b.Container.Context, df))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Dockerfile: %w", err)
+	}
+	defer f.Close()
+
+	defaults := map[string]string{}
+	stages := map[string]bool{}
+	images := []string{}
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			k, v, ok := strings.Cut(fields[1], "=")
+			if ok {
+				defaults[k] = strings.Trim(v, `"'`)
+			}
+		case "FROM":
+			// remove any flags such as --platform
+			fields = fields[1:]
+			for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+				fields = fields[1:]
+			}
+
+			if len(fields) == 0 {
+				continue
+			}
+
+			image := os.Expand(fields[0], func(k string) string {
+				if v, ok := b.Container.Args[k]; ok {
+					return v
+				}
+
+				return defaults[k]
+			})
+
+			if image != "" && image != "scratch" && !stages[strings.ToLower(image)] {
+				images = append(images, image)
+			}
+
+			if len(fields) == 3 && strings.EqualFold(fields[1], "as") {
+				stages[strings.ToLower(fields[2])] = true
+			}
+		}
+	}
+
+	return images, s.Err()
+}
@@ -1,6 +1,8 @@
 package build
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
@@ -14,7 +16,7 @@ func init() {
 
 func TestBuildRaisesErrorWhenDockerfileOutsideContext(t *testing.T) {
 	c := &Build{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Container: BuildContainer{
 			Context:    "../../../../examples/build/src",
 			DockerFile: "/Dockerfile/Dockerfile",
@@ -27,7 +29,7 @@ func TestBuildRaisesErrorWhenDockerfileOutsideContext(t *testing.T) {
 
 func TestBuildNoErrorWhenDockerfileInContext(t *testing.T) {
 	c := &Build{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Container: BuildContainer{
 			Context:    "../../../../examples/build/src",
 			DockerFile: "./Docker/Dockerfile",
@@ -37,3 +39,56 @@ func TestBuildNoErrorWhenDockerfileInContext(t *testing.T) {
 	err := c.Process()
 	require.NoError(t, err)
 }
+
+func TestBuildRaisesErrorWhenPlatformAndPlatformsSet(t *testing.T) {
+	c := &Build{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Container: BuildContainer{
+			Context: "../../../../examples/build/src",
+		},
+		Platform:  "linux/arm64",
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+
+	err := c.Process()
+	require.ErrorContains(t, err, "only one of platform or platforms")
+}
+
+func TestBuildPlatformsReturnsPlatform(t *testing.T) {
+	c := &Build{Platform: "linux/arm64"}
+	require.Equal(t, []string{"linux/arm64"}, c.BuildPlatforms())
+
+	c = &Build{Platforms: []string{"linux/amd64", "linux/arm64"}}
+	require.Equal(t, []string{"linux/amd64", "linux/arm64"}, c.BuildPlatforms())
+
+	c = &Build{}
+	require.Empty(t, c.BuildPlatforms())
+}
+
+func TestBuildBaseImagesReturnsFromImages(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(`
+ARG GO_VERSION=1.22
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS build
+RUN go build -o /app
+
+FROM ${BASE} AS base
+
+FROM build AS test
+
+FROM scratch
+COPY --from=build /app /app
+`), 0644)
+	require.NoError(t, err)
+
+	c := &Build{
+		Container: BuildContainer{
+			Context: dir,
+			Args:    map[string]string{"BASE": "alpine:3.19"},
+		},
+	}
+
+	images, err := c.BaseImages()
+	require.NoError(t, err)
+	require.Equal(t, []string{"golang:1.22", "alpine:3.19"}, images)
+}
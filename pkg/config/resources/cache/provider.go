@@ -147,7 +147,7 @@ func (p *Provider) createImageCache(registries []string, authRegistries []string
 
 	// Create the volume to store the cache
 	// if this volume exists it will not be recreated
-	volID, err := p.client.CreateVolume("images")
+	volID, err := p.client.CreateVolume(utils.ImageVolumeName())
 	if err != nil {
 		return "", err
 	}
@@ -174,7 +174,7 @@ func (p *Provider) createImageCache(registries []string, authRegistries []string
 
 	cc.Volumes = []types.Volume{
 		{
-			Source:      utils.FQDNVolumeName("images"),
+			Source:      utils.FQDNVolumeName(utils.ImageVolumeName()),
 			Destination: "/cache",
 			Type:        "volume",
 		},
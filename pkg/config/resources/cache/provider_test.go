@@ -11,6 +11,7 @@ import (
 	cmocks "github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/mock"
@@ -18,7 +19,7 @@ import (
 )
 
 func setupImageCacheTests() (*ImageCache, *cmocks.ContainerTasks) {
-	cc := &ImageCache{ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test"}}}
+	cc := &ImageCache{ResourceOptions: config.ResourceOptions{ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test"}}}}
 
 	md := &cmocks.ContainerTasks{}
 
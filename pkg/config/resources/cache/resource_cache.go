@@ -1,7 +1,7 @@
 package cache
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	ctypes "github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 )
 
@@ -11,7 +11,7 @@ const TypeImageCache string = "image_cache"
 // ImageCache defines a structure for creating ImageCache containers
 type ImageCache struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Registries []Registry `hcl:"registry,block" json:"registries,omitempty"`
 
@@ -1,13 +1,15 @@
 package cache
 
-import "github.com/jumppad-labs/hclconfig/types"
+import (
+	"github.com/jumppad-labs/jumppad/pkg/config"
+)
 
 const TypeRegistry string = "container_registry"
 
 // Registry defines a structure for registering additional registries for the image cache
 type Registry struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Hostname string        `hcl:"hostname" json:"hostname"`         // Hostname of the registry
 	Auth     *RegistryAuth `hcl:"auth,block" json:"auth,omitempty"` // auth to authenticate against registry
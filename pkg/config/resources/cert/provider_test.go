@@ -8,13 +8,14 @@ import (
 
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
 func setupCACert(t *testing.T) (*CertificateCA, *CAProvider) {
 	dir := t.TempDir()
 
-	ca := &CertificateCA{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}
+	ca := &CertificateCA{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}}
 	ca.Output = dir
 
 	p := &CAProvider{ca, logger.NewTestLogger(t)}
@@ -29,7 +30,7 @@ func setupLeafCert(t *testing.T) (*CertificateLeaf, *LeafProvider) {
 	err := p.Create(context.Background())
 	require.NoError(t, err)
 
-	cl := &CertificateLeaf{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}
+	cl := &CertificateLeaf{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}}
 	cl.Output = dir
 	cl.IPAddresses = []string{"127.0.0.1"}
 	cl.DNSNames = []string{"localhost"}
@@ -1,7 +1,6 @@
 package cert
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
@@ -11,7 +10,7 @@ const TypeCertificateCA string = "certificate_ca"
 
 // CertificateCA allows the generate of CA certificates
 type CertificateCA struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	// Output directory to write the certificate and key too
 	Output string `hcl:"output" json:"output"`
@@ -59,7 +58,7 @@ const TypeCertificateLeaf string = "certificate_leaf"
 
 // CertificateCA allows the generate of CA certificates
 type CertificateLeaf struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	CAKey  string `hcl:"ca_key" json:"ca_key"`   // Path to the primary key for the root CA
 	CACert string `hcl:"ca_cert" json:"ca_cert"` // Path to the root CA
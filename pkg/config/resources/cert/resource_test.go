@@ -21,8 +21,8 @@ func TestCertCAProcessSetsAbsoluteValues(t *testing.T) {
 	require.NoError(t, err)
 
 	ca := &CertificateCA{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Output:       "./output",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Output:          "./output",
 	}
 
 	err = ca.Process()
@@ -59,10 +59,12 @@ func TestCertCALoadsValuesFromState(t *testing.T) {
 }`)
 
 	ca := &CertificateCA{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				File: "./",
-				ID:   "resource.certificate_ca.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					File: "./",
+					ID:   "resource.certificate_ca.test",
+				},
 			},
 		},
 		Output: "./output",
@@ -82,10 +84,10 @@ func TestCertLeafProcessSetsAbsoluteValues(t *testing.T) {
 	require.NoError(t, err)
 
 	ca := &CertificateLeaf{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		CAKey:        "./key.pem",
-		CACert:       "./cert.pem",
-		Output:       "./output",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		CAKey:           "./key.pem",
+		CACert:          "./cert.pem",
+		Output:          "./output",
 	}
 
 	err = ca.Process()
@@ -124,10 +126,12 @@ func TestCertLeafLoadsValuesFromState(t *testing.T) {
 }`)
 
 	ca := &CertificateLeaf{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				File: "./",
-				ID:   "resource.certificate_leaf.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					File: "./",
+					ID:   "resource.certificate_leaf.test",
+				},
 			},
 		},
 		Output: "./output",
@@ -47,6 +47,8 @@ func (v Volume) ToClientVolume() types.Volume {
 		BindPropagation:             v.BindPropagation,
 		BindPropagationNonRecursive: v.BindPropagationNonRecursive,
 		SelinuxRelabel:              v.SelinuxRelabel,
+		TmpfsSize:                   v.TmpfsSize,
+		TmpfsMode:                   v.TmpfsMode,
 	}
 }
 
@@ -59,6 +61,23 @@ func (v Volumes) ToClientVolumes() []types.Volume {
 	return vols
 }
 
+func (u Ulimit) ToClientUlimit() types.Ulimit {
+	return types.Ulimit{
+		Name: u.Name,
+		Soft: u.Soft,
+		Hard: u.Hard,
+	}
+}
+
+func (u Ulimits) ToClientUlimits() []types.Ulimit {
+	ulimits := []types.Ulimit{}
+	for _, ul := range u {
+		ulimits = append(ulimits, ul.ToClientUlimit())
+	}
+
+	return ulimits
+}
+
 func (p Port) ToClientPort() types.Port {
 	return types.Port{
 		Local:         p.Local,
@@ -0,0 +1,166 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	ctasks "github.com/jumppad-labs/jumppad/pkg/clients/container"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/http"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
+)
+
+const (
+	// HealthStarting is reported while the container is in the health check
+	// start period and no checks have failed
+	HealthStarting = "starting"
+	// HealthHealthy is reported when all health checks pass
+	HealthHealthy = "healthy"
+	// HealthUnhealthy is reported when any health check fails or the container
+	// is not running
+	HealthUnhealthy = "unhealthy"
+)
+
+// defaultHealthCheckInterval is the time between the container engine
+// running the exec health checks
+const defaultHealthCheckInterval = 10 * time.Second
+
+// Health is the current health of a container or sidecar
+type Health struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// toClientHealthCheck converts the exec health checks to a health check that
+// is run by the container engine for the life of the container. HTTP and TCP
+// checks address the container from the host so can not be run by the engine,
+// these are checked by ContainerHealth. Returns nil when there are no exec
+// checks
+//
+// A single command check that expects exit code 0 is run directly so that it
+// works in images without a shell such as distroless or scratch images. All
+// other checks are combined into a script that requires sh in the image
+func toClientHealthCheck(hc *healthcheck.HealthCheckContainer) *types.HealthCheck {
+	if hc == nil || len(hc.Exec) == 0 {
+		return nil
+	}
+
+	// the create timeout is used as the start period, checks that fail before
+	// the container is created are not counted
+	sp, err := time.ParseDuration(hc.Timeout)
+	if err != nil {
+		sp = 30 * time.Second
+	}
+
+	check := &types.HealthCheck{
+		Interval:    defaultHealthCheckInterval,
+		Timeout:     defaultHealthCheckInterval,
+		StartPeriod: sp,
+		Retries:     3,
+	}
+
+	if e := hc.Exec[0]; len(hc.Exec) == 1 && e.Script == "" && e.ExitCode == 0 {
+		check.Test = append([]string{"CMD"}, e.Command...)
+		return check
+	}
+
+	// each check must return the expected exit code, scripts are run with sh
+	// in the same way as the checks run on create
+	lines := []string{}
+	for _, e := range hc.Exec {
+		cmd := shellQuote(e.Command...)
+		if e.Script != "" {
+			cmd = shellQuote("sh", "-c", e.Script)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s; [ $? -eq %d ] || exit 1", cmd, e.ExitCode))
+	}
+
+	check.Test = []string{"CMD-SHELL", strings.Join(lines, "\n")}
+
+	return check
+}
+
+// shellQuote quotes the arguments so they can be safely passed to sh
+func shellQuote(args ...string) string {
+	quoted := []string{}
+	for _, a := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(a, "'", `'"'"'`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// ContainerHealth returns the current health of the container with the given
+// fully qualified name. The health of the exec checks is read from the
+// container engine, HTTP and TCP checks are run once from the host with the
+// given timeout. When the container has no health checks nil is returned
+func ContainerHealth(ct ctasks.ContainerTasks, hc http.HTTP, name string, check *healthcheck.HealthCheckContainer, timeout time.Duration) *Health {
+	if check == nil || (len(check.Exec) == 0 && len(check.HTTP) == 0 && len(check.TCP) == 0) {
+		return nil
+	}
+
+	ids, err := ct.FindContainerIDs(name)
+	if err != nil || len(ids) == 0 {
+		return &Health{Status: HealthUnhealthy, Message: "container not found"}
+	}
+
+	i, err := ct.ContainerInfo(ids[0])
+	if err != nil {
+		return &Health{Status: HealthUnhealthy, Message: err.Error()}
+	}
+
+	info, ok := i.(container.InspectResponse)
+	if !ok || info.State == nil {
+		return &Health{Status: HealthUnhealthy, Message: "unable to read container state"}
+	}
+
+	if !info.State.Running {
+		return &Health{Status: HealthUnhealthy, Message: fmt.Sprintf("container is %s", info.State.Status)}
+	}
+
+	status := HealthHealthy
+
+	if h := info.State.Health; h != nil {
+		switch h.Status {
+		case container.Unhealthy:
+			return &Health{Status: HealthUnhealthy, Message: lastHealthFailure(h)}
+		case container.Starting:
+			status = HealthStarting
+		}
+	}
+
+	for _, t := range check.TCP {
+		err := hc.HealthCheckTCP(t.Address, timeout)
+		if err != nil {
+			return &Health{Status: HealthUnhealthy, Message: err.Error()}
+		}
+	}
+
+	for _, h := range check.HTTP {
+		err := hc.HealthCheckHTTP(h.Address, h.Method, h.Headers, h.Body, h.SuccessCodes, timeout)
+		if err != nil {
+			return &Health{Status: HealthUnhealthy, Message: err.Error()}
+		}
+	}
+
+	return &Health{Status: status}
+}
+
+// lastHealthFailure returns the output of the most recent failed health check
+func lastHealthFailure(h *container.Health) string {
+	for i := len(h.Log) - 1; i >= 0; i-- {
+		if h.Log[i].ExitCode != 0 {
+			out := strings.TrimSpace(h.Log[i].Output)
+			if out == "" {
+				out = fmt.Sprintf("health check failed with exit code %d", h.Log[i].ExitCode)
+			}
+
+			return out
+		}
+	}
+
+	return fmt.Sprintf("%d consecutive health check failures", h.FailingStreak)
+}
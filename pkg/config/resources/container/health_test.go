@@ -0,0 +1,160 @@
+package container
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	hmocks "github.com/jumppad-labs/jumppad/pkg/clients/http/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupHealthTests(state *container.State) (*mocks.ContainerTasks, *hmocks.HTTP) {
+	md := &mocks.ContainerTasks{}
+	md.On("FindContainerIDs", "tests.container.local.jmpd.in").Return([]string{"abc"}, nil)
+	md.On("ContainerInfo", "abc").Return(container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{State: state}}, nil)
+
+	hc := &hmocks.HTTP{}
+	hc.On("HealthCheckTCP", mock.Anything, mock.Anything).Return(nil)
+	hc.On("HealthCheckHTTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	return md, hc
+}
+
+func TestToClientHealthCheckReturnsNilWithoutExecChecks(t *testing.T) {
+	hc := toClientHealthCheck(&healthcheck.HealthCheckContainer{
+		Timeout: "30s",
+		HTTP:    []healthcheck.HealthCheckHTTP{{Address: "http://localhost:8080"}},
+	})
+
+	assert.Nil(t, hc)
+}
+
+func TestToClientHealthCheckConvertsExecChecks(t *testing.T) {
+	hc := toClientHealthCheck(&healthcheck.HealthCheckContainer{
+		Timeout: "60s",
+		Exec: []healthcheck.HealthCheckExec{
+			{Command: []string{"pg_isready", "-U", "postgres"}},
+			{Script: "echo 'ok'\nexit 2", ExitCode: 2},
+		},
+	})
+
+	assert.Equal(t, "CMD-SHELL", hc.Test[0])
+	assert.Equal(t, "'pg_isready' '-U' 'postgres'; [ $? -eq 0 ] || exit 1\n'sh' '-c' 'echo '\"'\"'ok'\"'\"'\nexit 2'; [ $? -eq 2 ] || exit 1", hc.Test[1])
+	assert.Equal(t, 60*time.Second, hc.StartPeriod)
+	assert.Equal(t, 3, hc.Retries)
+}
+
+func TestToClientHealthCheckRunsSingleCommandWithoutShell(t *testing.T) {
+	hc := toClientHealthCheck(&healthcheck.HealthCheckContainer{
+		Timeout: "30s",
+		Exec: []healthcheck.HealthCheckExec{
+			{Command: []string{"/app/healthcheck", "--port", "8080"}},
+		},
+	})
+
+	assert.Equal(t, []string{"CMD", "/app/healthcheck", "--port", "8080"}, hc.Test)
+	assert.Equal(t, 30*time.Second, hc.StartPeriod)
+}
+
+func TestToClientHealthCheckRunsCommandWithExitCodeInShell(t *testing.T) {
+	hc := toClientHealthCheck(&healthcheck.HealthCheckContainer{
+		Timeout: "30s",
+		Exec: []healthcheck.HealthCheckExec{
+			{Command: []string{"false"}, ExitCode: 1},
+		},
+	})
+
+	assert.Equal(t, "CMD-SHELL", hc.Test[0])
+}
+
+func TestToClientHealthCheckTestRunsInShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	hc := toClientHealthCheck(&healthcheck.HealthCheckContainer{
+		Timeout: "30s",
+		Exec: []healthcheck.HealthCheckExec{
+			{Command: []string{"true"}},
+			{Script: "echo 'it'\"'\"'s'\nexit 3", ExitCode: 3},
+		},
+	})
+
+	err := exec.Command("sh", "-c", hc.Test[1]).Run()
+	assert.NoError(t, err)
+}
+
+func TestContainerHealthReturnsNilWithoutChecks(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{Running: true})
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", &healthcheck.HealthCheckContainer{}, time.Second)
+	assert.Nil(t, h)
+}
+
+func TestContainerHealthReturnsUnhealthyWhenNotRunning(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{Running: false, Status: "exited"})
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", &healthcheck.HealthCheckContainer{TCP: []healthcheck.HealthCheckTCP{{Address: "localhost:8500"}}}, time.Second)
+	assert.Equal(t, HealthUnhealthy, h.Status)
+	assert.Equal(t, "container is exited", h.Message)
+}
+
+func TestContainerHealthReturnsEngineHealth(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{
+		Running: true,
+		Health: &container.Health{
+			Status: container.Unhealthy,
+			Log: []*container.HealthcheckResult{
+				{ExitCode: 1, Output: "connection refused\n"},
+				{ExitCode: 0, Output: "ok"},
+				{ExitCode: 1, Output: "no response\n"},
+			},
+		},
+	})
+
+	check := &healthcheck.HealthCheckContainer{Exec: []healthcheck.HealthCheckExec{{Command: []string{"true"}}}}
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", check, time.Second)
+	assert.Equal(t, HealthUnhealthy, h.Status)
+	assert.Equal(t, "no response", h.Message)
+}
+
+func TestContainerHealthReturnsStartingWhenEngineStarting(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{Running: true, Health: &container.Health{Status: container.Starting}})
+
+	check := &healthcheck.HealthCheckContainer{Exec: []healthcheck.HealthCheckExec{{Command: []string{"true"}}}}
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", check, time.Second)
+	assert.Equal(t, HealthStarting, h.Status)
+}
+
+func TestContainerHealthReturnsUnhealthyWhenHTTPCheckFails(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{Running: true})
+	hc.ExpectedCalls = nil
+	hc.On("HealthCheckHTTP", "http://localhost:8500", "", mock.Anything, "", mock.Anything, time.Second).Return(fmt.Errorf("timeout waiting for HTTP health check"))
+
+	check := &healthcheck.HealthCheckContainer{HTTP: []healthcheck.HealthCheckHTTP{{Address: "http://localhost:8500"}}}
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", check, time.Second)
+	assert.Equal(t, HealthUnhealthy, h.Status)
+	assert.Equal(t, "timeout waiting for HTTP health check", h.Message)
+}
+
+func TestContainerHealthReturnsHealthy(t *testing.T) {
+	md, hc := setupHealthTests(&container.State{Running: true, Health: &container.Health{Status: container.Healthy}})
+
+	check := &healthcheck.HealthCheckContainer{
+		Exec: []healthcheck.HealthCheckExec{{Command: []string{"true"}}},
+		TCP:  []healthcheck.HealthCheckTCP{{Address: "localhost:8500"}},
+	}
+
+	h := ContainerHealth(md, hc, "tests.container.local.jmpd.in", check, time.Second)
+	assert.Equal(t, HealthHealthy, h.Status)
+	hc.AssertCalled(t, "HealthCheckTCP", "localhost:8500", time.Second)
+}
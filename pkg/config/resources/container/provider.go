@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/http"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
@@ -52,9 +54,17 @@ func (p *Provider) Init(cfg htypes.Resource, l sdk.Logger) error {
 		co.Environment = cs.Environment
 		co.HealthCheck = cs.HealthCheck
 		co.Image = cs.Image
+		co.Platform = cs.Platform
 		co.Privileged = cs.Privileged
 		co.Resources = cs.Resources
 		co.MaxRestartCount = cs.MaxRestartCount
+		co.InitContainers = cs.InitContainers
+		co.Files = cs.Files
+		co.Ulimits = cs.Ulimits
+		co.Sysctls = cs.Sysctls
+		co.ShmSize = cs.ShmSize
+		co.StopSignal = cs.StopSignal
+		co.StopTimeout = cs.StopTimeout
 
 		p.sidecar = cs
 		p.config = co
@@ -148,6 +158,18 @@ func (c *Provider) Changed() (bool, error) {
 		return true, nil
 	}
 
+	// has the content of any of the files changed
+	files, err := c.config.Files.Changed()
+	if err != nil {
+		c.log.Error("Unable to read container files", "ref", c.config.Meta.ID, "error", err)
+		return false, err
+	}
+
+	if len(files) > 0 {
+		c.log.Debug("Container files changed, needs refresh", "ref", c.config.Meta.ID)
+		return true, nil
+	}
+
 	return false, nil
 }
 
@@ -161,6 +183,7 @@ func (c *Provider) internalCreate(ctx context.Context, sidecar bool) error {
 		Name:     c.config.Image.Name,
 		Username: c.config.Image.Username,
 		Password: c.config.Image.Password,
+		Platform: c.config.Platform,
 	}
 
 	err := c.client.PullImage(img, false)
@@ -180,6 +203,23 @@ func (c *Provider) internalCreate(ctx context.Context, sidecar bool) error {
 	// id should never be blank here as we have pulled the image
 	c.config.Image.ID = id
 
+	user := ""
+	if c.config.RunAs != nil {
+		user = c.config.RunAs.User
+	}
+
+	err = policy.Enforce(ctx, c.client, img.Name, user)
+	if err != nil {
+		c.log.Error("Container image does not meet the image policy", "ref", c.config.Meta.ID, "image", img.Name, "error", err)
+		return err
+	}
+
+	// run any init containers, these must complete before the container is created
+	err = c.runInitContainers(ctx, fqdn, sidecar)
+	if err != nil {
+		return err
+	}
+
 	new := types.Container{
 		Name:            fqdn,
 		Image:           &img,
@@ -190,6 +230,19 @@ func (c *Provider) internalCreate(ctx context.Context, sidecar bool) error {
 		DNS:             c.config.DNS,
 		Privileged:      c.config.Privileged,
 		MaxRestartCount: c.config.MaxRestartCount,
+		Sysctls:         c.config.Sysctls,
+		ExtraHosts:      c.config.ExtraHosts,
+		ShmSize:         c.config.ShmSize,
+		StopSignal:      c.config.StopSignal,
+		StopTimeout:     c.config.StopTimeout,
+		HealthCheck:     toClientHealthCheck(c.config.HealthCheck),
+	}
+
+	// read the files, the checksums are updated so that changes can be detected
+	new.Files, err = c.config.Files.ToClientFiles()
+	if err != nil {
+		c.log.Error("Unable to read container files", "ref", c.config.Meta.ID, "error", err)
+		return err
 	}
 
 	for _, v := range c.config.Networks {
@@ -203,15 +256,11 @@ func (c *Provider) internalCreate(ctx context.Context, sidecar bool) error {
 	}
 
 	for _, v := range c.config.Volumes {
-		new.Volumes = append(new.Volumes, types.Volume{
-			Source:                      v.Source,
-			Destination:                 v.Destination,
-			Type:                        v.Type,
-			ReadOnly:                    v.ReadOnly,
-			BindPropagation:             v.BindPropagation,
-			BindPropagationNonRecursive: v.BindPropagationNonRecursive,
-			SelinuxRelabel:              v.SelinuxRelabel,
-		})
+		new.Volumes = append(new.Volumes, v.ToClientVolume())
+	}
+
+	for _, u := range c.config.Ulimits {
+		new.Ulimits = append(new.Ulimits, u.ToClientUlimit())
 	}
 
 	for _, p := range c.config.Ports {
@@ -282,6 +331,13 @@ func (c *Provider) internalCreate(ctx context.Context, sidecar bool) error {
 		}
 	}
 
+	// set the address of any published host ports
+	for i, p := range c.config.Ports {
+		if p.Host != "" {
+			c.config.Ports[i].HostAddress = fmt.Sprintf("%s:%s", utils.GetDockerIP(), p.Host)
+		}
+	}
+
 	if c.config.HealthCheck == nil {
 		return nil
 	}
@@ -386,6 +442,141 @@ func (c *Provider) runExecHealthCheck(ctx context.Context, id string, command []
 	}
 }
 
+// runInitContainers runs the init containers in order, each init container
+// must exit with a zero exit code before the next is started. Completed init
+// containers are not removed so that their logs can be viewed with jumppad logs
+func (c *Provider) runInitContainers(ctx context.Context, fqdn string, sidecar bool) error {
+	for i, ic := range c.config.InitContainers {
+		name := InitContainerName(ic.Name, fqdn)
+
+		c.log.Info("Running init container", "ref", c.config.Meta.ID, "name", ic.Name)
+
+		// remove any init container left from a previous run
+		err := c.removeInitContainer(name)
+		if err != nil {
+			return err
+		}
+
+		img := types.Image{
+			Name:     ic.Image.Name,
+			Username: ic.Image.Username,
+			Password: ic.Image.Password,
+			Platform: c.config.Platform,
+		}
+
+		err = c.client.PullImage(img, false)
+		if err != nil {
+			c.log.Error("Error pulling init container image", "ref", c.config.Meta.ID, "name", ic.Name, "image", ic.Image.Name)
+			return err
+		}
+
+		user := ""
+		if ic.RunAs != nil {
+			user = ic.RunAs.User
+		}
+
+		err = policy.Enforce(ctx, c.client, img.Name, user)
+		if err != nil {
+			c.log.Error("Init container image does not meet the image policy", "ref", c.config.Meta.ID, "name", ic.Name, "image", ic.Image.Name, "error", err)
+			return err
+		}
+
+		new := types.Container{
+			Name:        name,
+			Image:       &img,
+			Entrypoint:  ic.Entrypoint,
+			Command:     ic.Command,
+			Environment: ic.Environment,
+		}
+
+		// init containers share the networks of the main container, ip addresses
+		// and aliases are reserved for the main container
+		for _, v := range c.config.Networks {
+			new.Networks = append(new.Networks, types.NetworkAttachment{
+				ID:          v.ID,
+				Name:        v.Name,
+				IsContainer: sidecar,
+			})
+		}
+
+		for _, v := range append(append([]Volume{}, c.config.Volumes...), ic.Volumes...) {
+			new.Volumes = append(new.Volumes, v.ToClientVolume())
+		}
+
+		if ic.RunAs != nil {
+			new.RunAs = &types.User{
+				User:  ic.RunAs.User,
+				Group: ic.RunAs.Group,
+			}
+		}
+
+		// timeout has been validated when the config was processed
+		timeout, _ := time.ParseDuration(ic.Timeout)
+
+		id, err := c.client.CreateContainer(&new)
+		if err != nil {
+			c.log.Error("Unable to create init container", "ref", c.config.Meta.ID, "name", ic.Name, "error", err)
+			return fmt.Errorf("unable to create init container %s: %w", ic.Name, err)
+		}
+
+		code, err := c.client.WaitForContainer(id, int(timeout.Seconds()))
+		c.config.InitContainers[i].ExitCode = code
+
+		if err != nil || code != 0 {
+			logs := c.initContainerLogs(id)
+			c.log.Error("Init container failed", "ref", c.config.Meta.ID, "name", ic.Name, "exit_code", code, "logs", logs)
+
+			if err != nil {
+				return fmt.Errorf("init container %s failed: %w, logs: %s", ic.Name, err, logs)
+			}
+
+			return fmt.Errorf("init container %s failed with exit code %d, logs: %s", ic.Name, code, logs)
+		}
+
+		c.log.Debug("Init container completed", "ref", c.config.Meta.ID, "name", ic.Name)
+	}
+
+	return nil
+}
+
+// initContainerLogs returns the last lines of the logs for the init container
+func (c *Provider) initContainerLogs(id string) string {
+	rc, err := c.client.ContainerLogs(id, true, true)
+	if err != nil {
+		return fmt.Sprintf("unable to read logs: %s", err)
+	}
+	defer rc.Close()
+
+	out, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Sprintf("unable to read logs: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) > 20 {
+		lines = lines[len(lines)-20:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// removeInitContainer removes the init container with the given name
+func (c *Provider) removeInitContainer(name string) error {
+	ids, err := c.client.FindContainerIDs(name)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := c.client.RemoveContainer(id, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *Provider) internalDestroy(ctx context.Context, force bool) error {
 	if ctx.Err() != nil {
 		c.log.Debug("Context cancelled, skipping container destroy", "ref", c.config.Meta.ID)
@@ -407,5 +598,12 @@ func (c *Provider) internalDestroy(ctx context.Context, force bool) error {
 		}
 	}
 
+	for _, ic := range c.config.InitContainers {
+		err := c.removeInitContainer(InitContainerName(ic.Name, c.config.ContainerName))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
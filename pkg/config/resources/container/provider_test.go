@@ -3,6 +3,8 @@ package container
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,15 +13,19 @@ import (
 	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	hmocks "github.com/jumppad-labs/jumppad/pkg/clients/http/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/mock"
 	assert "github.com/stretchr/testify/require"
 )
 
 func setupContainerTests(t *testing.T) (*Container, *mocks.ContainerTasks, *hmocks.HTTP) {
-	cc := &Container{ResourceBase: types.ResourceBase{
-		Meta: types.Meta{Name: "tests", Type: TypeContainer},
+	cc := &Container{ResourceOptions: config.ResourceOptions{
+		ResourceBase: types.ResourceBase{
+			Meta: types.Meta{Name: "tests", Type: TypeContainer},
+		},
 	}}
 
 	cc.Image = Image{Name: "consul"}
@@ -58,8 +64,10 @@ func TestContainerSidecarCreatesContainerSuccessfully(t *testing.T) {
 	testutils.RemoveOn(&md.Mock, "CreateContainer")
 	md.On("CreateContainer", mock.Anything).Once().Return("12345", nil)
 
-	cs := &Sidecar{ResourceBase: types.ResourceBase{
-		Meta: types.Meta{Name: "tests", Type: TypeSidecar},
+	cs := &Sidecar{ResourceOptions: config.ResourceOptions{
+		ResourceBase: types.ResourceBase{
+			Meta: types.Meta{Name: "tests", Type: TypeSidecar},
+		},
 	}}
 
 	cs.Target = *c
@@ -203,6 +211,35 @@ func TestContainerDoesNOTCreateWhenPullImageFail(t *testing.T) {
 	assert.Equal(t, imageErr, err)
 }
 
+func TestContainerDoesNOTCreateWhenImageViolatesPolicy(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+
+	md.On("InspectImage", "consul").Return(&ctypes.ImageInfo{User: "root"}, nil)
+
+	ip := &policy.ImagePolicy{ForbidRootUser: true}
+	ctx := policy.WithPolicies(context.Background(), []*policy.ImagePolicy{ip})
+
+	err := p.Create(ctx)
+	assert.ErrorContains(t, err, "root")
+
+	md.AssertNotCalled(t, "CreateContainer", mock.Anything)
+}
+
+func TestContainerChecksRunAsUserAgainstPolicy(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	cc.RunAs = &User{User: "1000", Group: "1000"}
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+
+	md.On("InspectImage", "consul").Return(&ctypes.ImageInfo{User: "root"}, nil)
+
+	ip := &policy.ImagePolicy{ForbidRootUser: true}
+	ctx := policy.WithPolicies(context.Background(), []*policy.ImagePolicy{ip})
+
+	err := p.Create(ctx)
+	assert.NoError(t, err)
+}
+
 func TestContainerDestroysCorrectlyWhenContainerExists(t *testing.T) {
 	cc, md, hc := setupContainerTests(t)
 	cc.Networks = []NetworkAttachment{NetworkAttachment{Name: "cloud"}}
@@ -275,3 +312,185 @@ func TestContainerAddsResources(t *testing.T) {
 	assert.Equal(t, "nvidia", ac.Resources.GPU.Driver)
 	assert.Equal(t, []string{"1"}, ac.Resources.GPU.DeviceIDs)
 }
+
+func TestContainerAddsLimitsAndStopSettings(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	cc.Ulimits = []Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}
+	cc.Sysctls = map[string]string{"net.core.somaxconn": "1024"}
+	cc.ExtraHosts = map[string]string{"db.local": "10.0.0.2"}
+	cc.ShmSize = 256
+	cc.StopSignal = "SIGINT"
+	cc.StopTimeout = 120
+	cc.Volumes = []Volume{{Destination: "/tmp", Type: "tmpfs", TmpfsSize: 64, TmpfsMode: "1777"}}
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	p.Create(context.Background())
+
+	ac := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, []ctypes.Ulimit{{Name: "nofile", Soft: 1024, Hard: 2048}}, ac.Ulimits)
+	assert.Equal(t, cc.Sysctls, ac.Sysctls)
+	assert.Equal(t, cc.ExtraHosts, ac.ExtraHosts)
+	assert.Equal(t, 256, ac.ShmSize)
+	assert.Equal(t, "SIGINT", ac.StopSignal)
+	assert.Equal(t, 120, ac.StopTimeout)
+	assert.Equal(t, 64, ac.Volumes[0].TmpfsSize)
+	assert.Equal(t, "1777", ac.Volumes[0].TmpfsMode)
+}
+
+func setupInitContainerTests(t *testing.T) (*Container, *mocks.ContainerTasks, *hmocks.HTTP) {
+	cc, md, hc := setupContainerTests(t)
+	cc.Networks = []NetworkAttachment{{ID: "resource.network.cloud", IPAddress: "10.0.0.2", Aliases: []string{"db"}}}
+	cc.Volumes = []Volume{{Source: "/data", Destination: "/data"}}
+	cc.InitContainers = InitContainers{
+		{
+			Name:    "migrate",
+			Image:   Image{Name: "migrate"},
+			Command: []string{"migrate", "up"},
+			Timeout: "30s",
+			Volumes: []Volume{{Source: "/migrations", Destination: "/migrations"}},
+		},
+	}
+
+	testutils.RemoveOn(&md.Mock, "CreateContainer")
+	md.On("CreateContainer", mock.Anything).Return("12345", nil)
+	md.On("PullImage", ctypes.Image{Name: "migrate"}, false).Return(nil)
+	md.On("FindContainerIDs", "migrate.init.tests.container.local.jmpd.in").Return(nil, nil)
+	md.On("WaitForContainer", "12345", 30).Return(0, nil)
+
+	return cc, md, hc
+}
+
+func TestContainerRunsInitContainersBeforeContainer(t *testing.T) {
+	cc, md, hc := setupInitContainerTests(t)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	calls := testutils.GetCalls(&md.Mock, "CreateContainer")
+	assert.Len(t, calls, 2)
+
+	ic := calls[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "migrate.init.tests.container.local.jmpd.in", ic.Name)
+	assert.Equal(t, []string{"migrate", "up"}, ic.Command)
+	assert.Equal(t, "resource.network.cloud", ic.Networks[0].ID)
+	assert.Empty(t, ic.Networks[0].IPAddress)
+	assert.Empty(t, ic.Networks[0].Aliases)
+	assert.Len(t, ic.Volumes, 2)
+	assert.Equal(t, "/data", ic.Volumes[0].Destination)
+	assert.Equal(t, "/migrations", ic.Volumes[1].Destination)
+
+	ac := calls[1].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "tests.container.local.jmpd.in", ac.Name)
+	assert.Equal(t, "10.0.0.2", ac.Networks[0].IPAddress)
+
+	assert.Equal(t, 0, cc.InitContainers[0].ExitCode)
+}
+
+func TestContainerDoesNotCreateWhenInitContainerFails(t *testing.T) {
+	cc, md, hc := setupInitContainerTests(t)
+	testutils.RemoveOn(&md.Mock, "WaitForContainer")
+	md.On("WaitForContainer", "12345", 30).Return(3, nil)
+	md.On("ContainerLogs", "12345", true, true).Return(io.NopCloser(strings.NewReader("no such table: users")), nil)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.ErrorContains(t, err, "exit code 3")
+	assert.ErrorContains(t, err, "no such table: users")
+
+	md.AssertNumberOfCalls(t, "CreateContainer", 1)
+	assert.Equal(t, 3, cc.InitContainers[0].ExitCode)
+}
+
+func TestContainerRemovesPreviousInitContainer(t *testing.T) {
+	cc, md, hc := setupInitContainerTests(t)
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", "migrate.init.tests.container.local.jmpd.in").Return([]string{"abc"}, nil)
+	md.On("RemoveContainer", "abc", true).Return(nil)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "RemoveContainer", "abc", true)
+}
+
+func TestContainerDestroyRemovesInitContainers(t *testing.T) {
+	cc, md, hc := setupInitContainerTests(t)
+	cc.ContainerName = "tests.container.local.jmpd.in"
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", "tests.container.local.jmpd.in").Return([]string{"abc"}, nil)
+	md.On("FindContainerIDs", "migrate.init.tests.container.local.jmpd.in").Return([]string{"def"}, nil)
+	md.On("RemoveContainer", "abc", false).Return(nil)
+	md.On("RemoveContainer", "def", true).Return(nil)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Destroy(context.Background(), false)
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "RemoveContainer", "def", true)
+}
+
+func TestContainerAddsEngineHealthCheckForExecChecks(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	cc.HealthCheck = &healthcheck.HealthCheckContainer{
+		Timeout: "30s",
+		Exec:    []healthcheck.HealthCheckExec{{Command: []string{"pg_isready"}}},
+	}
+
+	md.On("ExecuteCommand", "12345", []string{"pg_isready"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	ac := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, []string{"CMD", "pg_isready"}, ac.HealthCheck.Test)
+}
+
+func TestContainerAddsFiles(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	cc.Files = Files{{Destination: "/etc/app/config.hcl", Content: "port = 8080", Mode: "0600"}}
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	ac := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, []ctypes.File{{Destination: "/etc/app/config.hcl", Content: "port = 8080", Mode: 0600}}, ac.Files)
+	assert.NotEmpty(t, cc.Files[0].Checksum)
+}
+
+func TestContainerChangedWhenFileContentChanges(t *testing.T) {
+	cc, md, hc := setupContainerTests(t)
+	cc.Files = Files{{Destination: "/etc/app/config.hcl", Content: "port = 8080", Mode: "0644"}}
+	md.On("FindImageInLocalRegistry", mock.Anything).Return("myimage", nil)
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	changed, err := p.Changed()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	cc.Files[0].Content = "port = 9090"
+
+	changed, err = p.Changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestContainerSetsHostAddressForPublishedPorts(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "tcp://10.5.0.20:2376")
+
+	cc, md, hc := setupContainerTests(t)
+	cc.Ports = Ports{{Local: "80", Host: "8080"}, {Local: "443"}}
+
+	p := Provider{config: cc, client: md, httpClient: hc, log: logger.NewTestLogger(t)}
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "10.5.0.20:8080", cc.Ports[0].HostAddress)
+	assert.Empty(t, cc.Ports[1].HostAddress)
+}
@@ -1,9 +1,10 @@
 package container
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -15,10 +16,11 @@ const TypeContainer string = "container"
 // Container defines a structure for creating Docker containers
 type Container struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Networks        []NetworkAttachment `hcl:"network,block" json:"networks,omitempty"`           // Attach to the correct network // only when Image is specified
 	Image           Image               `hcl:"image,block" json:"image"`                          // Image to use for the container
+	Platform        string              `hcl:"platform,optional" json:"platform,omitempty"`       // Platform of the image e.g. linux/arm64, defaults to the platform of the engine
 	Entrypoint      []string            `hcl:"entrypoint,optional" json:"entrypoint,omitempty"`   // Entrypoint to use when starting the container
 	Command         []string            `hcl:"command,optional" json:"command,omitempty"`         // Command to use when starting the container
 	Environment     map[string]string   `hcl:"environment,optional" json:"environment,omitempty"` // Environment variables to set when starting the container
@@ -30,10 +32,22 @@ type Container struct {
 	Privileged      bool                `hcl:"privileged,optional" json:"privileged,omitempty"`   // Run the container in privileged mode?
 	Capabilities    *Capabilities       `hcl:"capabilities,block" json:"capabilities,omitempty"`  // Capabilities to add or drop from the container
 	MaxRestartCount int                 `hcl:"max_restart_count,optional" json:"max_restart_count,omitempty"`
+	Ulimits         []Ulimit            `hcl:"ulimit,block" json:"ulimits,omitempty"`               // Ulimits to set for the container
+	Sysctls         map[string]string   `hcl:"sysctls,optional" json:"sysctls,omitempty"`           // Kernel parameters to set in the container namespace
+	ExtraHosts      map[string]string   `hcl:"extra_hosts,optional" json:"extra_hosts,omitempty"`   // Additional hostname to ip mappings added to /etc/hosts
+	ShmSize         int                 `hcl:"shm_size,optional" json:"shm_size,omitempty"`         // Size of /dev/shm in MB
+	StopSignal      string              `hcl:"stop_signal,optional" json:"stop_signal,omitempty"`   // Signal sent to the container to stop it e.g. SIGINT
+	StopTimeout     int                 `hcl:"stop_timeout,optional" json:"stop_timeout,omitempty"` // Seconds to wait for the container to stop before it is killed
 
 	// resource constraints
 	Resources *Resources `hcl:"resources,block" json:"resources,omitempty"` // resource constraints for the container
 
+	// init containers that must complete before the container is created
+	InitContainers InitContainers `hcl:"init_container,block" json:"init_containers,omitempty"`
+
+	// files to write to the container before it is started
+	Files Files `hcl:"file,block" json:"files,omitempty"`
+
 	// health checks for the container
 	HealthCheck *healthcheck.HealthCheckContainer `hcl:"health_check,block" json:"health_check,omitempty"`
 
@@ -84,6 +98,15 @@ type GPU struct {
 	DeviceIDs []string `hcl:"device_ids" json:"device_ids"` // device ids to use for the GPU
 }
 
+// Ulimit sets the soft and hard limits for a resource e.g. nofile
+type Ulimit struct {
+	Name string `hcl:"name" json:"name"` // name of the ulimit e.g. nofile, nproc, memlock
+	Soft int    `hcl:"soft" json:"soft"` // soft limit for the resource
+	Hard int    `hcl:"hard" json:"hard"` // hard limit for the resource
+}
+
+type Ulimits []Ulimit
+
 type Capabilities struct {
 	Add  []string `hcl:"add,optional" json:"add"`   // CapAdd is a list of kernel capabilities to add to the container
 	Drop []string `hcl:"drop,optional" json:"drop"` // CapDrop is a list of kernel capabilities to remove from the container
@@ -98,10 +121,34 @@ type Volume struct {
 	BindPropagation             string `hcl:"bind_propagation,optional" json:"bind_propagation,omitempty"`                             // propagation mode for bind mounts [shared, private, slave, rslave, rprivate]
 	BindPropagationNonRecursive bool   `hcl:"bind_propagation_non_recursive,optional" json:"bind_propagation_non_recursive,omitempty"` // recursive bind mount, default true
 	SelinuxRelabel              string `hcl:"selinux_relabel,optional" json:"selinux_relabel,omitempty"`                               // selinux_relabeling ["", shared, private]
+	TmpfsSize                   int    `hcl:"tmpfs_size,optional" json:"tmpfs_size,omitempty"`                                         // size of a tmpfs mount in MB, default unlimited
+	TmpfsMode                   string `hcl:"tmpfs_mode,optional" json:"tmpfs_mode,omitempty"`                                         // file mode of a tmpfs mount in octal e.g. 1777
 }
 
 type Volumes []Volume
 
+// validateTmpfs checks that the tmpfs options are only set for tmpfs volumes
+// and that the mode is a valid octal file mode
+func (v Volume) validateTmpfs() error {
+	t := v.Type
+	if t == "" {
+		t = "bind"
+	}
+
+	if t != "tmpfs" && (v.TmpfsSize != 0 || v.TmpfsMode != "") {
+		return fmt.Errorf("tmpfs_size and tmpfs_mode can only be set for volumes with the type tmpfs, volume %s has the type %s", v.Destination, t)
+	}
+
+	if v.TmpfsMode != "" {
+		_, err := strconv.ParseUint(v.TmpfsMode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid tmpfs_mode %s for volume %s, the mode must be specified in octal e.g. 1777", v.TmpfsMode, v.Destination)
+		}
+	}
+
+	return nil
+}
+
 func (c *Container) Process() error {
 	// process volumes
 	for i, v := range c.Volumes {
@@ -109,6 +156,21 @@ func (c *Container) Process() error {
 		if v.Type == "" || v.Type == "bind" {
 			c.Volumes[i].Source = utils.EnsureAbsolute(v.Source, c.Meta.File)
 		}
+
+		err := v.validateTmpfs()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.InitContainers.process(c.Meta.File)
+	if err != nil {
+		return err
+	}
+
+	err = c.Files.Process(c.Meta.File)
+	if err != nil {
+		return err
 	}
 
 	// make sure line endings are linux
@@ -131,6 +193,12 @@ func (c *Container) Process() error {
 			// add the image id from state
 			c.Image.ID = kstate.Image.ID
 
+			// add the init container exit codes
+			c.InitContainers.setOutputs(kstate.InitContainers)
+
+			// add the file checksums
+			c.Files.SetChecksums(kstate.Files)
+
 			// add the network addresses
 			for _, a := range kstate.Networks {
 				for i, m := range c.Networks {
@@ -141,6 +209,16 @@ func (c *Container) Process() error {
 					}
 				}
 			}
+
+			// add the host port addresses
+			for _, a := range kstate.Ports {
+				for i, p := range c.Ports {
+					if p.Local == a.Local && p.Host == a.Host && p.Protocol == a.Protocol {
+						c.Ports[i].HostAddress = a.HostAddress
+						break
+					}
+				}
+			}
 		}
 	}
 
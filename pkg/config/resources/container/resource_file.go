@@ -0,0 +1,136 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// File defines a file that is written to the container before it is started,
+// the content of the file can be set inline or read from a source file on the
+// host
+type File struct {
+	Source      string `hcl:"source,optional" json:"source,omitempty"`   // path to a file on the host to copy to the container
+	Content     string `hcl:"content,optional" json:"content,omitempty"` // inline content of the file
+	Destination string `hcl:"destination" json:"destination"`            // absolute path of the file in the container
+	Mode        string `hcl:"mode,optional" json:"mode,omitempty"`       // octal permissions for the file, default 0644
+
+	// Output parameters
+
+	// Checksum is the checksum of the file content when it was last written
+	// to the container, this is used to detect changes to the content
+	Checksum string `hcl:"checksum,optional" json:"checksum,omitempty"`
+}
+
+type Files []File
+
+// Process validates the files and sets the defaults, relative source paths
+// are resolved from the location of the config file
+func (f Files) Process(file string) error {
+	for i, fi := range f {
+		if (fi.Source == "") == (fi.Content == "") {
+			return fmt.Errorf("file %s must specify either source or content", fi.Destination)
+		}
+
+		if !filepath.IsAbs(fi.Destination) {
+			return fmt.Errorf("file destination %s must be an absolute path", fi.Destination)
+		}
+
+		if fi.Mode == "" {
+			f[i].Mode = "0644"
+		}
+
+		_, err := strconv.ParseUint(f[i].Mode, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode %s for file %s, mode must be specified in octal i.e. 0644", f[i].Mode, fi.Destination)
+		}
+
+		if fi.Source != "" {
+			f[i].Source = utils.EnsureAbsolute(fi.Source, file)
+		}
+	}
+
+	return nil
+}
+
+// SetChecksums copies the checksums for the files in the state
+func (f Files) SetChecksums(state Files) {
+	for _, s := range state {
+		for i, fi := range f {
+			if fi.Destination == s.Destination {
+				f[i].Checksum = s.Checksum
+				break
+			}
+		}
+	}
+}
+
+// ToClientFiles reads the content of the files and converts them to the
+// client type, the checksum of each file is updated to the content read
+func (f Files) ToClientFiles() ([]types.File, error) {
+	files := []types.File{}
+
+	for i, fi := range f {
+		content, err := fi.content()
+		if err != nil {
+			return nil, err
+		}
+
+		mode, _ := strconv.ParseUint(fi.Mode, 8, 32)
+
+		files = append(files, types.File{
+			Destination: fi.Destination,
+			Content:     content,
+			Mode:        os.FileMode(mode),
+		})
+
+		f[i].Checksum, err = utils.HashString(content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate checksum for file %s: %w", fi.Destination, err)
+		}
+	}
+
+	return files, nil
+}
+
+// Changed returns the files where the content differs from the content when
+// the files were last written to the container
+func (f Files) Changed() (Files, error) {
+	changed := Files{}
+
+	for _, fi := range f {
+		content, err := fi.content()
+		if err != nil {
+			return nil, err
+		}
+
+		cs, err := utils.HashString(content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate checksum for file %s: %w", fi.Destination, err)
+		}
+
+		if cs != fi.Checksum {
+			changed = append(changed, fi)
+		}
+	}
+
+	return changed, nil
+}
+
+// content returns the content of the file, reading the source when set
+func (fi File) content() (string, error) {
+	if fi.Source == "" {
+		return fi.Content, nil
+	}
+
+	d, err := os.ReadFile(fi.Source)
+	if err != nil {
+		return "", fmt.Errorf("unable to read source %s for file %s: %w", fi.Source, fi.Destination, err)
+	}
+
+	return string(d), nil
+}
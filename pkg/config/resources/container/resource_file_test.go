@@ -0,0 +1,77 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesProcessSetsDefaults(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	f := Files{{Source: "./config.hcl", Destination: "/etc/config.hcl"}}
+
+	err = f.Process("./")
+	require.NoError(t, err)
+
+	require.Equal(t, filepath.Join(wd, "config.hcl"), f[0].Source)
+	require.Equal(t, "0644", f[0].Mode)
+}
+
+func TestFilesProcessReturnsErrorWhenSourceAndContentSet(t *testing.T) {
+	f := Files{{Source: "./config.hcl", Content: "abc", Destination: "/etc/config.hcl"}}
+
+	err := f.Process("./")
+	require.Error(t, err)
+}
+
+func TestFilesProcessReturnsErrorWhenSourceAndContentEmpty(t *testing.T) {
+	f := Files{{Destination: "/etc/config.hcl"}}
+
+	err := f.Process("./")
+	require.Error(t, err)
+}
+
+func TestFilesProcessReturnsErrorWhenDestinationRelative(t *testing.T) {
+	f := Files{{Content: "abc", Destination: "config.hcl"}}
+
+	err := f.Process("./")
+	require.Error(t, err)
+}
+
+func TestFilesProcessReturnsErrorWhenModeInvalid(t *testing.T) {
+	f := Files{{Content: "abc", Destination: "/etc/config.hcl", Mode: "rw-r--r--"}}
+
+	err := f.Process("./")
+	require.Error(t, err)
+}
+
+func TestFilesChangedReturnsFilesWhenSourceChanges(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "config.hcl")
+	err := os.WriteFile(src, []byte("port = 8080"), 0644)
+	require.NoError(t, err)
+
+	f := Files{
+		{Source: src, Destination: "/etc/config.hcl", Mode: "0644"},
+		{Content: "abc", Destination: "/etc/other.hcl", Mode: "0644"},
+	}
+
+	cf, err := f.ToClientFiles()
+	require.NoError(t, err)
+	require.Equal(t, "port = 8080", cf[0].Content)
+
+	changed, err := f.Changed()
+	require.NoError(t, err)
+	require.Len(t, changed, 0)
+
+	err = os.WriteFile(src, []byte("port = 9090"), 0644)
+	require.NoError(t, err)
+
+	changed, err = f.Changed()
+	require.NoError(t, err)
+	require.Len(t, changed, 1)
+	require.Equal(t, "/etc/config.hcl", changed[0].Destination)
+}
@@ -0,0 +1,82 @@
+package container
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// InitContainer defines a container that must run to completion before the
+// main container is created, init containers run in the order they are
+// defined and share the volumes and networks of the main container
+type InitContainer struct {
+	Name        string            `hcl:"name,label" json:"name"`                            // name of the init container
+	Image       Image             `hcl:"image,block" json:"image"`                          // image to use for the init container
+	Entrypoint  []string          `hcl:"entrypoint,optional" json:"entrypoint,omitempty"`   // entrypoint to use when starting the init container
+	Command     []string          `hcl:"command,optional" json:"command,omitempty"`         // command to use when starting the init container
+	Environment map[string]string `hcl:"environment,optional" json:"environment,omitempty"` // environment variables to set when starting the init container
+	Volumes     []Volume          `hcl:"volume,block" json:"volumes,omitempty"`             // additional volumes to attach to the init container
+	RunAs       *User             `hcl:"run_as,block" json:"run_as,omitempty"`              // user to run the init container as
+	Timeout     string            `hcl:"timeout,optional" json:"timeout,omitempty"`         // maximum time to wait for the init container to complete, default 300s
+
+	// Output parameters
+
+	// ExitCode is the exit code returned by the init container
+	ExitCode int `hcl:"exit_code,optional" json:"exit_code,omitempty"`
+}
+
+type InitContainers []InitContainer
+
+// InitContainerName returns the fully qualified name of the init container for
+// the container with the given fqdn
+func InitContainerName(name, fqdn string) string {
+	return fmt.Sprintf("%s.init.%s", name, fqdn)
+}
+
+// process sets the defaults for the init containers and validates the config
+func (ic InitContainers) process(file string) error {
+	names := map[string]bool{}
+
+	for i, c := range ic {
+		if names[c.Name] {
+			return fmt.Errorf("init container %s is defined more than once, init container names must be unique", c.Name)
+		}
+
+		names[c.Name] = true
+
+		if c.Timeout == "" {
+			ic[i].Timeout = "300s"
+		}
+
+		_, err := time.ParseDuration(ic[i].Timeout)
+		if err != nil {
+			return fmt.Errorf("unable to parse timeout for init container %s, please specify as a go duration i.e 30s, 1m: %s", c.Name, err)
+		}
+
+		for n, v := range c.Volumes {
+			if v.Type == "" || v.Type == "bind" {
+				ic[i].Volumes[n].Source = utils.EnsureAbsolute(v.Source, file)
+			}
+
+			err := v.validateTmpfs()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setOutputs copies the output parameters from the init containers in the state
+func (ic InitContainers) setOutputs(state InitContainers) {
+	for _, s := range state {
+		for i, c := range ic {
+			if c.Name == s.Name {
+				ic[i].ExitCode = s.ExitCode
+				break
+			}
+		}
+	}
+}
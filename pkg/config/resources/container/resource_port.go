@@ -7,6 +7,12 @@ type Port struct {
 	Host          string `hcl:"host,optional" json:"host,omitempty"`                                            // Host port
 	Protocol      string `hcl:"protocol,optional" json:"protocol,omitempty"`                                    // Protocol tcp, udp
 	OpenInBrowser string `hcl:"open_in_browser,optional" json:"open_in_browser" mapstructure:"open_in_browser"` // When a host port is defined open this port with the given path in a browser
+
+	// Output parameters
+
+	// HostAddress is the address where the host port can be reached, when the
+	// Docker host is remote this is the address of the remote engine
+	HostAddress string `hcl:"host_address,optional" json:"host_address,omitempty"`
 }
 
 type Ports []Port
@@ -1,7 +1,6 @@
 package container
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -13,11 +12,12 @@ const TypeSidecar string = "sidecar"
 // Sidecar defines a structure for creating Docker containers
 type Sidecar struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Target Container `hcl:"target" json:"target"`
 
 	Image       Image             `hcl:"image,block" json:"image"`                          // image to use for the container
+	Platform    string            `hcl:"platform,optional" json:"platform,omitempty"`       // platform of the image e.g. linux/arm64, defaults to the platform of the engine
 	Entrypoint  []string          `hcl:"entrypoint,optional" json:"entrypoint,omitempty"`   // entrypoint to use when starting the container
 	Command     []string          `hcl:"command,optional" json:"command,omitempty"`         // command to use when starting the container
 	Environment map[string]string `hcl:"environment,optional" json:"environment,omitempty"` // environment variables to set when starting the container
@@ -29,11 +29,23 @@ type Sidecar struct {
 	// resource constraints
 	Resources *Resources `hcl:"resources,block" json:"resources,omitempty"` // resource constraints for the container
 
+	// init containers that must complete before the sidecar is created
+	InitContainers InitContainers `hcl:"init_container,block" json:"init_containers,omitempty"`
+
+	// files to write to the container before it is started
+	Files Files `hcl:"file,block" json:"files,omitempty"`
+
 	// health checks for the container
 	HealthCheck *healthcheck.HealthCheckContainer `hcl:"health_check,block" json:"health_check,omitempty"`
 
 	MaxRestartCount int `hcl:"max_restart_count,optional" json:"max_restart_count,omitempty"`
 
+	Ulimits     []Ulimit          `hcl:"ulimit,block" json:"ulimits,omitempty"`               // ulimits to set for the container
+	Sysctls     map[string]string `hcl:"sysctls,optional" json:"sysctls,omitempty"`           // kernel parameters to set in the container namespace
+	ShmSize     int               `hcl:"shm_size,optional" json:"shm_size,omitempty"`         // size of /dev/shm in MB
+	StopSignal  string            `hcl:"stop_signal,optional" json:"stop_signal,omitempty"`   // signal sent to the container to stop it e.g. SIGINT
+	StopTimeout int               `hcl:"stop_timeout,optional" json:"stop_timeout,omitempty"` // seconds to wait for the container to stop before it is killed
+
 	// Output parameters
 
 	// ContainerName is the fully qualified domain name for the container the sidecar is linked to, this can be used
@@ -48,6 +60,21 @@ func (c *Sidecar) Process() error {
 		if v.Type == "" || v.Type == "bind" {
 			c.Volumes[i].Source = utils.EnsureAbsolute(v.Source, c.Meta.File)
 		}
+
+		err := v.validateTmpfs()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.InitContainers.process(c.Meta.File)
+	if err != nil {
+		return err
+	}
+
+	err = c.Files.Process(c.Meta.File)
+	if err != nil {
+		return err
 	}
 
 	// do we have an existing resource in the state?
@@ -62,6 +89,12 @@ func (c *Sidecar) Process() error {
 
 			// add the image id from state
 			c.Image.ID = kstate.Image.ID
+
+			// add the init container exit codes
+			c.InitContainers.setOutputs(kstate.InitContainers)
+
+			// add the file checksums
+			c.Files.SetChecksums(kstate.Files)
 		}
 	}
 
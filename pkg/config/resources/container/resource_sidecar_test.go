@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,7 +14,7 @@ func TestContainerProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Container{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Volumes: []Volume{
 			{
 				Source:      "./",
@@ -26,3 +27,66 @@ func TestContainerProcessSetsAbsolute(t *testing.T) {
 
 	require.Equal(t, wd, c.Volumes[0].Source)
 }
+
+func TestContainerProcessReturnsErrorWhenTmpfsOptionsSetForBind(t *testing.T) {
+	c := &Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Volumes: []Volume{
+			{
+				Source:      "./",
+				Destination: "/data",
+				TmpfsSize:   64,
+			},
+		},
+	}
+
+	err := c.Process()
+	require.Error(t, err)
+}
+
+func TestContainerProcessReturnsErrorWhenTmpfsModeInvalid(t *testing.T) {
+	c := &Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Volumes: []Volume{
+			{
+				Destination: "/tmp",
+				Type:        "tmpfs",
+				TmpfsMode:   "rwx",
+			},
+		},
+	}
+
+	err := c.Process()
+	require.Error(t, err)
+}
+
+func TestContainerProcessSetsInitContainerDefaults(t *testing.T) {
+	c := &Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		InitContainers: InitContainers{
+			{
+				Name:    "migrate",
+				Volumes: []Volume{{Source: "./", Destination: "/migrations"}},
+			},
+		},
+	}
+
+	err := c.Process()
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	require.Equal(t, "300s", c.InitContainers[0].Timeout)
+	require.Equal(t, wd, c.InitContainers[0].Volumes[0].Source)
+}
+
+func TestContainerProcessReturnsErrorWhenInitContainerNamesNotUnique(t *testing.T) {
+	c := &Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		InitContainers:  InitContainers{{Name: "migrate"}, {Name: "migrate"}},
+	}
+
+	err := c.Process()
+	require.Error(t, err)
+}
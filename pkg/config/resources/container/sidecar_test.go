@@ -19,7 +19,7 @@ func TestSidecarProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Sidecar{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Volumes: []Volume{
 			{
 				Source:      "./",
@@ -50,10 +50,12 @@ func TestSidecarLoadsValuesFromState(t *testing.T) {
 }`)
 
 	docs := &Sidecar{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				File: "./",
-				ID:   "resource.sidecar.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					File: "./",
+					ID:   "resource.sidecar.test",
+				},
 			},
 		},
 	}
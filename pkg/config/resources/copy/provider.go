@@ -12,11 +12,14 @@ import (
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/getter"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 	cp "github.com/otiai10/copy"
 )
 
+var _ config.Updater = &Provider{}
+
 type Provider struct {
 	log    sdk.Logger
 	config *Copy
@@ -131,6 +134,13 @@ func (p *Provider) Create(ctx context.Context) error {
 		os.Chmod(p.config.Destination, originalPerms)
 	}
 
+	cs, err := p.sourceChecksum()
+	if err != nil {
+		return fmt.Errorf("unable to generate checksum for copy resource, ref=%s: %w", p.config.Meta.Name, err)
+	}
+
+	p.config.Checksum = cs
+
 	return nil
 }
 
@@ -167,7 +177,74 @@ func (p *Provider) Refresh(ctx context.Context) error {
 	return nil
 }
 
+// Update copies the source files over the existing destination and removes
+// any previously copied files that no longer exist in the source
+func (p *Provider) Update(ctx context.Context) error {
+	if ctx.Err() != nil {
+		p.log.Debug("Context is cacncelled, skipping update", "ref", p.config.Meta.ID)
+		return nil
+	}
+
+	previous := p.config.CopiedFiles
+
+	err := p.Create(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, f := range p.config.CopiedFiles {
+		current[f] = true
+	}
+
+	for _, f := range previous {
+		if current[f] {
+			continue
+		}
+
+		fn := strings.Replace(f, p.config.Source, p.config.Destination, -1)
+		p.log.Debug("Remove file no longer in source", "ref", p.config.Meta.Name, "file", fn)
+
+		// double check that the replacement has worked, we do not want to remove the original
+		if fn != f {
+			err := os.RemoveAll(fn)
+			if err != nil {
+				p.log.Debug("Unable to remove file", "ref", p.config.Meta.Name, "file", fn)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Changed returns true when the local source files have changed since they
+// were copied, remote sources are not checked
 func (p *Provider) Changed() (bool, error) {
 	p.log.Debug("Checking changes", "ref", p.config.Meta.Name)
-	return false, nil
+
+	if p.config.Checksum == "" {
+		return false, nil
+	}
+
+	cs, err := p.sourceChecksum()
+	if err != nil {
+		return false, err
+	}
+
+	return cs != "" && cs != p.config.Checksum, nil
+}
+
+// sourceChecksum returns a checksum of the source when it is a local file or
+// folder, for remote sources an empty string is returned
+func (p *Provider) sourceChecksum() (string, error) {
+	fi, err := os.Stat(p.config.Source)
+	if err != nil {
+		return "", nil
+	}
+
+	if fi.IsDir() {
+		return utils.HashDir(p.config.Source)
+	}
+
+	return utils.HashFile(p.config.Source)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/getter"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,7 +26,7 @@ func setupCopy(t *testing.T) (*Copy, *Provider) {
 	os.WriteFile(path.Join(inDir, "file1.txt"), []byte("file1"), 0755)
 	os.WriteFile(path.Join(inDir, "file2.txt"), []byte("file2"), 0755)
 
-	cc := &Copy{ResourceBase: types.ResourceBase{Meta: types.Meta{ID: "tests"}}}
+	cc := &Copy{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{ID: "tests"}}}}
 	cc.Source = inDir
 	cc.Destination = outDir
 
@@ -106,3 +107,39 @@ func TestFetchesRemoteFiles(t *testing.T) {
 
 	require.FileExists(t, path.Join(c.Destination, "README.md"))
 }
+
+func TestChangedReturnsTrueWhenSourceChanges(t *testing.T) {
+	c, p := setupCopy(t)
+
+	err := p.Create(context.Background())
+	require.NoError(t, err)
+
+	changed, err := p.Changed()
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	os.WriteFile(path.Join(c.Source, "file1.txt"), []byte("updated"), 0755)
+
+	changed, err = p.Changed()
+	require.NoError(t, err)
+	require.True(t, changed)
+}
+
+func TestUpdateCopiesChangesAndRemovesDeletedFiles(t *testing.T) {
+	c, p := setupCopy(t)
+
+	err := p.Create(context.Background())
+	require.NoError(t, err)
+
+	os.WriteFile(path.Join(c.Source, "file1.txt"), []byte("updated"), 0755)
+	os.Remove(path.Join(c.Source, "file2.txt"))
+
+	err = p.Update(context.Background())
+	require.NoError(t, err)
+
+	d, err := os.ReadFile(path.Join(c.Destination, "file1.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "updated", string(d))
+
+	require.NoFileExists(t, path.Join(c.Destination, "file2.txt"))
+}
@@ -3,7 +3,6 @@ package copy
 import (
 	"os"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
@@ -15,7 +14,7 @@ const TypeCopy string = "copy"
 // online tutorials or documentation
 type Copy struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Depends []string `hcl:"depends_on,optional" json:"depends,omitempty"`
 
@@ -25,6 +24,9 @@ type Copy struct {
 
 	// outputs
 	CopiedFiles []string `hcl:"copied_files,optional" json:"copied_files"`
+
+	// Checksum of the local source used to detect when the files need to be copied again
+	Checksum string `hcl:"checksum,optional" json:"checksum,omitempty"`
 }
 
 func (t *Copy) Process() error {
@@ -43,6 +45,7 @@ func (t *Copy) Process() error {
 		if r != nil {
 			kstate := r.(*Copy)
 			t.CopiedFiles = kstate.CopiedFiles
+			t.Checksum = kstate.Checksum
 		}
 	}
 
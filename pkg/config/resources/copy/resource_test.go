@@ -19,9 +19,9 @@ func TestCopyProcessSetsAbsoluteIfLocal(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Copy{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Source:       "./",
-		Destination:  "./",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Source:          "./",
+		Destination:     "./",
 	}
 
 	c.Process()
@@ -35,9 +35,9 @@ func TestCopyProcessSetsAbsoluteIfNotLocal(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Copy{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Source:       "github.com/jumppad-labs/jumppad",
-		Destination:  "./",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Source:          "github.com/jumppad-labs/jumppad",
+		Destination:     "./",
 	}
 
 	c.Process()
@@ -63,10 +63,12 @@ func TestCopySetsOutputsFromState(t *testing.T) {
 }`)
 
 	c := &Copy{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				ID:   "resource.copy.test",
-				File: "./",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					ID:   "resource.copy.test",
+					File: "./",
+				},
 			},
 		},
 		Source:      "./",
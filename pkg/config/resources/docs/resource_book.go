@@ -1,13 +1,13 @@
 package docs
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
 const TypeBook string = "book"
 
 type Book struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Title    string    `hcl:"title" json:"title"`
 	Chapters []Chapter `hcl:"chapters" json:"chapters"`
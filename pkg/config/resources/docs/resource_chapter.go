@@ -1,13 +1,13 @@
 package docs
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
 const TypeChapter string = "chapter"
 
 type Chapter struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Prerequisites []string `hcl:"prerequisites,optional" json:"prerequisites"`
 
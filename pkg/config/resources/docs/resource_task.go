@@ -1,13 +1,13 @@
 package docs
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
 const TypeTask string = "task"
 
 type Task struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Prerequisites []string    `hcl:"prerequisites,optional" json:"prerequisites"`
 	Config        *Config     `hcl:"config,block" json:"config,omitempty"`
@@ -15,7 +15,7 @@ func init() {
 
 func TestDocsProcessSetsAbsolute(t *testing.T) {
 	h := &Docs{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 	}
 
 	err := h.Process()
@@ -39,10 +39,12 @@ func TestDocsLoadsValuesFromState(t *testing.T) {
 }`)
 
 	docs := &Docs{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				File: "./",
-				ID:   "resource.docs.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					File: "./",
+					ID:   "resource.docs.test",
+				},
 			},
 		},
 	}
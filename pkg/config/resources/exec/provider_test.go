@@ -11,6 +11,7 @@ import (
 	cmdTypes "github.com/jumppad-labs/jumppad/pkg/clients/command/types"
 	containerMocks "github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/jumppad-labs/jumppad/testutils"
@@ -28,7 +29,7 @@ func setupProvider(t *testing.T) (*Exec, *Provider, *commandMocks.Command, *cont
 	dm.On("CopyFromContainer", "abc123", mock.Anything, mock.Anything).Return(nil)
 	dm.On("ExecuteCommand", "abc123", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
 
-	e := &Exec{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test", ID: "resource.exec.test"}}}
+	e := &Exec{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test", ID: "resource.exec.test"}}}}
 	p := &Provider{config: e, log: logger.NewTestLogger(t), command: cm, container: dm}
 
 	return e, p, cm, dm
@@ -83,7 +84,7 @@ func TestDeletesOutput(t *testing.T) {
 }
 
 func TestCopiesOutputInExec(t *testing.T) {
-	c := &container.Container{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test", ID: "container.exec.test"}}}
+	c := &container.Container{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test", ID: "container.exec.test"}}}}
 
 	e, p, _, dm := setupProvider(t)
 	e.Target = c
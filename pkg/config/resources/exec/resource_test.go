@@ -32,9 +32,11 @@ func TestExecSetsOutputsFromState(t *testing.T) {
 }`)
 
 	c := &Exec{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				ID: "resource.exec.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					ID: "resource.exec.test",
+				},
 			},
 		},
 	}
@@ -49,7 +51,7 @@ func TestExecProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Exec{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Image: &ctypes.Image{
 			Name: "test",
 		},
@@ -68,7 +70,7 @@ func TestExecProcessSetsAbsolute(t *testing.T) {
 
 func TestExecLocalWithVolumesReturnsError(t *testing.T) {
 	c := &Exec{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Volumes: []ctypes.Volume{
 			{
 				Source:      "./",
@@ -83,7 +85,7 @@ func TestExecLocalWithVolumesReturnsError(t *testing.T) {
 
 func TestExecLocalWithNetworksReturnsError(t *testing.T) {
 	c := &Exec{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Networks: []ctypes.NetworkAttachment{
 			{
 				Name: "test",
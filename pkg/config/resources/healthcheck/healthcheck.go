@@ -1,7 +1,9 @@
 package healthcheck
 
 // HealthCheckContainer is an internal block for configuration which
-// allows the user to define the criteria for successful creation
+// allows the user to define the criteria for successful creation.
+// After creation exec checks are run periodically by the container engine,
+// HTTP and TCP checks are only run again by `jumppad status`
 type HealthCheckContainer struct {
 	// Timeout expressed as a go duration i.e 10s
 	Timeout string `hcl:"timeout" json:"timeout"`
@@ -29,7 +31,8 @@ type HealthCheckExec struct {
 	// Command to execute, the command is run in the target container
 	Command []string `hcl:"command,optional" json:"command,omitempty"`
 	// Script specified as a string to execute, the script can be a bash or a sh script
-	// scripts are copied to the container /tmp directory, marked as executable and run
+	// scripts are copied to the container /tmp directory, marked as executable and run.
+	// Scripts, non zero exit codes and multiple checks require sh in the image
 	Script string `hcl:"script,optional" json:"script,omitempty"`
 	// ExitCode to mark a successful check, default 0
 	ExitCode int `hcl:"exit_code,optional" json:"exit_code,omitempty"`
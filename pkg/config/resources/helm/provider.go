@@ -11,11 +11,13 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/clients/helm"
 	"github.com/jumppad-labs/jumppad/pkg/clients/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
 
 var _ sdk.Provider = &Provider{}
+var _ config.Updater = &Provider{}
 
 type Provider struct {
 	config       *Helm
@@ -57,6 +59,123 @@ func (p *Provider) Create(ctx context.Context) error {
 
 	p.log.Info("Creating Helm chart", "ref", p.config.Meta.ID)
 
+	// generate the checksum before the chart is resolved to a local path
+	cs, err := p.checksum()
+	if err != nil {
+		return fmt.Errorf("unable to generate checksum: %w", err)
+	}
+
+	err = p.setup()
+	if err != nil {
+		return err
+	}
+
+	// sanitize the chart name
+	newName, _ := utils.ReplaceNonURIChars(p.config.Meta.Name)
+
+	to := time.Duration(300 * time.Second)
+	if p.config.Timeout != "" {
+		to, err = time.ParseDuration(p.config.Timeout)
+		if err != nil {
+			return fmt.Errorf("unable to parse timeout duration: %w", err)
+		}
+	}
+
+	timeout := time.After(to)
+	errChan := make(chan error, 1)
+	doneChan := make(chan struct{}, 1)
+
+	// failed installs are retried by the engine when the resource defines
+	// a retry block
+	go func() {
+		err := p.helmClient.Create(
+			p.config.Cluster.KubeConfig.ConfigPath,
+			newName,
+			p.config.Namespace,
+			p.config.CreateNamespace,
+			p.config.SkipCRDs,
+			p.config.Chart,
+			p.config.Version,
+			p.config.Values,
+			p.config.ValuesString)
+
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		doneChan <- struct{}{}
+	}()
+
+	select {
+	case <-timeout:
+		return fmt.Errorf("timeout waiting for helm chart to complete")
+	case createErr := <-errChan:
+		return createErr
+	case <-doneChan:
+		p.log.Debug("Helm chart applied", "ref", p.config.Meta.Name)
+	}
+
+	err = p.healthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.config.Checksum = cs
+
+	return nil
+}
+
+// Update upgrades the existing Helm release in place with the current chart
+// and values
+func (p *Provider) Update(ctx context.Context) error {
+	if ctx.Err() != nil {
+		p.log.Debug("Skipping update, context cancelled", "ref", p.config.Meta.ID)
+		return nil
+	}
+
+	p.log.Info("Upgrading Helm chart", "ref", p.config.Meta.ID)
+
+	cs, err := p.checksum()
+	if err != nil {
+		return fmt.Errorf("unable to generate checksum: %w", err)
+	}
+
+	err = p.setup()
+	if err != nil {
+		return err
+	}
+
+	// sanitize the chart name
+	newName, _ := utils.ReplaceNonURIChars(p.config.Meta.Name)
+
+	err = p.helmClient.Upgrade(
+		p.config.Cluster.KubeConfig.ConfigPath,
+		newName,
+		p.config.Namespace,
+		p.config.SkipCRDs,
+		p.config.Chart,
+		p.config.Version,
+		p.config.Values,
+		p.config.ValuesString)
+
+	if err != nil {
+		return fmt.Errorf("unable to upgrade Helm chart: %w", err)
+	}
+
+	err = p.healthCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.config.Checksum = cs
+
+	return nil
+}
+
+// setup configures the chart repository, downloads remote charts and
+// configures the Kubernetes client
+func (p *Provider) setup() error {
 	// if the namespace is null set to default
 	if p.config.Namespace == "" {
 		p.config.Namespace = "default"
@@ -96,83 +215,47 @@ func (p *Provider) Create(ctx context.Context) error {
 		return fmt.Errorf("unable to create Kubernetes client: %w", err)
 	}
 
-	// sanitize the chart name
-	newName, _ := utils.ReplaceNonURIChars(p.config.Meta.Name)
-
-	failCount := 0
+	return nil
+}
 
-	to := time.Duration(300 * time.Second)
-	if p.config.Timeout != "" {
-		to, err = time.ParseDuration(p.config.Timeout)
-		if err != nil {
-			return fmt.Errorf("unable to parse timeout duration: %w", err)
-		}
+// healthCheck waits for the pods defined in the health check to be running
+func (p *Provider) healthCheck(ctx context.Context) error {
+	if p.config.HealthCheck == nil || len(p.config.HealthCheck.Pods) == 0 {
+		return nil
 	}
 
-	timeout := time.After(to)
-	errChan := make(chan error)
-	doneChan := make(chan struct{})
-
-	go func() {
-		for {
-
-			// context is cancelled do not retry
-			if ctx.Err() != nil {
-				p.log.Debug("Skipping create, context cancelled", "ref", p.config.Meta.ID)
-				err := fmt.Errorf("context cancelled, skipping helm chart creation, ref: %s", p.config.Meta.ID)
-				errChan <- err
-			}
-
-			err = p.helmClient.Create(
-				p.config.Cluster.KubeConfig.ConfigPath,
-				newName,
-				p.config.Namespace,
-				p.config.CreateNamespace,
-				p.config.SkipCRDs,
-				p.config.Chart,
-				p.config.Version,
-				p.config.Values,
-				p.config.ValuesString)
-
-			if err == nil {
-				doneChan <- struct{}{}
-				break
-			}
-
-			failCount++
-
-			if failCount >= p.config.Retry {
-				errChan <- err
-			} else {
-				p.log.Debug("Chart apply failed, retrying", "error", err)
-				time.Sleep(5 * time.Second)
-			}
-		}
-	}()
+	to, err := time.ParseDuration(p.config.HealthCheck.Timeout)
+	if err != nil {
+		return fmt.Errorf("unable to parse health check duration: %w", err)
+	}
 
-	select {
-	case <-timeout:
-		return fmt.Errorf("timeout waiting for helm chart to complete")
-	case createErr := <-errChan:
-		return createErr
-	case <-doneChan:
-		p.log.Debug("Helm chart applied", "ref", p.config.Meta.Name)
+	err = p.kubeClient.HealthCheckPods(ctx, p.config.HealthCheck.Pods, to)
+	if err != nil {
+		return fmt.Errorf("health check failed after helm chart setup: %w", err)
 	}
 
-	// we can now health check the install
-	if p.config.HealthCheck != nil && len(p.config.HealthCheck.Pods) > 0 {
-		to, err := time.ParseDuration(p.config.HealthCheck.Timeout)
-		if err != nil {
-			return fmt.Errorf("unable to parse health check duration: %w", err)
-		}
+	return nil
+}
 
-		err = p.kubeClient.HealthCheckPods(ctx, p.config.HealthCheck.Pods, to)
+// checksum generates a checksum of the chart, version and values used to
+// detect when the release needs to be upgraded
+func (p *Provider) checksum() (string, error) {
+	valuesFile := ""
+	if p.config.Values != "" {
+		var err error
+		valuesFile, err = utils.HashFile(p.config.Values)
 		if err != nil {
-			return fmt.Errorf("health check failed after helm chart setup: %w", err)
+			return "", err
 		}
 	}
 
-	return nil
+	return utils.ChecksumFromInterface(map[string]interface{}{
+		"chart":         p.config.Chart,
+		"version":       p.config.Version,
+		"namespace":     p.config.Namespace,
+		"values":        valuesFile,
+		"values_string": p.config.ValuesString,
+	})
 }
 
 // Destroy implements the provider Destroy method
@@ -218,8 +301,20 @@ func (p *Provider) Refresh(ctx context.Context) error {
 	return nil
 }
 
+// Changed returns true when the chart, version or values have changed since
+// the release was installed
 func (p *Provider) Changed() (bool, error) {
 	p.log.Debug("Checking changes", "ref", p.config.Meta.Name)
 
-	return false, nil
+	// resources created before checksums were added are not upgraded
+	if p.config.Checksum == "" {
+		return false, nil
+	}
+
+	cs, err := p.checksum()
+	if err != nil {
+		return false, err
+	}
+
+	return cs != p.config.Checksum, nil
 }
@@ -1,7 +1,7 @@
 package helm
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -12,7 +12,8 @@ const TypeHelm string = "helm"
 
 // Helm defines configuration for running Helm charts
 type Helm struct {
-	types.ResourceBase `hcl:",remain"`
+	// embedded type holding name, lifecycle and retry blocks
+	config.ResourceOptions `hcl:",remain"`
 
 	Depends []string `hcl:"depends_on,optional" json:"depends,omitempty"`
 
@@ -39,14 +40,17 @@ type Helm struct {
 	// Skip the install of any CRDs
 	SkipCRDs bool `hcl:"skip_crds,optional" json:"skip_crds,omitempty"`
 
-	// Retry the install n number of times
-	Retry int `hcl:"retry,optional" json:"retry,omitempty"`
-
 	// Timeout specifies the maximum time a chart can run, default 300s
 	Timeout string `hcl:"timeout,optional" json:"timeout"`
 
 	// Define health checks for the pods deployed by the chart
 	HealthCheck *healthcheck.HealthCheckKubernetes `hcl:"health_check,block" json:"health_check,omitempty"`
+
+	// output
+
+	// Checksum of the chart, version and values used to detect when the
+	// release needs to be upgraded
+	Checksum string `hcl:"checksum,optional" json:"checksum,omitempty"`
 }
 
 type HelmRepository struct {
@@ -64,5 +68,15 @@ func (h *Helm) Process() error {
 		h.Values = utils.EnsureAbsolute(h.Values, h.Meta.File)
 	}
 
+	cfg, err := config.LoadState()
+	if err == nil {
+		// try and find the resource in the state
+		r, _ := cfg.FindResource(h.Meta.ID)
+		if r != nil {
+			state := r.(*Helm)
+			h.Checksum = state.Checksum
+		}
+	}
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,9 +15,9 @@ func TestHelmProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	h := &Helm{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Chart:        "./",
-		Values:       "./values.yaml",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Chart:           "./",
+		Values:          "./values.yaml",
 	}
 
 	err = h.Process()
@@ -11,11 +11,12 @@ import (
 
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
 func setupHttp(t *testing.T) (*HTTP, *Provider) {
-	h := &HTTP{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}
+	h := &HTTP{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}}
 
 	p := &Provider{h, logger.NewTestLogger(t), *http.DefaultClient}
 
@@ -1,14 +1,13 @@
 package http
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
 const TypeHTTP string = "http"
 
 type HTTP struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Method string `hcl:"method" json:"method"`
 	URL    string `hcl:"url" json:"url"`
@@ -22,6 +21,10 @@ type HTTP struct {
 	Body   string `hcl:"body,optional" json:"body"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the request does not
+// create anything that is removed on destroy
+func (t *HTTP) Replaceable() {}
+
 func (t *HTTP) Process() error {
 	cfg, err := config.LoadState()
 	if err == nil {
@@ -13,7 +13,7 @@ const TypeIngress string = "ingress"
 
 // Ingress defines an ingress service mapping ports between local host and resources like containers and kube cluster
 type Ingress struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	// local port to expose the service on
 	Port int `hcl:"port" json:"port"`
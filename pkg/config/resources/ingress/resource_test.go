@@ -31,9 +31,11 @@ func TestIngressSetsOutputsFromState(t *testing.T) {
 }`)
 
 	c := &Ingress{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				ID: "resource.ingress.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					ID: "resource.ingress.test",
+				},
 			},
 		},
 	}
@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+)
+
+// auditPolicyPath is the location of the audit policy on the server
+const auditPolicyPath = "/etc/jumppad/audit-policy.yaml"
+
+// auditLogPath is the location of the audit log on the server
+const auditLogPath = "/var/log/kubernetes/audit.log"
+
+// driver implements the parts of creating a cluster that are specific to the
+// Kubernetes distribution
+type driver interface {
+	// generateToken returns a new token used to join agent nodes to the server
+	generateToken() (string, error)
+
+	// configureNode adds the settings shared by the server and agent nodes to
+	// the container config, i.e. registry mirrors and volumes
+	configureNode(cc *ctypes.Container, v *semver.Version) error
+
+	// configureServer adds the settings for the server node to the container
+	// config
+	configureServer(cc *ctypes.Container, v *semver.Version) error
+
+	// configureAgent adds the settings for an agent node to the container
+	// config
+	configureAgent(cc *ctypes.Container, v *semver.Version) error
+
+	// startServer waits for the server container to start and bootstraps the
+	// control plane
+	startServer(ctx context.Context, id string) error
+
+	// startAgent waits for the agent container to start and joins it to the
+	// server
+	startAgent(ctx context.Context, id string) error
+
+	// removeNode removes any resources that were created for the node by
+	// configureNode once the container has been removed
+	removeNode(name string) error
+
+	// kubeConfig returns the location of the admin kubeconfig in the server
+	// container and the server address in the file that is replaced with the
+	// address of the docker host
+	kubeConfig() (string, string)
+
+	// ctr returns the command used to manage the images in containerd
+	ctr() []string
+
+	// kubectl returns the command used to run kubectl on the server
+	kubectl() []string
+}
+
+// driver returns the driver for the distribution of the cluster
+func (p *ClusterProvider) driver() driver {
+	if p.config.Distribution == DistributionKind {
+		return &kindDriver{p}
+	}
+
+	return &k3sDriver{p}
+}
+
+// featureGates returns the feature gates in the format used by the
+// --feature-gates flag i.e. A=true,B=false
+func featureGates(fg map[string]bool) string {
+	keys := make([]string, 0, len(fg))
+	for k := range fg {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	gates := []string{}
+	for _, k := range keys {
+		gates = append(gates, fmt.Sprintf("%s=%t", k, fg[k]))
+	}
+
+	return strings.Join(gates, ",")
+}
+
+// flagValue splits a flag in the format --name=value into the name and the
+// value
+func flagValue(flag string) (string, string) {
+	parts := strings.SplitN(strings.TrimLeft(flag, "-"), "=", 2)
+	if len(parts) == 1 {
+		return parts[0], "true"
+	}
+
+	return parts[0], parts[1]
+}
+
+func contains(s []string, v string) bool {
+	for _, i := range s {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
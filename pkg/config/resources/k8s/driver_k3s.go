@@ -0,0 +1,234 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// k3sDriver creates clusters using the jumppad k3s images
+type k3sDriver struct {
+	p *ClusterProvider
+}
+
+func (d *k3sDriver) generateToken() (string, error) {
+	return secrets.GenerateToken(32)
+}
+
+func (d *k3sDriver) configureNode(cc *ctypes.Container, v *semver.Version) error {
+	// add the registries volume
+	rc, err := d.p.createRegistriesConfig()
+	if err != nil {
+		return fmt.Errorf("unable to create registries.yaml: %s", err)
+	}
+
+	if rc != "" {
+		cc.Volumes = append(cc.Volumes, ctypes.Volume{
+			Source:      rc,
+			Destination: "/etc/rancher/k3s/registries.yaml",
+			Type:        "bind",
+		})
+	}
+
+	// only add the variables for the cache when the kubernetes version is >= v1.18.16
+	sv, err := semver.NewConstraint(">= v1.18.16")
+	if err != nil {
+		// Handle constraint not being parsable.
+		return err
+	}
+
+	if sv.Check(v) {
+		// load the CA from a file
+		ca, err := os.ReadFile(filepath.Join(utils.CertsDir(""), "/root.cert"))
+		if err != nil {
+			return fmt.Errorf("unable to read root CA for proxy: %s", err)
+		}
+
+		cc.Environment["CONTAINERD_HTTP_PROXY"] = utils.ImageCacheAddress()
+		cc.Environment["CONTAINERD_HTTPS_PROXY"] = utils.ImageCacheAddress()
+		cc.Environment["PROXY_CA"] = string(ca)
+
+		// add the no-proxy overrides
+		if d.p.config.Config != nil &&
+			d.p.config.Config.DockerConfig != nil &&
+			len(d.p.config.Config.DockerConfig.NoProxy) > 0 {
+			cc.Environment["CONTAINERD_NO_PROXY"] = strings.Join(d.p.config.Config.DockerConfig.NoProxy, ",")
+		}
+	}
+
+	return nil
+}
+
+func (d *k3sDriver) configureServer(cc *ctypes.Container, v *semver.Version) error {
+	// set the environment variables for the K3S_KUBECONFIG_OUTPUT
+	cc.Environment["K3S_KUBECONFIG_OUTPUT"] = "/output/kubeconfig.yaml"
+
+	sv, err := semver.NewConstraint(">= v1.25.0")
+	if err != nil {
+		// Handle constraint not being parsable.
+		return err
+	}
+
+	disableArgs := "--no-deploy=traefik"
+	clusterToken := ""
+
+	if sv.Check(v) {
+		disableArgs = "--disable=traefik"
+		clusterToken = fmt.Sprintf("--token=%s", d.p.config.ClusterToken)
+	} else {
+		// add the cluster secret as an env this is deprecated in v1.25 and
+		// replaced with --token
+		cc.Environment["K3S_CLUSTER_SECRET"] = d.p.config.ClusterToken
+	}
+
+	// Set the default startup args
+	// Also set netfilter settings to fix behaviour introduced in Linux Kernel 5.12
+	// https://k3d.io/faq/faq/#solved-nodes-fail-to-start-or-get-stuck-in-notready-state-with-log-nf_conntrack_max-permission-denied
+	cc.Command = []string{
+		"server",
+		fmt.Sprintf("--https-listen-port=%d", d.p.config.APIPort),
+		"--kube-proxy-arg=conntrack-max-per-core=0",
+		disableArgs,
+		fmt.Sprintf("--snapshotter=%s", d.p.snapshotter()),
+		fmt.Sprintf("--tls-san=%s", d.p.config.ContainerName), // add the FQDN for the server
+		fmt.Sprintf("--tls-san=%s", utils.GetDockerIP()),      // add the docker host IP
+		clusterToken,
+	}
+
+	return d.configureKubernetes(cc)
+}
+
+// configureKubernetes adds the flags and the files for the kubernetes config
+// to the server container config
+func (d *k3sDriver) configureKubernetes(cc *ctypes.Container) error {
+	k := d.p.config.Kubernetes
+	if k == nil {
+		return nil
+	}
+
+	for _, c := range k.Disable {
+		// traefik is always disabled
+		if c != ComponentTraefik {
+			cc.Command = append(cc.Command, fmt.Sprintf("--disable=%s", c))
+		}
+	}
+
+	if !d.p.cniInstalled() || d.p.cniManifest() != "" {
+		cc.Command = append(cc.Command, "--flannel-backend=none", "--disable-network-policy")
+	}
+
+	// k3s deploys any manifests in the server manifests folder
+	if m := d.p.cniManifest(); m != "" {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			return fmt.Errorf("unable to read CNI manifest: %w", err)
+		}
+
+		cc.Files = append(cc.Files, ctypes.File{
+			Destination: "/var/lib/rancher/k3s/server/manifests/jumppad-cni.yaml",
+			Content:     string(data),
+			Mode:        0644,
+		})
+	}
+
+	if fg := featureGates(k.FeatureGates); fg != "" {
+		cc.Command = append(cc.Command,
+			fmt.Sprintf("--kube-apiserver-arg=feature-gates=%s", fg),
+			fmt.Sprintf("--kube-controller-manager-arg=feature-gates=%s", fg),
+			fmt.Sprintf("--kube-scheduler-arg=feature-gates=%s", fg),
+		)
+	}
+
+	if len(k.AdmissionPlugins) > 0 {
+		cc.Command = append(cc.Command, fmt.Sprintf("--kube-apiserver-arg=enable-admission-plugins=%s", strings.Join(k.AdmissionPlugins, ",")))
+	}
+
+	if k.AuditPolicy != "" {
+		data, err := os.ReadFile(k.AuditPolicy)
+		if err != nil {
+			return fmt.Errorf("unable to read audit policy: %w", err)
+		}
+
+		cc.Files = append(cc.Files, ctypes.File{
+			Destination: auditPolicyPath,
+			Content:     string(data),
+			Mode:        0644,
+		})
+
+		cc.Command = append(cc.Command,
+			fmt.Sprintf("--kube-apiserver-arg=audit-policy-file=%s", auditPolicyPath),
+			fmt.Sprintf("--kube-apiserver-arg=audit-log-path=%s", auditLogPath),
+		)
+	}
+
+	cc.Command = append(cc.Command, k.ServerArgs...)
+	cc.Command = append(cc.Command, d.kubeletArgs()...)
+
+	return nil
+}
+
+// kubeletArgs returns the k3s flags for the kubelet args and feature gates
+func (d *k3sDriver) kubeletArgs() []string {
+	args := []string{}
+
+	k := d.p.config.Kubernetes
+	if k == nil {
+		return args
+	}
+
+	if fg := featureGates(k.FeatureGates); fg != "" {
+		args = append(args, fmt.Sprintf("--kubelet-arg=feature-gates=%s", fg))
+	}
+
+	for _, a := range k.KubeletArgs {
+		args = append(args, fmt.Sprintf("--kubelet-arg=%s", strings.TrimLeft(a, "-")))
+	}
+
+	return args
+}
+
+func (d *k3sDriver) configureAgent(cc *ctypes.Container, v *semver.Version) error {
+	cc.Command = []string{
+		"agent",
+		fmt.Sprintf("--server=https://%s:%d", d.p.config.ContainerName, d.p.config.APIPort),
+		fmt.Sprintf("--node-name=%s", cc.Name),
+		"--kube-proxy-arg=conntrack-max-per-core=0",
+		fmt.Sprintf("--snapshotter=%s", d.p.snapshotter()),
+		fmt.Sprintf("--token=%s", d.p.clusterToken()),
+	}
+
+	cc.Command = append(cc.Command, d.kubeletArgs()...)
+
+	return nil
+}
+
+func (d *k3sDriver) startServer(ctx context.Context, id string) error {
+	return d.p.waitForLog(ctx, id, "Running kubelet")
+}
+
+func (d *k3sDriver) startAgent(ctx context.Context, id string) error {
+	return d.p.waitForLog(ctx, id, "Running kubelet")
+}
+
+func (d *k3sDriver) removeNode(name string) error {
+	return nil
+}
+
+func (d *k3sDriver) kubeConfig() (string, string) {
+	return "/output/kubeconfig.yaml", "https://127.0.0.1"
+}
+
+func (d *k3sDriver) ctr() []string {
+	return []string{"ctr"}
+}
+
+func (d *k3sDriver) kubectl() []string {
+	return []string{"kubectl"}
+}
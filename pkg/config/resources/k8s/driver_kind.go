@@ -0,0 +1,364 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+const kindKubeadmConfigPath = "/kind/kubeadm.conf"
+const kindKubeConfigPath = "/etc/kubernetes/admin.conf"
+const kindPodSubnet = "10.244.0.0/16"
+const kindServiceSubnet = "10.96.0.0/16"
+const kindCNIManifestPath = "/kind/manifests/jumppad-cni.yaml"
+
+// kindDriver creates clusters using the kind node images, the nodes are
+// bootstrapped with kubeadm. The image cache is not used by kind clusters,
+// images are pulled directly by containerd.
+type kindDriver struct {
+	p *ClusterProvider
+}
+
+// generateToken returns a kubeadm bootstrap token in the format
+// [a-z0-9]{6}.[a-z0-9]{16}
+func (d *kindDriver) generateToken() (string, error) {
+	id, err := secrets.GenerateToken(3)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := secrets.GenerateToken(8)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", id, secret), nil
+}
+
+func (d *kindDriver) configureNode(cc *ctypes.Container, v *semver.Version) error {
+	// systemd in the node image requires /var to be a volume and /tmp and
+	// /run to be tmpfs
+	vol, err := d.p.client.CreateVolume(cc.Name)
+	if err != nil {
+		return fmt.Errorf("unable to create volume for node %s: %w", cc.Name, err)
+	}
+
+	cc.Volumes = append(cc.Volumes,
+		ctypes.Volume{
+			Source:      vol,
+			Destination: "/var",
+			Type:        "volume",
+		},
+		ctypes.Volume{
+			Destination: "/tmp",
+			Type:        "tmpfs",
+		},
+		ctypes.Volume{
+			Destination: "/run",
+			Type:        "tmpfs",
+		},
+		ctypes.Volume{
+			Source:      "/lib/modules",
+			Destination: "/lib/modules",
+			Type:        "bind",
+			ReadOnly:    true,
+		},
+	)
+
+	// add the registry mirrors for containerd
+	if d.p.config.Config != nil && d.p.config.Config.DockerConfig != nil {
+		for _, ir := range d.p.config.Config.DockerConfig.InsecureRegistries {
+			cc.Files = append(cc.Files, ctypes.File{
+				Destination: fmt.Sprintf("/etc/containerd/certs.d/%s/hosts.toml", ir),
+				Content:     fmt.Sprintf(kindRegistryHosts, ir, ir),
+				Mode:        0644,
+			})
+		}
+	}
+
+	// the kubelet service reads the extra args from the environment file
+	if d.p.config.Kubernetes != nil && len(d.p.config.Kubernetes.KubeletArgs) > 0 {
+		args := []string{}
+		for _, a := range d.p.config.Kubernetes.KubeletArgs {
+			args = append(args, "--"+strings.TrimLeft(a, "-"))
+		}
+
+		cc.Files = append(cc.Files, ctypes.File{
+			Destination: "/etc/default/kubelet",
+			Content:     fmt.Sprintf("KUBELET_EXTRA_ARGS=%s\n", strings.Join(args, " ")),
+			Mode:        0644,
+		})
+	}
+
+	return nil
+}
+
+func (d *kindDriver) configureServer(cc *ctypes.Container, v *semver.Version) error {
+	apiServer := map[string]string{}
+	apiServerVolumes := ""
+	controlPlane := ""
+	kubelet := ""
+
+	if k := d.p.config.Kubernetes; k != nil {
+		if fg := featureGates(k.FeatureGates); fg != "" {
+			apiServer["feature-gates"] = fg
+			controlPlane = fmt.Sprintf(
+				"controllerManager:\n%sscheduler:\n%s",
+				kubeadmExtraArgs(map[string]string{"feature-gates": fg}),
+				kubeadmExtraArgs(map[string]string{"feature-gates": fg}),
+			)
+
+			kubelet = "featureGates:\n"
+			for _, g := range strings.Split(fg, ",") {
+				n, v := flagValue(g)
+				kubelet += fmt.Sprintf("  %s: %s\n", n, v)
+			}
+		}
+
+		if len(k.AdmissionPlugins) > 0 {
+			apiServer["enable-admission-plugins"] = strings.Join(k.AdmissionPlugins, ",")
+		}
+
+		for _, a := range k.ServerArgs {
+			n, v := flagValue(a)
+			apiServer[n] = v
+		}
+
+		if m := d.p.cniManifest(); m != "" {
+			data, err := os.ReadFile(m)
+			if err != nil {
+				return fmt.Errorf("unable to read CNI manifest: %w", err)
+			}
+
+			cc.Files = append(cc.Files, ctypes.File{
+				Destination: kindCNIManifestPath,
+				Content:     string(data),
+				Mode:        0644,
+			})
+		}
+
+		// the API server runs as a static pod, the audit policy and the log
+		// are mounted from the node
+		if k.AuditPolicy != "" {
+			data, err := os.ReadFile(k.AuditPolicy)
+			if err != nil {
+				return fmt.Errorf("unable to read audit policy: %w", err)
+			}
+
+			cc.Files = append(cc.Files, ctypes.File{
+				Destination: auditPolicyPath,
+				Content:     string(data),
+				Mode:        0644,
+			})
+
+			apiServer["audit-policy-file"] = auditPolicyPath
+			apiServer["audit-log-path"] = auditLogPath
+			apiServerVolumes = fmt.Sprintf(kindAuditVolumes, auditPolicyPath, auditPolicyPath, path.Dir(auditLogPath), path.Dir(auditLogPath))
+		}
+	}
+
+	cc.Files = append(cc.Files, ctypes.File{
+		Destination: kindKubeadmConfigPath,
+		Content: fmt.Sprintf(
+			kindKubeadmConfig,
+			d.p.config.ClusterToken,
+			d.p.config.APIPort,
+			d.p.config.Meta.Name,
+			d.p.config.ContainerName,
+			d.p.config.APIPort,
+			d.p.config.ContainerName,
+			utils.GetDockerIP(),
+			kubeadmExtraArgs(apiServer)+apiServerVolumes,
+			controlPlane,
+			kindPodSubnet,
+			kindServiceSubnet,
+			kubelet,
+		),
+		Mode: 0644,
+	})
+
+	return nil
+}
+
+func (d *kindDriver) configureAgent(cc *ctypes.Container, v *semver.Version) error {
+	return nil
+}
+
+func (d *kindDriver) startServer(ctx context.Context, id string) error {
+	err := d.waitForSystemd(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	kubectl := strings.Join(d.kubectl(), " ")
+
+	// disabled addons are not installed by kubeadm
+	skip := []string{"preflight"}
+	for _, c := range []string{ComponentCoreDNS, ComponentKubeProxy} {
+		if d.p.disabled(c) {
+			skip = append(skip, fmt.Sprintf("addon/%s", c))
+		}
+	}
+
+	commands := [][]string{
+		// bootstrap the control plane
+		{"kubeadm", "init", fmt.Sprintf("--skip-phases=%s", strings.Join(skip, ",")), fmt.Sprintf("--config=%s", kindKubeadmConfigPath)},
+	}
+
+	// install the network and storage provided by the node image
+	switch {
+	case d.p.cniManifest() != "":
+		commands = append(commands, []string{"sh", "-c", fmt.Sprintf("%s apply -f %s", kubectl, kindCNIManifestPath)})
+	case d.p.cniInstalled():
+		commands = append(commands, []string{"sh", "-c", fmt.Sprintf("sed 's#{{ .PodSubnet }}#%s#g' /kind/manifests/default-cni.yaml | %s apply -f -", kindPodSubnet, kubectl)})
+	}
+
+	if !d.p.disabled(ComponentLocalStorage) {
+		commands = append(commands, []string{"sh", "-c", fmt.Sprintf("%s apply -f /kind/manifests/default-storage.yaml", kubectl)})
+	}
+
+	// allow workloads to be scheduled on the server
+	commands = append(commands, []string{"sh", "-c", fmt.Sprintf("%s taint nodes --all node-role.kubernetes.io/control-plane-", kubectl)})
+
+	for _, c := range commands {
+		d.p.log.Debug("Bootstrapping server node", "ref", d.p.config.Meta.ID, "command", c[len(c)-1])
+
+		_, err := d.p.client.ExecuteCommand(id, c, nil, "/", "", "", 300, d.p.log.StandardWriter())
+		if err != nil {
+			return fmt.Errorf("unable to bootstrap server node: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *kindDriver) startAgent(ctx context.Context, id string) error {
+	err := d.waitForSystemd(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	cmd := []string{
+		"kubeadm",
+		"join",
+		"--skip-phases=preflight",
+		fmt.Sprintf("--token=%s", d.p.config.ClusterToken),
+		"--discovery-token-unsafe-skip-ca-verification",
+		fmt.Sprintf("%s:%d", d.p.config.ContainerName, d.p.config.APIPort),
+	}
+
+	_, err = d.p.client.ExecuteCommand(id, cmd, nil, "/", "", "", 300, d.p.log.StandardWriter())
+	if err != nil {
+		return fmt.Errorf("unable to join agent node: %w", err)
+	}
+
+	return nil
+}
+
+// removeNode removes the /var volume for the node
+func (d *kindDriver) removeNode(name string) error {
+	return d.p.client.RemoveVolume(name)
+}
+
+func (d *kindDriver) kubeConfig() (string, string) {
+	return kindKubeConfigPath, fmt.Sprintf("https://%s", d.p.config.ContainerName)
+}
+
+func (d *kindDriver) ctr() []string {
+	return []string{"ctr", "--namespace=k8s.io"}
+}
+
+func (d *kindDriver) kubectl() []string {
+	return []string{"kubectl", fmt.Sprintf("--kubeconfig=%s", kindKubeConfigPath)}
+}
+
+// waitForSystemd waits for the init system in the node to start
+func (d *kindDriver) waitForSystemd(ctx context.Context, id string) error {
+	return d.p.waitForLog(ctx, id, "Multi-User System")
+}
+
+// kubeadmExtraArgs returns the extraArgs block for a kubeadm component
+func kubeadmExtraArgs(args map[string]string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	out := "  extraArgs:\n"
+	for _, k := range keys {
+		out += fmt.Sprintf("    %s: %s\n", k, strconv.Quote(args[k]))
+	}
+
+	return out
+}
+
+var kindAuditVolumes = `  extraVolumes:
+  - name: audit-policy
+    hostPath: "%s"
+    mountPath: "%s"
+    readOnly: true
+    pathType: File
+  - name: audit-log
+    hostPath: "%s"
+    mountPath: "%s"
+    pathType: DirectoryOrCreate
+`
+
+var kindRegistryHosts = `
+server = "http://%s"
+
+[host."http://%s"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+`
+
+var kindKubeadmConfig = `
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: InitConfiguration
+bootstrapTokens:
+- token: "%s"
+  ttl: "0s"
+localAPIEndpoint:
+  bindPort: %d
+---
+apiVersion: kubeadm.k8s.io/v1beta3
+kind: ClusterConfiguration
+clusterName: "%s"
+controlPlaneEndpoint: "%s:%d"
+apiServer:
+  certSANs:
+  - "localhost"
+  - "127.0.0.1"
+  - "%s"
+  - "%s"
+%s%snetworking:
+  podSubnet: "%s"
+  serviceSubnet: "%s"
+---
+apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+cgroupDriver: systemd
+cgroupRoot: /kubelet
+failSwapOn: false
+imageGCHighThresholdPercent: 100
+%s---
+apiVersion: kubeproxy.config.k8s.io/v1alpha1
+kind: KubeProxyConfiguration
+conntrack:
+  maxPerCore: 0
+`
@@ -4,17 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"os"
 	"path"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
+	"github.com/google/uuid"
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/connector"
@@ -23,6 +24,9 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/clients/http"
 	"github.com/jumppad-labs/jumppad/pkg/clients/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 	"gopkg.in/yaml.v3"
@@ -34,6 +38,10 @@ var _ sdk.Provider = &ClusterProvider{}
 
 var startTimeout = (300 * time.Second)
 
+// legacyClusterToken is the token used by clusters that were created before
+// a token was generated for each cluster
+const legacyClusterToken = "mysupersecret"
+
 //var startTimeout = (60 * time.Second)
 
 // K8sCluster defines a provider which can create Kubernetes clusters
@@ -74,7 +82,7 @@ func (p *ClusterProvider) Create(ctx context.Context) error {
 		return nil
 	}
 
-	return p.createK3s(ctx)
+	return p.createCluster(ctx)
 }
 
 // Destroy implements interface method to destroy a cluster
@@ -84,12 +92,27 @@ func (p *ClusterProvider) Destroy(ctx context.Context, force bool) error {
 		return nil
 	}
 
-	return p.destroyK3s(force)
+	return p.destroyCluster(force)
 }
 
-// Lookup the a clusters current state
+// Lookup the a clusters current state, the id of the server is returned
+// first followed by the ids of any agent nodes
 func (p *ClusterProvider) Lookup() ([]string, error) {
-	return p.client.FindContainerIDs(utils.FQDN(fmt.Sprintf("server.%s", p.config.Meta.Name), p.config.Meta.Module, p.config.Meta.Type))
+	ids, err := p.client.FindContainerIDs(utils.FQDN(fmt.Sprintf("server.%s", p.config.Meta.Name), p.config.Meta.Module, p.config.Meta.Type))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range p.config.AgentContainerNames {
+		aids, err := p.client.FindContainerIDs(a)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, aids...)
+	}
+
+	return ids, nil
 }
 
 func (p *ClusterProvider) Refresh(ctx context.Context) error {
@@ -100,6 +123,24 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 
 	p.log.Debug("Refresh Kubernetes Cluster", "ref", p.config.Meta.Name)
 
+	// the control plane can not be reconfigured, changes to the kubernetes
+	// config recreate the cluster
+	kc, err := p.kubernetesChecksum()
+	if err != nil {
+		return err
+	}
+
+	if kc != p.config.KubernetesChecksum {
+		p.log.Info("Kubernetes configuration changed, recreating cluster", "ref", p.config.Meta.ID)
+
+		err := p.destroyCluster(false)
+		if err != nil {
+			return err
+		}
+
+		return p.createCluster(ctx)
+	}
+
 	ci, err := p.getChangedImages()
 	if err != nil {
 		return err
@@ -107,12 +148,91 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 
 	if len(ci) > 0 {
 		p.log.Info("Copied images changed, pushing new copy to the cluster", "ref", p.config.Meta.ID)
-		err := p.ImportLocalDockerImages(ci, false)
+		err := p.importLocalDockerImages(ctx, ci, false)
 		if err != nil {
 			return err
 		}
 	}
 
+	cf, err := p.config.Files.Changed()
+	if err != nil {
+		return err
+	}
+
+	if len(cf) > 0 {
+		p.log.Info("Files changed, writing new content to the cluster", "ref", p.config.Meta.ID)
+		err := p.copyFiles(cf)
+		if err != nil {
+			return err
+		}
+	}
+
+	// find any agent nodes that have crashed or have been deleted, these are
+	// replaced when the cluster is scaled
+	agents := []string{}
+	for _, a := range p.config.AgentContainerNames {
+		ids, _ := p.client.FindContainerIDs(a)
+		if len(ids) == 0 {
+			p.log.Debug("Agent node does not exist", "ref", p.config.Meta.ID, "agent", a)
+			continue
+		}
+
+		agents = append(agents, a)
+	}
+
+	p.config.AgentContainerNames = agents
+
+	// are we scaling down
+	if p.agentNodes() < len(p.config.AgentContainerNames) {
+		removeCount := len(p.config.AgentContainerNames) - p.agentNodes()
+		p.log.Info("Scaling cluster down", "ref", p.config.Meta.ID, "current_scale", len(p.config.AgentContainerNames)+1, "new_scale", p.agentNodes()+1, "removing", removeCount)
+
+		for _, a := range p.config.AgentContainerNames[:removeCount] {
+			err := p.removeAgentNode(a, false)
+			if err != nil {
+				return fmt.Errorf("unable to remove agent node %s: %w", a, err)
+			}
+		}
+
+		p.config.AgentContainerNames = p.config.AgentContainerNames[removeCount:]
+	}
+
+	// are we scaling up
+	if p.agentNodes() > len(p.config.AgentContainerNames) {
+		addCount := p.agentNodes() - len(p.config.AgentContainerNames)
+		p.log.Info("Scaling cluster up", "ref", p.config.Meta.ID, "current_scale", len(p.config.AgentContainerNames)+1, "new_scale", p.agentNodes()+1, "adding", addCount)
+
+		img := ctypes.Image{Name: p.config.Image.Name, Username: p.config.Image.Username, Password: p.config.Image.Password, Platform: p.config.Platform}
+		err := p.client.PullImage(img, false)
+		if err != nil {
+			return err
+		}
+
+		err = policy.EnforceNode(ctx, p.client, img.Name)
+		if err != nil {
+			p.log.Error("Cluster image does not meet the image policy", "ref", p.config.Meta.ID, "image", img.Name, "error", err)
+			return err
+		}
+
+		volID, err := p.client.CreateVolume(utils.ImageVolumeName())
+		if err != nil {
+			return err
+		}
+
+		ids, err := p.createAgentNodes(ctx, img, volID, addCount)
+		if err != nil {
+			return err
+		}
+
+		// the new nodes need a copy of the images that the existing nodes have
+		if len(p.config.CopyImages) > 0 {
+			err = p.importImages(ctx, ids, p.copyImages(), false)
+			if err != nil {
+				return fmt.Errorf("unable to importing Docker images: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -129,16 +249,80 @@ func (p *ClusterProvider) Changed() (bool, error) {
 		return true, nil
 	}
 
+	// check to see if the content of any of the files has changed
+	f, err := p.config.Files.Changed()
+	if err != nil {
+		return false, err
+	}
+
+	if len(f) > 0 {
+		return true, nil
+	}
+
+	// check to see if the number of agent nodes has changed
+	if p.agentNodes() != len(p.config.AgentContainerNames) {
+		return true, nil
+	}
+
+	// check to see if the kubernetes config has changed
+	kc, err := p.kubernetesChecksum()
+	if err != nil {
+		return false, err
+	}
+
+	if kc != p.config.KubernetesChecksum {
+		return true, nil
+	}
+
 	return false, nil
 }
 
+// copyFiles writes the given files to the running server container and
+// updates the checksums for the files in the config
+func (p *ClusterProvider) copyFiles(files container.Files) error {
+	ids, err := p.Lookup()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return fmt.Errorf("unable to find server container for cluster %s", p.config.Meta.ID)
+	}
+
+	cf, err := files.ToClientFiles()
+	if err != nil {
+		return err
+	}
+
+	err = p.client.CopyFilesToContainer(ids[0], cf)
+	if err != nil {
+		return err
+	}
+
+	// update the checksums in the config
+	p.config.Files.SetChecksums(files)
+
+	return nil
+}
+
 // ImportLocalDockerImages fetches Docker images stored on the local client and imports them into the cluster
 func (p *ClusterProvider) ImportLocalDockerImages(images []ctypes.Image, force bool) error {
-	id, err := p.Lookup()
+	return p.importLocalDockerImages(context.Background(), images, force)
+}
+
+// importLocalDockerImages imports the images into all the cluster nodes, the
+// images are checked against the image policies in the context
+func (p *ClusterProvider) importLocalDockerImages(ctx context.Context, images []ctypes.Image, force bool) error {
+	ids, err := p.Lookup()
 	if err != nil {
 		return err
 	}
 
+	return p.importImages(ctx, ids, images, force)
+}
+
+// importImages imports the Docker images into the given cluster nodes
+func (p *ClusterProvider) importImages(ctx context.Context, ids []string, images []ctypes.Image, force bool) error {
 	imgs := []string{}
 
 	for _, i := range images {
@@ -147,34 +331,46 @@ func (p *ClusterProvider) ImportLocalDockerImages(images []ctypes.Image, force b
 			continue
 		}
 
+		// images are pulled for the platform of the cluster
+		i.Platform = p.config.Platform
+
 		err := p.client.PullImage(i, false)
 		if err != nil {
 			return err
 		}
 
+		err = policy.Enforce(ctx, p.client, i.Name, "")
+		if err != nil {
+			p.log.Error("Copied image does not meet the image policy", "ref", p.config.Meta.ID, "image", i.Name, "error", err)
+			return err
+		}
+
 		imgs = append(imgs, i.Name)
 	}
 
 	// import to volume
-	vn := utils.FQDNVolumeName(utils.ImageVolumeName)
+	vn := utils.FQDNVolumeName(utils.ImageVolumeName())
 	imagesFile, err := p.client.CopyLocalDockerImagesToVolume(imgs, vn, force)
 	if err != nil {
 		return err
 	}
 
-	for _, i := range imagesFile {
-		p.log.Debug("Importing docker image", "ref", p.config.Meta.ID, "image", i)
+	for _, id := range ids {
+		for _, i := range imagesFile {
+			p.log.Debug("Importing docker image", "ref", p.config.Meta.ID, "id", id, "image", i)
 
-		// execute the command to import the image
-		// write any command output to the logger
-		_, err = p.client.ExecuteCommand(id[0], []string{"ctr", "image", "import", i}, nil, "/", "", "", 300, p.log.StandardWriter())
-		if err != nil {
-			return err
+			// execute the command to import the image
+			// write any command output to the logger
+			cmd := append(p.driver().ctr(), "image", "import", i)
+			_, err = p.client.ExecuteCommand(id, cmd, nil, "/", "", "", 300, p.log.StandardWriter())
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	// prune the build images
-	p.pruneBuildImages()
+	p.pruneBuildImages(ids)
 
 	// update the config with the image ids
 	p.updateCopyImageIDs()
@@ -182,12 +378,7 @@ func (p *ClusterProvider) ImportLocalDockerImages(images []ctypes.Image, force b
 	return nil
 }
 
-func (p *ClusterProvider) pruneBuildImages() error {
-	ids, err := p.Lookup()
-	if err != nil {
-		return err
-	}
-
+func (p *ClusterProvider) pruneBuildImages(ids []string) error {
 	// build a list of current images, we do not want to prune these
 	filter := []string{}
 	for _, i := range p.config.CopyImages {
@@ -199,16 +390,34 @@ func (p *ClusterProvider) pruneBuildImages() error {
 	filters := strings.Join(filter, "| ")
 	filters = strings.TrimSuffix(filters, "| ")
 
-	command := fmt.Sprintf("ctr image rm $(ctr images ls name~=jumppad.dev/localcache/* -q | %s)", filters)
+	ctr := strings.Join(p.driver().ctr(), " ")
+	command := fmt.Sprintf("%s image rm $(%s images ls name~=jumppad.dev/localcache/* -q | %s)", ctr, ctr, filters)
 
-	p.log.Debug("Prune build images from nomad node", "id", ids[0], "command", command)
-	output := bytes.NewBufferString("")
-	_, _ = p.client.ExecuteCommand(ids[0], []string{"sh", "-c", command}, nil, "", "", "", 30, output)
+	for _, id := range ids {
+		p.log.Debug("Prune build images from node", "id", id, "command", command)
+		output := bytes.NewBufferString("")
+		_, _ = p.client.ExecuteCommand(id, []string{"sh", "-c", command}, nil, "", "", "", 30, output)
+
+		p.log.Debug("output", "result", output.String())
+	}
 
-	p.log.Debug("output", "result", output.String())
 	return nil
 }
 
+// copyImages returns the images that are copied to the cluster nodes
+func (p *ClusterProvider) copyImages() []ctypes.Image {
+	imgs := []ctypes.Image{}
+	for _, i := range p.config.CopyImages {
+		imgs = append(imgs, ctypes.Image{
+			Name:     i.Name,
+			Username: i.Username,
+			Password: i.Password,
+		})
+	}
+
+	return imgs
+}
+
 func (p *ClusterProvider) updateCopyImageIDs() error {
 	for n, i := range p.config.CopyImages {
 		id, err := p.client.FindImageInLocalRegistry(i.ToClientImage())
@@ -244,8 +453,18 @@ func (p *ClusterProvider) getChangedImages() ([]ctypes.Image, error) {
 	return changed, nil
 }
 
-func (p *ClusterProvider) createK3s(ctx context.Context) error {
-	p.log.Info("Creating Cluster", "ref", p.config.Meta.ID)
+func (p *ClusterProvider) createCluster(ctx context.Context) error {
+	p.log.Info("Creating Cluster", "ref", p.config.Meta.ID, "distribution", p.config.Distribution)
+
+	d := p.driver()
+
+	// store the checksum of the kubernetes config so changes can be detected
+	checksum, err := p.kubernetesChecksum()
+	if err != nil {
+		return err
+	}
+
+	p.config.KubernetesChecksum = checksum
 
 	// check the cluster does not already exist
 	ids, err := p.Lookup()
@@ -257,15 +476,21 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 		return fmt.Errorf("error, cluster exists")
 	}
 
-	img := ctypes.Image{Name: p.config.Image.Name, Username: p.config.Image.Username, Password: p.config.Image.Password}
+	img := ctypes.Image{Name: p.config.Image.Name, Username: p.config.Image.Username, Password: p.config.Image.Password, Platform: p.config.Platform}
 	// pull the container image
 	err = p.client.PullImage(img, false)
 	if err != nil {
 		return err
 	}
 
+	err = policy.EnforceNode(ctx, p.client, img.Name)
+	if err != nil {
+		p.log.Error("Cluster image does not meet the image policy", "ref", p.config.Meta.ID, "image", img.Name, "error", err)
+		return err
+	}
+
 	// create the volume for the cluster
-	volID, err := p.client.CreateVolume("images")
+	volID, err := p.client.CreateVolume(utils.ImageVolumeName())
 	if err != nil {
 		return err
 	}
@@ -274,152 +499,37 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 	name := fmt.Sprintf("server.%s", p.config.Meta.Name)
 	fqrn := utils.FQDN(name, p.config.Meta.Module, p.config.Meta.Type)
 
-	cc := &ctypes.Container{}
-	cc.Name = fqrn
-
-	cc.Image = &img
-	cc.Privileged = true // k3s must run Privileged
-
-	for _, v := range p.config.Networks {
-		cc.Networks = append(cc.Networks, ctypes.NetworkAttachment{
-			ID:        v.ID,
-			Name:      v.Name,
-			IPAddress: v.IPAddress,
-			Aliases:   v.Aliases,
-		})
-	}
-
-	// set the volume mount for the images
-	cc.Volumes = []ctypes.Volume{
-		{
-			Source:      volID,
-			Destination: "/cache",
-			Type:        "volume",
-		},
-	}
-
-	// if there are any custom volumes to mount
-	for _, v := range p.config.Volumes {
-		cc.Volumes = append(cc.Volumes, ctypes.Volume{
-			Source:                      v.Source,
-			Destination:                 v.Destination,
-			Type:                        v.Type,
-			ReadOnly:                    v.ReadOnly,
-			BindPropagation:             v.BindPropagation,
-			BindPropagationNonRecursive: v.BindPropagationNonRecursive,
-			SelinuxRelabel:              v.SelinuxRelabel,
-		})
-	}
-
-	// add the registries volume
-	rc, err := p.createRegistriesConfig()
+	cc, v, err := p.createNodeConfig(fqrn, img, volID)
 	if err != nil {
-		return fmt.Errorf("unable to create registries.yaml: %s", err)
-	}
-
-	if rc != "" {
-		cc.Volumes = append(cc.Volumes, ctypes.Volume{
-			Source:      rc,
-			Destination: "/etc/rancher/k3s/registries.yaml",
-			Type:        "bind",
-		})
-	}
-
-	// Add any custom environment variables
-	cc.Environment = map[string]string{}
-
-	// set the environment variables for the K3S_KUBECONFIG_OUTPUT and K3S_CLUSTER_SECRET
-	cc.Environment["K3S_KUBECONFIG_OUTPUT"] = "/output/kubeconfig.yaml"
-
-	// only add the variables for the cache when the kubernetes version is >= v1.18.16
-	sv, err := semver.NewConstraint(">= v1.18.16")
-	if err != nil {
-		// Handle constraint not being parsable.
 		return err
 	}
 
-	// get the version from the image so we can calculate parameters
-	version := "v99"
-	vParts := strings.Split(p.config.Image.Name, ":")
-	if len(vParts) == 2 && vParts[1] != "latest" {
-		version = vParts[1]
-	}
-
-	v, err := semver.NewVersion(version)
-	if err != nil {
-		return fmt.Errorf("kubernetes version is not valid semantic version: %s", err)
-	}
-
-	if sv.Check(v) {
-		// load the CA from a file
-		ca, err := os.ReadFile(filepath.Join(utils.CertsDir(""), "/root.cert"))
-		if err != nil {
-			return fmt.Errorf("unable to read root CA for proxy: %s", err)
-		}
-
-		cc.Environment["CONTAINERD_HTTP_PROXY"] = utils.ImageCacheAddress()
-		cc.Environment["CONTAINERD_HTTPS_PROXY"] = utils.ImageCacheAddress()
-		cc.Environment["PROXY_CA"] = string(ca)
-
-		// add the no-proxy overrides
-		if p.config.Config != nil &&
-			p.config.Config.DockerConfig != nil &&
-			len(p.config.Config.DockerConfig.NoProxy) > 0 {
-			cc.Environment["CONTAINERD_NO_PROXY"] = strings.Join(p.config.Config.DockerConfig.NoProxy, ",")
-		}
-	}
-
-	// add any custom environment variables
-	for k, v := range p.config.Environment {
-		cc.Environment[k] = v
+	for _, n := range p.config.Networks {
+		cc.Networks = append(cc.Networks, ctypes.NetworkAttachment{
+			ID:        n.ID,
+			Name:      n.Name,
+			IPAddress: n.IPAddress,
+			Aliases:   n.Aliases,
+		})
 	}
 
 	// set the Connector server port to a random number
 	p.config.ConnectorPort = rand.Intn(utils.MaxRandomPort-utils.MinRandomPort) + utils.MinRandomPort
 
-	// determine the snapshotter, if a storage driver other than overlay is used then
-	// snapshotter must be set to native or the container will not start
-	snapShotter := "native"
-
-	if p.client.EngineInfo().StorageDriver == ctypes.StorageDriverOverlay || p.client.EngineInfo().StorageDriver == ctypes.StorageDriverOverlay2 {
-		snapShotter = "overlayfs"
-	}
-
-	// only add the variables for the cache when the kubernetes version is >= v1.18.16
-	sv, err = semver.NewConstraint(">= v1.25.0")
+	// generate a new token for the cluster that is used to join the agents
+	p.config.ClusterToken, err = d.generateToken()
 	if err != nil {
-		// Handle constraint not being parsable.
-		return err
+		return fmt.Errorf("unable to generate cluster token: %w", err)
 	}
 
-	disableArgs := "--no-deploy=traefik"
-	clusterToken := ""
-
-	if sv.Check(v) {
-		disableArgs = "--disable=traefik"
-		clusterToken = "--token=mysupersecret"
-	} else {
-		// add the cluster secret as an env this is deprecated in v1.25 and
-		// replaced with --token
-		cc.Environment["K3S_CLUSTER_SECRET"] = "mysupersecret"
-	}
+	secrets.Register(p.config.ClusterToken)
 
 	// create the server address
-	FQDN := fmt.Sprintf("server.%s", utils.FQDN(p.config.Meta.Name, p.config.Meta.Module, p.config.Meta.Type))
-	p.config.ContainerName = FQDN
-
-	// Set the default startup args
-	// Also set netfilter settings to fix behaviour introduced in Linux Kernel 5.12
-	// https://k3d.io/faq/faq/#solved-nodes-fail-to-start-or-get-stuck-in-notready-state-with-log-nf_conntrack_max-permission-denied
-	args := []string{
-		"server",
-		fmt.Sprintf("--https-listen-port=%d", p.config.APIPort),
-		"--kube-proxy-arg=conntrack-max-per-core=0",
-		disableArgs,
-		fmt.Sprintf("--snapshotter=%s", snapShotter),
-		fmt.Sprintf("--tls-san=%s", FQDN),                // add the FQDN for the server
-		fmt.Sprintf("--tls-san=%s", utils.GetDockerIP()), // add the docker host IP
-		clusterToken,
+	p.config.ContainerName = fmt.Sprintf("server.%s", utils.FQDN(p.config.Meta.Name, p.config.Meta.Module, p.config.Meta.Type))
+
+	err = d.configureServer(cc, v)
+	if err != nil {
+		return err
 	}
 
 	// expose the API server and Connector ports
@@ -459,22 +569,12 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 		})
 	}
 
-	if p.config.Resources != nil {
-		cc.Resources = &ctypes.Resources{
-			CPU:    p.config.Resources.CPU,
-			CPUPin: p.config.Resources.CPUPin,
-			Memory: p.config.Resources.Memory,
-		}
-
-		if p.config.Resources.GPU != nil {
-			cc.Resources.GPU = &ctypes.GPU{
-				Driver:    p.config.Resources.GPU.Driver,
-				DeviceIDs: p.config.Resources.GPU.DeviceIDs,
-			}
-		}
+	files, err := p.config.Files.ToClientFiles()
+	if err != nil {
+		return err
 	}
 
-	cc.Command = args
+	cc.Files = append(cc.Files, files...)
 
 	id, err := p.client.CreateContainer(cc)
 	if err != nil {
@@ -482,7 +582,7 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 	}
 
 	// wait for the server to start
-	err = p.waitForStart(ctx, id)
+	err = d.startServer(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -500,6 +600,14 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 		}
 	}
 
+	// join any agent nodes to the server
+	p.config.AgentContainerNames = []string{}
+
+	_, err = p.createAgentNodes(ctx, img, volID, p.agentNodes())
+	if err != nil {
+		return err
+	}
+
 	// set the external IP
 	p.config.ExternalIP = utils.GetDockerIP()
 
@@ -544,8 +652,13 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 		return err
 	}
 
-	// ensure essential pods have started before announcing the resource is available
-	err = p.kubeClient.HealthCheckPods(ctx, []string{"app=local-path-provisioner", "k8s-app=kube-dns"}, startTimeout)
+	// ensure essential pods have started before announcing the resource is available,
+	// pods can not start until a network plugin has been installed
+	if !p.cniInstalled() {
+		p.log.Warn("Cluster does not have a network plugin, pods will not start until a plugin is installed", "ref", p.config.Meta.ID)
+	}
+
+	err = p.kubeClient.HealthCheckPods(ctx, p.defaultPods(), startTimeout)
 	if err != nil {
 		// fetch the logs from the container before exit
 		lr, lerr := p.client.ContainerLogs(id, true, true)
@@ -559,20 +672,10 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 		return fmt.Errorf("timeout waiting for Kubernetes default pods: %w", err)
 	}
 
-	// import the images to the servers container d instance
+	// import the images to the containerd instance of each node
 	// importing images means that k3s does not need to pull from a remote docker hub
 	if len(p.config.CopyImages) > 0 {
-		imgs := []ctypes.Image{}
-		for _, i := range p.config.CopyImages {
-			imgs = append(imgs, ctypes.Image{
-				Name:     i.Name,
-				Username: i.Username,
-				Password: i.Password,
-			})
-
-		}
-
-		err := p.ImportLocalDockerImages(imgs, false)
+		err := p.importLocalDockerImages(ctx, p.copyImages(), false)
 		if err != nil {
 			return fmt.Errorf("unable to importing Docker images: %w", err)
 		}
@@ -583,7 +686,275 @@ func (p *ClusterProvider) createK3s(ctx context.Context) error {
 	return p.deployConnector(ctx, p.config.ConnectorPort, p.config.ConnectorPort+1)
 }
 
-func (p *ClusterProvider) waitForStart(ctx context.Context, id string) error {
+// createNodeConfig returns the container config that is shared by the server
+// and the agent nodes, and the Kubernetes version parsed from the image
+func (p *ClusterProvider) createNodeConfig(name string, img ctypes.Image, volID string) (*ctypes.Container, *semver.Version, error) {
+	cc := &ctypes.Container{}
+	cc.Name = name
+
+	cc.Image = &img
+	cc.Privileged = true // nodes must run Privileged
+
+	// set the volume mount for the images
+	cc.Volumes = []ctypes.Volume{
+		{
+			Source:      volID,
+			Destination: "/cache",
+			Type:        "volume",
+		},
+	}
+
+	// if there are any custom volumes to mount
+	for _, v := range p.config.Volumes {
+		cc.Volumes = append(cc.Volumes, ctypes.Volume{
+			Source:                      v.Source,
+			Destination:                 v.Destination,
+			Type:                        v.Type,
+			ReadOnly:                    v.ReadOnly,
+			BindPropagation:             v.BindPropagation,
+			BindPropagationNonRecursive: v.BindPropagationNonRecursive,
+			SelinuxRelabel:              v.SelinuxRelabel,
+		})
+	}
+
+	cc.Environment = map[string]string{}
+
+	// get the version from the image so we can calculate parameters
+	version := "v99"
+	vParts := strings.Split(p.config.Image.Name, ":")
+	if len(vParts) == 2 && vParts[1] != "latest" {
+		version = vParts[1]
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("kubernetes version is not valid semantic version: %s", err)
+	}
+
+	// add the distribution specific config
+	err = p.driver().configureNode(cc, v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// add any custom environment variables
+	for k, v := range p.config.Environment {
+		cc.Environment[k] = v
+	}
+
+	if p.config.Resources != nil {
+		cc.Resources = &ctypes.Resources{
+			CPU:    p.config.Resources.CPU,
+			CPUPin: p.config.Resources.CPUPin,
+			Memory: p.config.Resources.Memory,
+		}
+
+		if p.config.Resources.GPU != nil {
+			cc.Resources.GPU = &ctypes.GPU{
+				Driver:    p.config.Resources.GPU.Driver,
+				DeviceIDs: p.config.Resources.GPU.DeviceIDs,
+			}
+		}
+	}
+
+	return cc, v, nil
+}
+
+// kubernetesChecksum returns the checksum of the kubernetes config and the
+// content of the files it references, when the config is not set the checksum
+// is empty
+func (p *ClusterProvider) kubernetesChecksum() (string, error) {
+	if p.config.Kubernetes == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(p.config.Kubernetes)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize kubernetes config: %w", err)
+	}
+
+	content := string(data)
+
+	for _, f := range []string{p.config.Kubernetes.AuditPolicy, p.cniManifest()} {
+		if f == "" {
+			continue
+		}
+
+		d, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("unable to read file %s: %w", f, err)
+		}
+
+		content += string(d)
+	}
+
+	return utils.HashString(content)
+}
+
+// cniManifest returns the path of the manifest that installs the network
+// plugin, when the default plugin is used or the plugin is disabled the path
+// is empty
+func (p *ClusterProvider) cniManifest() string {
+	if p.config.Kubernetes == nil ||
+		p.config.Kubernetes.CNI == "" ||
+		p.config.Kubernetes.CNI == CNIFlannel ||
+		p.config.Kubernetes.CNI == CNINone {
+		return ""
+	}
+
+	return p.config.Kubernetes.CNI
+}
+
+// cniInstalled returns false when the cluster is created without a network
+// plugin
+func (p *ClusterProvider) cniInstalled() bool {
+	return p.config.Kubernetes == nil || p.config.Kubernetes.CNI != CNINone
+}
+
+// disabled returns true when the default component is not deployed
+func (p *ClusterProvider) disabled(component string) bool {
+	return p.config.Kubernetes != nil && contains(p.config.Kubernetes.Disable, component)
+}
+
+// defaultPods returns the selectors for the pods of the default components
+// that must be running before the cluster is available
+func (p *ClusterProvider) defaultPods() []string {
+	pods := []string{}
+
+	if !p.cniInstalled() {
+		return pods
+	}
+
+	if !p.disabled(ComponentLocalStorage) {
+		pods = append(pods, "app=local-path-provisioner")
+	}
+
+	if !p.disabled(ComponentCoreDNS) {
+		pods = append(pods, "k8s-app=kube-dns")
+	}
+
+	return pods
+}
+
+// clusterToken returns the token used to join agents to the server, clusters
+// created before tokens were generated use the legacy token
+func (p *ClusterProvider) clusterToken() string {
+	if p.config.ClusterToken == "" {
+		return legacyClusterToken
+	}
+
+	return p.config.ClusterToken
+}
+
+// snapshotter returns the containerd snapshotter for the nodes, if a storage
+// driver other than overlay is used then snapshotter must be set to native or
+// the container will not start
+func (p *ClusterProvider) snapshotter() string {
+	if p.client.EngineInfo().StorageDriver == ctypes.StorageDriverOverlay || p.client.EngineInfo().StorageDriver == ctypes.StorageDriverOverlay2 {
+		return "overlayfs"
+	}
+
+	return "native"
+}
+
+// agentNodes returns the number of agent nodes for the cluster, the server
+// counts as one of the nodes
+func (p *ClusterProvider) agentNodes() int {
+	if p.config.Nodes <= 1 {
+		return 0
+	}
+
+	return p.config.Nodes - 1
+}
+
+// createAgentNodes creates the given number of agent nodes, joins them to the
+// server and waits for them to start. The names of the nodes are added to the
+// config and the ids of the new containers are returned.
+func (p *ClusterProvider) createAgentNodes(ctx context.Context, img ctypes.Image, volID string, count int) ([]string, error) {
+	ids := []string{}
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s.agent.%s", randomID(), p.config.Meta.Name)
+		fqrn := utils.FQDN(name, p.config.Meta.Module, p.config.Meta.Type)
+
+		p.log.Debug("Creating agent node", "ref", p.config.Meta.ID, "agent", fqrn)
+
+		cc, v, err := p.createNodeConfig(fqrn, img, volID)
+		if err != nil {
+			return nil, err
+		}
+
+		// agents are attached to the same networks as the server, the
+		// addresses are assigned by the engine
+		for _, n := range p.config.Networks {
+			cc.Networks = append(cc.Networks, ctypes.NetworkAttachment{
+				ID:   n.ID,
+				Name: n.Name,
+			})
+		}
+
+		err = p.driver().configureAgent(cc, v)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := p.client.CreateContainer(cc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create agent node %s: %w", fqrn, err)
+		}
+
+		p.config.AgentContainerNames = append(p.config.AgentContainerNames, fqrn)
+		ids = append(ids, id)
+	}
+
+	// wait for the agents to start
+	for _, id := range ids {
+		err := p.driver().startAgent(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// removeAgentNode deletes the node from the cluster and removes the container
+func (p *ClusterProvider) removeAgentNode(name string, force bool) error {
+	p.log.Debug("Removing agent node", "ref", p.config.Meta.ID, "agent", name)
+
+	// delete the node so that it is not left in the cluster as NotReady
+	sids, err := p.client.FindContainerIDs(p.config.ContainerName)
+	if err == nil && len(sids) > 0 {
+		output := bytes.NewBufferString("")
+		cmd := append(p.driver().kubectl(), "delete", "node", name)
+		_, err := p.client.ExecuteCommand(sids[0], cmd, nil, "/", "", "", 60, output)
+		if err != nil {
+			p.log.Debug("Unable to delete node from cluster", "ref", p.config.Meta.ID, "agent", name, "output", output.String())
+		}
+	}
+
+	ids, err := p.client.FindContainerIDs(name)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := p.client.RemoveContainer(id, force)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = p.driver().removeNode(name)
+	if err != nil {
+		p.log.Debug("Unable to remove node resources", "ref", p.config.Meta.ID, "agent", name, "error", err)
+	}
+
+	return nil
+}
+
+// waitForLog waits until the container logs contain the given text
+func (p *ClusterProvider) waitForLog(ctx context.Context, id, text string) error {
 	start := time.Now()
 
 	for {
@@ -604,12 +975,12 @@ func (p *ClusterProvider) waitForStart(ctx context.Context, id string) error {
 			return fmt.Errorf("unable to get docker logs for %s\n%+v", id, err)
 		}
 
-		// read from the log and check for Kublet running
+		// read from the log and check for the text
 		buf := new(bytes.Buffer)
 		nRead, _ := buf.ReadFrom(out)
 		out.Close()
 		output := buf.String()
-		if nRead > 0 && strings.Contains(string(output), "Running kubelet") {
+		if nRead > 0 && strings.Contains(string(output), text) {
 			break
 		}
 
@@ -625,7 +996,8 @@ func (p *ClusterProvider) copyKubeConfig(id string) (string, error) {
 	_, kubePath, _ := utils.CreateKubeConfigPath(p.config.Meta.ID)
 
 	// get kubeconfig file from container and read contents
-	err := p.client.CopyFromContainer(id, "/output/kubeconfig.yaml", kubePath)
+	kc, _ := p.driver().kubeConfig()
+	err := p.client.CopyFromContainer(id, kc, kubePath)
 	if err != nil {
 		return "", err
 	}
@@ -637,7 +1009,10 @@ func (p *ClusterProvider) createLocalKubeConfig(kubeconfig string) (string, erro
 	ip := utils.GetDockerIP()
 	_, kubePath, _ := utils.CreateKubeConfigPath(p.config.Meta.ID)
 
+	_, server := p.driver().kubeConfig()
+
 	err := p.changeServerAddressInK8sConfig(
+		server,
 		fmt.Sprintf("https://%s", ip),
 		kubeconfig,
 		kubePath,
@@ -649,7 +1024,7 @@ func (p *ClusterProvider) createLocalKubeConfig(kubeconfig string) (string, erro
 	return kubePath, nil
 }
 
-func (p *ClusterProvider) changeServerAddressInK8sConfig(addr, origFile, newFile string) error {
+func (p *ClusterProvider) changeServerAddressInK8sConfig(server, addr, origFile, newFile string) error {
 	// read the config into a string
 	f, err := os.OpenFile(origFile, os.O_RDONLY, 0666)
 	if err != nil {
@@ -665,7 +1040,7 @@ func (p *ClusterProvider) changeServerAddressInK8sConfig(addr, origFile, newFile
 	// manipulate the file
 	newConfig := strings.Replace(
 		string(readBytes),
-		"server: https://127.0.0.1",
+		fmt.Sprintf("server: %s", server),
 		fmt.Sprintf("server: %s", addr),
 		-1,
 	)
@@ -752,11 +1127,15 @@ func (p *ClusterProvider) deployConnector(ctx context.Context, grpcPort, httpPor
 	}
 
 	// deploy the application config
-	err = p.kubeClient.Apply(files, true)
+	err = p.kubeClient.Apply(files, p.cniInstalled())
 	if err != nil {
 		return fmt.Errorf("unable to apply configuration: %s", err)
 	}
 
+	if !p.cniInstalled() {
+		return nil
+	}
+
 	// wait for it to start
 	err = p.kubeClient.HealthCheckPods(ctx, []string{"app=connector"}, 60*time.Second)
 	if err != nil {
@@ -766,7 +1145,7 @@ func (p *ClusterProvider) deployConnector(ctx context.Context, grpcPort, httpPor
 	return nil
 }
 
-func (p *ClusterProvider) destroyK3s(force bool) error {
+func (p *ClusterProvider) destroyCluster(force bool) error {
 	p.log.Info("Destroy Cluster", "ref", p.config.Meta.ID)
 
 	ids, err := p.Lookup()
@@ -781,6 +1160,20 @@ func (p *ClusterProvider) destroyK3s(force bool) error {
 		}
 	}
 
+	// remove any resources the driver created for the nodes, the resources
+	// may not exist when the cluster was not completely created
+	d := p.driver()
+	for _, n := range append([]string{p.config.ContainerName}, p.config.AgentContainerNames...) {
+		if n == "" {
+			continue
+		}
+
+		err := d.removeNode(n)
+		if err != nil {
+			p.log.Debug("Unable to remove node resources", "ref", p.config.Meta.ID, "node", n, "error", err)
+		}
+	}
+
 	configDir, _, _ := utils.CreateKubeConfigPath(p.config.Meta.ID)
 	os.RemoveAll(configDir)
 
@@ -1014,3 +1407,9 @@ metadata:
   name: connector-certs
   namespace: jumppad
 `
+
+func randomID() string {
+	id := uuid.New()
+	short := strings.Replace(id.String(), "-", "", -1)
+	return short[:8]
+}
@@ -18,8 +18,10 @@ import (
 	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 
 	container "github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/mohae/deepcopy"
@@ -39,8 +41,9 @@ func setupClusterMocks(t *testing.T) (
 	md.On("CreateVolume", mock.Anything, mock.Anything).Return("123", nil)
 	md.On("CreateContainer", mock.Anything).Return("containerid", nil)
 	md.On("ContainerLogs", mock.Anything, true, true).Return(
-		io.NopCloser(bytes.NewBufferString("Running kubelet")),
-		nil,
+		func(string, bool, bool) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewBufferString("Running kubelet")), nil
+		},
 	)
 	md.On("CopyFromContainer", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	md.On("CopyLocalDockerImagesToVolume", mock.Anything, mock.Anything, mock.Anything).Return([]string{"/images/file.tar.gz"}, nil)
@@ -205,6 +208,48 @@ func TestClusterK3PullsImage(t *testing.T) {
 	md.AssertCalled(t, "PullImage", ctypes.Image{Name: "shipyardrun/k3s:v1.27.4"}, false)
 }
 
+func TestClusterK3WithImageViolatingPolicyReturnsError(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	md.On("InspectImage", mock.Anything).Return(&ctypes.ImageInfo{Created: time.Now()}, nil)
+
+	ip := &policy.ImagePolicy{AllowedRegistries: []string{"ghcr.io/jumppad-labs"}}
+	ip.Meta.ID = "resource.image_policy.strict"
+	ctx := policy.WithPolicies(context.Background(), []*policy.ImagePolicy{ip})
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(ctx)
+
+	ve := &policy.ViolationError{}
+	assert.ErrorAs(t, err, &ve)
+	assert.Equal(t, "shipyardrun/k3s:v1.27.4", ve.Image)
+	md.AssertNotCalled(t, "CreateContainer", mock.Anything)
+}
+
+func TestClusterK3WithCopyImageViolatingPolicyReturnsError(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.CopyImages = append(cc.CopyImages, container.Image{Name: "test:123"})
+
+	md.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	md.On("FindImageInLocalRegistry", mock.Anything).Return("abc123", nil)
+	md.On("InspectImage", "shipyardrun/k3s:v1.27.4").Return(&ctypes.ImageInfo{Created: time.Now()}, nil)
+	md.On("InspectImage", "test:123").Return(&ctypes.ImageInfo{Created: time.Now(), User: "root"}, nil)
+
+	// cluster nodes run as root, only the copied image is checked
+	ip := &policy.ImagePolicy{ForbidRootUser: true}
+	ip.Meta.ID = "resource.image_policy.strict"
+	ctx := policy.WithPolicies(context.Background(), []*policy.ImagePolicy{ip})
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(ctx)
+
+	ve := &policy.ViolationError{}
+	assert.ErrorAs(t, err, &ve)
+	assert.Equal(t, "test:123", ve.Image)
+	md.AssertNotCalled(t, "CopyLocalDockerImagesToVolume", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestClusterK3CreatesNewVolume(t *testing.T) {
 	cc, md, mk, mc := setupClusterMocks(t)
 
@@ -212,7 +257,7 @@ func TestClusterK3CreatesNewVolume(t *testing.T) {
 
 	err := p.Create(context.Background())
 	assert.NoError(t, err)
-	md.AssertCalled(t, "CreateVolume", utils.ImageVolumeName)
+	md.AssertCalled(t, "CreateVolume", utils.ImageVolumeName())
 }
 
 func TestClusterK3FailsWhenUnableToCreatesANewVolume(t *testing.T) {
@@ -225,7 +270,7 @@ func TestClusterK3FailsWhenUnableToCreatesANewVolume(t *testing.T) {
 
 	err := p.Create(context.Background())
 	assert.Error(t, err)
-	md.AssertCalled(t, "CreateVolume", utils.ImageVolumeName)
+	md.AssertCalled(t, "CreateVolume", utils.ImageVolumeName())
 }
 
 func TestClusterK3CreatesAServer(t *testing.T) {
@@ -504,7 +549,7 @@ func TestClusterK3sImportDockerCopiesImages(t *testing.T) {
 
 	err := p.Create(context.Background())
 	assert.NoError(t, err)
-	md.AssertCalled(t, "CopyLocalDockerImagesToVolume", []string{"test:123", "test:abc"}, utils.FQDNVolumeName(utils.ImageVolumeName), false)
+	md.AssertCalled(t, "CopyLocalDockerImagesToVolume", []string{"test:123", "test:abc"}, utils.FQDNVolumeName(utils.ImageVolumeName()), false)
 }
 
 func TestClusterK3sImportDockerCopyImageFailReturnsError(t *testing.T) {
@@ -700,11 +745,501 @@ func TestLookupReturnsIDs(t *testing.T) {
 	assert.Equal(t, []string{"found"}, ids)
 }
 
+func TestClusterK3sRefreshCopiesChangedFiles(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Files = container.Files{
+		{Destination: "/etc/rancher/k3s/config.yaml", Content: "debug: true", Mode: "0644"},
+		{Destination: "/etc/rancher/k3s/other.yaml", Content: "a: b", Mode: "0644"},
+	}
+
+	// set the checksums as if the files had been written
+	_, err := cc.Files.ToClientFiles()
+	assert.NoError(t, err)
+
+	cc.Files[0].Content = "debug: false"
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", mock.Anything, mock.Anything).Return([]string{"123"}, nil)
+	md.On("CopyFilesToContainer", "123", mock.Anything).Return(nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	changed, err := p.Changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	err = p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	files := testutils.GetCalls(&md.Mock, "CopyFilesToContainer")[0].Arguments[1].([]ctypes.File)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "debug: false", files[0].Content)
+
+	changed, err = p.Changed()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestClusterK3sCreatesAgentNodes(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 3
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	calls := testutils.GetCalls(&md.Mock, "CreateContainer")
+	assert.Len(t, calls, 3)
+	assert.Len(t, cc.AgentContainerNames, 2)
+
+	params := calls[1].Arguments[0].(*ctypes.Container)
+
+	assert.Equal(t, cc.AgentContainerNames[0], params.Name)
+	assert.Contains(t, params.Name, ".agent.test.k8s-cluster.local.jmpd.in")
+	assert.Equal(t, "cloud", params.Networks[0].ID)
+	assert.Empty(t, params.Networks[0].IPAddress)
+	assert.True(t, params.Privileged)
+	assert.Empty(t, params.Ports)
+
+	assert.Equal(t, "123", params.Volumes[0].Source)
+	assert.Equal(t, "/cache", params.Volumes[0].Destination)
+	assert.Equal(t, params.Environment["CONTAINERD_HTTP_PROXY"], utils.ImageCacheAddress())
+
+	assert.Equal(t, "agent", params.Command[0])
+	assert.Equal(t, "--server=https://server.test.k8s-cluster.local.jmpd.in:443", params.Command[1])
+	assert.Equal(t, "--node-name="+params.Name, params.Command[2])
+	assert.Contains(t, params.Command, "--token="+cc.ClusterToken)
+}
+
+func TestClusterK3sGeneratesClusterToken(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, cc.ClusterToken, 64)
+	assert.NotEqual(t, legacyClusterToken, cc.ClusterToken)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Contains(t, params.Command, "--token="+cc.ClusterToken)
+}
+
+func TestClusterK3sAgentsUseLegacyTokenWhenNotGenerated(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 2
+	cc.ContainerName = "server.test.k8s-cluster.local.jmpd.in"
+	cc.ClusterToken = ""
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"123"}, nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Contains(t, params.Command, "--token="+legacyClusterToken)
+}
+
+func TestClusterK3sImportsImagesToAgentNodes(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 2
+	cc.CopyImages = append(cc.CopyImages, container.Image{Name: "test:123"})
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", "server.test.k8s-cluster.local.jmpd.in").Return([]string{}, nil).Once()
+	md.On("FindContainerIDs", "server.test.k8s-cluster.local.jmpd.in").Return([]string{"server"}, nil)
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"agent"}, nil)
+	md.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	md.On("FindImageInLocalRegistry", mock.Anything).Return("abc123", nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "ExecuteCommand", "server", []string{"ctr", "image", "import", "/images/file.tar.gz"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	md.AssertCalled(t, "ExecuteCommand", "agent", []string{"ctr", "image", "import", "/images/file.tar.gz"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClusterK3sDestroyRemovesAgentNodes(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.AgentContainerNames = []string{"abc.agent.test.k8s-cluster.local.jmpd.in"}
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", "server.test.k8s-cluster.local.jmpd.in").Return([]string{"server"}, nil)
+	md.On("FindContainerIDs", "abc.agent.test.k8s-cluster.local.jmpd.in").Return([]string{"agent"}, nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Destroy(context.Background(), false)
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "RemoveContainer", "server", false)
+	md.AssertCalled(t, "RemoveContainer", "agent", false)
+}
+
+func TestClusterK3sRefreshScalesAgentNodesUp(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 3
+	cc.ContainerName = "server.test.k8s-cluster.local.jmpd.in"
+	cc.AgentContainerNames = []string{"abc.agent.test.k8s-cluster.local.jmpd.in"}
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"123"}, nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	changed, err := p.Changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	err = p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertNumberOfCalls(t, "CreateContainer", 1)
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "agent", params.Command[0])
+
+	assert.Len(t, cc.AgentContainerNames, 2)
+	assert.Equal(t, "abc.agent.test.k8s-cluster.local.jmpd.in", cc.AgentContainerNames[0])
+
+	changed, err = p.Changed()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestClusterK3sRefreshScalesAgentNodesDown(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 2
+	cc.ContainerName = "server.test.k8s-cluster.local.jmpd.in"
+	cc.AgentContainerNames = []string{"abc.agent.test.k8s-cluster.local.jmpd.in", "def.agent.test.k8s-cluster.local.jmpd.in"}
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", "server.test.k8s-cluster.local.jmpd.in").Return([]string{"server"}, nil)
+	md.On("FindContainerIDs", "abc.agent.test.k8s-cluster.local.jmpd.in").Return([]string{"abc"}, nil)
+	md.On("FindContainerIDs", "def.agent.test.k8s-cluster.local.jmpd.in").Return([]string{"def"}, nil)
+	md.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	changed, err := p.Changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	err = p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "ExecuteCommand", "server", []string{"kubectl", "delete", "node", "abc.agent.test.k8s-cluster.local.jmpd.in"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	md.AssertCalled(t, "RemoveContainer", "abc", false)
+	md.AssertNotCalled(t, "RemoveContainer", "def", mock.Anything)
+
+	assert.Equal(t, []string{"def.agent.test.k8s-cluster.local.jmpd.in"}, cc.AgentContainerNames)
+}
+
+func TestClusterK3sAddsKubernetesConfig(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Nodes = 2
+
+	policy := filepath.Join(t.TempDir(), "audit.yaml")
+	err := os.WriteFile(policy, []byte("kind: Policy"), 0644)
+	assert.NoError(t, err)
+
+	cc.Kubernetes = &KubernetesConfig{
+		FeatureGates:     map[string]bool{"B": false, "A": true},
+		AdmissionPlugins: []string{"NodeRestriction", "PodSecurity"},
+		AuditPolicy:      policy,
+		Disable:          []string{ComponentTraefik, ComponentMetricsServer},
+		CNI:              CNIFlannel,
+		ServerArgs:       []string{"--cluster-cidr=10.50.0.0/16"},
+		KubeletArgs:      []string{"--max-pods=200"},
+	}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err = p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "server", params.Command[0])
+	assert.Contains(t, params.Command, "--disable=metrics-server")
+	assert.NotContains(t, params.Command, "--flannel-backend=none")
+	assert.Contains(t, params.Command, "--kube-apiserver-arg=feature-gates=A=true,B=false")
+	assert.Contains(t, params.Command, "--kube-apiserver-arg=enable-admission-plugins=NodeRestriction,PodSecurity")
+	assert.Contains(t, params.Command, "--kube-apiserver-arg=audit-policy-file=/etc/jumppad/audit-policy.yaml")
+	assert.Contains(t, params.Command, "--cluster-cidr=10.50.0.0/16")
+	assert.Contains(t, params.Command, "--kubelet-arg=max-pods=200")
+
+	assert.Equal(t, "/etc/jumppad/audit-policy.yaml", params.Files[0].Destination)
+	assert.Equal(t, "kind: Policy", params.Files[0].Content)
+
+	params = testutils.GetCalls(&md.Mock, "CreateContainer")[1].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "agent", params.Command[0])
+	assert.Contains(t, params.Command, "--kubelet-arg=feature-gates=A=true,B=false")
+	assert.Contains(t, params.Command, "--kubelet-arg=max-pods=200")
+	assert.NotContains(t, params.Command, "--cluster-cidr=10.50.0.0/16")
+
+	assert.NotEmpty(t, cc.KubernetesChecksum)
+}
+
+func TestClusterK3sWritesCNIManifest(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+
+	manifest := filepath.Join(t.TempDir(), "calico.yaml")
+	err := os.WriteFile(manifest, []byte("kind: DaemonSet"), 0644)
+	assert.NoError(t, err)
+
+	cc.Kubernetes = &KubernetesConfig{CNI: manifest}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err = p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Contains(t, params.Command, "--flannel-backend=none")
+	assert.Equal(t, "/var/lib/rancher/k3s/server/manifests/jumppad-cni.yaml", params.Files[0].Destination)
+	assert.Equal(t, "kind: DaemonSet", params.Files[0].Content)
+}
+
+func TestClusterK3sDoesNotWaitForPodsWithoutCNI(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Kubernetes = &KubernetesConfig{CNI: CNINone}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Contains(t, params.Command, "--flannel-backend=none")
+
+	mk.AssertCalled(t, "HealthCheckPods", mock.Anything, []string{}, startTimeout)
+	mk.AssertCalled(t, "Apply", mock.Anything, false)
+	mk.AssertNotCalled(t, "HealthCheckPods", mock.Anything, []string{"app=connector"}, mock.Anything)
+}
+
+func TestClusterK3sChangedWhenKubernetesConfigChanges(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.AgentContainerNames = []string{}
+	cc.Kubernetes = &KubernetesConfig{AdmissionPlugins: []string{"PodSecurity"}}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	checksum, err := p.kubernetesChecksum()
+	assert.NoError(t, err)
+	cc.KubernetesChecksum = checksum
+
+	changed, err := p.Changed()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	cc.Kubernetes.AdmissionPlugins = []string{"NodeRestriction"}
+
+	changed, err = p.Changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestClusterK3sRefreshRecreatesWhenKubernetesConfigChanges(t *testing.T) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.ContainerName = "server.test.k8s-cluster.local.jmpd.in"
+	cc.AgentContainerNames = []string{}
+	cc.KubernetesChecksum = "abc"
+	cc.Kubernetes = &KubernetesConfig{FeatureGates: map[string]bool{"A": true}}
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"123"}, nil).Once()
+	md.On("FindContainerIDs", mock.Anything).Return([]string{}, nil).Once()
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"123"}, nil)
+
+	// destroying the cluster removes the kubeconfig
+	testutils.RemoveOn(&md.Mock, "CopyFromContainer")
+	md.On("CopyFromContainer", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		os.WriteFile(args.String(2), []byte(kubeconfig), 0644)
+	}).Return(nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "RemoveContainer", "123", false)
+	md.AssertNumberOfCalls(t, "CreateContainer", 1)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Contains(t, params.Command, "--kube-apiserver-arg=feature-gates=A=true")
+
+	assert.NotEqual(t, "abc", cc.KubernetesChecksum)
+}
+
+func setupKindMocks(t *testing.T) (*Cluster, *cmocks.ContainerTasks, *k8s.MockKubernetes, *conmocks.Connector) {
+	cc, md, mk, mc := setupClusterMocks(t)
+	cc.Distribution = DistributionKind
+	cc.Image = &container.Image{Name: "kindest/node:v1.31.0"}
+	cc.ClusterToken = ""
+
+	testutils.RemoveOn(&md.Mock, "ContainerLogs")
+	md.On("ContainerLogs", mock.Anything, true, true).Return(
+		func(string, bool, bool) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewBufferString("Reached target Multi-User System")), nil
+		},
+	)
+
+	md.On("ExecuteCommand", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	return cc, md, mk, mc
+}
+
+func TestClusterKindCreatesServerWithKubeadmConfig(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	assert.Regexp(t, "^[a-z0-9]{6}\\.[a-z0-9]{16}$", cc.ClusterToken)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Empty(t, params.Command)
+	assert.Empty(t, params.Environment["CONTAINERD_HTTP_PROXY"])
+
+	assert.Equal(t, "/var", params.Volumes[1].Destination)
+	assert.Equal(t, "tmpfs", params.Volumes[2].Type)
+
+	assert.Equal(t, kindKubeadmConfigPath, params.Files[0].Destination)
+	assert.Contains(t, params.Files[0].Content, fmt.Sprintf(`token: "%s"`, cc.ClusterToken))
+	assert.Contains(t, params.Files[0].Content, `controlPlaneEndpoint: "server.test.k8s-cluster.local.jmpd.in:443"`)
+
+	md.AssertCalled(t, "ExecuteCommand", "containerid", []string{"kubeadm", "init", "--skip-phases=preflight", "--config=/kind/kubeadm.conf"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	md.AssertCalled(t, "CopyFromContainer", "containerid", kindKubeConfigPath, mock.Anything)
+}
+
+func TestClusterKindWritesRegistryMirrors(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+	cc.Config = &ClusterConfig{DockerConfig: &DockerConfig{InsecureRegistries: []string{"registry.local:5000"}}}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+	assert.Equal(t, "/etc/containerd/certs.d/registry.local:5000/hosts.toml", params.Files[0].Destination)
+	assert.Contains(t, params.Files[0].Content, `server = "http://registry.local:5000"`)
+}
+
+func TestClusterKindJoinsAgentNodes(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+	cc.Nodes = 2
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[1].Arguments[0].(*ctypes.Container)
+	assert.Empty(t, params.Command)
+	assert.Empty(t, params.Files)
+
+	md.AssertCalled(t, "ExecuteCommand", "containerid", []string{
+		"kubeadm",
+		"join",
+		"--skip-phases=preflight",
+		"--token=" + cc.ClusterToken,
+		"--discovery-token-unsafe-skip-ca-verification",
+		"server.test.k8s-cluster.local.jmpd.in:443",
+	}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClusterKindImportsImagesIntoKubernetesNamespace(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+	cc.CopyImages = append(cc.CopyImages, container.Image{Name: "test:123"})
+
+	md.On("FindImageInLocalRegistry", mock.Anything).Return("abc123", nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "ExecuteCommand", "123", []string{"ctr", "--namespace=k8s.io", "image", "import", "/images/file.tar.gz"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClusterKindDestroyRemovesNodeVolumes(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+	cc.ContainerName = "server.test.k8s-cluster.local.jmpd.in"
+	cc.AgentContainerNames = []string{"abc.agent.test.k8s-cluster.local.jmpd.in"}
+
+	testutils.RemoveOn(&md.Mock, "FindContainerIDs")
+	md.On("FindContainerIDs", mock.Anything).Return([]string{"123"}, nil)
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err := p.Destroy(context.Background(), false)
+	assert.NoError(t, err)
+
+	md.AssertCalled(t, "RemoveVolume", "server.test.k8s-cluster.local.jmpd.in")
+	md.AssertCalled(t, "RemoveVolume", "abc.agent.test.k8s-cluster.local.jmpd.in")
+}
+
+func TestClusterKindAddsKubernetesConfig(t *testing.T) {
+	cc, md, mk, mc := setupKindMocks(t)
+
+	policy := filepath.Join(t.TempDir(), "audit.yaml")
+	err := os.WriteFile(policy, []byte("kind: Policy"), 0644)
+	assert.NoError(t, err)
+
+	cc.Kubernetes = &KubernetesConfig{
+		FeatureGates:     map[string]bool{"A": true},
+		AdmissionPlugins: []string{"PodSecurity"},
+		AuditPolicy:      policy,
+		Disable:          []string{ComponentKubeProxy, ComponentLocalStorage},
+		CNI:              CNINone,
+		ServerArgs:       []string{"--service-node-port-range=20000-30000"},
+		KubeletArgs:      []string{"--max-pods=200"},
+	}
+
+	p := ClusterProvider{cc, md, mk, nil, mc, logger.NewTestLogger(t)}
+
+	err = p.Create(context.Background())
+	assert.NoError(t, err)
+
+	params := testutils.GetCalls(&md.Mock, "CreateContainer")[0].Arguments[0].(*ctypes.Container)
+
+	assert.Equal(t, "/etc/default/kubelet", params.Files[0].Destination)
+	assert.Equal(t, "KUBELET_EXTRA_ARGS=--max-pods=200\n", params.Files[0].Content)
+	assert.Equal(t, "/etc/jumppad/audit-policy.yaml", params.Files[1].Destination)
+
+	kc := params.Files[2].Content
+	assert.Contains(t, kc, `    feature-gates: "A=true"`)
+	assert.Contains(t, kc, `    enable-admission-plugins: "PodSecurity"`)
+	assert.Contains(t, kc, `    audit-policy-file: "/etc/jumppad/audit-policy.yaml"`)
+	assert.Contains(t, kc, `    service-node-port-range: "20000-30000"`)
+	assert.Contains(t, kc, "  - name: audit-policy")
+	assert.Contains(t, kc, "controllerManager:\n  extraArgs:\n    feature-gates: \"A=true\"")
+	assert.Contains(t, kc, "featureGates:\n  A: true\n")
+
+	md.AssertCalled(t, "ExecuteCommand", "containerid", []string{"kubeadm", "init", "--skip-phases=preflight,addon/kube-proxy", "--config=/kind/kubeadm.conf"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// the default network and storage are not installed
+	for _, c := range testutils.GetCalls(&md.Mock, "ExecuteCommand") {
+		cmd := c.Arguments[1].([]string)
+		assert.NotContains(t, cmd[len(cmd)-1], "default-cni.yaml")
+		assert.NotContains(t, cmd[len(cmd)-1], "default-storage.yaml")
+	}
+}
+
 var clusterConfig = &Cluster{
-	ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test", Type: TypeK8sCluster}},
-	Image:        &container.Image{Name: "shipyardrun/k3s:v1.27.4"},
-	Networks:     []container.NetworkAttachment{container.NetworkAttachment{ID: "cloud"}},
-	APIPort:      443,
+	ResourceOptions: config.ResourceOptions{ResourceBase: htypes.ResourceBase{Meta: htypes.Meta{Name: "test", Type: TypeK8sCluster}}},
+	Image:           &container.Image{Name: "shipyardrun/k3s:v1.27.4"},
+	Networks:        []container.NetworkAttachment{container.NetworkAttachment{ID: "cloud"}},
+	APIPort:         443,
 }
 
 var kubeconfig = `apiVersion: v1
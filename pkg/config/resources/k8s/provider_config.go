@@ -4,16 +4,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/k8s"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
 
 var _ sdk.Provider = &ConfigProvider{}
+var _ config.Updater = &ConfigProvider{}
 
 type ConfigProvider struct {
 	config *Config
@@ -56,11 +59,26 @@ func (p *ConfigProvider) create(ctx context.Context) error {
 		return err
 	}
 
-	err = p.client.Apply(p.config.Paths, p.config.WaitUntilReady)
+	objects, err := p.client.ApplyConfig(p.config.Paths, p.applyOptions())
 	if err != nil {
 		return err
 	}
 
+	inventory := map[string][]string{}
+	for _, o := range objects {
+		inventory[o.Source] = append(inventory[o.Source], o.String())
+	}
+
+	// remove any objects that are no longer in the config
+	if p.config.Prune {
+		err := p.prune(inventory)
+		if err != nil {
+			return fmt.Errorf("unable to prune Kubernetes objects: %w", err)
+		}
+	}
+
+	p.config.Inventory = inventory
+
 	// run any health checks
 	if p.config.HealthCheck != nil && len(p.config.HealthCheck.Pods) > 0 {
 		to, err := time.ParseDuration(p.config.HealthCheck.Timeout)
@@ -102,7 +120,7 @@ func (p *ConfigProvider) destroy(ctx context.Context, force bool) error {
 		return err
 	}
 
-	err = p.client.Delete(p.config.Paths)
+	err = p.client.DeleteConfig(p.config.Paths, p.applyOptions())
 	if err != nil {
 		p.log.Debug("There was a problem destroying Kubernetes config, logging message but ignoring error", "ref", p.config.Meta.ID, "error", err)
 	}
@@ -114,7 +132,19 @@ func (p *ConfigProvider) Lookup() ([]string, error) {
 	return []string{}, nil
 }
 
+// Refresh re-applies any Kubernetes config that has changed
 func (p *ConfigProvider) Refresh(ctx context.Context) error {
+	return p.Update(ctx)
+}
+
+// Update applies the changed Kubernetes config in place and deletes any
+// config that has been removed
+func (p *ConfigProvider) Update(ctx context.Context) error {
+	if ctx.Err() != nil {
+		p.log.Debug("Skipping update, context cancelled", "ref", p.config.Meta.ID)
+		return nil
+	}
+
 	cp, dp, err := p.getChangedAndDeletedPaths()
 	if err != nil {
 		return err
@@ -124,11 +154,14 @@ func (p *ConfigProvider) Refresh(ctx context.Context) error {
 		return nil
 	}
 
-	p.log.Info("Refresh Kubernetes config", "ref", p.config.Meta.ID, "paths", cp)
+	p.log.Info("Update Kubernetes config", "ref", p.config.Meta.ID, "paths", cp)
 
-	err = p.client.Delete(dp)
-	if err != nil {
-		p.log.Debug("There was a problem destroying Kubernetes config, logging message but ignoring error", "ref", p.config.Meta.ID, "error", err)
+	// objects from removed paths are deleted when pruning
+	if !p.config.Prune {
+		err = p.client.DeleteConfig(dp, p.applyOptions())
+		if err != nil {
+			p.log.Debug("There was a problem destroying Kubernetes config, logging message but ignoring error", "ref", p.config.Meta.ID, "error", err)
+		}
 	}
 
 	return p.create(ctx)
@@ -158,6 +191,56 @@ func (p *ConfigProvider) setup() error {
 	return nil
 }
 
+func (p *ConfigProvider) applyOptions() k8s.ApplyOptions {
+	return k8s.ApplyOptions{
+		Namespace:      p.config.Namespace,
+		ServerSide:     p.config.ServerSideApply,
+		WaitUntilReady: p.config.WaitUntilReady,
+	}
+}
+
+// prune deletes the objects in the current inventory that are not in the
+// inventory of the applied config
+func (p *ConfigProvider) prune(inventory map[string][]string) error {
+	applied := map[string]bool{}
+	for _, objects := range inventory {
+		for _, o := range objects {
+			applied[o] = true
+		}
+	}
+
+	paths := []string{}
+	for k := range p.config.Inventory {
+		paths = append(paths, k)
+	}
+
+	sort.Strings(paths)
+
+	orphans := []k8s.Object{}
+	for _, path := range paths {
+		for _, o := range p.config.Inventory[path] {
+			if applied[o] {
+				continue
+			}
+
+			obj, err := k8s.ParseObject(o)
+			if err != nil {
+				return err
+			}
+
+			orphans = append(orphans, obj)
+		}
+	}
+
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	p.log.Info("Pruning Kubernetes objects", "ref", p.config.Meta.ID, "objects", len(orphans))
+
+	return p.client.DeleteObjects(orphans)
+}
+
 // generateChecksums generates a sha256 checksum for each of the the paths
 func (p *ConfigProvider) generateChecksums() (map[string]string, error) {
 	checksums := map[string]string{}
@@ -10,6 +10,7 @@ import (
 	"github.com/jumppad-labs/hclconfig/types"
 	k8scli "github.com/jumppad-labs/jumppad/pkg/clients/k8s"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/assert"
@@ -19,16 +20,17 @@ import (
 func setupK8sConfig(t *testing.T) (*k8scli.MockKubernetes, *ConfigProvider) {
 	mk := &k8scli.MockKubernetes{}
 	mk.On("SetConfig", mock.Anything).Return(nil)
-	mk.On("Apply", mock.Anything, mock.Anything).Return(nil)
-	mk.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	mk.On("ApplyConfig", mock.Anything, mock.Anything).Return([]k8scli.Object{}, nil)
+	mk.On("DeleteConfig", mock.Anything, mock.Anything).Return(nil)
+	mk.On("DeleteObjects", mock.Anything).Return(nil)
 
 	// create the test files
 	d := t.TempDir()
 	os.WriteFile(fmt.Sprintf("%s/testfile1", d), []byte("test1"), 0644)
 	os.WriteFile(fmt.Sprintf("%s/testfile2", d), []byte("test2"), 0644)
 
-	c := Cluster{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testcluster"}}}
-	kc := Config{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "config"}}}
+	c := Cluster{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testcluster"}}}}
+	kc := Config{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "config"}}}}
 	kc.Cluster = c
 	kc.Paths = []string{
 		fmt.Sprintf("%s/testfile1", d),
@@ -48,7 +50,7 @@ func TestCreatesCorrectly(t *testing.T) {
 
 	//_, destPath, _ := utils.CreateKubeConfigPath("testcluster")
 	//mk.AssertCalled(t, "SetConfig", destPath)
-	mk.AssertCalled(t, "Apply", p.config.Paths, p.config.WaitUntilReady)
+	mk.AssertCalled(t, "ApplyConfig", p.config.Paths, k8scli.ApplyOptions{WaitUntilReady: p.config.WaitUntilReady})
 }
 
 func TestRunsHealthChecks(t *testing.T) {
@@ -94,7 +96,7 @@ func TestDestroysCorrectly(t *testing.T) {
 	err := p.Destroy(context.Background(), false)
 	assert.NoError(t, err)
 
-	mk.AssertCalled(t, "Delete", p.config.Paths)
+	mk.AssertCalled(t, "DeleteConfig", p.config.Paths, k8scli.ApplyOptions{})
 }
 
 func TestDestroySetupErrorReturnsError(t *testing.T) {
@@ -113,7 +115,7 @@ func TestRefreshWithChangedFileReapplies(t *testing.T) {
 	err := p.Create(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Apply", 1)
+	mk.AssertNumberOfCalls(t, "ApplyConfig", 1)
 
 	// change the file
 	os.WriteFile(p.config.Paths[0], []byte("test3"), 0644)
@@ -121,7 +123,7 @@ func TestRefreshWithChangedFileReapplies(t *testing.T) {
 	err = p.Refresh(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Apply", 2)
+	mk.AssertNumberOfCalls(t, "ApplyConfig", 2)
 }
 
 func TestRefreshWithRemovedFileDeletes(t *testing.T) {
@@ -131,7 +133,7 @@ func TestRefreshWithRemovedFileDeletes(t *testing.T) {
 	err := p.Create(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Apply", 1)
+	mk.AssertNumberOfCalls(t, "ApplyConfig", 1)
 
 	// change the file
 	p.config.Paths = p.config.Paths[1:]
@@ -139,7 +141,7 @@ func TestRefreshWithRemovedFileDeletes(t *testing.T) {
 	err = p.Refresh(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Delete", 1)
+	mk.AssertNumberOfCalls(t, "DeleteConfig", 1)
 }
 
 func TestRefreshWithAddedFileApplies(t *testing.T) {
@@ -149,7 +151,7 @@ func TestRefreshWithAddedFileApplies(t *testing.T) {
 	err := p.Create(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Apply", 1)
+	mk.AssertNumberOfCalls(t, "ApplyConfig", 1)
 
 	// change the file
 	d := t.TempDir()
@@ -159,5 +161,76 @@ func TestRefreshWithAddedFileApplies(t *testing.T) {
 	err = p.Refresh(context.Background())
 	assert.NoError(t, err)
 
-	mk.AssertNumberOfCalls(t, "Apply", 2)
+	mk.AssertNumberOfCalls(t, "ApplyConfig", 2)
+}
+
+func TestCreatesWithNamespaceAndServerSideApply(t *testing.T) {
+	mk, p := setupK8sConfig(t)
+	p.config.Namespace = "apps"
+	p.config.ServerSideApply = true
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	mk.AssertCalled(t, "ApplyConfig", p.config.Paths, k8scli.ApplyOptions{Namespace: "apps", ServerSide: true})
+}
+
+func TestCreateStoresInventory(t *testing.T) {
+	mk, p := setupK8sConfig(t)
+	testutils.RemoveOn(&mk.Mock, "ApplyConfig")
+	mk.On("ApplyConfig", mock.Anything, mock.Anything).Return([]k8scli.Object{
+		{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "one", Source: p.config.Paths[0]},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "default", Name: "two", Source: p.config.Paths[1]},
+	}, nil)
+
+	err := p.Create(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v1/ConfigMap/default/one"}, p.config.Inventory[p.config.Paths[0]])
+	assert.Equal(t, []string{"apps/v1/Deployment/default/two"}, p.config.Inventory[p.config.Paths[1]])
+	mk.AssertNotCalled(t, "DeleteObjects", mock.Anything)
+}
+
+func TestRefreshWithPruneDeletesOrphans(t *testing.T) {
+	mk, p := setupK8sConfig(t)
+	p.config.Prune = true
+	p.config.Inventory = map[string][]string{
+		p.config.Paths[0]: {"v1/ConfigMap/default/one", "v1/ConfigMap/default/old"},
+		"/removed":        {"apps/v1/Deployment/default/removed"},
+	}
+	p.config.JobChecksums = map[string]string{p.config.Paths[0]: "changed", "/removed": "removed"}
+
+	testutils.RemoveOn(&mk.Mock, "ApplyConfig")
+	mk.On("ApplyConfig", mock.Anything, mock.Anything).Return([]k8scli.Object{
+		{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "one", Source: p.config.Paths[0]},
+	}, nil)
+
+	err := p.Refresh(context.Background())
+	assert.NoError(t, err)
+
+	mk.AssertCalled(t, "DeleteObjects", []k8scli.Object{
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "default", Name: "removed"},
+		{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "old"},
+	})
+
+	// removed paths are pruned rather than deleted
+	mk.AssertNotCalled(t, "DeleteConfig", mock.Anything, mock.Anything)
+	assert.Equal(t, map[string][]string{p.config.Paths[0]: {"v1/ConfigMap/default/one"}}, p.config.Inventory)
+}
+
+func TestPruneErrorReturnsError(t *testing.T) {
+	mk, p := setupK8sConfig(t)
+	p.config.Prune = true
+	p.config.Inventory = map[string][]string{
+		p.config.Paths[0]: {"v1/ConfigMap/default/old"},
+	}
+
+	testutils.RemoveOn(&mk.Mock, "DeleteObjects")
+	mk.On("DeleteObjects", mock.Anything).Return(fmt.Errorf("boom"))
+
+	err := p.Create(context.Background())
+	assert.Error(t, err)
+
+	// the inventory is kept so that the prune is retried
+	assert.Equal(t, []string{"v1/ConfigMap/default/old"}, p.config.Inventory[p.config.Paths[0]])
 }
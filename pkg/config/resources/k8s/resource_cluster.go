@@ -2,8 +2,8 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -16,14 +16,31 @@ const TypeKubernetesCluster string = "kubernetes_cluster"
 // Cluster is a config stanza which defines a Kubernetes or a Nomad cluster
 type Cluster struct {
 	// embedded type holding name, etc.
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Networks []container.NetworkAttachment `hcl:"network,block" json:"networks,omitempty"` // Attach to the correct network // only when Image is specified
 
-	Image   *container.Image   `hcl:"image,block" json:"images,omitempty"` // optional image to use when creating the cluster
-	Nodes   int                `hcl:"nodes,optional" json:"nodes,omitempty"`
+	Image *container.Image `hcl:"image,block" json:"images,omitempty"` // optional image to use when creating the cluster
+
+	// Distribution of Kubernetes run by the nodes, either k3s or kind,
+	// defaults to k3s. The image must be a node image for the distribution
+	// i.e. kindest/node for kind.
+	Distribution string `hcl:"distribution,optional" json:"distribution,omitempty"`
+
+	// Nodes is the total number of nodes in the cluster including the server,
+	// when greater than 1 additional agent nodes are joined to the server
+	Nodes int `hcl:"nodes,optional" json:"nodes,omitempty"`
+
 	Volumes []container.Volume `hcl:"volume,block" json:"volumes,omitempty"` // volumes to attach to the cluster
 
+	// Files to write to the server container before it is started, changes
+	// to the content are written to the running cluster
+	Files container.Files `hcl:"file,block" json:"files,omitempty"`
+
+	// Platform of the cluster image and the copied images e.g. linux/arm64,
+	// defaults to the platform of the engine
+	Platform string `hcl:"platform,optional" json:"platform,omitempty"`
+
 	// Images that will be copied from the local docker cache to the cluster
 	CopyImages []container.Image `hcl:"copy_image,block" json:"copy_images,omitempty"`
 
@@ -46,6 +63,10 @@ type Cluster struct {
 
 	Config *ClusterConfig `hcl:"config,block" json:"config,omitempty"`
 
+	// Kubernetes configures the control plane and the kubelet, changes to
+	// the configuration recreate the cluster
+	Kubernetes *KubernetesConfig `hcl:"kubernetes,block" json:"kubernetes,omitempty"`
+
 	// output parameters
 
 	// Kubernetes config details
@@ -61,9 +82,20 @@ type Cluster struct {
 	// used to reference the container within docker and from other containers
 	ContainerName string `hcl:"container_name,optional" json:"container_name,omitempty"`
 
+	// Fully qualified domain names for the agent node containers
+	AgentContainerNames []string `hcl:"agent_container_names,optional" json:"agent_container_names,omitempty"`
+
+	// ClusterToken is the generated token used to join agent nodes to the
+	// server, the value is redacted from logs and status output
+	ClusterToken string `hcl:"cluster_token,optional" json:"cluster_token,omitempty" sensitive:"true"`
+
 	// ExternalIP is the ip address of the cluster, this generally resolves
 	// to the docker ip
 	ExternalIP string `hcl:"external_ip,optional" json:"external_ip,omitempty"`
+
+	// KubernetesChecksum is the checksum of the kubernetes block and the
+	// files it references when the cluster was created
+	KubernetesChecksum string `hcl:"kubernetes_checksum,optional" json:"kubernetes_checksum,omitempty"`
 }
 
 type ClusterConfig struct {
@@ -79,6 +111,34 @@ type DockerConfig struct {
 	InsecureRegistries []string `hcl:"insecure_registries,optional" json:"insecure-registries,omitempty"`
 }
 
+type KubernetesConfig struct {
+	// FeatureGates to enable or disable on the control plane and the kubelet
+	FeatureGates map[string]bool `hcl:"feature_gates,optional" json:"feature_gates,omitempty"`
+
+	// AdmissionPlugins to enable on the API server in addition to the defaults
+	AdmissionPlugins []string `hcl:"admission_plugins,optional" json:"admission_plugins,omitempty"`
+
+	// AuditPolicy is the path to an audit policy file for the API server, the
+	// audit log is written to /var/log/kubernetes/audit.log on the server
+	AuditPolicy string `hcl:"audit_policy,optional" json:"audit_policy,omitempty"`
+
+	// Disable is a list of the default components that are not deployed,
+	// k3s supports coredns, servicelb, traefik, local-storage and
+	// metrics-server, kind supports coredns, kube-proxy and local-storage
+	Disable []string `hcl:"disable,optional" json:"disable,omitempty"`
+
+	// CNI is the network plugin for the cluster, either flannel (default),
+	// none, or the path to a manifest that installs a plugin i.e. calico
+	CNI string `hcl:"cni,optional" json:"cni,omitempty"`
+
+	// ServerArgs are additional flags for the server i.e. --cluster-cidr=10.50.0.0/16,
+	// k3s passes the flags to the k3s server, kind to the API server
+	ServerArgs []string `hcl:"server_args,optional" json:"server_args,omitempty"`
+
+	// KubeletArgs are additional flags for the kubelet on all nodes i.e. --max-pods=200
+	KubeletArgs []string `hcl:"kubelet_args,optional" json:"kubelet_args,omitempty"`
+}
+
 type KubeConfig struct {
 	ConfigPath        string `hcl:"path" json:"path"`                             // path to the kubeconfig file
 	CA                string `hcl:"ca" json:"ca"`                                 // base64 encoded ca certificate
@@ -86,22 +146,88 @@ type KubeConfig struct {
 	ClientKey         string `hcl:"client_key" json:"client_key"`                 // base64 encoded client key
 }
 
+const (
+	// DistributionK3s runs the cluster using k3s
+	DistributionK3s = "k3s"
+	// DistributionKind runs the cluster using kind node images and kubeadm
+	DistributionKind = "kind"
+)
+
+const (
+	// CNIFlannel is the default network plugin for the distribution
+	CNIFlannel = "flannel"
+	// CNINone does not install a network plugin, pods do not start until a
+	// plugin is installed
+	CNINone = "none"
+)
+
+const (
+	ComponentCoreDNS       = "coredns"
+	ComponentKubeProxy     = "kube-proxy"
+	ComponentLocalStorage  = "local-storage"
+	ComponentMetricsServer = "metrics-server"
+	ComponentServiceLB     = "servicelb"
+	ComponentTraefik       = "traefik"
+)
+
+// components are the default components for each distribution that can be
+// disabled
+var components = map[string][]string{
+	DistributionK3s:  {ComponentCoreDNS, ComponentServiceLB, ComponentTraefik, ComponentLocalStorage, ComponentMetricsServer},
+	DistributionKind: {ComponentCoreDNS, ComponentKubeProxy, ComponentLocalStorage},
+}
+
 const k3sBaseImage = "ghcr.io/jumppad-labs/kubernetes"
 const k3sBaseVersion = "v1.31.1"
 
+const kindBaseImage = "kindest/node"
+const kindBaseVersion = "v1.31.0"
+
 func (k *Cluster) Process() error {
 	if k.APIPort == 0 {
 		k.APIPort = 443
 	}
 
+	if k.Distribution == "" {
+		k.Distribution = DistributionK3s
+	}
+
+	if k.Distribution != DistributionK3s && k.Distribution != DistributionKind {
+		return fmt.Errorf("invalid distribution %s, the distribution must be one of %s or %s", k.Distribution, DistributionK3s, DistributionKind)
+	}
+
 	if k.Image == nil {
 		k.Image = &container.Image{Name: fmt.Sprintf("%s:%s", k3sBaseImage, k3sBaseVersion)}
+
+		if k.Distribution == DistributionKind {
+			k.Image = &container.Image{Name: fmt.Sprintf("%s:%s", kindBaseImage, kindBaseVersion)}
+		}
 	}
 
 	for i, v := range k.Volumes {
 		k.Volumes[i].Source = utils.EnsureAbsolute(v.Source, k.Meta.File)
 	}
 
+	if k.Kubernetes != nil {
+		if k.Kubernetes.CNI == "" {
+			k.Kubernetes.CNI = CNIFlannel
+		}
+
+		if k.Kubernetes.CNI != CNIFlannel && k.Kubernetes.CNI != CNINone {
+			k.Kubernetes.CNI = utils.EnsureAbsolute(k.Kubernetes.CNI, k.Meta.File)
+		}
+
+		if k.Kubernetes.AuditPolicy != "" {
+			k.Kubernetes.AuditPolicy = utils.EnsureAbsolute(k.Kubernetes.AuditPolicy, k.Meta.File)
+		}
+
+		for _, d := range k.Kubernetes.Disable {
+			if !contains(components[k.Distribution], d) {
+				return fmt.Errorf("invalid component %s in kubernetes.disable, %s clusters can disable %s", d, k.Distribution, strings.Join(components[k.Distribution], ", "))
+			}
+		}
+	}
+
 	if k.Resources == nil {
 		k.Resources = &container.Resources{
 			CPU:    500,
@@ -109,6 +235,11 @@ func (k *Cluster) Process() error {
 		}
 	}
 
+	err := k.Files.Process(k.Meta.File)
+	if err != nil {
+		return err
+	}
+
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
 	c, err := config.LoadState()
@@ -119,6 +250,9 @@ func (k *Cluster) Process() error {
 			kstate := r.(*Cluster)
 			k.KubeConfig = kstate.KubeConfig
 			k.ContainerName = kstate.ContainerName
+			k.AgentContainerNames = kstate.AgentContainerNames
+			k.ClusterToken = kstate.ClusterToken
+			k.KubernetesChecksum = kstate.KubernetesChecksum
 			k.APIPort = kstate.APIPort
 			k.ConnectorPort = kstate.ConnectorPort
 			k.ExternalIP = kstate.ExternalIP
@@ -145,6 +279,9 @@ func (k *Cluster) Process() error {
 				}
 			}
 
+			// add the file checksums
+			k.Files.SetChecksums(kstate.Files)
+
 			// the network name is set
 			copy(k.Networks, kstate.Networks)
 		}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
@@ -13,6 +14,7 @@ import (
 
 func init() {
 	config.RegisterResource(TypeK8sCluster, &Cluster{}, &ClusterProvider{})
+	config.RegisterResource(TypeK8sConfig, &Config{}, &ConfigProvider{})
 }
 
 func TestK8sClusterProcessSetsAbsolute(t *testing.T) {
@@ -20,7 +22,7 @@ func TestK8sClusterProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Cluster{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Volumes: []ctypes.Volume{
 			{
 				Source:      "./",
@@ -34,6 +36,59 @@ func TestK8sClusterProcessSetsAbsolute(t *testing.T) {
 	require.Equal(t, wd, c.Volumes[0].Source)
 }
 
+func TestK8sClusterProcessSetsDefaultKindImage(t *testing.T) {
+	c := &Cluster{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Distribution:    DistributionKind,
+	}
+
+	err := c.Process()
+	require.NoError(t, err)
+
+	require.Equal(t, "kindest/node:v1.31.0", c.Image.Name)
+}
+
+func TestK8sClusterProcessReturnsErrorForInvalidDistribution(t *testing.T) {
+	c := &Cluster{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Distribution:    "microk8s",
+	}
+
+	err := c.Process()
+	require.ErrorContains(t, err, "invalid distribution microk8s")
+}
+
+func TestK8sClusterProcessSetsKubernetesDefaults(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	c := &Cluster{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Kubernetes: &KubernetesConfig{
+			AuditPolicy: "./audit.yaml",
+		},
+	}
+
+	err = c.Process()
+	require.NoError(t, err)
+
+	require.Equal(t, CNIFlannel, c.Kubernetes.CNI)
+	require.Equal(t, filepath.Join(wd, "audit.yaml"), c.Kubernetes.AuditPolicy)
+}
+
+func TestK8sClusterProcessReturnsErrorForInvalidDisabledComponent(t *testing.T) {
+	c := &Cluster{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Distribution:    DistributionKind,
+		Kubernetes: &KubernetesConfig{
+			Disable: []string{ComponentTraefik},
+		},
+	}
+
+	err := c.Process()
+	require.ErrorContains(t, err, "invalid component traefik")
+}
+
 func TestK8sClusterSetsOutputsFromState(t *testing.T) {
 	testutils.SetupState(t, `
 {
@@ -60,9 +115,11 @@ func TestK8sClusterSetsOutputsFromState(t *testing.T) {
 }`)
 
 	c := &Cluster{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{
-				ID: "resource.k8s_cluster.test",
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					ID: "resource.k8s_cluster.test",
+				},
 			},
 		},
 		Networks: []ctypes.NetworkAttachment{
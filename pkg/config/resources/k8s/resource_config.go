@@ -1,7 +1,6 @@
 package k8s
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -13,15 +12,27 @@ const TypeKubernetesConfig string = "kubernetes_config"
 
 // K8sConfig applies and deletes and deletes Kubernetes configuration
 type Config struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Cluster Cluster `hcl:"cluster" json:"cluster"`
 
-	// Path of a file or directory of Kubernetes config files to apply
+	// Path of a file, a directory of Kubernetes config files, or a kustomize
+	// directory to apply
 	Paths []string `hcl:"paths" validator:"filepath" json:"paths"`
 	// WaitUntilReady when set to true waits until all resources have been created and are in a "Running" state
 	WaitUntilReady bool `hcl:"wait_until_ready" json:"wait_until_ready"`
 
+	// Namespace for the objects that do not specify a namespace, defaults to
+	// the default namespace, the namespace must exist
+	Namespace string `hcl:"namespace,optional" json:"namespace,omitempty"`
+	// ServerSideApply applies the config with server-side apply, fields
+	// removed from the config are also removed from existing objects. Without
+	// server-side apply existing objects are updated with a merge patch
+	ServerSideApply bool `hcl:"server_side_apply,optional" json:"server_side_apply,omitempty"`
+	// Prune deletes the objects that were previously applied but are no
+	// longer in the config
+	Prune bool `hcl:"prune,optional" json:"prune,omitempty"`
+
 	// HealthCheck defines a health check for the resource
 	HealthCheck *healthcheck.HealthCheckKubernetes `hcl:"health_check,block" json:"health_check,omitempty"`
 
@@ -30,6 +41,11 @@ type Config struct {
 	// JobChecksums store a checksum of the files or paths referenced in the Paths field
 	// this is used to detect when a file changes so that it can be re-applied
 	JobChecksums map[string]string `hcl:"job_checksums,optional" json:"job_checksums,omitempty"`
+
+	// Inventory stores the objects that were applied from each of the paths
+	// in the format [group/]version/kind/namespace/name, this is used to find
+	// the objects to delete when prune is set
+	Inventory map[string][]string `hcl:"inventory,optional" json:"inventory,omitempty"`
 }
 
 func (k *Config) Process() error {
@@ -45,6 +61,7 @@ func (k *Config) Process() error {
 		if r != nil {
 			state := r.(*Config)
 			k.JobChecksums = state.JobChecksums
+			k.Inventory = state.Inventory
 		}
 	}
 
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,8 +16,8 @@ func TestK8sConfigProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	k := &Config{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Paths:        []string{"./one.yaml", "./two.yaml"},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Paths:           []string{"./one.yaml", "./two.yaml"},
 	}
 
 	err = k.Process()
@@ -24,3 +26,41 @@ func TestK8sConfigProcessSetsAbsolute(t *testing.T) {
 	require.Equal(t, path.Join(wd, "one.yaml"), k.Paths[0])
 	require.Equal(t, path.Join(wd, "two.yaml"), k.Paths[1])
 }
+
+func TestK8sConfigSetsOutputsFromState(t *testing.T) {
+	testutils.SetupState(t, `
+{
+  "blueprint": null,
+  "resources": [
+  {
+      "meta": {
+      "id": "resource.k8s_config.test",
+      "name": "test",
+      "type": "k8s_config"
+      },
+      "job_checksums": {
+        "/app.yaml": "abc"
+      },
+      "inventory": {
+        "/app.yaml": ["v1/ConfigMap/default/config"]
+      }
+  }]
+}`)
+
+	k := &Config{
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{
+					File: "./",
+					ID:   "resource.k8s_config.test",
+				},
+			},
+		},
+	}
+
+	err := k.Process()
+	require.NoError(t, err)
+
+	require.Equal(t, "abc", k.JobChecksums["/app.yaml"])
+	require.Equal(t, []string{"v1/ConfigMap/default/config"}, k.Inventory["/app.yaml"])
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net"
 
-	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
@@ -18,7 +17,7 @@ var _ sdk.Provider = &Provider{}
 // Network is a provider for creating docker networks
 type Provider struct {
 	config *Network
-	client container.Docker
+	client container.ContainerTasks
 	log    sdk.Logger
 }
 
@@ -34,7 +33,7 @@ func (p *Provider) Init(cfg htypes.Resource, l sdk.Logger) error {
 	}
 
 	p.config = c
-	p.client = cli.Docker
+	p.client = cli.ContainerTasks
 	p.log = l
 
 	return nil
@@ -185,15 +184,11 @@ func (p *Provider) createWithDriver(driver string) error {
 		Attachable: true,
 	}
 
-	_, err := p.client.NetworkCreate(context.Background(), p.config.Meta.Name, opts)
-
-	return err
+	return p.client.NetworkCreate(context.Background(), p.config.Meta.Name, opts)
 }
 
 func (p *Provider) getNetworks(name string) ([]network.Summary, error) {
-	args := filters.NewArgs()
-	args.Add("name", name)
-	return p.client.NetworkList(context.Background(), network.ListOptions{Filters: args})
+	return p.client.NetworkList(context.Background(), name)
 }
 
 func (p *Provider) getHostIPs() ([]net.IP, error) {
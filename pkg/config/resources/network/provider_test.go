@@ -3,12 +3,15 @@ package network
 import (
 	"context"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/docker/docker/api/types/network"
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/testutils"
 	"github.com/stretchr/testify/mock"
 	assert "github.com/stretchr/testify/require"
@@ -22,9 +25,9 @@ var bridgeNetwork = network.Summary{
 	},
 }
 
-func setupNetworkTests(t *testing.T, c *Network) (*mocks.Docker, *Provider) {
-	md := &mocks.Docker{}
-	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Return(network.CreateResponse{}, nil)
+func setupNetworkTests(t *testing.T, c *Network) (*mocks.ContainerTasks, *Provider) {
+	md := &mocks.ContainerTasks{}
+	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	md.On("NetworkList", mock.Anything, mock.Anything).Return([]network.Summary{bridgeNetwork}, nil)
 
 	return md, &Provider{
@@ -36,7 +39,7 @@ func setupNetworkTests(t *testing.T, c *Network) (*mocks.Docker, *Provider) {
 
 func TestLookupReturnsID(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 
 	c.Subnet = "10.1.2.0/24"
@@ -59,7 +62,7 @@ func TestLookupReturnsID(t *testing.T) {
 }
 func TestLookupFailReturnsError(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 
 	c.Subnet = "10.1.2.0/24"
@@ -73,7 +76,7 @@ func TestLookupFailReturnsError(t *testing.T) {
 }
 func TestNetworkCreatesCorrectly(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 	c.Subnet = "10.1.2.0/24"
 
@@ -97,7 +100,7 @@ func TestNetworkCreatesCorrectly(t *testing.T) {
 
 func TestNetworkCreatesNatWhenNoBridge(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 	c.Subnet = "10.1.2.0/24"
 
@@ -106,8 +109,8 @@ func TestNetworkCreatesNatWhenNoBridge(t *testing.T) {
 	testutils.RemoveOn(&md.Mock, "NetworkList")
 	testutils.RemoveOn(&md.Mock, "NetworkCreate")
 	md.On("NetworkList", mock.Anything, mock.Anything).Return(nil, nil)
-	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Once().Return(network.CreateResponse{}, fmt.Errorf("boom"))
-	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Once().Return(network.CreateResponse{}, nil)
+	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Once().Return(fmt.Errorf("boom"))
+	md.On("NetworkCreate", mock.Anything, mock.Anything, mock.Anything).Once().Return(nil)
 
 	p.Create(context.Background())
 
@@ -120,7 +123,7 @@ func TestNetworkCreatesNatWhenNoBridge(t *testing.T) {
 
 func TestNetworkDoesNOTCreateWhenExists(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 	c.Subnet = "10.1.2.0/24"
 
@@ -142,7 +145,7 @@ func TestNetworkDoesNOTCreateWhenExists(t *testing.T) {
 
 func TestCreateWithCorrectNameAndDifferentSubnetReturnsError(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 	c.Subnet = "10.1.2.0/16"
 
@@ -163,7 +166,7 @@ func TestCreateWithCorrectNameAndDifferentSubnetReturnsError(t *testing.T) {
 
 func TestCreateWithOverlappingSubnetReturnsError(t *testing.T) {
 	c := &Network{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork"}}},
 	}
 	c.Subnet = "10.2.3.0/16"
 
@@ -181,3 +184,30 @@ func TestCreateWithOverlappingSubnetReturnsError(t *testing.T) {
 	err := p.Create(context.Background())
 	assert.Error(t, err)
 }
+
+func TestNetworkCreatesWithContainerd(t *testing.T) {
+	c := &Network{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "testnetwork", ID: "resource.network.testnetwork"}}},
+	}
+	c.Subnet = "10.1.2.0/24"
+
+	_, p := setupNetworkTests(t, c)
+
+	mn := &mocks.Nerdctl{}
+	mn.On("Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, []string{"info", "--format", "{{json .}}"}).
+		Run(func(args mock.Arguments) { args.Get(2).(io.Writer).Write([]byte(`{"Driver":"overlayfs"}`)) }).
+		Return(nil)
+	mn.On("Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ct, err := container.NewContainerdTasks(mn, nil, nil, logger.NewTestLogger(t))
+	assert.NoError(t, err)
+	p.client = ct
+
+	err = p.Create(context.Background())
+	assert.NoError(t, err)
+
+	mn.AssertCalled(t, "Execute", mock.Anything, mock.Anything, mock.Anything, mock.Anything, []string{
+		"network", "create", "--driver", "bridge", "--subnet", "10.1.2.0/24",
+		"--label", "created_by=jumppad", "--label", "id=resource.network.testnetwork", "testnetwork",
+	})
+}
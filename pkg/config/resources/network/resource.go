@@ -1,7 +1,7 @@
 package network
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
 // TypeNetwork is the string resource type for Network resources
@@ -10,7 +10,7 @@ const TypeNetwork string = "network"
 // Network defines a Docker network
 type Network struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Subnet     string `hcl:"subnet" json:"subnet"`
 	EnableIPv6 bool   `hcl:"enable_ipv6,optional" json:"enable_ipv6"`
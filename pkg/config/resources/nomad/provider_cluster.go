@@ -22,6 +22,9 @@ import (
 	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
 	"github.com/jumppad-labs/jumppad/pkg/clients/nomad"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
@@ -134,6 +137,21 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 		}
 	}
 
+	// write any changed files to the existing nodes, this must happen before
+	// any new nodes are created as creating a node updates the checksums
+	cf, err := p.config.Files.Changed()
+	if err != nil {
+		return err
+	}
+
+	if len(cf) > 0 {
+		p.log.Info("Files changed, writing new content to the cluster nodes", "ref", p.config.Meta.ID)
+		err := p.copyFiles(cf)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Has the number of clients nodes changed and are we scaling down?
 	if p.config.ClientNodes < len(p.config.ClientContainerName) {
 		// calculate the number of nodes that should be removed
@@ -178,6 +196,12 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 		return fmt.Errorf("unable to create docker config: %s", err)
 	}
 
+	// read the files for any new nodes
+	files, err := p.config.Files.ToClientFiles()
+	if err != nil {
+		return err
+	}
+
 	// do we need to scale the cluster up
 	if p.config.ClientNodes > len(p.config.ClientContainerName) {
 		// need to scale up
@@ -188,7 +212,7 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 
 			p.log.Debug("Create client node", "ref", p.config.Meta.ID, "client", id)
 
-			fqdn, _, err := p.createClientNode(randomID(), p.config.Image.Name, utils.ImageVolumeName, p.config.ServerContainerName, dockerConfigPath)
+			fqdn, _, err := p.createClientNode(randomID(), p.config.Image.Name, utils.ImageVolumeName(), p.config.ServerContainerName, dockerConfigPath, files)
 			if err != nil {
 				return fmt.Errorf(`unable to recreate client node "%s", %s`, id, err)
 			}
@@ -213,7 +237,7 @@ func (p *ClusterProvider) Refresh(ctx context.Context) error {
 
 	if len(ci) > 0 {
 		p.log.Info("Copied images changed, pushing new copy to the cluster", "ref", p.config.Meta.ID)
-		err := p.ImportLocalDockerImages(ci, false)
+		err := p.importLocalDockerImages(ctx, ci, false)
 		if err != nil {
 			return err
 		}
@@ -235,11 +259,53 @@ func (p *ClusterProvider) Changed() (bool, error) {
 		return true, nil
 	}
 
+	// check to see if the content of any of the files has changed
+	f, err := p.config.Files.Changed()
+	if err != nil {
+		return false, err
+	}
+
+	if len(f) > 0 {
+		return true, nil
+	}
+
 	return false, nil
 }
 
+// copyFiles writes the given files to the server and client nodes and
+// updates the checksums for the files in the config
+func (p *ClusterProvider) copyFiles(files container.Files) error {
+	ids, err := p.Lookup()
+	if err != nil {
+		return err
+	}
+
+	cf, err := files.ToClientFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := p.client.CopyFilesToContainer(id, cf)
+		if err != nil {
+			return err
+		}
+	}
+
+	// update the checksums in the config
+	p.config.Files.SetChecksums(files)
+
+	return nil
+}
+
 // ImportLocalDockerImages fetches Docker images stored on the local client and imports them into the cluster
 func (p *ClusterProvider) ImportLocalDockerImages(images []ctypes.Image, force bool) error {
+	return p.importLocalDockerImages(context.Background(), images, force)
+}
+
+// importLocalDockerImages imports the images into all the cluster nodes, the
+// images are checked against the image policies in the context
+func (p *ClusterProvider) importLocalDockerImages(ctx context.Context, images []ctypes.Image, force bool) error {
 	ids, err := p.Lookup()
 	if err != nil {
 		return err
@@ -252,16 +318,25 @@ func (p *ClusterProvider) ImportLocalDockerImages(images []ctypes.Image, force b
 			continue
 		}
 
+		// images are pulled for the platform of the cluster
+		i.Platform = p.config.Platform
+
 		err := p.client.PullImage(i, false)
 		if err != nil {
 			return err
 		}
 
+		err = policy.Enforce(ctx, p.client, i.Name, "")
+		if err != nil {
+			p.log.Error("Copied image does not meet the image policy", "ref", p.config.Meta.ID, "image", i.Name, "error", err)
+			return err
+		}
+
 		imgs = append(imgs, i.Name)
 	}
 
 	// import to volume
-	vn := utils.FQDNVolumeName(utils.ImageVolumeName)
+	vn := utils.FQDNVolumeName(utils.ImageVolumeName())
 	imagesFile, err := p.client.CopyLocalDockerImagesToVolume(imgs, vn, force)
 	if err != nil {
 		return err
@@ -344,13 +419,22 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 	}
 
 	// pull the container image
-	err = p.client.PullImage(p.config.Image.ToClientImage(), false)
+	img := p.config.Image.ToClientImage()
+	img.Platform = p.config.Platform
+
+	err = p.client.PullImage(img, false)
+	if err != nil {
+		return err
+	}
+
+	err = policy.EnforceNode(ctx, p.client, img.Name)
 	if err != nil {
+		p.log.Error("Cluster image does not meet the image policy", "ref", p.config.Meta.ID, "image", img.Name, "error", err)
 		return err
 	}
 
 	// create the volume for the cluster
-	volID, err := p.client.CreateVolume(utils.ImageVolumeName)
+	volID, err := p.client.CreateVolume(utils.ImageVolumeName())
 	if err != nil {
 		return err
 	}
@@ -362,7 +446,7 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 
 	// set the API server port to a random number
 	p.config.ConnectorPort = rand.Intn(utils.MaxRandomPort-utils.MinRandomPort) + utils.MinRandomPort
-	p.config.ConfigDir = path.Join(utils.JumppadHome(), strings.Replace(p.config.Meta.ID, ".", "_", -1), "config")
+	p.config.ConfigDir = path.Join(utils.WorkspaceHome(utils.Workspace()), strings.Replace(p.config.Meta.ID, ".", "_", -1), "config")
 
 	// set the external IP to the address where the docker daemon is running
 	p.config.ExternalIP = utils.GetDockerIP()
@@ -380,7 +464,21 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 		return fmt.Errorf("unable to create docker config: %s", err)
 	}
 
-	_, err = p.createServerNode(p.config.Image.ToClientImage(), volID, isClient, dockerConfigPath)
+	// read the files once as the nodes are created concurrently
+	files, err := p.config.Files.ToClientFiles()
+	if err != nil {
+		return err
+	}
+
+	// generate a new key to encrypt the gossip traffic for the cluster
+	p.config.GossipKey, err = secrets.GenerateKey(32)
+	if err != nil {
+		return fmt.Errorf("unable to generate gossip key: %w", err)
+	}
+
+	secrets.Register(p.config.GossipKey)
+
+	_, err = p.createServerNode(img, volID, isClient, dockerConfigPath, files)
 	if err != nil {
 		return err
 	}
@@ -397,7 +495,7 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 	for i := 0; i < p.config.ClientNodes; i++ {
 		// create client node asynchronously
 		go func(id string, image, volID, name string) {
-			fqdn, _, err := p.createClientNode(id, image, volID, name, dockerConfigPath)
+			fqdn, _, err := p.createClientNode(id, image, volID, name, dockerConfigPath, files)
 			if err != nil {
 				clientError = err
 			}
@@ -439,7 +537,7 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 	// import the images to the servers container d instance
 	// importing images means that Nomad does not need to pull from a remote docker hub
 	if len(p.config.CopyImages) > 0 {
-		err := p.ImportLocalDockerImages(p.config.CopyImages.ToClientImages(), false)
+		err := p.importLocalDockerImages(ctx, p.config.CopyImages.ToClientImages(), false)
 		if err != nil {
 			return fmt.Errorf("unable to copy images to cluster: %w", err)
 		}
@@ -453,7 +551,7 @@ func (p *ClusterProvider) createNomad(ctx context.Context) error {
 	return nil
 }
 
-func (p *ClusterProvider) createServerNode(img ctypes.Image, volumeID string, isClient bool, dockerConfig string) (string, error) {
+func (p *ClusterProvider) createServerNode(img ctypes.Image, volumeID string, isClient bool, dockerConfig string, files []ctypes.File) (string, error) {
 	// set the resources for CPU, if not a client set the resources low
 	// so that we can only deploy the connector to the server
 	info := p.client.EngineInfo()
@@ -464,7 +562,7 @@ func (p *ClusterProvider) createServerNode(img ctypes.Image, volumeID string, is
 	}
 
 	// generate the server config
-	sc := dataDir + "\n" + fmt.Sprintf(serverConfig, p.config.Datacenter, cpu)
+	sc := dataDir + "\n" + fmt.Sprintf(serverConfig, p.config.Datacenter, p.config.GossipKey, cpu)
 
 	// write the nomad config to a file
 	os.MkdirAll(p.config.ConfigDir, os.ModePerm)
@@ -574,6 +672,8 @@ func (p *ClusterProvider) createServerNode(img ctypes.Image, volumeID string, is
 	// add the ca for the proxy
 	p.appendProxyEnv(cc)
 
+	cc.Files = files
+
 	id, err := p.client.CreateContainer(cc)
 	if err != nil {
 		return "", err
@@ -584,7 +684,7 @@ func (p *ClusterProvider) createServerNode(img ctypes.Image, volumeID string, is
 
 // createClient node creates a Nomad client node
 // returns the fqdn, docker id, and an error if unsuccessful
-func (p *ClusterProvider) createClientNode(id string, image, volumeID, serverID string, dockerConfig string) (string, string, error) {
+func (p *ClusterProvider) createClientNode(id string, image, volumeID, serverID string, dockerConfig string, files []ctypes.File) (string, string, error) {
 
 	info := p.client.EngineInfo()
 	cpu := fmt.Sprintf("cpu_total_compute = %d", info.CPU*1000)
@@ -604,7 +704,7 @@ func (p *ClusterProvider) createClientNode(id string, image, volumeID, serverID
 		Name: fqrn,
 	}
 
-	cc.Image = &ctypes.Image{Name: image}
+	cc.Image = &ctypes.Image{Name: image, Platform: p.config.Platform}
 	cc.Networks = p.config.Networks.ToClientNetworkAttachments()
 	cc.Privileged = true // nomad must run Privileged as Docker needs to manipulate ip tables and stuff
 
@@ -662,6 +762,8 @@ func (p *ClusterProvider) createClientNode(id string, image, volumeID, serverID
 	// add the ca for the proxy
 	p.appendProxyEnv(cc)
 
+	cc.Files = files
+
 	cid, err := p.client.CreateContainer(cc)
 
 	// add the name of the network, we only have the id
@@ -1092,6 +1194,7 @@ datacenter = "%s"
 server {
   enabled = true
   bootstrap_expect = 1
+  encrypt = "%s"
 }
 
 client {
@@ -9,11 +9,13 @@ import (
 	htypes "github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients"
 	"github.com/jumppad-labs/jumppad/pkg/clients/nomad"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 )
 
 var _ sdk.Provider = &JobProvider{}
+var _ config.Updater = &JobProvider{}
 
 // NomadJob is a provider which enabled the creation and destruction
 // of Nomad jobs
@@ -50,12 +52,19 @@ func (p *JobProvider) Create(ctx context.Context) error {
 
 	p.log.Info("Create Nomad Job", "ref", p.config.Meta.ID, "files", p.config.Paths)
 
+	return p.submit(ctx, p.config.Paths)
+}
+
+// submit registers the jobs in the given files, waits for the jobs to become
+// healthy and updates the checksums. Nomad updates jobs that are already
+// registered in place.
+func (p *JobProvider) submit(ctx context.Context, paths []string) error {
 	nomadCluster := p.config.Cluster
 
 	// load the config
 	p.client.SetConfig(fmt.Sprintf("http://%s", nomadCluster.ExternalIP), nomadCluster.APIPort, nomadCluster.ClientNodes)
 
-	err := p.client.Create(p.config.Paths)
+	err := p.client.Create(paths)
 	if err != nil {
 		return fmt.Errorf("unable to create Nomad jobs: %w", err)
 	}
@@ -131,9 +140,16 @@ func (p *JobProvider) Lookup() ([]string, error) {
 	return nil, nil
 }
 
+// Refresh re-submits any Nomad jobs that have changed
 func (p *JobProvider) Refresh(ctx context.Context) error {
+	return p.Update(ctx)
+}
+
+// Update re-submits the changed Nomad jobs, Nomad performs a rolling update
+// of the existing allocations rather than stopping the job
+func (p *JobProvider) Update(ctx context.Context) error {
 	if ctx.Err() != nil {
-		p.log.Debug("Skipping refresh, context cancelled", "ref", p.config.Meta.ID)
+		p.log.Debug("Skipping update, context cancelled", "ref", p.config.Meta.ID)
 		return nil
 	}
 
@@ -146,14 +162,9 @@ func (p *JobProvider) Refresh(ctx context.Context) error {
 		return nil
 	}
 
-	p.log.Info("Refresh Nomad Jobs", "ref", p.config.Meta.ID, "paths", cp)
-
-	err = p.Destroy(ctx, false)
-	if err != nil {
-		return err
-	}
+	p.log.Info("Update Nomad Jobs", "ref", p.config.Meta.ID, "paths", cp)
 
-	return p.Create(context.Background())
+	return p.submit(ctx, cp)
 }
 
 func (p *JobProvider) Changed() (bool, error) {
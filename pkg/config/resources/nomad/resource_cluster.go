@@ -3,7 +3,6 @@ package nomad
 import (
 	"fmt"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	ctypes "github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -15,7 +14,7 @@ const TypeNomadCluster string = "nomad_cluster"
 // Cluster is a config stanza which defines a Kubernetes or a Nomad cluster
 type NomadCluster struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Networks      ctypes.NetworkAttachments `hcl:"network,block" json:"networks,omitempty"` // Attach to the correct network // only when Image is specified
 	Image         *ctypes.Image             `hcl:"image,block" json:"images,omitempty"`     // optional image to use for the cluster
@@ -25,10 +24,15 @@ type NomadCluster struct {
 	ClientConfig  string                    `hcl:"client_config,optional" json:"client_config,omitempty"`
 	ConsulConfig  string                    `hcl:"consul_config,optional" json:"consul_config,omitempty"`
 	Volumes       ctypes.Volumes            `hcl:"volume,block" json:"volumes,omitempty"`                     // volumes to attach to the cluster
+	Files         ctypes.Files              `hcl:"file,block" json:"files,omitempty"`                         // files to write to the server and client nodes
 	OpenInBrowser bool                      `hcl:"open_in_browser,optional" json:"open_in_browser,omitempty"` // open the UI in the browser after creation
 
 	Datacenter string `hcl:"datacenter,optional" json:"datacenter"` // Nomad datacenter, defaults dc1
 
+	// Platform of the cluster image and the copied images e.g. linux/arm64,
+	// defaults to the platform of the engine
+	Platform string `hcl:"platform,optional" json:"platform,omitempty"`
+
 	// Images that will be copied from the local docker cache to the cluster
 	CopyImages ctypes.Images `hcl:"copy_image,block" json:"copy_images,omitempty"`
 
@@ -56,6 +60,10 @@ type NomadCluster struct {
 	// The fully qualified docker address for the client nodes
 	ClientContainerName []string `hcl:"client_container_name,optional" json:"client_container_name,omitempty"`
 
+	// GossipKey is the generated key used to encrypt the gossip traffic of the
+	// servers, the value is redacted from logs and status output
+	GossipKey string `hcl:"gossip_key,optional" json:"gossip_key,omitempty" sensitive:"true"`
+
 	// ExternalIP is the ip address of the cluster, this generally resolves
 	// to the docker ip
 	ExternalIP string `hcl:"external_ip,optional" json:"external_ip,omitempty"`
@@ -107,6 +115,11 @@ func (n *NomadCluster) Process() error {
 		}
 	}
 
+	err := n.Files.Process(n.Meta.File)
+	if err != nil {
+		return err
+	}
+
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
 	c, err := config.LoadState()
@@ -119,6 +132,7 @@ func (n *NomadCluster) Process() error {
 			n.ConfigDir = state.ConfigDir
 			n.ServerContainerName = state.ServerContainerName
 			n.ClientContainerName = state.ClientContainerName
+			n.GossipKey = state.GossipKey
 			n.APIPort = state.APIPort
 			n.ConnectorPort = state.ConnectorPort
 
@@ -134,6 +148,9 @@ func (n *NomadCluster) Process() error {
 				}
 			}
 
+			// add the file checksums
+			n.Files.SetChecksums(state.Files)
+
 			// the network name is set
 			copy(n.Networks, state.Networks)
 		}
@@ -21,7 +21,7 @@ func TestNomadClusterProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &NomadCluster{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 
 		ServerConfig: "./server_config.hcl",
 		ClientConfig: "./client_config.hcl",
@@ -45,7 +45,7 @@ func TestNomadClusterProcessSetsAbsolute(t *testing.T) {
 
 func TestNomadClusterProcessDoesNotSetAbsoluteForNonBindMounts(t *testing.T) {
 	c := &NomadCluster{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 
 		Volumes: []ctypes.Volume{
 			{
@@ -83,8 +83,10 @@ func TestNomadClusterSetsOutputsFromState(t *testing.T) {
 }`)
 
 	c := &NomadCluster{
-		ResourceBase: types.ResourceBase{
-			Meta: types.Meta{ID: "resource.nomad_cluster.test"},
+		ResourceOptions: config.ResourceOptions{
+			ResourceBase: types.ResourceBase{
+				Meta: types.Meta{ID: "resource.nomad_cluster.test"},
+			},
 		},
 	}
 
@@ -1,7 +1,6 @@
 package nomad
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/healthcheck"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
@@ -13,7 +12,7 @@ const TypeNomadJob string = "nomad_job"
 // NomadJob applies and deletes and deletes Nomad cluster jobs
 type NomadJob struct {
 	// embedded type holding name, etc
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	// Cluster is the name of the cluster to apply configuration to
 	Cluster NomadCluster `hcl:"cluster" json:"cluster"`
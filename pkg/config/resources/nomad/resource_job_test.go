@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,7 +15,7 @@ func TestNomadJobProcessSetsAbsolute(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &NomadJob{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
 		Paths: []string{
 			"./one.hcl",
 			"./two.hcl",
@@ -0,0 +1,202 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/jumppad-labs/hclconfig"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// ViolationError is returned when an image does not meet the rules of one or
+// more image policies
+type ViolationError struct {
+	Image      string
+	Violations []string
+}
+
+func (v *ViolationError) Error() string {
+	return fmt.Sprintf("image %s violates the image policy: %s", v.Image, strings.Join(v.Violations, ", "))
+}
+
+type contextKey struct{}
+
+// WithPolicies returns a copy of the context containing the policies, the
+// context is passed to the providers when resources are created
+func WithPolicies(ctx context.Context, policies []*ImagePolicy) context.Context {
+	return context.WithValue(ctx, contextKey{}, policies)
+}
+
+// FromContext returns the policies added to the context with WithPolicies
+func FromContext(ctx context.Context) []*ImagePolicy {
+	p, _ := ctx.Value(contextKey{}).([]*ImagePolicy)
+	return p
+}
+
+// Find returns the image policies that are not disabled in the config
+func Find(c *hclconfig.Config) []*ImagePolicy {
+	if c == nil {
+		return nil
+	}
+
+	rs, _ := c.FindResourcesByType(TypeImagePolicy)
+
+	policies := []*ImagePolicy{}
+	for _, r := range rs {
+		if !r.GetDisabled() {
+			policies = append(policies, r.(*ImagePolicy))
+		}
+	}
+
+	return policies
+}
+
+// Enforce checks the image in the local registry against the policies in the
+// context. The user is the user the container runs as, when empty the
+// default user of the image is checked. A ViolationError is returned when the
+// image does not meet the rules of the policies.
+func Enforce(ctx context.Context, ct container.ContainerTasks, image, user string) error {
+	return enforce(ctx, ct, image, user, true)
+}
+
+// EnforceNode checks the image used for the nodes of a cluster against the
+// policies in the context, cluster nodes must run as root so the user of the
+// image is not checked
+func EnforceNode(ctx context.Context, ct container.ContainerTasks, image string) error {
+	return enforce(ctx, ct, image, "", false)
+}
+
+// EnforceReference checks the image reference against the policies in the
+// context, it is used for images that are not pulled by jumppad such as the
+// base images of a build
+func EnforceReference(ctx context.Context, image string) error {
+	violations := []string{}
+	for _, p := range FromContext(ctx) {
+		violations = append(violations, p.CheckReference(image)...)
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Image: image, Violations: violations}
+	}
+
+	return nil
+}
+
+func enforce(ctx context.Context, ct container.ContainerTasks, image, user string, checkUser bool) error {
+	policies := FromContext(ctx)
+	if len(policies) == 0 {
+		return nil
+	}
+
+	info, err := ct.InspectImage(image)
+	if err != nil {
+		return err
+	}
+
+	violations := []string{}
+	for _, p := range policies {
+		violations = append(violations, p.CheckReference(image)...)
+
+		if checkUser {
+			violations = append(violations, p.CheckImage(info, user)...)
+		} else {
+			violations = append(violations, p.CheckAge(info)...)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ViolationError{Image: image, Violations: violations}
+	}
+
+	return nil
+}
+
+// CheckReference returns the violations for the image reference, images built
+// by jumppad are only stored locally and are not checked
+func (p *ImagePolicy) CheckReference(image string) []string {
+	if image == "" || strings.HasPrefix(image, utils.BuildImagePrefix) {
+		return nil
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to parse image reference: %s", err)}
+	}
+
+	violations := []string{}
+
+	if len(p.AllowedRegistries) > 0 && !p.allowedRegistry(named) {
+		violations = append(violations, fmt.Sprintf("registry %s is not allowed by %s", reference.Domain(named), p.Meta.ID))
+	}
+
+	_, digested := named.(reference.Digested)
+
+	if p.ForbidLatest && !digested {
+		tagged, ok := named.(reference.Tagged)
+		if !ok || tagged.Tag() == "latest" {
+			violations = append(violations, fmt.Sprintf("the latest tag is forbidden by %s", p.Meta.ID))
+		}
+	}
+
+	if p.RequireDigest && !digested {
+		violations = append(violations, fmt.Sprintf("a digest is required by %s", p.Meta.ID))
+	}
+
+	return violations
+}
+
+// CheckImage returns the violations for the metadata of the image, user is the
+// user the container runs as when it overrides the default user of the image
+func (p *ImagePolicy) CheckImage(info *types.ImageInfo, user string) []string {
+	violations := p.CheckAge(info)
+
+	if user == "" {
+		user = info.User
+	}
+
+	if p.ForbidRootUser && isRoot(user) {
+		violations = append(violations, fmt.Sprintf("running as root is forbidden by %s", p.Meta.ID))
+	}
+
+	return violations
+}
+
+// CheckAge returns the violations for the time since the image was built
+func (p *ImagePolicy) CheckAge(info *types.ImageInfo) []string {
+	if p.MaxAge == "" {
+		return nil
+	}
+
+	// max age has been validated when the config was processed
+	maxAge, _ := time.ParseDuration(p.MaxAge)
+	if age := time.Since(info.Created); age > maxAge {
+		return []string{fmt.Sprintf("the image was built %s ago, %s allows a maximum age of %s", age.Truncate(time.Hour), p.Meta.ID, p.MaxAge)}
+	}
+
+	return nil
+}
+
+func (p *ImagePolicy) allowedRegistry(named reference.Named) bool {
+	domain := reference.Domain(named)
+	name := domain + "/" + reference.Path(named)
+
+	for _, r := range p.AllowedRegistries {
+		r = strings.TrimSuffix(r, "/")
+		if r == domain || strings.HasPrefix(name, r+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRoot returns true when the user is empty, the root user, or the root uid
+func isRoot(user string) bool {
+	u, _, _ := strings.Cut(user, ":")
+	return u == "" || u == "root" || u == "0"
+}
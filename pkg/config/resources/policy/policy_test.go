@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicy() *ImagePolicy {
+	return &ImagePolicy{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{ID: "resource.image_policy.strict"}}},
+	}
+}
+
+func TestProcessWithInvalidMaxAgeReturnsError(t *testing.T) {
+	p := testPolicy()
+	p.MaxAge = "30 days"
+
+	err := p.Process()
+	require.Error(t, err)
+}
+
+func TestCheckReferenceWithAllowedRegistries(t *testing.T) {
+	p := testPolicy()
+	p.AllowedRegistries = []string{"ghcr.io/jumppad-labs", "docker.io"}
+
+	require.Empty(t, p.CheckReference("ghcr.io/jumppad-labs/connector:v0.4.0"))
+	require.Empty(t, p.CheckReference("nginx:1.27"))
+	require.Len(t, p.CheckReference("ghcr.io/other/connector:v0.4.0"), 1)
+	require.Len(t, p.CheckReference("quay.io/jumppad-labs/connector:v0.4.0"), 1)
+}
+
+func TestCheckReferenceWithForbidLatest(t *testing.T) {
+	p := testPolicy()
+	p.ForbidLatest = true
+
+	require.Len(t, p.CheckReference("nginx"), 1)
+	require.Len(t, p.CheckReference("nginx:latest"), 1)
+	require.Empty(t, p.CheckReference("nginx:1.27"))
+	require.Empty(t, p.CheckReference("nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"))
+}
+
+func TestCheckReferenceWithRequireDigest(t *testing.T) {
+	p := testPolicy()
+	p.RequireDigest = true
+
+	require.Len(t, p.CheckReference("nginx:1.27"), 1)
+	require.Empty(t, p.CheckReference("nginx:1.27@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"))
+}
+
+func TestCheckReferenceIgnoresBuiltImages(t *testing.T) {
+	p := testPolicy()
+	p.AllowedRegistries = []string{"ghcr.io"}
+	p.RequireDigest = true
+
+	require.Empty(t, p.CheckReference("jumppad.dev/localcache/app:abc123"))
+}
+
+func TestCheckImageWithMaxAge(t *testing.T) {
+	p := testPolicy()
+	p.MaxAge = "720h"
+
+	require.Empty(t, p.CheckImage(&ctypes.ImageInfo{Created: time.Now().Add(-24 * time.Hour)}, ""))
+	require.Len(t, p.CheckImage(&ctypes.ImageInfo{Created: time.Now().Add(-1000 * time.Hour)}, ""), 1)
+}
+
+func TestCheckImageWithForbidRootUser(t *testing.T) {
+	p := testPolicy()
+	p.ForbidRootUser = true
+
+	require.Len(t, p.CheckImage(&ctypes.ImageInfo{}, ""), 1)
+	require.Len(t, p.CheckImage(&ctypes.ImageInfo{User: "0:0"}, ""), 1)
+	require.Empty(t, p.CheckImage(&ctypes.ImageInfo{User: "nginx"}, ""))
+
+	// the user the container runs as takes precedence
+	require.Empty(t, p.CheckImage(&ctypes.ImageInfo{User: "root"}, "1000"))
+	require.Len(t, p.CheckImage(&ctypes.ImageInfo{User: "nginx"}, "root"), 1)
+}
+
+func TestEnforceWithoutPoliciesDoesNotInspectImage(t *testing.T) {
+	ct := &mocks.ContainerTasks{}
+
+	err := Enforce(context.Background(), ct, "nginx", "")
+	require.NoError(t, err)
+
+	ct.AssertNotCalled(t, "InspectImage", mock.Anything)
+}
+
+func TestEnforceReturnsViolations(t *testing.T) {
+	ct := &mocks.ContainerTasks{}
+	ct.On("InspectImage", "nginx:latest").Return(&ctypes.ImageInfo{Created: time.Now()}, nil)
+
+	p := testPolicy()
+	p.ForbidLatest = true
+	p.ForbidRootUser = true
+
+	err := Enforce(WithPolicies(context.Background(), []*ImagePolicy{p}), ct, "nginx:latest", "")
+
+	ve := &ViolationError{}
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Violations, 2)
+}
@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/config"
+)
+
+// TypeImagePolicy is the resource string for an ImagePolicy resource
+const TypeImagePolicy string = "image_policy"
+
+// ImagePolicy defines the rules that an image must meet before a container is
+// created from it. When more than one policy is defined an image must meet
+// the rules of every policy.
+//
+// The image reference is checked when the configuration is parsed, the age
+// and the user of the image are checked once the image has been pulled.
+// Images from build resources are checked when a container is created from
+// them, only the references of the base images of a build are checked.
+// Cluster nodes must run as root, forbid_root_user does not apply to the
+// image of a cluster, it does apply to the images copied to the cluster.
+type ImagePolicy struct {
+	// embedded type holding name, etc
+	config.ResourceOptions `hcl:",remain"`
+
+	// AllowedRegistries that images can be pulled from, an entry can be a
+	// registry i.e. docker.io or a registry and path i.e. ghcr.io/jumppad-labs
+	AllowedRegistries []string `hcl:"allowed_registries,optional" json:"allowed_registries,omitempty"`
+	// ForbidLatest rejects images that use the latest tag or do not specify a
+	// tag, images pinned with a digest are allowed
+	ForbidLatest bool `hcl:"forbid_latest,optional" json:"forbid_latest,omitempty"`
+	// RequireDigest rejects images that are not pinned with a digest
+	// i.e. nginx@sha256:...
+	RequireDigest bool `hcl:"require_digest,optional" json:"require_digest,omitempty"`
+	// MaxAge is the maximum time since the image was built expressed as a go
+	// duration i.e. 2160h
+	MaxAge string `hcl:"max_age,optional" json:"max_age,omitempty"`
+	// ForbidRootUser rejects containers that run as root, the user set with
+	// run_as takes precedence over the default user of the image
+	ForbidRootUser bool `hcl:"forbid_root_user,optional" json:"forbid_root_user,omitempty"`
+}
+
+func (p *ImagePolicy) Process() error {
+	for _, r := range p.AllowedRegistries {
+		if strings.TrimSpace(r) == "" {
+			return fmt.Errorf("allowed_registries must not contain empty values")
+		}
+	}
+
+	if p.MaxAge != "" {
+		d, err := time.ParseDuration(p.MaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid max_age %s, the value must be a duration i.e. 2160h: %s", p.MaxAge, err)
+		}
+
+		if d <= 0 {
+			return fmt.Errorf("invalid max_age %s, the value must be greater than zero", p.MaxAge)
+		}
+	}
+
+	return nil
+}
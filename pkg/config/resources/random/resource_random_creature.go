@@ -1,7 +1,6 @@
 package random
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
@@ -10,12 +9,16 @@ const TypeRandomCreature string = "random_creature"
 
 // allows the generation of random creatures
 type RandomCreature struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	// Output parameters
 	Value string `hcl:"value,optional" json:"value"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the value only exists in
+// the state
+func (c *RandomCreature) Replaceable() {}
+
 func (c *RandomCreature) Process() error {
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
@@ -1,7 +1,6 @@
 package random
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
@@ -10,7 +9,7 @@ const TypeRandomID string = "random_id"
 
 // allows the generation of random IDs
 type RandomID struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	ByteLength int64 `hcl:"byte_length" json:"byte_length"`
 
@@ -20,6 +19,10 @@ type RandomID struct {
 	Dec    string `hcl:"dec,optional" json:"dec"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the value only exists in
+// the state
+func (c *RandomID) Replaceable() {}
+
 func (c *RandomID) Process() error {
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
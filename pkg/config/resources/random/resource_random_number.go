@@ -1,7 +1,6 @@
 package random
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
@@ -10,7 +9,7 @@ const TypeRandomNumber string = "random_number"
 
 // allows the generation of random numbers
 type RandomNumber struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Minimum int `hcl:"minimum" json:"minimum"`
 	Maximum int `hcl:"maximum" json:"maximum"`
@@ -19,6 +18,10 @@ type RandomNumber struct {
 	Value int `hcl:"value,optional" json:"value"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the value only exists in
+// the state
+func (c *RandomNumber) Replaceable() {}
+
 func (c *RandomNumber) Process() error {
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
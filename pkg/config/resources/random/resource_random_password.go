@@ -1,7 +1,6 @@
 package random
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
@@ -10,7 +9,7 @@ const TypeRandomPassword string = "random_password"
 
 // allows the generation of random Passwords
 type RandomPassword struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Length int64 `hcl:"length" json:"lenght"`
 
@@ -29,6 +28,10 @@ type RandomPassword struct {
 	Value string `hcl:"value,optional" json:"value"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the value only exists in
+// the state
+func (c *RandomPassword) Replaceable() {}
+
 func (c *RandomPassword) Process() error {
 	if c.Special == nil {
 		c.Special = boolPointer(true)
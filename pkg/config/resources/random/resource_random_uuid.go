@@ -1,7 +1,6 @@
 package random
 
 import (
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 )
 
@@ -10,12 +9,16 @@ const TypeRandomUUID string = "random_uuid"
 
 // allows the generation of random UUIDs
 type RandomUUID struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	// Output parameters
 	Value string `hcl:"value,optional" json:"value"`
 }
 
+// Replaceable allows lifecycle.create_before_destroy, the value only exists in
+// the state
+func (c *RandomUUID) Replaceable() {}
+
 func (c *RandomUUID) Process() error {
 	// do we have an existing resource in the state?
 	// if so we need to set any computed resources for dependents
@@ -9,12 +9,14 @@ import (
 
 	"github.com/infinytum/raymond/v2"
 	htypes "github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	sdk "github.com/jumppad-labs/plugin-sdk"
 	"github.com/zclconf/go-cty/cty"
 )
 
 var _ sdk.Provider = &TemplateProvider{}
+var _ config.Updater = &TemplateProvider{}
 
 // Template provider allows parsing and output of file based templates
 type TemplateProvider struct {
@@ -41,42 +43,9 @@ func (p *TemplateProvider) Create(ctx context.Context) error {
 		return nil
 	}
 
-	// check the template is valid
-	if p.config.Source == "" {
-		return fmt.Errorf("template source empty")
-	}
-
-	output := p.config.Source
-	if p.config.Variables != nil {
-
-		vars := parseVars(p.config.Variables)
-
-		tmpl, err := raymond.Parse(p.config.Source)
-		if err != nil {
-			return fmt.Errorf("error parsing template: %s", err)
-		}
-
-		tmpl.RegisterHelpers(map[string]interface{}{
-			"quote": func(in string) string {
-				return fmt.Sprintf(`"%s"`, in)
-			},
-			"trim": func(in string) string {
-				return strings.TrimSpace(in)
-			},
-		})
-
-		result, err := tmpl.Exec(vars)
-		if err != nil {
-			return fmt.Errorf("error processing template: %s", err)
-		}
-
-		output = result
-	}
-
-	// gemerate a checksum from the result
-	cs, err := utils.ChecksumFromInterface(output)
+	output, cs, err := p.render()
 	if err != nil {
-		return fmt.Errorf("unable to generate checksum for template: %s", err)
+		return err
 	}
 
 	outputExists := false
@@ -140,7 +109,7 @@ func (p *TemplateProvider) Lookup() ([]string, error) {
 	return []string{}, nil
 }
 
-// Refresh causes the template to be destroyed and recreated
+// Refresh regenerates the template when the output has changed
 func (p *TemplateProvider) Refresh(ctx context.Context) error {
 	if ctx.Err() != nil {
 		p.log.Debug("Context cancelled, skipping refresh", "ref", p.config.Meta.ID)
@@ -152,8 +121,75 @@ func (p *TemplateProvider) Refresh(ctx context.Context) error {
 	return p.Create(ctx)
 }
 
+// Update rewrites the destination file in place when the rendered output
+// has changed
+func (p *TemplateProvider) Update(ctx context.Context) error {
+	if ctx.Err() != nil {
+		p.log.Debug("Context cancelled, skipping update", "ref", p.config.Meta.ID)
+		return nil
+	}
+
+	p.log.Debug("Update Template", "ref", p.config.Meta.ID)
+
+	return p.Create(ctx)
+}
+
+// Changed returns true when the rendered template differs from the last
+// generated output or the destination file no longer exists
 func (p *TemplateProvider) Changed() (bool, error) {
-	return false, nil
+	_, cs, err := p.render()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(p.config.Destination); err != nil {
+		return true, nil
+	}
+
+	return cs != p.config.Checksum, nil
+}
+
+// render processes the template returning the output and its checksum
+func (p *TemplateProvider) render() (string, string, error) {
+	// check the template is valid
+	if p.config.Source == "" {
+		return "", "", fmt.Errorf("template source empty")
+	}
+
+	output := p.config.Source
+	if p.config.Variables != nil {
+
+		vars := parseVars(p.config.Variables)
+
+		tmpl, err := raymond.Parse(p.config.Source)
+		if err != nil {
+			return "", "", fmt.Errorf("error parsing template: %s", err)
+		}
+
+		tmpl.RegisterHelpers(map[string]interface{}{
+			"quote": func(in string) string {
+				return fmt.Sprintf(`"%s"`, in)
+			},
+			"trim": func(in string) string {
+				return strings.TrimSpace(in)
+			},
+		})
+
+		result, err := tmpl.Exec(vars)
+		if err != nil {
+			return "", "", fmt.Errorf("error processing template: %s", err)
+		}
+
+		output = result
+	}
+
+	// gemerate a checksum from the result
+	cs, err := utils.ChecksumFromInterface(output)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to generate checksum for template: %s", err)
+	}
+
+	return output, cs, nil
 }
 
 // parseVars converts a map[string]cty.Value into map[string]interface
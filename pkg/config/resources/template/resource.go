@@ -4,7 +4,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 	"github.com/zclconf/go-cty/cty"
@@ -15,7 +14,7 @@ const TypeTemplate string = "template"
 
 // Template allows the process of user defined templates
 type Template struct {
-	types.ResourceBase `hcl:",remain"`
+	config.ResourceOptions `hcl:",remain"`
 
 	Source      string               `hcl:"source" json:"source"`                          // Source template to be processed as string
 	Destination string               `hcl:"destination" json:"destination"`                // Destination filename to write
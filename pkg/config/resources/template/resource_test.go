@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,9 +15,9 @@ func TestTemplateProcessSetsAbsoluteWhenBothFiles(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Template{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Source:       "./",
-		Destination:  "./output.hcl",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Source:          "./",
+		Destination:     "./output.hcl",
 	}
 
 	c.Process()
@@ -30,9 +31,9 @@ func TestTemplateProcessSetsAbsoluteWhenSourceString(t *testing.T) {
 	require.NoError(t, err)
 
 	c := &Template{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}},
-		Source:       "foobar",
-		Destination:  "./output.hcl",
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{File: "./"}}},
+		Source:          "foobar",
+		Destination:     "./output.hcl",
 	}
 
 	c.Process()
@@ -469,7 +469,7 @@ func terraformStateFolder(r *Terraform) string {
 	p = strings.Replace(p, ".", "_", -1)
 	p = strings.Replace(p, "-", "_", -1)
 
-	data := filepath.Join(utils.JumppadHome(), "terraform", "state", p)
+	data := filepath.Join(utils.WorkspaceHome(utils.Workspace()), "terraform", "state", p)
 
 	// create the folder if it does not exist
 	os.MkdirAll(data, 0755)
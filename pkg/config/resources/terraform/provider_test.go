@@ -10,6 +10,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
 	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -38,7 +39,7 @@ func setupProvider(t *testing.T, c *Terraform) (*TerraformProvider, *mocks.Conta
 }
 
 func TestCreateWithNoVariablesDoesNotReturnError(t *testing.T) {
-	p, _, _ := setupProvider(t, &Terraform{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}})
+	p, _, _ := setupProvider(t, &Terraform{ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}}})
 
 	err := p.Create(context.Background())
 	require.NoError(t, err)
@@ -50,8 +51,8 @@ func TestCreateWithWithVariablesGeneratesFile(t *testing.T) {
 	})
 
 	p, _, sd := setupProvider(t, &Terraform{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}},
-		Variables:    variables,
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}},
+		Variables:       variables,
 	},
 	)
 
@@ -66,7 +67,7 @@ func TestCreateWithWithVariablesGeneratesFile(t *testing.T) {
 
 func TestCreatesTerraformContainerWithTheCorrectValues(t *testing.T) {
 	res := &Terraform{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}},
 		Networks: []container.NetworkAttachment{
 			{
 				ID: "Abc123",
@@ -107,7 +108,7 @@ func TestCreatesTerraformContainerWithTheCorrectValues(t *testing.T) {
 
 func TestCreateExecutesCommandInContainer(t *testing.T) {
 	res := &Terraform{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}},
 		Networks: []container.NetworkAttachment{
 			container.NetworkAttachment{
 				ID: "Abc123",
@@ -142,7 +143,7 @@ func TestCreateExecutesCommandInContainer(t *testing.T) {
 
 func TestCreateSetsOutput(t *testing.T) {
 	res := &Terraform{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}},
 		Networks: []container.NetworkAttachment{
 			container.NetworkAttachment{
 				ID: "Abc123",
@@ -161,7 +162,7 @@ func TestCreateSetsOutput(t *testing.T) {
 
 func TestDestroyExecutesCommandInContainer(t *testing.T) {
 	res := &Terraform{
-		ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}},
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "test"}}},
 		Networks: []container.NetworkAttachment{
 			container.NetworkAttachment{
 				ID: "Abc123",
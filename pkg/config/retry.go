@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/hclconfig/types"
+)
+
+const (
+	// DefaultRetryAttempts is the number of attempts made when a retry block
+	// does not specify attempts
+	DefaultRetryAttempts = 3
+	// DefaultRetryInitialBackoff is the time waited before the first retry
+	DefaultRetryInitialBackoff = 1 * time.Second
+	// DefaultRetryMaxBackoff is the maximum time waited between retries
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// Retry defines the optional retry block that can be added to any resource,
+// when the provider fails to create or refresh the resource the operation is
+// retried, doubling the time waited between each attempt
+type Retry struct {
+	// Attempts is the total number of attempts including the first, defaults to 3
+	Attempts int `hcl:"attempts,optional" json:"attempts,omitempty"`
+	// InitialBackoff is the time to wait before the first retry e.g. 1s, defaults to 1s
+	InitialBackoff string `hcl:"initial_backoff,optional" json:"initial_backoff,omitempty"`
+	// MaxBackoff is the maximum time to wait between retries e.g. 1m, defaults to 30s
+	MaxBackoff string `hcl:"max_backoff,optional" json:"max_backoff,omitempty"`
+}
+
+// UnmarshalJSON decodes the retry block, Helm resources previously stored the
+// number of install attempts as the retry attribute, this is mapped to the
+// attempts for the block so that existing state can be loaded
+func (r *Retry) UnmarshalJSON(d []byte) error {
+	var attempts int
+	if err := json.Unmarshal(d, &attempts); err == nil {
+		r.Attempts = attempts
+		return nil
+	}
+
+	// alias the type to decode the block without calling UnmarshalJSON
+	type retry Retry
+	return json.Unmarshal(d, (*retry)(r))
+}
+
+// Policy returns the number of attempts and the backoff for the retry block,
+// when the block is nil a single attempt is returned
+func (r *Retry) Policy() (int, time.Duration, time.Duration, error) {
+	if r == nil {
+		return 1, 0, 0, nil
+	}
+
+	attempts := r.Attempts
+	if attempts < 1 {
+		attempts = DefaultRetryAttempts
+	}
+
+	initial := DefaultRetryInitialBackoff
+	if r.InitialBackoff != "" {
+		d, err := time.ParseDuration(r.InitialBackoff)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("unable to parse initial_backoff: %s", err)
+		}
+
+		initial = d
+	}
+
+	max := DefaultRetryMaxBackoff
+	if r.MaxBackoff != "" {
+		d, err := time.ParseDuration(r.MaxBackoff)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("unable to parse max_backoff: %s", err)
+		}
+
+		max = d
+	}
+
+	if initial > max {
+		initial = max
+	}
+
+	return attempts, initial, max, nil
+}
+
+// RetryFromResource returns the retry block for the given resource, nil is
+// returned when the resource does not define a retry block
+func RetryFromResource(r types.Resource) *Retry {
+	o := optionsFromResource(r)
+	if o == nil {
+		return nil
+	}
+
+	return o.Retry
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyWithNilRetryReturnsSingleAttempt(t *testing.T) {
+	var r *Retry
+
+	attempts, _, _, err := r.Policy()
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyReturnsDefaults(t *testing.T) {
+	r := &Retry{}
+
+	attempts, initial, max, err := r.Policy()
+	require.NoError(t, err)
+	require.Equal(t, DefaultRetryAttempts, attempts)
+	require.Equal(t, DefaultRetryInitialBackoff, initial)
+	require.Equal(t, DefaultRetryMaxBackoff, max)
+}
+
+func TestRetryPolicyWithInvalidBackoffReturnsError(t *testing.T) {
+	r := &Retry{InitialBackoff: "soon"}
+
+	_, _, _, err := r.Policy()
+	require.Error(t, err)
+}
+
+func TestRetryPolicyLimitsInitialBackoffToMax(t *testing.T) {
+	r := &Retry{InitialBackoff: "1m", MaxBackoff: "10s"}
+
+	_, initial, _, err := r.Policy()
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, initial)
+}
+
+func TestRetryUnmarshalJSONDecodesBlock(t *testing.T) {
+	r := &Retry{}
+
+	err := json.Unmarshal([]byte(`{"attempts":5,"max_backoff":"10s"}`), r)
+	require.NoError(t, err)
+	require.Equal(t, 5, r.Attempts)
+	require.Equal(t, "10s", r.MaxBackoff)
+}
+
+func TestRetryUnmarshalJSONMapsAttemptsFromNumber(t *testing.T) {
+	o := &ResourceOptions{}
+
+	err := json.Unmarshal([]byte(`{"retry":3}`), o)
+	require.NoError(t, err)
+	require.Equal(t, 3, o.Retry.Attempts)
+}
+
+type optionsResource struct {
+	ResourceOptions `hcl:",remain"`
+}
+
+func TestRetryAndLifecycleFromResourceReturnsOptions(t *testing.T) {
+	r := &optionsResource{}
+	require.Nil(t, RetryFromResource(r))
+	require.Nil(t, LifecycleFromResource(r))
+
+	r.Retry = &Retry{Attempts: 2}
+	r.Lifecycle = &Lifecycle{PreventDestroy: true}
+
+	require.Equal(t, 2, RetryFromResource(r).Attempts)
+	require.True(t, LifecycleFromResource(r).PreventDestroy)
+}
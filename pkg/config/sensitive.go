@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
+)
+
+// SensitiveValues returns the values of the string fields of the resource
+// that are tagged with `sensitive:"true"`, empty values are not returned
+func SensitiveValues(r types.Resource) []string {
+	v := reflect.ValueOf(r)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	values := []string{}
+
+	e := v.Elem()
+	for i := 0; i < e.NumField(); i++ {
+		f := e.Type().Field(i)
+		if f.Tag.Get("sensitive") != "true" || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		if s := e.Field(i).String(); s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
+
+// RegisterSensitiveValues registers the sensitive values of the resources so
+// that they are redacted from logs and output
+func RegisterSensitiveValues(rs []types.Resource) {
+	for _, r := range rs {
+		secrets.Register(SensitiveValues(r)...)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+type sensitiveResource struct {
+	types.ResourceBase `hcl:",remain"`
+
+	Name  string `hcl:"name,optional" json:"name,omitempty"`
+	Token string `hcl:"token,optional" json:"token,omitempty" sensitive:"true"`
+	Key   string `hcl:"key,optional" json:"key,omitempty" sensitive:"true"`
+}
+
+func TestSensitiveValuesReturnsTaggedFields(t *testing.T) {
+	r := &sensitiveResource{Name: "test", Token: "abc123"}
+
+	require.Equal(t, []string{"abc123"}, SensitiveValues(r))
+}
+
+func TestRegisterSensitiveValuesRedactsValues(t *testing.T) {
+	r := &sensitiveResource{Name: "test", Token: "def456", Key: "ghi789"}
+
+	RegisterSensitiveValues([]types.Resource{r})
+
+	require.Equal(t, "test (sensitive) (sensitive)", secrets.Redact("test def456 ghi789"))
+}
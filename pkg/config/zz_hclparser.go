@@ -58,6 +58,7 @@ func NewParser(callback hclconfig.WalkCallback, variables map[string]string, var
 	p.RegisterFunction("data_with_permissions", customHCLFuncDataFolderWithPermissions)
 	p.RegisterFunction("system", customHCLFuncSystem)
 	p.RegisterFunction("exists", customHCLFuncExists)
+	p.RegisterFunction("compose", customHCLFuncCompose)
 
 	return p
 }
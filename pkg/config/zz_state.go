@@ -1,17 +1,111 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"time"
 
 	"github.com/jumppad-labs/hclconfig"
+	"github.com/jumppad-labs/jumppad/pkg/clients/state"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
 )
 
+// StateBackendEnvVar is the environment variable that can be used to override
+// the backend used to store the state, it takes precedence over any backend
+// defined in a blueprint. See state.ParseURL for the supported formats.
+const StateBackendEnvVar = "JUMPPAD_STATE_BACKEND"
+
+// StateBackend returns the backend that is used to store the state.
+// The backend is selected in the following order:
+//
+//  1. the JUMPPAD_STATE_BACKEND environment variable
+//  2. the backend recorded by the last applied blueprint, see SetStateBackend
+//  3. the local state file at utils.StatePath()
+func StateBackend() (state.Backend, error) {
+	c, err := StateBackendConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.New(c)
+}
+
+// StateBackendConfig returns the config for the currently selected state backend
+func StateBackendConfig() (*state.Config, error) {
+	if u := os.Getenv(StateBackendEnvVar); u != "" {
+		return state.ParseURL(u)
+	}
+
+	d, err := os.ReadFile(utils.StateBackendPath())
+	if err == nil {
+		c := &state.Config{}
+		err := json.Unmarshal(d, c)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read state backend config '%s': %s", utils.StateBackendPath(), err)
+		}
+
+		return c, nil
+	}
+
+	return &state.Config{Type: state.BackendLocal, Local: &state.LocalConfig{Path: utils.StatePath()}}, nil
+}
+
+// SetStateBackend records the backend that should be used by subsequent
+// commands to load and save the state. Passing nil resets the state to the
+// default local file.
+func SetStateBackend(c *state.Config) error {
+	if c == nil {
+		err := os.Remove(utils.StateBackendPath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("unable to remove state backend config '%s': %s", utils.StateBackendPath(), err)
+		}
+
+		return nil
+	}
+
+	// ensure that the config is valid before saving
+	_, err := state.New(c)
+	if err != nil {
+		return err
+	}
+
+	d, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("unable to serialize state backend config: %s", err)
+	}
+
+	err = os.MkdirAll(utils.StateDir(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create directory for state file '%s', error: %s", utils.StateDir(), err)
+	}
+
+	// the backend config can contain credentials
+	err = os.WriteFile(utils.StateBackendPath(), d, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to write state backend config '%s', error: %s", utils.StateBackendPath(), err)
+	}
+
+	return nil
+}
+
 func LoadState() (*hclconfig.Config, error) {
-	d, err := os.ReadFile(utils.StatePath())
+	b, err := StateBackend()
+	if err != nil {
+		return hclconfig.NewConfig(), fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	return LoadStateFromBackend(b)
+}
+
+// LoadStateFromBackend loads the state from the given backend rather than
+// the currently selected backend
+func LoadStateFromBackend(b state.Backend) (*hclconfig.Config, error) {
+	d, err := b.Read()
 	if err != nil {
-		return hclconfig.NewConfig(), fmt.Errorf("unable to read state file: %s", err)
+		return hclconfig.NewConfig(), fmt.Errorf("unable to read state file: %w", err)
 	}
 
 	p := NewParser(nil, nil, nil)
@@ -30,15 +124,100 @@ func SaveState(c *hclconfig.Config) error {
 		return fmt.Errorf("unable to serialize config to JSON: %s", err)
 	}
 
-	err = os.MkdirAll(utils.StateDir(), os.ModePerm)
+	b, err := StateBackend()
 	if err != nil {
-		return fmt.Errorf("unable to create directory for state file '%s', error: %s", utils.StateDir(), err)
+		return fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	return b.Write(d)
+}
+
+// DeleteState removes the state from the configured backend and resets the
+// backend selection to the default
+func DeleteState() error {
+	b, err := StateBackend()
+	if err != nil {
+		return fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	err = b.Delete()
+	if err != nil {
+		return fmt.Errorf("unable to delete state from '%s': %s", b, err)
+	}
+
+	return SetStateBackend(nil)
+}
+
+// StateLock is an advisory lock held on the state
+type StateLock struct {
+	backend state.Backend
+	info    *state.LockInfo
+}
+
+// LockState acquires an advisory lock on the configured state backend, if the
+// state is locked by another process LockState waits for the given timeout
+// before returning an error.
+func LockState(timeout time.Duration) (*StateLock, error) {
+	b, err := StateBackend()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	return LockStateBackend(b, timeout)
+}
+
+// LockStateBackend acquires an advisory lock on the given backend rather than
+// the currently selected backend, this is used to lock a new backend before
+// state is migrated to it
+func LockStateBackend(b state.Backend, timeout time.Duration) (*StateLock, error) {
+	info := state.NewLockInfo()
+
+	err := state.AcquireLock(b, info, timeout, 1*time.Second)
+	if err != nil {
+		var le *state.LockedError
+		if errors.As(err, &le) {
+			return nil, fmt.Errorf("%w\n\nIf the process that holds the lock is no longer running the lock can be removed with 'jumppad state unlock'", err)
+		}
+
+		return nil, err
 	}
 
-	err = os.WriteFile(utils.StatePath(), d, os.ModePerm)
+	return &StateLock{b, info}, nil
+}
+
+// Unlock releases the lock, it is safe to call Unlock multiple times
+func (l *StateLock) Unlock() error {
+	if l == nil || l.info == nil {
+		return nil
+	}
+
+	err := l.backend.Unlock(l.info.ID)
 	if err != nil {
-		return fmt.Errorf("unable to write state file '%s', error: %s", utils.StatePath(), err)
+		return err
 	}
 
+	l.info = nil
 	return nil
 }
+
+// StateLockInfo returns the details of the current lock holder for the
+// configured backend, nil is returned when the state is not locked
+func StateLockInfo() (*state.LockInfo, error) {
+	b, err := StateBackend()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	return b.LockInfo()
+}
+
+// ForceUnlockState removes any lock from the configured state backend
+// regardless of the owner
+func ForceUnlockState() error {
+	b, err := StateBackend()
+	if err != nil {
+		return fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	return b.Unlock("")
+}
@@ -3,6 +3,14 @@ package constants
 // PropertyStatus is the key for the Metadata property that contains the status
 const PropertyStatus = "status"
 
+// PropertyAttempts is the key for the Metadata property that contains the
+// number of attempts made by the last create or refresh of the resource
+const PropertyAttempts = "attempts"
+
+// PropertyHealth is the key for the Metadata property that contains the
+// current health of a container, it is only set by jumppad status
+const PropertyHealth = "health"
+
 const (
 	// StatusCreated is set once the resource has been successfully created
 	StatusCreated = "created"
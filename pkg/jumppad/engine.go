@@ -5,22 +5,31 @@ import (
 	// "fmt"
 
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/jumppad-labs/hclconfig"
 	hclerrors "github.com/jumppad-labs/hclconfig/errors"
 	"github.com/jumppad-labs/hclconfig/resources"
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/clients/state"
 	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/compose"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/backend"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/cache"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/network"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+	"github.com/jumppad-labs/jumppad/pkg/secrets"
 	"github.com/jumppad-labs/jumppad/pkg/utils"
+	sdk "github.com/jumppad-labs/plugin-sdk"
 )
 
 // Clients contains clients which are responsible for creating and destroying resources
@@ -40,16 +49,56 @@ type Engine interface {
 	Destroy(ctx context.Context, force bool) error
 	Config() *hclconfig.Config
 	Diff(path string, variables map[string]string, variablesFile string) (new []types.Resource, changed []types.Resource, removed []types.Resource, cfg *hclconfig.Config, err error)
+
+	// Plan returns the changes that applying the configuration would make
+	// without modifying any resources or the state
+	Plan(path string, variables map[string]string, variablesFile string) (*Plan, error)
+
+	// SetLockTimeout sets the duration that Apply and Destroy will wait to acquire
+	// the state lock when it is held by another process
+	SetLockTimeout(d time.Duration)
+
+	// SetParallelism sets the maximum number of resources that Apply and Destroy
+	// will process concurrently, a value less than 1 removes the limit
+	SetParallelism(n int)
+
+	// SetTargets limits Apply to the given resources and their dependencies and
+	// Destroy to the given resources and their dependents
+	SetTargets(targets []string) error
+
+	// Subscribe returns a channel that receives the events emitted as resources
+	// are processed, the returned function cancels the subscription
+	Subscribe() (<-chan Event, func())
 }
 
+// DefaultParallelism is the default number of resources that are
+// processed concurrently
+const DefaultParallelism = 10
+
 // EngineImpl is responsible for creating and destroying resources
 type EngineImpl struct {
-	providers  config.Providers
-	log        logger.Logger
-	config     *hclconfig.Config
-	ctx        context.Context
-	force      bool
-	cacheMutex sync.Mutex
+	providers   config.Providers
+	log         logger.Logger
+	config      *hclconfig.Config
+	ctx         context.Context
+	force       bool
+	cacheMutex  sync.Mutex
+	stateMutex  sync.Mutex
+	lockTimeout time.Duration
+
+	// limiter is a semaphore that bounds the number of resources that are
+	// processed concurrently by the DAG walk, nil when there is no limit
+	limiter chan struct{}
+
+	// targets are the resources Apply and Destroy are limited to, targeted
+	// contains the ids of the targets and resources related to them and is
+	// nil when all resources are processed
+	targets  []resources.FQRN
+	targeted map[string]bool
+
+	// subscribers receive the events emitted by the engine
+	eventMutex  sync.Mutex
+	subscribers map[chan Event]bool
 }
 
 // New creates a new Jumppad engine
@@ -58,6 +107,7 @@ func New(p config.Providers, l logger.Logger) (Engine, error) {
 	e.log = l
 	e.providers = p
 	e.cacheMutex = sync.Mutex{}
+	e.SetParallelism(DefaultParallelism)
 
 	// Set the standard writer to our logger as the DAG uses the standard library log.
 	log.SetOutput(l.StandardWriter())
@@ -65,6 +115,21 @@ func New(p config.Providers, l logger.Logger) (Engine, error) {
 	return e, nil
 }
 
+// SetLockTimeout sets the duration to wait for the state lock
+func (e *EngineImpl) SetLockTimeout(d time.Duration) {
+	e.lockTimeout = d
+}
+
+// SetParallelism sets the maximum number of resources processed concurrently
+func (e *EngineImpl) SetParallelism(n int) {
+	if n < 1 {
+		e.limiter = nil
+		return
+	}
+
+	e.limiter = make(chan struct{}, n)
+}
+
 // Config returns the parsed config
 func (e *EngineImpl) Config() *hclconfig.Config {
 	return e.config
@@ -104,7 +169,13 @@ func (e *EngineImpl) ParseConfigWithVariables(path string, vars map[string]strin
 		return nil
 	})
 
-	return e.config, err
+	if err != nil {
+		return e.config, err
+	}
+
+	// images that do not meet the image policies fail before any resources
+	// are created
+	return e.config, checkImageReferences(e.config)
 }
 
 // Diff compares the current configuration with the state and returns the resources that are new, changed or removed
@@ -115,9 +186,6 @@ func (e *EngineImpl) Diff(path string, variables map[string]string, variablesFil
 	var changed []types.Resource
 	var removed []types.Resource
 
-	// load the stack
-	past, _ := config.LoadState()
-
 	// Parse the config to check it is valid
 	res, parseErr := e.ParseConfigWithVariables(path, variables, variablesFile)
 
@@ -135,6 +203,14 @@ func (e *EngineImpl) Diff(path string, variables map[string]string, variablesFil
 		}
 	}
 
+	// the config may define where the state is stored, the backend is only
+	// configured when the config is applied, diff reads the state that
+	// would be used without changing the backend
+	past, err := e.loadStateForConfig(res)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	unchanged := []types.Resource{}
 
 	for _, r := range res.Resources {
@@ -148,11 +224,26 @@ func (e *EngineImpl) Diff(path string, variables map[string]string, variablesFil
 			continue
 		}
 
+		// resources that are being disabled will be destroyed
+		if r.GetDisabled() && preventsDestroy(r) &&
+			cr.Metadata().Properties[constants.PropertyStatus] == constants.StatusCreated {
+			return nil, nil, nil, nil, preventDestroyError(r, "it has been disabled")
+		}
+
 		// check if the hcl resource text has changed
 		if cr.Metadata().Checksum.Parsed != r.Metadata().Checksum.Parsed {
-			// resource has changes rebuild
-			changed = append(changed, r)
-			continue
+			// changes to attributes in lifecycle.ignore_changes do not
+			// cause the resource to be rebuilt
+			attrs, err := changedAttributes(cr, r)
+			if err != nil {
+				return nil, nil, nil, nil, fmt.Errorf(`unable to compare resource "%s", %s`, r.Metadata().ID, err)
+			}
+
+			if len(attrs) > 0 {
+				// resource has changes rebuild
+				changed = append(changed, r)
+				continue
+			}
 		}
 
 		unchanged = append(unchanged, r)
@@ -175,6 +266,10 @@ func (e *EngineImpl) Diff(path string, variables map[string]string, variablesFil
 		}
 
 		if !found {
+			if preventsDestroy(r) {
+				return nil, nil, nil, nil, preventDestroyError(r, "it has been removed from the config")
+			}
+
 			removed = append(removed, r)
 		}
 	}
@@ -210,6 +305,21 @@ func (e *EngineImpl) Apply(ctx context.Context, path string) (*hclconfig.Config,
 
 // ApplyWithVariables applies the current config creating the resources
 func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars map[string]string, variablesFile string) (*hclconfig.Config, error) {
+	start := time.Now()
+
+	c, err := e.applyWithVariables(ctx, path, vars, variablesFile)
+
+	ev := Event{Type: EventApplyFinished, Duration: time.Since(start)}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+
+	e.emit(ev)
+
+	return c, err
+}
+
+func (e *EngineImpl) applyWithVariables(ctx context.Context, path string, vars map[string]string, variablesFile string) (*hclconfig.Config, error) {
 	e.ctx = ctx
 
 	// abs paths
@@ -228,8 +338,45 @@ func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars m
 		}
 	}
 
+	// prevent any other process modifying the state while applying
+	lock, err := config.LockState(e.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err := lock.Unlock()
+		if err != nil {
+			e.log.Error("Unable to release state lock", "error", err)
+		}
+	}()
+
 	// get a diff of resources
-	_, _, removed, _, err := e.Diff(path, vars, variablesFile)
+	_, _, removed, parsed, err := e.Diff(path, vars, variablesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// the config may define where the state is stored, this needs to be
+	// set before the state is loaded. When the backend changes the lock is
+	// moved to the new backend
+	lock, err = e.configureStateBackend(parsed, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	// the providers check the images they create containers from against
+	// the image policies
+	e.ctx = policy.WithPolicies(e.ctx, policy.Find(parsed))
+
+	// when targets are set only the targets and their dependencies are created,
+	// removed resources are only destroyed when explicitly targeted
+	all := []types.Resource{}
+	if parsed != nil {
+		all = append(all, parsed.Resources...)
+	}
+
+	e.targeted, err = e.resolveTargets(append(all, removed...), true)
 	if err != nil {
 		return nil, err
 	}
@@ -247,12 +394,14 @@ func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars m
 	if err != nil {
 		// create a new cache with the correct registries
 		ca := &cache.ImageCache{
-			ResourceBase: types.ResourceBase{
-				Meta: types.Meta{
-					Name:       "default",
-					Type:       cache.TypeImageCache,
-					ID:         "resource.image_cache.default",
-					Properties: map[string]interface{}{},
+			ResourceOptions: config.ResourceOptions{
+				ResourceBase: types.ResourceBase{
+					Meta: types.Meta{
+						Name:       "default",
+						Type:       cache.TypeImageCache,
+						ID:         "resource.image_cache.default",
+						Properties: map[string]interface{}{},
+					},
 				},
 			},
 		}
@@ -291,6 +440,10 @@ func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars m
 
 	// we need to remove any resources that are in the state but not in the config
 	for _, r := range removed {
+		if !e.isTargeted(r) {
+			continue
+		}
+
 		e.log.Debug("removing resource in state but not current config", "id", r.Metadata().ID)
 
 		p := e.providers.GetProvider(r)
@@ -300,7 +453,10 @@ func (e *EngineImpl) ApplyWithVariables(ctx context.Context, path string, vars m
 		}
 
 		// call destroy
+		start := time.Now()
 		err := p.Destroy(e.ctx, e.force)
+		e.emitResult(EventResourceDestroyed, r.Metadata().ID, start, err)
+
 		if err != nil {
 			processErr = fmt.Errorf("unable to destroy resource Name: %s, Type: %s", r.Metadata().Name, r.Metadata().Type)
 			continue
@@ -324,6 +480,19 @@ func (e *EngineImpl) Destroy(ctx context.Context, force bool) error {
 	e.force = force
 	e.ctx = ctx
 
+	// prevent any other process modifying the state while destroying
+	lock, err := config.LockState(e.lockTimeout)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := lock.Unlock()
+		if err != nil {
+			e.log.Error("Unable to release state lock", "error", err)
+		}
+	}()
+
 	// load the state
 	c, err := config.LoadState()
 	if err != nil {
@@ -332,6 +501,13 @@ func (e *EngineImpl) Destroy(ctx context.Context, force bool) error {
 
 	e.config = c
 
+	// when targets are set only the targets and the resources that depend on
+	// them are destroyed
+	e.targeted, err = e.resolveTargets(c.Resources, false)
+	if err != nil {
+		return err
+	}
+
 	// run through the graph and call the destroy callback
 	// disabled resources are not included in this callback
 	// image cache which is manually added by Apply process
@@ -344,8 +520,168 @@ func (e *EngineImpl) Destroy(ctx context.Context, force bool) error {
 		return fmt.Errorf("error trying to call Destroy on provider: %s", err)
 	}
 
+	// resources that were not targeted remain in the state
+	if e.targeted != nil {
+		return config.SaveState(e.config)
+	}
+
 	// remove the state
-	return os.Remove(utils.StatePath())
+	return config.DeleteState()
+}
+
+// configureStateBackend sets the backend used to store the state when
+// the given config contains a state_backend resource. The environment
+// variable JUMPPAD_STATE_BACKEND always takes precedence.
+// When the backend changes the new backend is locked before any existing
+// state is migrated, providing the new backend does not already contain
+// state. The lock for the backend in use is returned, when the backend
+// changes the lock for the previous backend is released.
+func (e *EngineImpl) configureStateBackend(c *hclconfig.Config, lock *config.StateLock) (*config.StateLock, error) {
+	sb, newConfig, err := e.stateBackendFromConfig(c)
+	if err != nil || newConfig == nil {
+		return lock, err
+	}
+
+	newBackend, err := state.New(newConfig)
+	if err != nil {
+		return lock, fmt.Errorf("unable to create state backend %s: %s", sb.Meta.ID, err)
+	}
+
+	e.log.Info("Configuring state backend", "ref", sb.Meta.ID, "location", newBackend.String())
+
+	// lock the new backend before writing to it so that the state is not
+	// modified by another process using the same backend
+	newLock, err := config.LockStateBackend(newBackend, e.lockTimeout)
+	if err != nil {
+		return lock, err
+	}
+
+	err = e.migrateState(newBackend)
+	if err == nil {
+		err = config.SetStateBackend(newConfig)
+	}
+
+	if err != nil {
+		ue := newLock.Unlock()
+		if ue != nil {
+			e.log.Error("Unable to release state lock", "location", newBackend.String(), "error", ue)
+		}
+
+		return lock, err
+	}
+
+	err = lock.Unlock()
+	if err != nil {
+		e.log.Error("Unable to release state lock for previous backend", "error", err)
+	}
+
+	return newLock, nil
+}
+
+// migrateState copies the state from the current backend to the new backend
+// when the new backend is empty, the state is removed from the current backend
+func (e *EngineImpl) migrateState(newBackend state.Backend) error {
+	currentBackend, err := config.StateBackend()
+	if err != nil {
+		return nil
+	}
+
+	current, err := currentBackend.Read()
+	_, newErr := newBackend.Read()
+
+	if err != nil || !errors.Is(newErr, state.ErrStateNotFound) {
+		return nil
+	}
+
+	e.log.Info("Migrating state", "from", currentBackend.String(), "to", newBackend.String())
+
+	err = newBackend.Write(current)
+	if err != nil {
+		return fmt.Errorf("unable to migrate state to %s: %s", newBackend.String(), err)
+	}
+
+	err = currentBackend.Delete()
+	if err != nil {
+		e.log.Error("Unable to remove state after migration", "location", currentBackend.String(), "error", err)
+	}
+
+	return nil
+}
+
+// loadStateForConfig loads the state that is used when the given config is
+// applied without configuring the backend. When the config changes the
+// backend the state is read from the new backend, or from the current backend
+// when the new backend is empty as the state is migrated when applied.
+func (e *EngineImpl) loadStateForConfig(c *hclconfig.Config) (*hclconfig.Config, error) {
+	sb, newConfig, err := e.stateBackendFromConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if newConfig == nil {
+		s, _ := config.LoadState()
+		return s, nil
+	}
+
+	newBackend, err := state.New(newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create state backend %s: %s", sb.Meta.ID, err)
+	}
+
+	s, err := config.LoadStateFromBackend(newBackend)
+	if errors.Is(err, state.ErrStateNotFound) {
+		s, _ = config.LoadState()
+	}
+
+	return s, nil
+}
+
+// stateBackendFromConfig returns the state_backend resource defined in the
+// root module of the config and the client config for the backend. A nil
+// client config is returned when the config does not define a backend, the
+// backend is already in use, or JUMPPAD_STATE_BACKEND is set.
+func (e *EngineImpl) stateBackendFromConfig(c *hclconfig.Config) (*backend.StateBackend, *state.Config, error) {
+	if c == nil {
+		return nil, nil, nil
+	}
+
+	bes, err := c.FindResourcesByType(backend.TypeStateBackend)
+	if err != nil || len(bes) == 0 {
+		return nil, nil, nil
+	}
+
+	var sb *backend.StateBackend
+	for _, r := range bes {
+		// only backends defined in the root module are used
+		if r.Metadata().Module == "" && !r.GetDisabled() {
+			if sb != nil {
+				return nil, nil, fmt.Errorf("only a single state_backend can be defined, found %s and %s", sb.Meta.ID, r.Metadata().ID)
+			}
+
+			sb = r.(*backend.StateBackend)
+		}
+	}
+
+	if sb == nil {
+		return nil, nil, nil
+	}
+
+	if os.Getenv(config.StateBackendEnvVar) != "" {
+		e.log.Debug("Ignoring state_backend as environment variable is set", "ref", sb.Meta.ID, "env", config.StateBackendEnvVar)
+		return nil, nil, nil
+	}
+
+	newConfig := sb.ToClientConfig()
+	currentConfig, err := config.StateBackendConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if reflect.DeepEqual(newConfig, currentConfig) {
+		return nil, nil, nil
+	}
+
+	return sb, newConfig, nil
 }
 
 // ResourceCount defines the number of resources in a plan
@@ -376,7 +712,40 @@ func (e *EngineImpl) readAndProcessConfig(path string, variables map[string]stri
 		variablesFiles = append(variablesFiles, variablesFile)
 	}
 
-	hclParser := config.NewParser(callback, variables, variablesFiles)
+	// compose files are converted to configuration in the cache folder and
+	// processed like any other blueprint
+	if utils.IsComposeFile(path) {
+		dir, warnings, err := compose.Generate(path)
+		if err != nil {
+			ce := hclerrors.NewConfigError()
+			ce.AppendError(&hclerrors.ParserError{
+				Filename: path,
+				Message:  err.Error(),
+				Level:    hclerrors.ParserErrorLevelError,
+			})
+
+			return ce
+		}
+
+		for _, w := range warnings {
+			e.log.Warn("Compose file contains unsupported configuration", "path", path, "warning", w)
+		}
+
+		path = dir
+	}
+
+	// values restored from the state, like cluster tokens, are registered
+	// before the callback so they are redacted from any logs
+	hclParser := config.NewParser(func(r types.Resource) error {
+		secrets.Register(config.SensitiveValues(r)...)
+
+		err := config.ValidateLifecycle(r)
+		if err != nil {
+			return err
+		}
+
+		return callback(r)
+	}, variables, variablesFiles)
 
 	if utils.IsHCLFile(path) {
 		// ParseFile processes the HCL, builds a graph of resources then calls
@@ -421,7 +790,7 @@ func (e *EngineImpl) destroyDisabledResources(ctx context.Context, force bool) e
 	// these respurces should be destroyed
 
 	for _, r := range e.config.Resources {
-		if r.GetDisabled() &&
+		if r.GetDisabled() && e.isTargeted(r) &&
 			r.Metadata().Properties[constants.PropertyStatus] == constants.StatusCreated {
 
 			p := e.providers.GetProvider(r)
@@ -431,7 +800,10 @@ func (e *EngineImpl) destroyDisabledResources(ctx context.Context, force bool) e
 			}
 
 			// call destroy
+			start := time.Now()
 			err := p.Destroy(ctx, force)
+			e.emitResult(EventResourceDestroyed, r.Metadata().ID, start, err)
+
 			if err != nil {
 				r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
 				return fmt.Errorf("unable to destroy resource Name: %s, Type: %s", r.Metadata().Name, r.Metadata().Type)
@@ -451,7 +823,7 @@ func (e *EngineImpl) appendDisabledResources(c *hclconfig.Config) error {
 	}
 
 	for _, r := range c.Resources {
-		if r.GetDisabled() {
+		if r.GetDisabled() && e.isTargeted(r) {
 			// if the resource already exists just set the status to disabled
 			er, err := e.config.FindResource(resources.FQRNFromResource(r).String())
 			if err == nil {
@@ -470,12 +842,33 @@ func (e *EngineImpl) appendDisabledResources(c *hclconfig.Config) error {
 	return nil
 }
 
+// acquire blocks until the resource can be processed without exceeding the
+// parallelism limit, the returned function must be called to release the slot
+func (e *EngineImpl) acquire() func() {
+	l := e.limiter
+	if l == nil {
+		return func() {}
+	}
+
+	l <- struct{}{}
+	return func() { <-l }
+}
+
 func (e *EngineImpl) createCallback(r types.Resource) error {
 	// if the context is cancelled skip
 	if e.ctx.Err() != nil {
 		return nil
 	}
 
+	// resources that are not targeted are left untouched in the state
+	if !e.isTargeted(r) {
+		return nil
+	}
+
+	// the DAG walk calls the callback concurrently for independent resources
+	release := e.acquire()
+	defer release()
+
 	p := e.providers.GetProvider(r)
 	if p == nil {
 		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
@@ -484,6 +877,7 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 
 	// we need to check if a resource exists in the state, if so the status
 	// should take precedence as all new resources will have an empty state
+	e.stateMutex.Lock()
 	sr, err := e.config.FindResource(r.Metadata().ID)
 	if err == nil {
 		// set the current status to the state status
@@ -492,14 +886,28 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 		// remove the resource, we will add the new version to the state
 		err = e.config.RemoveResource(r)
 		if err != nil {
+			e.stateMutex.Unlock()
 			return fmt.Errorf(`unable to remove resource "%s" from state, %s`, r.Metadata().ID, err)
 		}
 	}
+	e.stateMutex.Unlock()
+
+	start := time.Now()
+	e.emit(Event{Type: EventResourceStarted, Resource: r.Metadata().ID, Time: start})
 
 	var providerError error
+	result := EventResourceCreated
+
 	switch r.Metadata().Properties[constants.PropertyStatus] {
 	case constants.StatusCreated:
-		providerError = p.Refresh(e.ctx)
+		result = EventResourceRefreshed
+
+		// attributes in lifecycle.ignore_changes keep the values from the state
+		providerError = restoreIgnoredChanges(sr, r)
+		if providerError == nil {
+			providerError = e.withRetry(r, "refresh", func() error { return e.refreshOrUpdate(p) })
+		}
+
 		if providerError != nil {
 			r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
 		}
@@ -512,23 +920,22 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 
 	// Always attempt to destroy and re-create failed resources
 	case constants.StatusFailed:
-		providerError = p.Destroy(e.ctx, false)
-		if providerError != nil {
-			r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
-		}
-
-		fallthrough // failed resources should always attempt recreation
+		providerError = e.recreate(p, sr, r)
 
 	default:
 		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusCreated
-		providerError = p.Create(e.ctx)
+		providerError = e.createWithRetry(r, p)
 		if providerError != nil {
 			r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
 		}
 	}
 
-	// add the resource to the state
+	e.emitResult(result, r.Metadata().ID, start, providerError)
+
+	// add the resource to the state, other resources may be appended concurrently
+	e.stateMutex.Lock()
 	err = e.config.AppendResource(r)
+	e.stateMutex.Unlock()
 	if err != nil {
 		return fmt.Errorf(`unable add resource "%s" to state, %s`, r.Metadata().ID, err)
 	}
@@ -538,7 +945,7 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 	if r.Metadata().Type == network.TypeNetwork && r.Metadata().Properties[constants.PropertyStatus] == constants.StatusCreated {
 		// get the image cache
 		e.cacheMutex.Lock()
-		ic, err := e.config.FindResource("resource.image_cache.default")
+		ic, err := e.findInState("resource.image_cache.default")
 		if err == nil {
 			e.log.Debug("Attaching image cache to network", "network", ic.Metadata().ID)
 			ic.AddDependency(r.Metadata().ID)
@@ -555,7 +962,7 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 	if r.Metadata().Type == cache.TypeRegistry && r.Metadata().Properties[constants.PropertyStatus] == constants.StatusCreated {
 		// get the image cache
 		e.cacheMutex.Lock()
-		ic, err := e.config.FindResource("resource.image_cache.default")
+		ic, err := e.findInState("resource.image_cache.default")
 		if err == nil {
 			// append the registry if not all ready present and not in the default list
 
@@ -578,11 +985,11 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 
 			e.log.Debug("Adding registry to image cache", "registry", r.(*cache.Registry).Hostname)
 
-			// we now need to stop and restart the container to pick up the new registry changes
+			// we now need to stop and restart the container to pick up the new registry changes,
+			// the lock is held until the cache has been recreated so that concurrent
+			// network or registry changes do not modify the cache while it is restarting
 			np := e.providers.GetProvider(ic)
 
-			e.cacheMutex.Unlock()
-
 			err := np.Destroy(e.ctx, e.force)
 			if err != nil {
 				e.log.Error("Unable to destroy Image Cache", "error", err)
@@ -595,27 +1002,111 @@ func (e *EngineImpl) createCallback(r types.Resource) error {
 		} else {
 			e.log.Error("Unable to find Image Cache", "error", err)
 		}
+		e.cacheMutex.Unlock()
 	}
 
 	return providerError
 }
 
+// recreate destroys and creates a failed or tainted resource, when the
+// lifecycle sets create_before_destroy the replacement is created before the
+// resource in the state is destroyed
+func (e *EngineImpl) recreate(p sdk.Provider, sr, r types.Resource) error {
+	lc := config.LifecycleFromResource(r)
+
+	if lc != nil && lc.PreventDestroy {
+		return preventDestroyError(r, fmt.Sprintf("it is %s and needs to be recreated", r.Metadata().Properties[constants.PropertyStatus]))
+	}
+
+	if lc != nil && lc.CreateBeforeDestroy {
+		// the provider for the state resource destroys the existing instance
+		op := e.providers.GetProvider(sr)
+		if op == nil {
+			r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
+			return fmt.Errorf("unable to create provider for resource Name: %s, Type: %s", sr.Metadata().Name, sr.Metadata().Type)
+		}
+
+		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusCreated
+		err := e.createWithRetry(r, p)
+		if err != nil {
+			// the existing resource is left in place when the replacement fails
+			r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
+			return err
+		}
+
+		err = op.Destroy(e.ctx, false)
+		if err != nil {
+			e.log.Error("Unable to destroy replaced resource", "ref", sr.Metadata().ID, "error", err)
+		}
+
+		return nil
+	}
+
+	err := p.Destroy(e.ctx, false)
+	if err != nil {
+		e.log.Debug("Unable to destroy resource before recreating", "ref", r.Metadata().ID, "error", err)
+	}
+
+	// failed resources should always attempt recreation
+	r.Metadata().Properties[constants.PropertyStatus] = constants.StatusCreated
+	err = e.createWithRetry(r, p)
+	if err != nil {
+		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
+	}
+
+	return err
+}
+
+// refreshOrUpdate updates the resource in place when the provider supports
+// updates and reports changes, otherwise the resource is refreshed
+func (e *EngineImpl) refreshOrUpdate(p sdk.Provider) error {
+	u, ok := p.(config.Updater)
+	if !ok {
+		return p.Refresh(e.ctx)
+	}
+
+	changed, err := p.Changed()
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return p.Refresh(e.ctx)
+	}
+
+	return u.Update(e.ctx)
+}
+
 func (e *EngineImpl) destroyCallback(r types.Resource) error {
 	// if the context is cancelled skip
 	if e.ctx.Err() != nil {
 		return nil
 	}
 
+	if !e.isTargeted(r) {
+		return nil
+	}
+
+	release := e.acquire()
+	defer release()
+
 	fqrn := resources.FQRNFromResource(r)
 
 	// do nothing for disabled resources
 	if r.GetDisabled() {
 		e.log.Info("Skipping disabled resource", "fqdn", fqrn.String())
 
-		e.config.RemoveResource(r)
+		e.removeFromState(r)
 		return nil
 	}
 
+	if preventsDestroy(r) {
+		return preventDestroyError(r, "the resources are being destroyed")
+	}
+
+	start := time.Now()
+	e.emit(Event{Type: EventResourceStarted, Resource: r.Metadata().ID, Time: start})
+
 	p := e.providers.GetProvider(r)
 
 	if p == nil {
@@ -626,11 +1117,33 @@ func (e *EngineImpl) destroyCallback(r types.Resource) error {
 	err := p.Destroy(e.ctx, e.force)
 	if err != nil && !e.force {
 		r.Metadata().Properties[constants.PropertyStatus] = constants.StatusFailed
+		e.emitResult(EventResourceDestroyed, r.Metadata().ID, start, err)
+
 		return fmt.Errorf("unable to destroy resource Name: %s, Type: %s, Error: %s", r.Metadata().Name, r.Metadata().Type, err)
 	}
 
+	e.emitResult(EventResourceDestroyed, r.Metadata().ID, start, nil)
+
 	// remove from the state
-	e.config.RemoveResource(r)
+	e.removeFromState(r)
 
 	return nil
 }
+
+// findInState finds a resource in the state, guarding against concurrent
+// modification by other callbacks in the DAG walk
+func (e *EngineImpl) findInState(fqrn string) (types.Resource, error) {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	return e.config.FindResource(fqrn)
+}
+
+// removeFromState removes the resource from the state, guarding against
+// concurrent modification by other callbacks in the DAG walk
+func (e *EngineImpl) removeFromState(r types.Resource) {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+
+	e.config.RemoveResource(r)
+}
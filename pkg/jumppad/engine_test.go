@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jumppad-labs/hclconfig"
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/clients/logger"
+	"github.com/jumppad-labs/jumppad/pkg/clients/state"
 	"github.com/jumppad-labs/jumppad/pkg/config"
 	"github.com/jumppad-labs/jumppad/pkg/config/mocks"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/cache"
@@ -418,6 +422,280 @@ func TestDestroyCallsProviderGenerateErrorStopsExecution(t *testing.T) {
 	require.FileExists(t, utils.StatePath())
 }
 
+func TestApplyWithStateBackendWritesStateToBackend(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	sp := filepath.Join(t.TempDir(), "remote", "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+
+resource "network" "onprem" {
+  subnet = "10.6.0.0/16"
+}
+`, filepath.ToSlash(sp)))
+
+	_, err := e.Apply(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.FileExists(t, sp)
+	require.FileExists(t, utils.StateBackendPath())
+	require.NoFileExists(t, utils.StatePath())
+
+	// subsequent loads should use the configured backend
+	sf := testLoadState(t)
+	_, err = sf.FindResource("resource.network.onprem")
+	require.NoError(t, err)
+
+	err = e.Destroy(context.Background(), false)
+	require.NoError(t, err)
+
+	require.NoFileExists(t, sp)
+	require.NoFileExists(t, utils.StateBackendPath())
+}
+
+func TestApplyWithStateBackendMigratesExistingState(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, existingState)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	_, err := e.Apply(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.FileExists(t, sp)
+	require.FileExists(t, utils.StateBackendPath())
+	require.NoFileExists(t, utils.StatePath())
+}
+
+func TestApplyWithStateBackendLockedDoesNotMigrateState(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, existingState)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	// another process holds the lock for the new backend
+	lock, err := config.LockStateBackend(state.NewLocal(sp), 0)
+	require.NoError(t, err)
+	defer lock.Unlock()
+
+	_, err = e.Apply(context.Background(), dir)
+	require.ErrorContains(t, err, "state is locked")
+
+	require.NoFileExists(t, sp)
+	require.FileExists(t, utils.StatePath())
+	require.NoFileExists(t, utils.StateBackendPath())
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+
+	// the lock for the current backend is released
+	info, err := config.StateLockInfo()
+	require.NoError(t, err)
+	require.Nil(t, info)
+}
+
+func TestApplyWithStateBackendMovesLockToNewBackend(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, existingState)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	_, err := e.Apply(context.Background(), dir)
+	require.NoError(t, err)
+
+	// both locks are released after the apply
+	info, err := state.NewLocal(sp).LockInfo()
+	require.NoError(t, err)
+	require.Nil(t, info)
+
+	info, err = state.NewLocal(utils.StatePath()).LockInfo()
+	require.NoError(t, err)
+	require.Nil(t, info)
+}
+
+func TestDiffWithStateBackendDoesNotMigrateState(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, existingState)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	_, _, removed, _, err := e.Diff(dir, nil, "")
+	require.NoError(t, err)
+
+	// the existing state would be migrated so it should be in the diff
+	require.NotEmpty(t, removed)
+
+	// the backend is only configured when the config is applied
+	require.NoFileExists(t, sp)
+	require.NoFileExists(t, utils.StateBackendPath())
+	require.FileExists(t, utils.StatePath())
+}
+
+func TestApplyWithStateBackendEnvironmentVariableIgnoresConfig(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	envPath := filepath.Join(t.TempDir(), "env.json")
+	t.Setenv(config.StateBackendEnvVar, envPath)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	_, err := e.Apply(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.FileExists(t, envPath)
+	require.NoFileExists(t, sp)
+}
+
+func TestApplyWhenStateLockedReturnsError(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	lock, err := config.LockState(0)
+	require.NoError(t, err)
+	defer lock.Unlock()
+
+	_, err = e.Apply(context.Background(), "../../examples/single_k3s_cluster")
+	require.ErrorContains(t, err, "state is locked")
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestApplyReleasesStateLock(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	_, err := e.Apply(context.Background(), "../../examples/single_k3s_cluster")
+	require.NoError(t, err)
+
+	info, err := config.StateLockInfo()
+	require.NoError(t, err)
+	require.Nil(t, info)
+}
+
+func TestApplyWithParallelismOfOneCreatesResources(t *testing.T) {
+	e, mp := setupTests(t, nil)
+	e.SetParallelism(1)
+
+	_, err := e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.NoError(t, err)
+
+	// 6 resources in the file plus the image cache
+	testAssertMethodCalled(t, mp, "Create", 7)
+}
+
+func TestAcquireLimitsConcurrency(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	e.SetParallelism(2)
+
+	mu := sync.Mutex{}
+	current := 0
+	max := 0
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			release := e.acquire()
+			defer release()
+
+			mu.Lock()
+			current++
+			if current > max {
+				max = current
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			current--
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 2, max)
+}
+
+type updatableProvider struct {
+	*mocks.Provider
+}
+
+func (p *updatableProvider) Update(ctx context.Context) error {
+	return p.Called(ctx).Error(0)
+}
+
+func setupUpdatableProvider(changed bool) *updatableProvider {
+	m := &mocks.Provider{}
+	m.On("Changed").Return(changed, nil)
+	m.On("Refresh", mock.Anything).Return(nil)
+	m.On("Update", mock.Anything).Return(nil)
+
+	return &updatableProvider{m}
+}
+
+func TestRefreshOrUpdateCallsUpdateWhenChanged(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	e.ctx = context.Background()
+
+	p := setupUpdatableProvider(true)
+
+	err := e.refreshOrUpdate(p)
+	require.NoError(t, err)
+
+	p.AssertCalled(t, "Update", mock.Anything)
+	p.AssertNotCalled(t, "Refresh", mock.Anything)
+}
+
+func TestRefreshOrUpdateCallsRefreshWhenNotChanged(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	e.ctx = context.Background()
+
+	p := setupUpdatableProvider(false)
+
+	err := e.refreshOrUpdate(p)
+	require.NoError(t, err)
+
+	p.AssertCalled(t, "Refresh", mock.Anything)
+	p.AssertNotCalled(t, "Update", mock.Anything)
+}
+
 func TestDestroyFailSetsStatus(t *testing.T) {
 	e, _ := setupTestsWithState(t, map[string]error{"mycontainer": fmt.Errorf("boom")}, complexState)
 
@@ -441,6 +719,35 @@ func TestParseConfig(t *testing.T) {
 	testAssertMethodCalled(t, mp, "Destroy", 0)
 }
 
+func TestParseConfigWithComposeFile(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	r, err := e.ParseConfig("../../examples/compose/docker-compose.yml")
+	require.NoError(t, err)
+
+	_, err = r.FindResource("resource.network.compose_default")
+	require.NoError(t, err)
+
+	c, err := r.FindResource("resource.container.web")
+	require.NoError(t, err)
+	require.Equal(t, "nginx:1.27", c.(*container.Container).Image.Name)
+	require.Equal(t, []string{"resource.container.cache"}, c.GetDependencies())
+
+	// should not have created any providers
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestParseConfigWithComposeModule(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	r, err := e.ParseConfig("../../examples/compose")
+	require.NoError(t, err)
+
+	c, err := r.FindResource("module.compose.resource.container.cache")
+	require.NoError(t, err)
+	require.Equal(t, []string{"redis-server", "--save", "60", "1"}, c.(*container.Container).Command)
+}
+
 func TestParseWithVariables(t *testing.T) {
 	e, mp := setupTests(t, nil)
 
@@ -0,0 +1,97 @@
+package jumppad
+
+import (
+	"time"
+)
+
+// EventType is the type of event emitted by the engine
+type EventType string
+
+const (
+	// EventResourceStarted is emitted when the engine starts to create,
+	// refresh or destroy a resource
+	EventResourceStarted EventType = "resource_started"
+	// EventResourceCreated is emitted when a resource has been created
+	EventResourceCreated EventType = "resource_created"
+	// EventResourceRefreshed is emitted when an existing resource has been
+	// refreshed or updated
+	EventResourceRefreshed EventType = "resource_refreshed"
+	// EventResourceFailed is emitted when the provider returns an error
+	EventResourceFailed EventType = "resource_failed"
+	// EventResourceDestroyed is emitted when a resource has been destroyed
+	EventResourceDestroyed EventType = "resource_destroyed"
+	// EventApplyFinished is emitted when Apply completes, Error is set when
+	// the apply failed
+	EventApplyFinished EventType = "apply_finished"
+)
+
+// eventBufferSize is the number of events buffered for each subscriber, events
+// are dropped for subscribers that do not keep up
+const eventBufferSize = 100
+
+// Event is emitted by the engine as resources are processed
+type Event struct {
+	Type EventType `json:"type"`
+	// Resource is the id of the resource, empty for apply events
+	Resource string    `json:"resource,omitempty"`
+	Time     time.Time `json:"time"`
+	// Duration is the time taken by the operation, not set for started events
+	Duration time.Duration `json:"duration,omitempty"`
+	// Error is the error returned by the operation
+	Error string `json:"error,omitempty"`
+}
+
+// Subscribe returns a channel that receives the events emitted by the engine,
+// the returned function removes the subscription and closes the channel
+func (e *EngineImpl) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	e.eventMutex.Lock()
+	defer e.eventMutex.Unlock()
+
+	if e.subscribers == nil {
+		e.subscribers = map[chan Event]bool{}
+	}
+
+	e.subscribers[ch] = true
+
+	return ch, func() {
+		e.eventMutex.Lock()
+		defer e.eventMutex.Unlock()
+
+		if e.subscribers[ch] {
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// emit sends the event to all subscribers without blocking the engine
+func (e *EngineImpl) emit(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	e.eventMutex.Lock()
+	defer e.eventMutex.Unlock()
+
+	for ch := range e.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			e.log.Debug("Dropping event, subscriber is not receiving", "type", ev.Type, "resource", ev.Resource)
+		}
+	}
+}
+
+// emitResult emits the event for a completed resource operation, when err is
+// not nil a failed event is emitted
+func (e *EngineImpl) emitResult(t EventType, id string, start time.Time, err error) {
+	ev := Event{Type: t, Resource: id, Duration: time.Since(start)}
+	if err != nil {
+		ev.Type = EventResourceFailed
+		ev.Error = err.Error()
+	}
+
+	e.emit(ev)
+}
@@ -0,0 +1,107 @@
+package jumppad
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func collectEvents(events <-chan Event, unsubscribe func()) []Event {
+	unsubscribe()
+
+	all := []Event{}
+	for ev := range events {
+		all = append(all, ev)
+	}
+
+	return all
+}
+
+func countEvents(events []Event, t EventType) int {
+	n := 0
+	for _, ev := range events {
+		if ev.Type == t {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestApplyEmitsResourceEvents(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	events, unsubscribe := e.Subscribe()
+
+	_, err := e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.NoError(t, err)
+
+	all := collectEvents(events, unsubscribe)
+
+	// the image cache is created outside of the graph and does not emit events
+	created := 0
+	for _, p := range mp.Providers {
+		for _, c := range p.Calls {
+			if c.Method == "Create" {
+				created++
+			}
+		}
+	}
+
+	require.Equal(t, created-1, countEvents(all, EventResourceStarted))
+	require.Equal(t, created-1, countEvents(all, EventResourceCreated))
+
+	last := all[len(all)-1]
+	require.Equal(t, EventApplyFinished, last.Type)
+	require.Empty(t, last.Error)
+}
+
+func TestApplyEmitsFailedEventWithError(t *testing.T) {
+	e, _ := setupTests(t, map[string]error{"onprem": fmt.Errorf("boom")})
+
+	events, unsubscribe := e.Subscribe()
+
+	_, err := e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.Error(t, err)
+
+	all := collectEvents(events, unsubscribe)
+
+	require.Equal(t, 1, countEvents(all, EventResourceFailed))
+
+	for _, ev := range all {
+		if ev.Type == EventResourceFailed {
+			require.Equal(t, "resource.network.onprem", ev.Resource)
+			require.Equal(t, "boom", ev.Error)
+		}
+	}
+
+	require.NotEmpty(t, all[len(all)-1].Error)
+}
+
+func TestDestroyEmitsDestroyedEvents(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, complexState)
+
+	events, unsubscribe := e.Subscribe()
+
+	err := e.Destroy(context.Background(), false)
+	require.NoError(t, err)
+
+	all := collectEvents(events, unsubscribe)
+
+	require.Equal(t, 4, countEvents(all, EventResourceDestroyed))
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	events, unsubscribe := e.Subscribe()
+	unsubscribe()
+
+	// calling unsubscribe again is a no-op
+	unsubscribe()
+
+	_, ok := <-events
+	require.False(t, ok)
+}
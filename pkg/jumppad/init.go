@@ -4,6 +4,7 @@ import (
 	"github.com/jumppad-labs/hclconfig/resources"
 	"github.com/jumppad-labs/hclconfig/types"
 	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/backend"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/blueprint"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/build"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/cache"
@@ -20,6 +21,7 @@ import (
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/nomad"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/null"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/ollama"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/random"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/template"
 	"github.com/jumppad-labs/jumppad/pkg/config/resources/terraform"
@@ -27,6 +29,7 @@ import (
 )
 
 func init() {
+	config.RegisterResource(backend.TypeStateBackend, &backend.StateBackend{}, &null.Provider{})
 	config.RegisterResource(blueprint.TypeBlueprint, &blueprint.Blueprint{}, &null.Provider{})
 	config.RegisterResource(build.TypeBuild, &build.Build{}, &build.Provider{})
 	config.RegisterResource(cache.TypeImageCache, &cache.ImageCache{}, &cache.Provider{})
@@ -52,6 +55,7 @@ func init() {
 	config.RegisterResource(network.TypeNetwork, &network.Network{}, &network.Provider{})
 	config.RegisterResource(nomad.TypeNomadCluster, &nomad.NomadCluster{}, &nomad.ClusterProvider{})
 	config.RegisterResource(nomad.TypeNomadJob, &nomad.NomadJob{}, &nomad.JobProvider{})
+	config.RegisterResource(policy.TypeImagePolicy, &policy.ImagePolicy{}, &null.Provider{})
 	config.RegisterResource(ollama.TypeOllamaModel, &ollama.OllamaModel{}, &ollama.ModelProvider{})
 	config.RegisterResource(random.TypeRandomNumber, &random.RandomNumber{}, &random.RandomNumberProvider{})
 	config.RegisterResource(random.TypeRandomID, &random.RandomID{}, &random.RandomIDProvider{})
@@ -0,0 +1,118 @@
+package jumppad
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+)
+
+// preventDestroyError is returned when a resource with lifecycle.prevent_destroy
+// would be destroyed
+func preventDestroyError(r types.Resource, reason string) error {
+	return fmt.Errorf(
+		`resource "%s" has lifecycle.prevent_destroy set and can not be destroyed as %s, set prevent_destroy to false and run up before destroying the resource`,
+		r.Metadata().ID,
+		reason,
+	)
+}
+
+// preventsDestroy returns true when the lifecycle of the resource does not
+// allow it to be destroyed
+func preventsDestroy(r types.Resource) bool {
+	lc := config.LifecycleFromResource(r)
+	return lc != nil && lc.PreventDestroy
+}
+
+// changedAttributes returns the differences between the resource in the state
+// and the parsed resource, attributes listed in lifecycle.ignore_changes and
+// the lifecycle block itself are not included
+func changedAttributes(old, new types.Resource) ([]AttributeChange, error) {
+	attrs, err := diffAttributes(old, new)
+	if err != nil {
+		return nil, err
+	}
+
+	lc := config.LifecycleFromResource(new)
+
+	changes := []AttributeChange{}
+	for _, a := range attrs {
+		if a.Path == "lifecycle" || strings.HasPrefix(a.Path, "lifecycle.") || lc.Ignores(a.Path) {
+			continue
+		}
+
+		changes = append(changes, a)
+	}
+
+	return changes, nil
+}
+
+// restoreIgnoredChanges sets the attributes listed in lifecycle.ignore_changes
+// to the values stored in the state so that the provider does not apply them.
+// Nested attributes are separated by a dot e.g. image.name
+func restoreIgnoredChanges(old, new types.Resource) error {
+	lc := config.LifecycleFromResource(new)
+	if lc == nil || len(lc.IgnoreChanges) == 0 {
+		return nil
+	}
+
+	om, err := resourceToMap(old)
+	if err != nil {
+		return err
+	}
+
+	nm, err := resourceToMap(new)
+	if err != nil {
+		return err
+	}
+
+	// only the top level attributes that contain ignored values are written
+	// back to the resource
+	restored := map[string]interface{}{}
+	for _, path := range lc.IgnoreChanges {
+		parts := strings.Split(path, ".")
+
+		v, _ := lookupPath(om, parts)
+		setPath(nm, parts, v)
+
+		restored[parts[0]] = nm[parts[0]]
+	}
+
+	d, err := json.Marshal(restored)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(d, new)
+}
+
+func lookupPath(m map[string]interface{}, parts []string) (interface{}, bool) {
+	v, ok := m[parts[0]]
+	if !ok || len(parts) == 1 {
+		return v, ok
+	}
+
+	child, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	return lookupPath(child, parts[1:])
+}
+
+func setPath(m map[string]interface{}, parts []string, v interface{}) {
+	if len(parts) == 1 {
+		m[parts[0]] = v
+		return
+	}
+
+	child, ok := m[parts[0]].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		m[parts[0]] = child
+	}
+
+	setPath(child, parts[1:], v)
+}
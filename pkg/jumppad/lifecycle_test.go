@@ -0,0 +1,230 @@
+package jumppad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffIgnoresChangesToIgnoredAttributes(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, lifecycleState)
+
+	new, changed, removed, _, err := e.Diff("../../examples/lifecycle", nil, "")
+	require.NoError(t, err)
+
+	require.Len(t, new, 1)
+	require.Len(t, changed, 0)
+	require.Len(t, removed, 0)
+}
+
+func TestApplyKeepsIgnoredAttributesFromState(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, lifecycleState)
+
+	_, err := e.Apply(context.Background(), "../../examples/lifecycle")
+	require.NoError(t, err)
+
+	sf := testLoadState(t)
+	r, err := sf.FindResource("resource.network.onprem")
+	require.NoError(t, err)
+
+	require.Equal(t, "10.15.0.0/16", r.(*network.Network).Subnet)
+	require.True(t, r.(*network.Network).Lifecycle.PreventDestroy)
+}
+
+func TestDiffWithRemovedProtectedResourceReturnsError(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, lifecycleRemovedState)
+
+	_, _, _, _, err := e.Diff("../../examples/lifecycle", nil, "")
+	require.ErrorContains(t, err, `resource "resource.network.old" has lifecycle.prevent_destroy set`)
+}
+
+func TestApplyWithRemovedProtectedResourceDoesNotDestroy(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, lifecycleRemovedState)
+
+	_, err := e.Apply(context.Background(), "../../examples/lifecycle")
+	require.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
+func TestApplyWithTaintedProtectedResourceReturnsError(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, lifecycleTaintedState)
+
+	err := e.SetTargets([]string{"resource.network.onprem"})
+	require.NoError(t, err)
+
+	_, err = e.Apply(context.Background(), "../../examples/lifecycle")
+	require.ErrorContains(t, err, "needs to be recreated")
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+
+	sf := testLoadState(t)
+	r, err := sf.FindResource("resource.network.onprem")
+	require.NoError(t, err)
+	require.Equal(t, "tainted", r.Metadata().Properties["status"])
+}
+
+func TestApplyWithTaintedCreateBeforeDestroyCreatesReplacementFirst(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, lifecycleTaintedState)
+
+	// the protected network is also tainted, only recreate the cloud password
+	err := e.SetTargets([]string{"resource.random_password.cloud"})
+	require.NoError(t, err)
+
+	_, err = e.Apply(context.Background(), "../../examples/lifecycle")
+	require.NoError(t, err)
+
+	// the image cache and the replacement password
+	testAssertMethodCalled(t, mp, "Create", 2)
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+
+	// the replacement is created with a different provider to the one
+	// that destroys the existing password
+	for _, p := range mp.Providers {
+		created, destroyed := false, false
+		for _, c := range p.Calls {
+			created = created || c.Method == "Create"
+			destroyed = destroyed || c.Method == "Destroy"
+		}
+
+		require.False(t, created && destroyed)
+	}
+}
+
+func TestParseConfigWithCreateBeforeDestroyContainerReturnsError(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, `
+resource "container" "app" {
+  image {
+    name = "ghcr.io/jumppad-labs/connector:v0.4.0"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`)
+
+	_, err := e.ParseConfig(dir)
+	require.ErrorContains(t, err, "can not set lifecycle.create_before_destroy")
+}
+
+func TestApplyWithCreateBeforeDestroyContainerDoesNotCreateReplacement(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, `
+{
+  "resources": [
+  {
+      "meta": {
+        "id": "resource.container.app",
+        "name": "app",
+        "properties": {
+          "status": "tainted"
+        },
+        "type": "container"
+      },
+      "image": {
+        "name": "ghcr.io/jumppad-labs/connector:v0.4.0"
+      }
+  }
+  ]
+}
+`)
+	dir := config.CreateTestFiles(t, `
+resource "container" "app" {
+  image {
+    name = "ghcr.io/jumppad-labs/connector:v0.4.0"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`)
+
+	_, err := e.Apply(context.Background(), dir)
+	require.ErrorContains(t, err, "lifecycle.create_before_destroy")
+
+	// the existing container is not replaced
+	testAssertMethodCalled(t, mp, "Create", 0)
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
+func TestDestroyWithProtectedResourceReturnsError(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, lifecycleRemovedState)
+
+	err := e.Destroy(context.Background(), false)
+	require.ErrorContains(t, err, "lifecycle.prevent_destroy")
+
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+}
+
+var lifecycleState = `
+{
+  "resources": [
+  {
+      "meta": {
+        "id": "resource.network.onprem",
+        "name": "onprem",
+        "properties": {
+          "status": "created"
+        },
+        "type": "network"
+      },
+      "subnet": "10.15.0.0/16"
+  }
+  ]
+}
+`
+
+var lifecycleRemovedState = `
+{
+  "resources": [
+  {
+      "meta": {
+        "id": "resource.network.old",
+        "name": "old",
+        "properties": {
+          "status": "created"
+        },
+        "type": "network"
+      },
+      "subnet": "10.15.0.0/16",
+      "lifecycle": {
+        "prevent_destroy": true
+      }
+  }
+  ]
+}
+`
+
+var lifecycleTaintedState = `
+{
+  "resources": [
+  {
+      "meta": {
+        "id": "resource.network.onprem",
+        "name": "onprem",
+        "properties": {
+          "status": "tainted"
+        },
+        "type": "network"
+      },
+      "subnet": "10.6.0.0/16"
+  },
+  {
+      "meta": {
+        "id": "resource.random_password.cloud",
+        "name": "cloud",
+        "properties": {
+          "status": "tainted"
+        },
+        "type": "random_password"
+      },
+      "length": 16
+  }
+  ]
+}
+`
@@ -4,9 +4,12 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	hclconfig "github.com/jumppad-labs/hclconfig"
 
+	jumppad "github.com/jumppad-labs/jumppad/pkg/jumppad"
+
 	mock "github.com/stretchr/testify/mock"
 
 	types "github.com/jumppad-labs/hclconfig/types"
@@ -204,6 +207,84 @@ func (_m *Engine) ParseConfigWithVariables(_a0 string, _a1 map[string]string, _a
 	return r0, r1
 }
 
+// Plan provides a mock function with given fields: path, variables, variablesFile
+func (_m *Engine) Plan(path string, variables map[string]string, variablesFile string) (*jumppad.Plan, error) {
+	ret := _m.Called(path, variables, variablesFile)
+
+	var r0 *jumppad.Plan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) (*jumppad.Plan, error)); ok {
+		return rf(path, variables, variablesFile)
+	}
+	if rf, ok := ret.Get(0).(func(string, map[string]string, string) *jumppad.Plan); ok {
+		r0 = rf(path, variables, variablesFile)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*jumppad.Plan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, map[string]string, string) error); ok {
+		r1 = rf(path, variables, variablesFile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetLockTimeout provides a mock function with given fields: d
+func (_m *Engine) SetLockTimeout(d time.Duration) {
+	_m.Called(d)
+}
+
+// SetParallelism provides a mock function with given fields: n
+func (_m *Engine) SetParallelism(n int) {
+	_m.Called(n)
+}
+
+// SetTargets provides a mock function with given fields: targets
+func (_m *Engine) SetTargets(targets []string) error {
+	ret := _m.Called(targets)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(targets)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields:
+func (_m *Engine) Subscribe() (<-chan jumppad.Event, func()) {
+	ret := _m.Called()
+
+	var r0 <-chan jumppad.Event
+	var r1 func()
+	if rf, ok := ret.Get(0).(func() (<-chan jumppad.Event, func())); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() <-chan jumppad.Event); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan jumppad.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() func()); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewEngine interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,298 @@
+package jumppad
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/jumppad-labs/hclconfig/resources"
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+)
+
+// PlanAction is the action that Apply will take for a resource
+type PlanAction string
+
+const (
+	// PlanActionCreate the resource does not exist and will be created
+	PlanActionCreate PlanAction = "create"
+	// PlanActionRecreate the resource is failed or tainted and will be destroyed then created
+	PlanActionRecreate PlanAction = "recreate"
+	// PlanActionRefresh the resource has changed and will be refreshed
+	PlanActionRefresh PlanAction = "refresh"
+	// PlanActionUpdate the resource has changed and will be updated in place
+	PlanActionUpdate PlanAction = "update"
+	// PlanActionDestroy the resource is no longer in the config and will be destroyed
+	PlanActionDestroy PlanAction = "destroy"
+	// PlanActionDisable the resource has been disabled and will be destroyed
+	PlanActionDisable PlanAction = "disable"
+	// PlanActionNoChange the config for the resource has changed but the
+	// provider reports that the resource does not need to be updated
+	PlanActionNoChange PlanAction = "no-change"
+)
+
+// AttributeChange is a difference in a single attribute between the state
+// and the parsed config
+type AttributeChange struct {
+	// Path is the location of the attribute e.g. image.name or ports[0].local
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// ResourceChange is the action that will be taken for a resource
+type ResourceChange struct {
+	ID         string            `json:"id"`
+	Action     PlanAction        `json:"action"`
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+// Plan is the set of changes that Apply would make
+type Plan struct {
+	Changes []ResourceChange `json:"changes"`
+}
+
+// HasChanges returns true when applying the plan would modify resources
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > p.Count(PlanActionNoChange)
+}
+
+// Count returns the number of changes for the given action
+func (p *Plan) Count(a PlanAction) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == a {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Plan returns the changes that would be made by applying the config at
+// the given path, no resources or state are modified
+func (e *EngineImpl) Plan(path string, variables map[string]string, variablesFile string) (*Plan, error) {
+	new, changed, removed, cfg, err := e.Diff(path, variables, variablesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the state used by Diff to compare attributes
+	past, err := e.loadStateForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Changes: []ResourceChange{}}
+
+	for _, r := range new {
+		if !planned(r) || r.GetDisabled() {
+			continue
+		}
+
+		plan.Changes = append(plan.Changes, ResourceChange{ID: r.Metadata().ID, Action: PlanActionCreate})
+	}
+
+	// resources in the state are processed in the same way as createCallback
+	// and destroyDisabledResources
+	isChanged := map[string]types.Resource{}
+	for _, r := range changed {
+		isChanged[r.Metadata().ID] = r
+	}
+
+	if past != nil {
+		for _, sr := range past.Resources {
+			if !planned(sr) {
+				continue
+			}
+
+			id := sr.Metadata().ID
+			status := sr.Metadata().Properties[constants.PropertyStatus]
+
+			cr, hasChanged := isChanged[id]
+			if !hasChanged {
+				continue
+			}
+
+			switch {
+			case cr.GetDisabled():
+				if status == constants.StatusCreated {
+					plan.Changes = append(plan.Changes, ResourceChange{ID: id, Action: PlanActionDisable})
+				}
+			case status == constants.StatusFailed || status == constants.StatusTainted:
+				plan.Changes = append(plan.Changes, ResourceChange{ID: id, Action: PlanActionRecreate})
+			case status == constants.StatusCreated:
+				attrs, err := changedAttributes(sr, cr)
+				if err != nil {
+					return nil, fmt.Errorf(`unable to compare resource "%s", %s`, id, err)
+				}
+
+				action, err := e.planRefreshOrUpdate(cr)
+				if err != nil {
+					return nil, err
+				}
+
+				plan.Changes = append(plan.Changes, ResourceChange{ID: id, Action: action, Attributes: attrs})
+			default:
+				// pending or previously disabled resources are created
+				plan.Changes = append(plan.Changes, ResourceChange{ID: id, Action: PlanActionCreate})
+			}
+		}
+
+		// failed and tainted resources are recreated even when the config
+		// has not changed
+		for _, sr := range past.Resources {
+			if !planned(sr) || sr.GetDisabled() {
+				continue
+			}
+
+			status := sr.Metadata().Properties[constants.PropertyStatus]
+			if status != constants.StatusFailed && status != constants.StatusTainted {
+				continue
+			}
+
+			if _, ok := isChanged[sr.Metadata().ID]; ok || containsResource(removed, sr) {
+				continue
+			}
+
+			plan.Changes = append(plan.Changes, ResourceChange{ID: sr.Metadata().ID, Action: PlanActionRecreate})
+		}
+	}
+
+	for _, r := range removed {
+		if !planned(r) {
+			continue
+		}
+
+		plan.Changes = append(plan.Changes, ResourceChange{ID: r.Metadata().ID, Action: PlanActionDestroy})
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].ID < plan.Changes[j].ID
+	})
+
+	return plan, nil
+}
+
+// planRefreshOrUpdate returns the action that refreshOrUpdate will take for
+// a changed resource, providers that support updates are only updated when
+// they report changes
+func (e *EngineImpl) planRefreshOrUpdate(r types.Resource) (PlanAction, error) {
+	p := e.providers.GetProvider(r)
+	if _, ok := p.(config.Updater); !ok {
+		return PlanActionRefresh, nil
+	}
+
+	changed, err := p.Changed()
+	if err != nil {
+		return "", fmt.Errorf(`unable to check changes for resource "%s", %s`, r.Metadata().ID, err)
+	}
+
+	if !changed {
+		return PlanActionNoChange, nil
+	}
+
+	return PlanActionUpdate, nil
+}
+
+// planned returns false for types that do not create anything
+func planned(r types.Resource) bool {
+	switch r.Metadata().Type {
+	case resources.TypeModule, resources.TypeVariable, resources.TypeOutput:
+		return false
+	}
+
+	return true
+}
+
+func containsResource(list []types.Resource, r types.Resource) bool {
+	for _, l := range list {
+		if l.Metadata().ID == r.Metadata().ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diffAttributes compares the JSON representation of two resources ignoring
+// the metadata which is managed by jumppad
+func diffAttributes(old, new types.Resource) ([]AttributeChange, error) {
+	om, err := resourceToMap(old)
+	if err != nil {
+		return nil, err
+	}
+
+	nm, err := resourceToMap(new)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(om, "meta")
+	delete(nm, "meta")
+
+	of := map[string]interface{}{}
+	flatten("", om, of)
+
+	nf := map[string]interface{}{}
+	flatten("", nm, nf)
+
+	keys := map[string]bool{}
+	for k := range of {
+		keys[k] = true
+	}
+
+	for k := range nf {
+		keys[k] = true
+	}
+
+	changes := []AttributeChange{}
+	for k := range keys {
+		if !reflect.DeepEqual(of[k], nf[k]) {
+			changes = append(changes, AttributeChange{Path: k, Old: of[k], New: nf[k]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+func resourceToMap(r types.Resource) (map[string]interface{}, error) {
+	d, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	err = json.Unmarshal(d, &m)
+
+	return m, err
+}
+
+// flatten converts nested maps and slices into a single map keyed by path
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+
+			flatten(p, val, out)
+		}
+	case []interface{}:
+		for i, val := range t {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), val, out)
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = t
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package jumppad
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/jumppad-labs/jumppad/testutils"
+	sdk "github.com/jumppad-labs/plugin-sdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanWithNoStateReturnsCreate(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	p, err := e.Plan("../../examples/single_file/container.hcl", nil, "")
+	require.NoError(t, err)
+
+	// variables and outputs are not included
+	require.True(t, p.HasChanges())
+	require.Equal(t, 3, p.Count(PlanActionCreate))
+
+	// plan should never create resources
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestPlanWithFailedStateReturnsRecreate(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, failedState)
+
+	p, err := e.Plan("../../examples/single_file/container.hcl", nil, "")
+	require.NoError(t, err)
+
+	require.Equal(t, 1, p.Count(PlanActionRecreate))
+	require.Equal(t, 2, p.Count(PlanActionCreate))
+}
+
+func TestPlanWithExistingStateReturnsRefreshAndDestroy(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, existingState)
+
+	p, err := e.Plan("../../examples/single_file", nil, "")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, p.Count(PlanActionCreate))
+	require.Equal(t, 1, p.Count(PlanActionRefresh))
+	require.Equal(t, 2, p.Count(PlanActionDestroy))
+
+	// plan should never destroy resources
+	testAssertMethodCalled(t, mp, "Destroy", 0)
+
+	// the state should not be modified
+	sf := testLoadState(t)
+	require.Equal(t, 4, sf.ResourceCount())
+}
+
+// updatableProviders returns providers that support in place updates and
+// report the given result from Changed
+type updatableProviders struct {
+	*mocks.Providers
+	changed bool
+}
+
+func (p *updatableProviders) GetProvider(c types.Resource) sdk.Provider {
+	m := p.Providers.GetProvider(c).(*mocks.Provider)
+	testutils.RemoveOn(&m.Mock, "Changed")
+	m.On("Changed").Return(p.changed, nil)
+
+	return &updatableProvider{m}
+}
+
+func TestPlanWithUpdaterReturnsUpdateWhenChanged(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, existingState)
+	e.providers = &updatableProviders{mp, true}
+
+	p, err := e.Plan("../../examples/single_file", nil, "")
+	require.NoError(t, err)
+
+	require.Equal(t, 1, p.Count(PlanActionUpdate))
+	require.Equal(t, 0, p.Count(PlanActionNoChange))
+}
+
+func TestPlanWithUpdaterReturnsNoChangeWhenNotChanged(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, existingState)
+	e.providers = &updatableProviders{mp, false}
+
+	p, err := e.Plan("../../examples/single_file", nil, "")
+	require.NoError(t, err)
+
+	require.Equal(t, 0, p.Count(PlanActionUpdate))
+	require.Equal(t, 1, p.Count(PlanActionNoChange))
+}
+
+func TestPlanHasChangesIgnoresUnchangedResources(t *testing.T) {
+	p := &Plan{Changes: []ResourceChange{{ID: "resource.helm.consul", Action: PlanActionNoChange}}}
+	require.False(t, p.HasChanges())
+
+	p.Changes = append(p.Changes, ResourceChange{ID: "resource.network.old", Action: PlanActionDestroy})
+	require.True(t, p.HasChanges())
+}
+
+func TestPlanWithStateBackendDoesNotModifyState(t *testing.T) {
+	e, _ := setupTestsWithState(t, nil, existingState)
+
+	before, err := os.ReadFile(utils.StatePath())
+	require.NoError(t, err)
+
+	sp := filepath.Join(t.TempDir(), "state.json")
+	dir := config.CreateTestFiles(t, fmt.Sprintf(`
+resource "state_backend" "shared" {
+  local {
+    path = "%s"
+  }
+}
+`, filepath.ToSlash(sp)))
+
+	p, err := e.Plan(dir, nil, "")
+	require.NoError(t, err)
+
+	// the resources in the existing state, apart from the image cache, are
+	// compared with the config
+	require.Equal(t, 3, p.Count(PlanActionDestroy))
+
+	after, err := os.ReadFile(utils.StatePath())
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	require.NoFileExists(t, sp)
+	require.NoFileExists(t, utils.StateBackendPath())
+}
+
+func TestDiffAttributesReturnsChangedPaths(t *testing.T) {
+	old := &container.Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "old"}}},
+		Image:           container.Image{Name: "consul:1.6.1"},
+		Command:         []string{"consul", "agent"},
+	}
+
+	new := &container.Container{
+		ResourceOptions: config.ResourceOptions{ResourceBase: types.ResourceBase{Meta: types.Meta{Name: "new"}}},
+		Image:           container.Image{Name: "consul:1.7.0"},
+		Command:         []string{"consul", "agent", "-dev"},
+	}
+
+	c, err := diffAttributes(old, new)
+	require.NoError(t, err)
+
+	// meta is ignored
+	require.Len(t, c, 2)
+	require.Equal(t, "command[2]", c[0].Path)
+	require.Nil(t, c[0].Old)
+	require.Equal(t, "-dev", c[0].New)
+	require.Equal(t, "image.name", c[1].Path)
+	require.Equal(t, "consul:1.6.1", c[1].Old)
+	require.Equal(t, "consul:1.7.0", c[1].New)
+}
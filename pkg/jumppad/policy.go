@@ -0,0 +1,185 @@
+package jumppad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jumppad-labs/hclconfig"
+	hclerrors "github.com/jumppad-labs/hclconfig/errors"
+	"github.com/jumppad-labs/hclconfig/types"
+	ctasks "github.com/jumppad-labs/jumppad/pkg/clients/container"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/build"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/k8s"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/nomad"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/policy"
+)
+
+// policyImage is an image used by a resource and the user the container
+// created from the image runs as
+type policyImage struct {
+	name string
+	user string
+	// node images are used for cluster nodes which must run as root
+	node bool
+	// base images are pulled by a build, only the reference is checked
+	base bool
+}
+
+// CheckImagePolicies checks the images used by the resources in the config
+// that are present in the local registry against the image policies in the
+// config. Images that have not been pulled are checked when the configuration
+// is applied.
+func CheckImagePolicies(ct ctasks.ContainerTasks, c *hclconfig.Config) error {
+	policies := policy.Find(c)
+	if len(policies) == 0 {
+		return nil
+	}
+
+	ctx := policy.WithPolicies(context.Background(), policies)
+	ce := hclerrors.NewConfigError()
+
+	for _, r := range c.Resources {
+		if r.GetDisabled() {
+			continue
+		}
+
+		for _, i := range policyImages(r) {
+			// the references of the base images have been checked when the
+			// config was parsed
+			if i.base {
+				continue
+			}
+
+			id, err := ct.FindImageInLocalRegistry(ctypes.Image{Name: i.name})
+			if err != nil || id == "" {
+				continue
+			}
+
+			if i.node {
+				err = policy.EnforceNode(ctx, ct, i.name)
+			} else {
+				err = policy.Enforce(ctx, ct, i.name, i.user)
+			}
+
+			ve := &policy.ViolationError{}
+			if errors.As(err, &ve) {
+				ce.AppendError(policyError(r, ve))
+			}
+		}
+	}
+
+	if len(ce.Errors) > 0 {
+		return ce
+	}
+
+	return nil
+}
+
+// checkImageReferences checks the image references for the resources in the
+// config against the image policies, the images do not need to be pulled
+func checkImageReferences(c *hclconfig.Config) error {
+	policies := policy.Find(c)
+	if len(policies) == 0 {
+		return nil
+	}
+
+	ce := hclerrors.NewConfigError()
+
+	for _, r := range c.Resources {
+		if r.GetDisabled() {
+			continue
+		}
+
+		for _, i := range policyImages(r) {
+			ve := &policy.ViolationError{Image: i.name}
+			for _, p := range policies {
+				ve.Violations = append(ve.Violations, p.CheckReference(i.name)...)
+			}
+
+			if len(ve.Violations) > 0 {
+				ce.AppendError(policyError(r, ve))
+			}
+		}
+	}
+
+	if len(ce.Errors) > 0 {
+		return ce
+	}
+
+	return nil
+}
+
+// policyImages returns the images that the image policies apply to for the
+// resource, images that reference other resources are empty until the
+// resource has been created and are ignored
+func policyImages(r types.Resource) []policyImage {
+	images := []policyImage{}
+
+	var inits container.InitContainers
+
+	switch v := r.(type) {
+	case *container.Container:
+		i := policyImage{name: v.Image.Name}
+		if v.RunAs != nil {
+			i.user = v.RunAs.User
+		}
+
+		images = append(images, i)
+		inits = v.InitContainers
+	case *container.Sidecar:
+		images = append(images, policyImage{name: v.Image.Name})
+		inits = v.InitContainers
+	case *k8s.Cluster:
+		if v.Image != nil {
+			images = append(images, policyImage{name: v.Image.Name, node: true})
+		}
+
+		for _, ci := range v.CopyImages {
+			images = append(images, policyImage{name: ci.Name})
+		}
+	case *nomad.NomadCluster:
+		if v.Image != nil {
+			images = append(images, policyImage{name: v.Image.Name, node: true})
+		}
+
+		for _, ci := range v.CopyImages {
+			images = append(images, policyImage{name: ci.Name})
+		}
+	case *build.Build:
+		// a Dockerfile that can not be read fails when the image is built
+		bi, _ := v.BaseImages()
+		for _, b := range bi {
+			images = append(images, policyImage{name: b, base: true})
+		}
+	}
+
+	for _, ic := range inits {
+		i := policyImage{name: ic.Image.Name}
+		if ic.RunAs != nil {
+			i.user = ic.RunAs.User
+		}
+
+		images = append(images, i)
+	}
+
+	filtered := []policyImage{}
+	for _, i := range images {
+		if i.name != "" {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
+
+func policyError(r types.Resource, ve *policy.ViolationError) error {
+	return &hclerrors.ParserError{
+		Filename: r.Metadata().File,
+		Line:     r.Metadata().Line,
+		Message:  fmt.Sprintf("%s: %s", r.Metadata().ID, ve.Error()),
+		Level:    hclerrors.ParserErrorLevelError,
+	}
+}
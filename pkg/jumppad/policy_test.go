@@ -0,0 +1,189 @@
+package jumppad
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var policyConfig = `
+resource "image_policy" "strict" {
+  allowed_registries = ["ghcr.io/jumppad-labs"]
+  forbid_latest      = true
+  forbid_root_user   = true
+}
+
+resource "container" "allowed" {
+  image {
+    name = "ghcr.io/jumppad-labs/connector:v0.4.0"
+  }
+}
+
+resource "container" "latest" {
+  image {
+    name = "nginx:latest"
+  }
+}
+`
+
+func TestParseConfigReturnsErrorWhenImageViolatesPolicy(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, policyConfig)
+
+	_, err := e.ParseConfig(dir)
+	require.ErrorContains(t, err, "resource.container.latest")
+	require.NotContains(t, err.Error(), "resource.container.allowed")
+}
+
+func TestParseConfigIgnoresDisabledResourcesForPolicy(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, `
+resource "image_policy" "strict" {
+  forbid_latest = true
+}
+
+resource "container" "latest" {
+  disabled = true
+
+  image {
+    name = "nginx:latest"
+  }
+}
+`)
+
+	_, err := e.ParseConfig(dir)
+	require.NoError(t, err)
+}
+
+func TestApplyWhenImageViolatesPolicyDoesNotCreateResources(t *testing.T) {
+	e, mp := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, policyConfig)
+
+	_, err := e.Apply(context.Background(), dir)
+	require.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestCheckImagePoliciesChecksLocalImages(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, `
+resource "image_policy" "strict" {
+  forbid_root_user = true
+}
+
+resource "container" "root" {
+  image {
+    name = "nginx:1.27"
+  }
+}
+
+resource "container" "user" {
+  image {
+    name = "nginx:1.27"
+  }
+
+  run_as {
+    user  = "1000"
+    group = "1000"
+  }
+}
+
+resource "container" "missing" {
+  image {
+    name = "consul:1.16"
+  }
+}
+`)
+
+	cfg, err := e.ParseConfig(dir)
+	require.NoError(t, err)
+
+	ct := &mocks.ContainerTasks{}
+	ct.On("FindImageInLocalRegistry", ctypes.Image{Name: "nginx:1.27"}).Return("abc", nil)
+	ct.On("FindImageInLocalRegistry", mock.Anything).Return("", nil)
+	ct.On("InspectImage", "nginx:1.27").Return(&ctypes.ImageInfo{Created: time.Now()}, nil)
+
+	err = CheckImagePolicies(ct, cfg)
+	require.ErrorContains(t, err, "resource.container.root")
+	require.NotContains(t, err.Error(), "resource.container.user")
+
+	// images that have not been pulled are checked when applied
+	ct.AssertNotCalled(t, "InspectImage", "consul:1.16")
+}
+
+func TestParseConfigChecksClusterAndBuildImages(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, `
+resource "image_policy" "strict" {
+  allowed_registries = ["ghcr.io/jumppad-labs"]
+}
+
+resource "k8s_cluster" "k3s" {
+  copy_image {
+    name = "nginx:1.27"
+  }
+}
+
+resource "nomad_cluster" "dev" {
+  image {
+    name = "docker.io/hashicorp/nomad:1.8"
+  }
+}
+
+resource "build" "app" {
+  container {
+    context = "./"
+  }
+}
+`)
+
+	err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine:3.19 AS base\nFROM base\n"), 0644)
+	require.NoError(t, err)
+
+	_, err = e.ParseConfig(dir)
+	require.ErrorContains(t, err, "image nginx:1.27 violates")
+	require.ErrorContains(t, err, "image docker.io/hashicorp/nomad:1.8 violates")
+	require.ErrorContains(t, err, "image alpine:3.19 violates")
+
+	// the default image for the k3s cluster is allowed
+	require.NotContains(t, err.Error(), "image ghcr.io/jumppad-labs/kubernetes")
+}
+
+func TestCheckImagePoliciesDoesNotCheckUserForClusterNodes(t *testing.T) {
+	e, _ := setupTests(t, nil)
+	dir := config.CreateTestFiles(t, `
+resource "image_policy" "strict" {
+  forbid_root_user = true
+}
+
+resource "nomad_cluster" "dev" {
+  image {
+    name = "ghcr.io/jumppad-labs/nomad:v1.8.4"
+  }
+
+  copy_image {
+    name = "nginx:1.27"
+  }
+}
+`)
+
+	cfg, err := e.ParseConfig(dir)
+	require.NoError(t, err)
+
+	ct := &mocks.ContainerTasks{}
+	ct.On("FindImageInLocalRegistry", mock.Anything).Return("abc", nil)
+	ct.On("InspectImage", mock.Anything).Return(&ctypes.ImageInfo{Created: time.Now()}, nil)
+
+	err = CheckImagePolicies(ct, cfg)
+	require.ErrorContains(t, err, "image nginx:1.27 violates")
+	require.NotContains(t, err.Error(), "image ghcr.io/jumppad-labs/nomad:v1.8.4 violates")
+}
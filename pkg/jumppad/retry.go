@@ -0,0 +1,70 @@
+package jumppad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+	sdk "github.com/jumppad-labs/plugin-sdk"
+)
+
+// createWithRetry creates the resource using withRetry, a failed create can
+// leave a partially created resource such as a running container with a
+// failing health check, this is destroyed before the create is retried
+func (e *EngineImpl) createWithRetry(r types.Resource, p sdk.Provider) error {
+	attempt := 0
+
+	return e.withRetry(r, "create", func() error {
+		attempt++
+
+		if attempt > 1 {
+			err := p.Destroy(e.ctx, false)
+			if err != nil {
+				e.log.Debug("Unable to destroy resource before retrying create", "ref", r.Metadata().ID, "error", err)
+			}
+		}
+
+		return p.Create(e.ctx)
+	})
+}
+
+// withRetry calls the given provider operation, when the resource defines a
+// retry block failed operations are retried with an exponential backoff.
+// The number of attempts made is recorded in the resource metadata.
+func (e *EngineImpl) withRetry(r types.Resource, operation string, f func() error) error {
+	attempts, backoff, maxBackoff, err := config.RetryFromResource(r).Policy()
+	if err != nil {
+		return fmt.Errorf(`invalid retry block for resource "%s", %s`, r.Metadata().ID, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		r.Metadata().Properties[constants.PropertyAttempts] = attempt
+
+		err = f()
+		if err == nil || attempt >= attempts || e.ctx.Err() != nil {
+			return err
+		}
+
+		e.log.Warn(
+			fmt.Sprintf("Unable to %s resource, retrying", operation),
+			"ref", r.Metadata().ID,
+			"attempt", attempt,
+			"attempts", attempts,
+			"backoff", backoff,
+			"error", err,
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-e.ctx.Done():
+			return err
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
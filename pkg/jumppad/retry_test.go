@@ -0,0 +1,139 @@
+package jumppad
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jumppad-labs/hclconfig/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/mocks"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+	sdk "github.com/jumppad-labs/plugin-sdk"
+	"github.com/stretchr/testify/require"
+)
+
+// leakyResource simulates a resource such as a container that is left running
+// when a health check fails during create
+type leakyResource struct {
+	exists   bool
+	creates  int
+	destroys int
+}
+
+type leakyProvider struct {
+	*mocks.Provider
+	r *leakyResource
+}
+
+func (p *leakyProvider) Create(ctx context.Context) error {
+	p.r.creates++
+
+	if p.r.exists {
+		return fmt.Errorf("resource already exists")
+	}
+
+	p.r.exists = true
+
+	// the first attempt fails after the resource has been created
+	if p.r.creates == 1 {
+		return fmt.Errorf("health check failed")
+	}
+
+	return nil
+}
+
+func (p *leakyProvider) Destroy(ctx context.Context, force bool) error {
+	p.r.destroys++
+	p.r.exists = false
+
+	return nil
+}
+
+// leakyProviders returns a leakyProvider for the named resource and mocks for
+// all other resources
+type leakyProviders struct {
+	*mocks.Providers
+	name string
+	r    *leakyResource
+}
+
+func (p *leakyProviders) GetProvider(c types.Resource) sdk.Provider {
+	m := p.Providers.GetProvider(c)
+	if c.Metadata().Name != p.name {
+		return m
+	}
+
+	return &leakyProvider{m.(*mocks.Provider), p.r}
+}
+
+func TestApplyRetriesFailedCreate(t *testing.T) {
+	e, mp := setupTests(t, map[string]error{"flaky": fmt.Errorf("boom")})
+
+	_, err := e.Apply(context.Background(), "../../examples/retry")
+	require.Error(t, err)
+
+	// the image cache and the three attempts for the network
+	testAssertMethodCalled(t, mp, "Create", 4)
+
+	sf := testLoadState(t)
+	r, err := sf.FindResource("resource.network.flaky")
+	require.NoError(t, err)
+
+	require.Equal(t, constants.StatusFailed, r.Metadata().Properties[constants.PropertyStatus])
+	require.Equal(t, float64(3), r.Metadata().Properties[constants.PropertyAttempts])
+}
+
+func TestApplyWithoutRetryMakesSingleAttempt(t *testing.T) {
+	e, mp := setupTests(t, map[string]error{"onprem": fmt.Errorf("boom")})
+
+	_, err := e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.Error(t, err)
+
+	// the failed network is not retried, each resource is created once
+	testAssertMethodCalled(t, mp, "Create", 5)
+}
+
+func TestApplyDestroysFailedCreateBeforeRetry(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	lr := &leakyResource{}
+	e.providers = &leakyProviders{mp, "flaky", lr}
+
+	_, err := e.Apply(context.Background(), "../../examples/retry")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, lr.creates)
+	require.Equal(t, 1, lr.destroys)
+	require.True(t, lr.exists)
+
+	sf := testLoadState(t)
+	r, err := sf.FindResource("resource.network.flaky")
+	require.NoError(t, err)
+
+	require.Equal(t, constants.StatusCreated, r.Metadata().Properties[constants.PropertyStatus])
+	require.Equal(t, float64(2), r.Metadata().Properties[constants.PropertyAttempts])
+}
+
+func TestParseHelmWithRetryBlock(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	dir := config.CreateTestFiles(t, `
+resource "helm" "consul" {
+  cluster = "k3s"
+  chart   = "./chart"
+
+  retry {
+    attempts = 2
+  }
+}
+`)
+
+	c, err := e.ParseConfig(dir)
+	require.NoError(t, err)
+
+	r, err := c.FindResource("resource.helm.consul")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, config.RetryFromResource(r).Attempts)
+}
@@ -0,0 +1,368 @@
+package jumppad
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/jumppad-labs/hclconfig"
+	ctasks "github.com/jumppad-labs/jumppad/pkg/clients/container"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/config"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/container"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/k8s"
+	"github.com/jumppad-labs/jumppad/pkg/config/resources/nomad"
+	"github.com/jumppad-labs/jumppad/pkg/jumppad/constants"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+)
+
+// Snapshot records the containers and volumes for the resources in the state
+// at a point in time, restoring a snapshot recreates the containers from the
+// committed images and replaces the contents of the volumes
+type Snapshot struct {
+	Name       string                      `json:"name"`
+	Created    time.Time                   `json:"created"`
+	Containers []*ctypes.ContainerSnapshot `json:"containers"`
+	Volumes    []string                    `json:"volumes,omitempty"`
+}
+
+var snapshotNameRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// SaveSnapshot commits every container, sidecar and cluster node in the state
+// for the current workspace and exports the volumes they reference. The state
+// is saved with the snapshot so that it can be restored without applying the
+// configuration. An existing snapshot with the same name is replaced and the
+// images for the existing snapshot are removed.
+func SaveSnapshot(ct ctasks.ContainerTasks, name string) (*Snapshot, error) {
+	err := validateSnapshotName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := config.StateBackend()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	state, err := b.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read state file: %s", err)
+	}
+
+	cfg, err := config.LoadState()
+	if err != nil {
+		return nil, err
+	}
+
+	names := snapshotContainerNames(cfg)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no containers found in the state, snapshots can only be taken for running blueprints")
+	}
+
+	// write to a temporary folder so that a failed save does not remove an
+	// existing snapshot
+	dir := snapshotDir(name)
+	tmp := dir + ".tmp"
+	os.RemoveAll(tmp)
+
+	err = os.MkdirAll(filepath.Join(tmp, "volumes"), os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create snapshot folder: %s", err)
+	}
+
+	defer os.RemoveAll(tmp)
+
+	// the snapshot being replaced, if any
+	existing, _ := LoadSnapshot(name)
+
+	s := &Snapshot{Name: name, Created: time.Now()}
+	volumes := map[string]bool{}
+
+	for _, n := range names {
+		ids, err := ct.FindContainerIDs(n)
+		if err != nil || len(ids) == 0 {
+			return nil, fmt.Errorf("unable to find container %s, please ensure all resources are running before taking a snapshot", n)
+		}
+
+		// images are unique to each save so that the images for an existing
+		// snapshot are kept until the new snapshot has been saved
+		cs, err := ct.SaveContainer(ids[0], fmt.Sprintf("jumppad-snapshot/%s/%d:%s", name, s.Created.UnixNano(), n))
+		if err != nil {
+			return nil, err
+		}
+
+		s.Containers = append(s.Containers, cs)
+
+		for _, v := range cs.Volumes {
+			if !volumes[v] {
+				volumes[v] = true
+				s.Volumes = append(s.Volumes, v)
+			}
+		}
+	}
+
+	for _, v := range s.Volumes {
+		err := exportSnapshotVolume(ct, v, filepath.Join(tmp, "volumes", v+".tar"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(tmp, "state.json"), state, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to write snapshot state: %s", err)
+	}
+
+	d, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize snapshot: %s", err)
+	}
+
+	err = os.WriteFile(filepath.Join(tmp, "snapshot.json"), d, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to write snapshot: %s", err)
+	}
+
+	os.RemoveAll(dir)
+
+	err = os.Rename(tmp, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to save snapshot: %s", err)
+	}
+
+	if existing != nil {
+		err = removeSnapshotImages(ct, existing)
+		if err != nil {
+			return s, fmt.Errorf("snapshot saved but unable to remove the images for the replaced snapshot: %s", err)
+		}
+	}
+
+	return s, nil
+}
+
+// DeleteSnapshot removes the snapshot with the given name and the images for
+// the containers in the snapshot
+func DeleteSnapshot(ct ctasks.ContainerTasks, name string) error {
+	s, err := LoadSnapshot(name)
+	if err != nil {
+		return err
+	}
+
+	err = removeSnapshotImages(ct, s)
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(snapshotDir(name))
+	if err != nil {
+		return fmt.Errorf("unable to remove snapshot %s: %s", name, err)
+	}
+
+	return nil
+}
+
+// RestoreSnapshot recreates the containers and volumes from the snapshot with
+// the given name and replaces the state with the state at the time the
+// snapshot was taken. Resources created after the snapshot are not removed.
+func RestoreSnapshot(ct ctasks.ContainerTasks, name string) (*Snapshot, error) {
+	s, err := LoadSnapshot(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := snapshotDir(name)
+
+	state, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read snapshot state: %s", err)
+	}
+
+	// volumes can not be replaced while they are used so remove the
+	// containers first, sidecars are last in the snapshot and are removed
+	// before the containers they are attached to
+	for i := len(s.Containers) - 1; i >= 0; i-- {
+		ids, err := ct.FindContainerIDs(s.Containers[i].Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			err := ct.RemoveContainer(id, true)
+			if err != nil {
+				return nil, fmt.Errorf("unable to remove container %s: %s", s.Containers[i].Name, err)
+			}
+		}
+	}
+
+	for _, v := range s.Volumes {
+		err := importSnapshotVolume(ct, v, filepath.Join(dir, "volumes", v+".tar"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range s.Containers {
+		_, err := ct.RestoreContainer(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := config.StateBackend()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create state backend: %s", err)
+	}
+
+	err = b.Write(state)
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore state: %s", err)
+	}
+
+	return s, nil
+}
+
+// LoadSnapshot returns the snapshot with the given name for the current
+// workspace
+func LoadSnapshot(name string) (*Snapshot, error) {
+	err := validateSnapshotName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := os.ReadFile(filepath.Join(snapshotDir(name), "snapshot.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot %s does not exist", name)
+		}
+
+		return nil, fmt.Errorf("unable to read snapshot %s: %s", name, err)
+	}
+
+	s := &Snapshot{}
+	err = json.Unmarshal(d, s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read snapshot %s: %s", name, err)
+	}
+
+	return s, nil
+}
+
+// ListSnapshots returns the snapshots for the current workspace ordered by the
+// time they were created
+func ListSnapshots() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(utils.SnapshotsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("unable to read snapshots: %s", err)
+	}
+
+	snapshots := []*Snapshot{}
+	for _, e := range entries {
+		if !e.IsDir() || validateSnapshotName(e.Name()) != nil {
+			continue
+		}
+
+		s, err := LoadSnapshot(e.Name())
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, s)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.Before(snapshots[j].Created)
+	})
+
+	return snapshots, nil
+}
+
+// snapshotContainerNames returns the names of the containers for the created
+// resources in the state, sidecars share the network of another container so
+// are returned last to ensure that the container exists when restoring
+func snapshotContainerNames(cfg *hclconfig.Config) []string {
+	names := []string{}
+	sidecars := []string{}
+
+	for _, r := range cfg.Resources {
+		if r.GetDisabled() || r.Metadata().Properties[constants.PropertyStatus] != constants.StatusCreated {
+			continue
+		}
+
+		switch v := r.(type) {
+		case *container.Container:
+			names = append(names, v.ContainerName)
+		case *container.Sidecar:
+			sidecars = append(sidecars, v.ContainerName)
+		case *k8s.Cluster:
+			names = append(names, v.ContainerName)
+			names = append(names, v.AgentContainerNames...)
+		case *nomad.NomadCluster:
+			names = append(names, v.ServerContainerName)
+			names = append(names, v.ClientContainerName...)
+		}
+	}
+
+	return append(names, sidecars...)
+}
+
+// removeSnapshotImages removes the images for the containers in the snapshot,
+// images that have already been removed are ignored
+func removeSnapshotImages(ct ctasks.ContainerTasks, s *Snapshot) error {
+	for _, c := range s.Containers {
+		id, err := ct.FindImageInLocalRegistry(ctypes.Image{Name: c.Image})
+		if err != nil {
+			return err
+		}
+
+		if id == "" {
+			continue
+		}
+
+		err = ct.RemoveImage(c.Image)
+		if err != nil {
+			return fmt.Errorf("unable to remove image %s: %s", c.Image, err)
+		}
+	}
+
+	return nil
+}
+
+func exportSnapshotVolume(ct ctasks.ContainerTasks, name, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create archive for volume %s: %s", name, err)
+	}
+	defer f.Close()
+
+	return ct.ExportVolume(name, f)
+}
+
+func importSnapshotVolume(ct ctasks.ContainerTasks, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open archive for volume %s: %s", name, err)
+	}
+	defer f.Close()
+
+	return ct.ImportVolume(name, f)
+}
+
+func validateSnapshotName(name string) error {
+	if !snapshotNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %s, names must contain lowercase letters, numbers, '.', '_' or '-'", name)
+	}
+
+	return nil
+}
+
+func snapshotDir(name string) string {
+	return filepath.Join(utils.SnapshotsDir(), name)
+}
@@ -0,0 +1,242 @@
+package jumppad
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jumppad-labs/jumppad/pkg/clients/container/mocks"
+	ctypes "github.com/jumppad-labs/jumppad/pkg/clients/container/types"
+	"github.com/jumppad-labs/jumppad/pkg/utils"
+	"github.com/jumppad-labs/jumppad/testutils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSnapshotTests(t *testing.T) *mocks.ContainerTasks {
+	testutils.SetupState(t, snapshotState)
+
+	ct := &mocks.ContainerTasks{}
+	ct.On("FindContainerIDs", mock.Anything).Return(func(name string) []string { return []string{name} }, nil)
+	ct.On("SaveContainer", mock.Anything, mock.Anything).Return(func(id, image string) *ctypes.ContainerSnapshot {
+		s := &ctypes.ContainerSnapshot{Name: id, Image: image, Config: []byte(`{}`)}
+		if id == "server.k3s.k8s-cluster.local.jmpd.in" {
+			s.Volumes = []string{"images.volume.jmpd.in"}
+		}
+
+		return s
+	}, nil)
+	ct.On("ExportVolume", mock.Anything, mock.Anything).Return(func(name string, w io.Writer) error {
+		_, err := w.Write([]byte("archive"))
+		return err
+	})
+	ct.On("ImportVolume", mock.Anything, mock.Anything).Return(nil)
+	ct.On("RemoveContainer", mock.Anything, mock.Anything).Return(nil)
+	ct.On("RestoreContainer", mock.Anything).Return("id", nil)
+	ct.On("FindImageInLocalRegistry", mock.Anything).Return("sha256:abc", nil)
+	ct.On("RemoveImage", mock.Anything).Return(nil)
+
+	return ct
+}
+
+func TestSaveSnapshotCommitsContainersWithSidecarsLast(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	s, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, c := range s.Containers {
+		names = append(names, c.Name)
+	}
+
+	require.Equal(t, []string{"consul.container.local.jmpd.in", "server.k3s.k8s-cluster.local.jmpd.in", "envoy.sidecar.local.jmpd.in"}, names)
+	require.Equal(t, fmt.Sprintf("jumppad-snapshot/exercise-2/%d:consul.container.local.jmpd.in", s.Created.UnixNano()), s.Containers[0].Image)
+	require.Equal(t, []string{"images.volume.jmpd.in"}, s.Volumes)
+
+	dir := filepath.Join(utils.SnapshotsDir(), "exercise-2")
+	require.FileExists(t, filepath.Join(dir, "snapshot.json"))
+	require.FileExists(t, filepath.Join(dir, "state.json"))
+	require.FileExists(t, filepath.Join(dir, "volumes", "images.volume.jmpd.in.tar"))
+
+	ct.AssertNotCalled(t, "RemoveImage", mock.Anything)
+}
+
+func TestSaveSnapshotRemovesImagesForReplacedSnapshot(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	old, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	s, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+	require.NotEqual(t, old.Containers[0].Image, s.Containers[0].Image)
+
+	removed := testutils.GetCalls(&ct.Mock, "RemoveImage")
+	require.Len(t, removed, 3)
+
+	for i, c := range old.Containers {
+		require.Equal(t, c.Image, removed[i].Arguments.String(0))
+	}
+}
+
+func TestDeleteSnapshotRemovesImagesAndSnapshot(t *testing.T) {
+	ct := setupSnapshotTests(t)
+	testutils.RemoveOn(&ct.Mock, "FindImageInLocalRegistry")
+
+	// the image for the first container has already been removed
+	ct.On("FindImageInLocalRegistry", mock.Anything).Return(func(i ctypes.Image) string {
+		if strings.HasSuffix(i.Name, ":consul.container.local.jmpd.in") {
+			return ""
+		}
+
+		return "sha256:abc"
+	}, nil)
+
+	s, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	err = DeleteSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	ct.AssertNumberOfCalls(t, "RemoveImage", 2)
+	ct.AssertNotCalled(t, "RemoveImage", s.Containers[0].Image)
+	require.NoDirExists(t, filepath.Join(utils.SnapshotsDir(), "exercise-2"))
+}
+
+func TestDeleteSnapshotReturnsErrorWhenImageNotRemoved(t *testing.T) {
+	ct := setupSnapshotTests(t)
+	testutils.RemoveOn(&ct.Mock, "RemoveImage")
+	ct.On("RemoveImage", mock.Anything).Return(fmt.Errorf("boom"))
+
+	_, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	err = DeleteSnapshot(ct, "exercise-2")
+	require.ErrorContains(t, err, "boom")
+
+	// the snapshot is kept so that the delete can be retried
+	require.DirExists(t, filepath.Join(utils.SnapshotsDir(), "exercise-2"))
+}
+
+func TestDeleteSnapshotReturnsErrorWhenNotFound(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	err := DeleteSnapshot(ct, "missing")
+	require.ErrorContains(t, err, "does not exist")
+}
+
+func TestSaveSnapshotWithInvalidNameReturnsError(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	_, err := SaveSnapshot(ct, "../exercise")
+	require.Error(t, err)
+
+	ct.AssertNotCalled(t, "SaveContainer", mock.Anything, mock.Anything)
+}
+
+func TestRestoreSnapshotRestoresContainersVolumesAndState(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	_, err := SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	// modify the state after the snapshot has been taken
+	err = os.WriteFile(utils.StatePath(), []byte(`{"resources":[]}`), 0644)
+	require.NoError(t, err)
+
+	s, err := RestoreSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	// sidecars must be removed before the containers they attach to
+	removed := testutils.GetCalls(&ct.Mock, "RemoveContainer")
+	require.Equal(t, "envoy.sidecar.local.jmpd.in", removed[0].Arguments.String(0))
+
+	ct.AssertCalled(t, "ImportVolume", "images.volume.jmpd.in", mock.Anything)
+
+	restored := testutils.GetCalls(&ct.Mock, "RestoreContainer")
+	require.Len(t, restored, 3)
+	require.Equal(t, s.Containers[2], restored[2].Arguments.Get(0))
+
+	d, err := os.ReadFile(utils.StatePath())
+	require.NoError(t, err)
+	require.JSONEq(t, snapshotState, string(d))
+}
+
+func TestRestoreSnapshotReturnsErrorWhenNotFound(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	_, err := RestoreSnapshot(ct, "missing")
+	require.ErrorContains(t, err, "does not exist")
+
+	ct.AssertNotCalled(t, "RemoveContainer", mock.Anything, mock.Anything)
+}
+
+func TestListSnapshotsReturnsSavedSnapshots(t *testing.T) {
+	ct := setupSnapshotTests(t)
+
+	_, err := SaveSnapshot(ct, "exercise-1")
+	require.NoError(t, err)
+
+	_, err = SaveSnapshot(ct, "exercise-2")
+	require.NoError(t, err)
+
+	s, err := ListSnapshots()
+	require.NoError(t, err)
+	require.Len(t, s, 2)
+	require.Equal(t, "exercise-1", s[0].Name)
+	require.Equal(t, "exercise-2", s[1].Name)
+}
+
+var snapshotState = `
+{
+  "resources": [
+  {
+      "meta": {
+        "name": "envoy",
+        "properties": {
+          "status": "created"
+        },
+        "type": "sidecar"
+      },
+      "container_name": "envoy.sidecar.local.jmpd.in"
+  },
+  {
+      "meta": {
+        "name": "consul",
+        "properties": {
+          "status": "created"
+        },
+        "type": "container"
+      },
+      "image": {
+        "name": "consul"
+      },
+      "container_name": "consul.container.local.jmpd.in"
+  },
+  {
+      "meta": {
+        "name": "k3s",
+        "properties": {
+          "status": "created"
+        },
+        "type": "k8s_cluster"
+      },
+      "container_name": "server.k3s.k8s-cluster.local.jmpd.in"
+  },
+  {
+      "meta": {
+        "name": "failed",
+        "properties": {
+          "status": "failed"
+        },
+        "type": "container"
+      },
+      "container_name": "failed.container.local.jmpd.in"
+  }
+  ]
+}
+`
@@ -0,0 +1,138 @@
+package jumppad
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jumppad-labs/hclconfig/resources"
+	"github.com/jumppad-labs/hclconfig/types"
+)
+
+// SetTargets limits Apply and Destroy to the given resources, targets are
+// fully qualified resource names e.g. resource.container.api or
+// module.consul.resource.container.server. An empty list removes the limit.
+func (e *EngineImpl) SetTargets(targets []string) error {
+	e.targets = nil
+
+	for _, t := range targets {
+		fqrn, err := resources.ParseFQRN(t)
+		if err != nil {
+			return fmt.Errorf(`invalid target "%s", %s`, t, err)
+		}
+
+		e.targets = append(e.targets, *fqrn)
+	}
+
+	return nil
+}
+
+// isTargeted returns true when the resource should be processed, when no
+// targets have been set all resources are processed
+func (e *EngineImpl) isTargeted(r types.Resource) bool {
+	if e.targeted == nil {
+		return true
+	}
+
+	return e.targeted[r.Metadata().ID]
+}
+
+// resolveTargets returns the ids of the resources matching the engine targets,
+// when dependencies is true the resources that the targets depend on are
+// added, otherwise the resources that depend on the targets are added.
+// Returns nil when no targets have been set.
+func (e *EngineImpl) resolveTargets(all []types.Resource, dependencies bool) (map[string]bool, error) {
+	if len(e.targets) == 0 {
+		return nil, nil
+	}
+
+	selected := map[string]bool{}
+
+	for _, t := range e.targets {
+		found := false
+		for _, r := range all {
+			if matchesTarget(t, r) {
+				selected[r.Metadata().ID] = true
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf(`target "%s" does not match any resource`, t.String())
+		}
+	}
+
+	// build a list of the resources each resource depends on
+	dependsOn := map[string][]string{}
+	for _, r := range all {
+		for _, d := range resourceDependencies(r) {
+			for _, dr := range all {
+				if matchesTarget(d, dr) {
+					dependsOn[r.Metadata().ID] = append(dependsOn[r.Metadata().ID], dr.Metadata().ID)
+				}
+			}
+		}
+	}
+
+	// walk the dependencies until no new resources are added
+	for changed := true; changed; {
+		changed = false
+
+		for id, deps := range dependsOn {
+			for _, d := range deps {
+				from, to := d, id
+				if dependencies {
+					from, to = id, d
+				}
+
+				if selected[from] && !selected[to] {
+					selected[to] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// resourceDependencies returns the explicit dependencies and references for
+// a resource, dependencies without a module are relative to the resource module
+func resourceDependencies(r types.Resource) []resources.FQRN {
+	deps := []resources.FQRN{}
+
+	refs := []string{}
+	refs = append(refs, r.GetDependencies()...)
+	refs = append(refs, r.Metadata().Links...)
+
+	for _, d := range refs {
+		fqrn, err := resources.ParseFQRN(d)
+		if err != nil {
+			continue
+		}
+
+		if fqrn.Module == "" {
+			fqrn.Module = r.Metadata().Module
+		}
+
+		deps = append(deps, *fqrn)
+	}
+
+	return deps
+}
+
+// matchesTarget returns true when the resource is the target, or for module
+// targets, when the resource is contained in the module
+func matchesTarget(t resources.FQRN, r types.Resource) bool {
+	m := r.Metadata()
+
+	if t.Type == resources.TypeModule {
+		module := t.Resource
+		if t.Module != "" {
+			module = t.Module + "." + t.Resource
+		}
+
+		return m.Module == module || strings.HasPrefix(m.Module, module+".")
+	}
+
+	return m.Module == t.Module && m.Type == t.Type && m.Name == t.Resource
+}
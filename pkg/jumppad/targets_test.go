@@ -0,0 +1,72 @@
+package jumppad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTargetsWithInvalidTargetReturnsError(t *testing.T) {
+	e, _ := setupTests(t, nil)
+
+	err := e.SetTargets([]string{"container"})
+	require.Error(t, err)
+}
+
+func TestApplyWithTargetOnlyCreatesTarget(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	err := e.SetTargets([]string{"resource.network.onprem"})
+	require.NoError(t, err)
+
+	_, err = e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.NoError(t, err)
+
+	// the network and the image cache which is always created
+	testAssertMethodCalled(t, mp, "Create", 2)
+}
+
+func TestApplyWithUnknownTargetReturnsError(t *testing.T) {
+	e, mp := setupTests(t, nil)
+
+	err := e.SetTargets([]string{"resource.container.missing"})
+	require.NoError(t, err)
+
+	_, err = e.Apply(context.Background(), "../../examples/single_file/container.hcl")
+	require.Error(t, err)
+
+	testAssertMethodCalled(t, mp, "Create", 0)
+}
+
+func TestDestroyWithTargetDestroysDependents(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, complexState)
+
+	err := e.SetTargets([]string{"resource.network.cloud"})
+	require.NoError(t, err)
+
+	err = e.Destroy(context.Background(), false)
+	require.NoError(t, err)
+
+	// the network, the image cache and the container depend on the network
+	testAssertMethodCalled(t, mp, "Destroy", 3)
+
+	// the template is not a dependent and should remain in the state
+	sf := testLoadState(t)
+	require.Equal(t, 1, sf.ResourceCount())
+}
+
+func TestDestroyWithTargetDoesNotDestroyDependencies(t *testing.T) {
+	e, mp := setupTestsWithState(t, nil, complexState)
+
+	err := e.SetTargets([]string{"resource.container.mycontainer"})
+	require.NoError(t, err)
+
+	err = e.Destroy(context.Background(), false)
+	require.NoError(t, err)
+
+	testAssertMethodCalled(t, mp, "Destroy", 1)
+
+	sf := testLoadState(t)
+	require.Equal(t, 3, sf.ResourceCount())
+}
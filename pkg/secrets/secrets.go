@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive values in output and logs
+const Redacted = "(sensitive)"
+
+var (
+	mutex     sync.RWMutex
+	sensitive = map[string]bool{}
+)
+
+// GenerateToken returns a random hex encoded token generated from the given
+// number of bytes, tokens are used to join nodes to a cluster
+func GenerateToken(size int) (string, error) {
+	b, err := randomBytes(size)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateKey returns a random base64 encoded key generated from the given
+// number of bytes, keys are used for encryption i.e. Nomad gossip
+func GenerateKey(size int) (string, error) {
+	b, err := randomBytes(size)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Register adds values that are replaced by Redact, empty values are ignored
+func Register(values ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, v := range values {
+		if v != "" {
+			sensitive[v] = true
+		}
+	}
+}
+
+// Redact replaces any registered values in the string
+func Redact(s string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	for v := range sensitive {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+
+	return s
+}
+
+// NewRedactWriter returns a writer that replaces any registered values in the
+// data before it is written to w
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w}
+}
+
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	mutex.RLock()
+	out := p
+	for v := range sensitive {
+		out = bytes.ReplaceAll(out, []byte(v), []byte(Redacted))
+	}
+	mutex.RUnlock()
+
+	_, err := r.w.Write(out)
+	if err != nil {
+		return 0, err
+	}
+
+	// the caller expects the length of the original data
+	return len(p), nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	b := make([]byte, size)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate random bytes: %w", err)
+	}
+
+	return b, nil
+}
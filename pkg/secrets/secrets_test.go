@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTokenReturnsUniqueValues(t *testing.T) {
+	t1, err := GenerateToken(16)
+	require.NoError(t, err)
+	require.Len(t, t1, 32)
+
+	t2, err := GenerateToken(16)
+	require.NoError(t, err)
+	require.NotEqual(t, t1, t2)
+}
+
+func TestGenerateKeyReturnsBase64Key(t *testing.T) {
+	k, err := GenerateKey(32)
+	require.NoError(t, err)
+
+	d, err := base64.StdEncoding.DecodeString(k)
+	require.NoError(t, err)
+	require.Len(t, d, 32)
+}
+
+func TestRedactReplacesRegisteredValues(t *testing.T) {
+	Register("abc123", "")
+
+	require.Equal(t, "--token=(sensitive)", Redact("--token=abc123"))
+	require.Equal(t, "--token=def456", Redact("--token=def456"))
+}
+
+func TestRedactWriterReplacesRegisteredValues(t *testing.T) {
+	Register("xyz789")
+
+	out := bytes.NewBufferString("")
+	w := NewRedactWriter(out)
+
+	n, err := w.Write([]byte("token xyz789 created"))
+	require.NoError(t, err)
+	require.Equal(t, 20, n)
+	require.Equal(t, "token (sensitive) created", out.String())
+}
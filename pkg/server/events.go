@@ -0,0 +1,132 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// eventBufferSize is the number of events buffered for each client streaming
+// events, events are dropped for clients that do not keep up
+const eventBufferSize = 100
+
+// maxEventSize is the maximum size in bytes of an event published to the API
+const maxEventSize = 1 << 20
+
+// eventBroker relays the engine events published to the API to the clients
+// streaming events, events are forwarded as received so that the API does
+// not need to know the event schema
+type eventBroker struct {
+	mutex   sync.Mutex
+	clients map[chan []byte]bool
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{clients: map[chan []byte]bool{}}
+}
+
+func (b *eventBroker) subscribe() chan []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	ch := make(chan []byte, eventBufferSize)
+	b.clients[ch] = true
+
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.clients, ch)
+}
+
+func (b *eventBroker) publish(ev []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for ch := range b.clients {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// publishEvent receives an event from the engine and sends it to the clients
+// streaming events. The endpoint is not authenticated and the API allows any
+// origin, events are only accepted from clients on the local machine that are
+// not browsers so that other hosts and web pages can not publish events
+func (a *API) publishEvent(rw http.ResponseWriter, r *http.Request) {
+	if !localRequest(r) {
+		http.Error(rw, "events can only be published from the local machine", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxEventSize))
+	if err != nil {
+		http.Error(rw, "unable to read event", http.StatusBadRequest)
+		return
+	}
+
+	if !json.Valid(body) {
+		http.Error(rw, "event is not valid JSON", http.StatusBadRequest)
+		return
+	}
+
+	a.events.publish(body)
+
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// streamEvents streams the engine events to the client as server sent events
+func (a *API) streamEvents(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := a.events.subscribe()
+	defer a.events.unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-ch:
+			fmt.Fprintf(rw, "data: %s\n\n", ev)
+			flusher.Flush()
+		}
+	}
+}
+
+// localRequest returns true when the request was made from the local machine
+// by a client that is not a browser. Browsers set the Origin header on cross
+// origin POST requests, the forwarding headers used to set the remote address
+// can be set by any client so requests with these headers are not local
+func localRequest(r *http.Request) bool {
+	for _, h := range []string{"Origin", "X-Forwarded-For", "X-Real-IP", "True-Client-IP"} {
+		if r.Header.Get(h) != "" {
+			return false
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
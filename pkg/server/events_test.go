@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupEventsTests() *API {
+	return &API{events: newEventBroker()}
+}
+
+func newPublishRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	r.RemoteAddr = "127.0.0.1:51234"
+
+	return r
+}
+
+// waitForSubscribers blocks until the broker has the given number of clients
+func waitForSubscribers(t *testing.T, b *eventBroker, n int) {
+	require.Eventually(t, func() bool {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		return len(b.clients) == n
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBrokerPublishSendsEventToAllClients(t *testing.T) {
+	b := newEventBroker()
+	c1 := b.subscribe()
+	c2 := b.subscribe()
+
+	b.publish([]byte(`{"id":"1"}`))
+
+	require.Equal(t, []byte(`{"id":"1"}`), <-c1)
+	require.Equal(t, []byte(`{"id":"1"}`), <-c2)
+}
+
+func TestBrokerPublishDropsEventsForSlowClients(t *testing.T) {
+	b := newEventBroker()
+	slow := b.subscribe()
+
+	// the client does not read any events, publish must not block
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < eventBufferSize+10; i++ {
+			b.publish([]byte(fmt.Sprintf(`{"id":"%d"}`, i)))
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on slow client")
+	}
+
+	// the slow client keeps the oldest events, later events are dropped
+	require.Len(t, slow, eventBufferSize)
+	require.Equal(t, []byte(`{"id":"0"}`), <-slow)
+
+	// events are received again once the client catches up
+	for len(slow) > 0 {
+		<-slow
+	}
+
+	b.publish([]byte(`{"id":"new"}`))
+	require.Equal(t, []byte(`{"id":"new"}`), <-slow)
+}
+
+func TestBrokerUnsubscribeStopsEvents(t *testing.T) {
+	b := newEventBroker()
+	c := b.subscribe()
+	b.unsubscribe(c)
+
+	b.publish([]byte(`{"id":"1"}`))
+
+	require.Len(t, c, 0)
+}
+
+func TestPublishEventSendsEventToClients(t *testing.T) {
+	a := setupEventsTests()
+	c := a.events.subscribe()
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, newPublishRequest(`{"id":"resource.container.app","status":"created"}`))
+
+	require.Equal(t, http.StatusAccepted, rw.Code)
+	require.Equal(t, []byte(`{"id":"resource.container.app","status":"created"}`), <-c)
+}
+
+func TestPublishEventWithInvalidJSONReturnsBadRequest(t *testing.T) {
+	a := setupEventsTests()
+	c := a.events.subscribe()
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, newPublishRequest(`{"id":`))
+
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), "not valid JSON")
+	require.Len(t, c, 0)
+}
+
+func TestPublishEventWithLargeEventReturnsBadRequest(t *testing.T) {
+	a := setupEventsTests()
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, newPublishRequest(`"`+strings.Repeat("a", maxEventSize)+`"`))
+
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+}
+
+func TestPublishEventFromRemoteHostReturnsForbidden(t *testing.T) {
+	a := setupEventsTests()
+	c := a.events.subscribe()
+
+	r := newPublishRequest(`{"id":"1"}`)
+	r.RemoteAddr = "10.5.0.20:51234"
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, r)
+
+	require.Equal(t, http.StatusForbidden, rw.Code)
+	require.Len(t, c, 0)
+}
+
+func TestPublishEventFromBrowserReturnsForbidden(t *testing.T) {
+	a := setupEventsTests()
+
+	r := newPublishRequest(`{"id":"1"}`)
+	r.Header.Set("Origin", "https://example.com")
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, r)
+
+	require.Equal(t, http.StatusForbidden, rw.Code)
+}
+
+func TestPublishEventWithForwardedAddressReturnsForbidden(t *testing.T) {
+	a := setupEventsTests()
+
+	r := newPublishRequest(`{"id":"1"}`)
+	r.Header.Set("X-Real-IP", "127.0.0.1")
+
+	rw := httptest.NewRecorder()
+	a.publishEvent(rw, r)
+
+	require.Equal(t, http.StatusForbidden, rw.Code)
+}
+
+func TestStreamEventsWritesServerSentEvents(t *testing.T) {
+	a := setupEventsTests()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	rw := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		a.streamEvents(rw, r)
+		close(done)
+	}()
+
+	waitForSubscribers(t, a.events, 1)
+
+	a.events.publish([]byte(`{"id":"1"}`))
+	a.events.publish([]byte(`{"id":"2"}`))
+
+	// cancel once the events have been read from the client channel
+	require.Eventually(t, func() bool {
+		a.events.mutex.Lock()
+		defer a.events.mutex.Unlock()
+
+		for ch := range a.events.clients {
+			return len(ch) == 0
+		}
+
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	// the client is removed when the request ends
+	waitForSubscribers(t, a.events, 0)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "text/event-stream", rw.Header().Get("Content-Type"))
+	require.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+	require.Equal(t, "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\n", rw.Body.String())
+}
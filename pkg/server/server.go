@@ -16,6 +16,7 @@ import (
 type API struct {
 	server *http.Server
 	log    sdk.Logger
+	events *eventBroker
 }
 
 // New creates a new server
@@ -45,10 +46,13 @@ func New(addr string, l logger.Logger) *API {
 	api := &API{
 		server: server,
 		log:    l,
+		events: newEventBroker(),
 	}
 
 	router.Get("/terminal", api.terminal)
 	router.Post("/validate/{task}/{action}", api.validation)
+	router.Post("/events", api.publishEvent)
+	router.Get("/events", api.streamEvents)
 
 	return api
 }
@@ -6,9 +6,6 @@ var ErrInvalidBlueprintURI = fmt.Errorf("inavlid blueprint URI")
 var ErrNameExceedsMaxLength = fmt.Errorf("name exceeds the max length of 128 characters")
 var ErrNameContainsInvalidCharacters = fmt.Errorf("name contains invalid characters characters must be either a-z, A-Z, 0-9, -, _")
 
-// ImageVolumeName is the name of the volume which stores the images for clusters
-const ImageVolumeName string = "images"
-
 // BuildImagePrefix is the default prefix added to any image built by jumppad
 const BuildImagePrefix = "jumppad.dev/localcache"
 
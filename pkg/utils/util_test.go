@@ -230,6 +230,71 @@ func TestIsHCLFile(t *testing.T) {
 	}
 }
 
+func TestIsComposeFile(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"compose.yaml":        "name: app\n",
+		"docker-compose.yml":  "name: app\n",
+		"app.yaml":            "services:\n  web:\n    image: nginx\n",
+		"consul-values.yaml":  "global:\n  name: consul\n",
+		"services-list.yaml":  "services:\n  - web\n",
+		"docker-compose.json": "{}",
+	}
+
+	for n, c := range files {
+		err := os.WriteFile(filepath.Join(dir, n), []byte(c), os.ModePerm)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{
+			"False when file not exist",
+			filepath.Join(dir, "missing.yaml"),
+			false,
+		}, {
+			"False when directory",
+			dir,
+			false,
+		}, {
+			"True when compose.yaml",
+			filepath.Join(dir, "compose.yaml"),
+			true,
+		}, {
+			"True when docker-compose.yml",
+			filepath.Join(dir, "docker-compose.yml"),
+			true,
+		}, {
+			"True when YAML file defines services",
+			filepath.Join(dir, "app.yaml"),
+			true,
+		}, {
+			"False when other YAML file",
+			filepath.Join(dir, "consul-values.yaml"),
+			false,
+		}, {
+			"False when services is not a map",
+			filepath.Join(dir, "services-list.yaml"),
+			false,
+		}, {
+			"False when not a YAML file",
+			filepath.Join(dir, "docker-compose.json"),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsComposeFile(tt.path); got != tt.want {
+				t.Errorf("IsComposeFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBlueprintLocalFolder(t *testing.T) {
 	dst := BlueprintLocalFolder("github.com/shipyard-run/blueprints?ref=dfdf&foo=bah//vault-k8s")
 
@@ -247,6 +312,34 @@ func TestDockerHostWithDefaultReturnsCorrectValue(t *testing.T) {
 	require.Equal(t, "/var/run/docker.sock", ds)
 }
 
+func TestDockerIPReturnsRemoteAddressForSSHHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "ssh://nic@10.5.0.20:2222")
+
+	require.Equal(t, "10.5.0.20", GetDockerIP())
+}
+
+func TestIsRemoteDockerHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"", false},
+		{"unix:///var/run/docker.sock", false},
+		{"tcp://localhost:2375", false},
+		{"tcp://127.0.0.1:2376", false},
+		{"tcp://10.5.0.20:2376", true},
+		{"ssh://nic@docker.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			t.Setenv("DOCKER_HOST", tt.host)
+
+			require.Equal(t, tt.want, IsRemoteDockerHost())
+		})
+	}
+}
+
 func TestGetLocalIPAndHostnameReturnsCorrectly(t *testing.T) {
 	ip, host := GetLocalIPAndHostname()
 
@@ -268,6 +361,18 @@ func TestImageCacheAddressReturnsEnvWhenEnvSet(t *testing.T) {
 	require.Equal(t, httpProxy, proxy)
 }
 
+func TestContainerRuntimeReturnsDockerWhenEnvNotSet(t *testing.T) {
+	t.Setenv("JUMPPAD_CONTAINER_RUNTIME", "")
+
+	require.Equal(t, "docker", ContainerRuntime())
+}
+
+func TestContainerRuntimeReturnsEnvWhenEnvSet(t *testing.T) {
+	t.Setenv("JUMPPAD_CONTAINER_RUNTIME", "containerd")
+
+	require.Equal(t, "containerd", ContainerRuntime())
+}
+
 var testData = `
 {
 	"checks": "test",
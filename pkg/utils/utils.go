@@ -18,6 +18,7 @@ import (
 
 	"github.com/jumppad-labs/jumppad/pkg/utils/dirhash"
 	"github.com/kennygrant/sanitize"
+	"gopkg.in/yaml.v3"
 )
 
 // EnsureAbsolute ensure that the given path is either absolute or
@@ -94,13 +95,21 @@ func ReplaceNonURIChars(s string) (string, error) {
 	return ret, nil
 }
 
-// FQDN generates the full qualified name for a container
+// FQDN generates the full qualified name for a container,
+// resources in a workspace other than the default workspace have the
+// workspace name added before the local domain so that names do not collide
 func FQDN(name, module, typeName string) string {
-	fqdn := fmt.Sprintf("%s.%s.local.%s", name, typeName, LocalTLD)
+	fqdn := fmt.Sprintf("%s.%s", name, typeName)
 	if module != "" {
-		fqdn = fmt.Sprintf("%s.%s.%s.local.%s", name, module, typeName, LocalTLD)
+		fqdn = fmt.Sprintf("%s.%s.%s", name, module, typeName)
 	}
 
+	if ws := Workspace(); ws != DefaultWorkspace {
+		fqdn = fmt.Sprintf("%s.%s", fqdn, ws)
+	}
+
+	fqdn = fmt.Sprintf("%s.local.%s", fqdn, LocalTLD)
+
 	// ensure that the name is valid for URI schema
 	cleanName, err := ReplaceNonURIChars(fqdn)
 	if err != nil {
@@ -110,6 +119,17 @@ func FQDN(name, module, typeName string) string {
 	return cleanName
 }
 
+// ImageVolumeName returns the name of the volume which stores the images for
+// clusters, workspaces other than the default workspace have their own volume
+// so that the image cache for each workspace does not share the volume
+func ImageVolumeName() string {
+	if ws := Workspace(); ws != DefaultWorkspace {
+		return fmt.Sprintf("images.%s", ws)
+	}
+
+	return "images"
+}
+
 // FQDNVolumeName creates a full qualified volume name
 func FQDNVolumeName(name string) string {
 	// ensure that the name is valid for URI schema
@@ -125,7 +145,7 @@ func FQDNVolumeName(name string) string {
 // using Kubernetes cluster
 func CreateKubeConfigPath(id string) (dir, filePath string, dockerPath string) {
 	id, _ = ReplaceNonURIChars(id)
-	dir = filepath.Join(JumppadHome(), "/config/", id)
+	dir = filepath.Join(WorkspaceHome(Workspace()), "/config/", id)
 	filePath = filepath.Join(dir, "/kubeconfig.yaml")
 	dockerPath = filepath.Join(dir, "/kubeconfig-docker.yaml")
 
@@ -171,9 +191,9 @@ func JumppadTemp() string {
 }
 
 // StateDir returns the location of the jumppad
-// state, usually $HOME/.jumppad/state
+// state for the current workspace, usually $HOME/.jumppad/state
 func StateDir() string {
-	return filepath.Join(JumppadHome(), "/state")
+	return workspaceStateDir(Workspace())
 }
 
 // PluginsDir returns the location of the plugins
@@ -204,9 +224,21 @@ func LogsDir() string {
 	return logs
 }
 
+// SnapshotsDir returns the location of the snapshots for the current
+// workspace, usually $HOME/.jumppad/snapshots
+func SnapshotsDir() string {
+	return filepath.Join(WorkspaceHome(Workspace()), "/snapshots")
+}
+
 // StatePath returns the full path for the state file
 func StatePath() string {
-	return filepath.Join(StateDir(), "/state.json")
+	return workspaceStatePath(Workspace())
+}
+
+// StateBackendPath returns the full path for the file that records
+// the state backend selected by the last applied blueprint
+func StateBackendPath() string {
+	return workspaceStateBackendPath(Workspace())
 }
 
 // ImageCacheLog returns the location of the image cache log
@@ -250,6 +282,56 @@ func IsHCLFile(path string) bool {
 	return true
 }
 
+// composeFileNames are the default file names used by Docker Compose
+var composeFileNames = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+// IsComposeFile tests if the given path resolves to a Docker Compose file,
+// files with the default Compose names are always treated as Compose files,
+// other YAML files must define the top level services key
+func IsComposeFile(path string) bool {
+	s, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	if s.IsDir() {
+		return false
+	}
+
+	ext := filepath.Ext(s.Name())
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	for _, n := range composeFileNames {
+		if s.Name() == n {
+			return true
+		}
+	}
+
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	// services must be a map of service names for the file to be valid
+	cf := struct {
+		Services map[string]interface{} `yaml:"services"`
+	}{}
+
+	err = yaml.Unmarshal(d, &cf)
+	if err != nil {
+		return false
+	}
+
+	return len(cf.Services) > 0
+}
+
 // BlueprintFolder parses a blueprint uri and returns the top level
 // blueprint folder
 // if the URI is not a blueprint will return an error
@@ -297,9 +379,10 @@ func ReleasesFolder() string {
 	return filepath.Join(JumppadHome(), "releases")
 }
 
-// DataFolder creates the data directory used by the application
+// DataFolder creates the data directory used by the application,
+// data folders are isolated for each workspace
 func DataFolder(p string, perms os.FileMode) string {
-	data := filepath.Join(JumppadHome(), "data", p)
+	data := filepath.Join(WorkspaceHome(Workspace()), "data", p)
 
 	// create the folder if it does not exist
 	os.MkdirAll(data, perms)
@@ -341,17 +424,15 @@ func GetDockerHost() string {
 	return "/var/run/docker.sock"
 }
 
-// GetDockerIP returns the location of the Docker Server IP address
+// GetDockerIP returns the location of the Docker Server IP address, when
+// the Docker host is remote this is the address of the remote host
 func GetDockerIP() string {
 	if dh := os.Getenv("DOCKER_HOST"); dh != "" {
-		if strings.HasPrefix(dh, "tcp://") {
-			u, err := url.Parse(dh)
-			if err == nil {
-				host := strings.Split(u.Host, ":")[0]
-				ip, err := net.LookupHost(host)
-				if err == nil && len(ip) > 0 {
-					return ip[0]
-				}
+		u, err := url.Parse(dh)
+		if err == nil && (u.Scheme == "tcp" || u.Scheme == "ssh") {
+			ip, err := net.LookupHost(u.Hostname())
+			if err == nil && len(ip) > 0 {
+				return ip[0]
 			}
 		}
 	}
@@ -361,6 +442,32 @@ func GetDockerIP() string {
 	return sp
 }
 
+// IsRemoteDockerHost returns true when DOCKER_HOST points to a Docker engine
+// on another machine, a remote engine does not share the local filesystem
+// or the loopback interface
+func IsRemoteDockerHost() bool {
+	u, err := url.Parse(os.Getenv("DOCKER_HOST"))
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme != "tcp" && u.Scheme != "ssh" {
+		return false
+	}
+
+	host := u.Hostname()
+	if host == "" || host == "localhost" {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+		return false
+	}
+
+	return true
+}
+
 // GetConnectorPIDFile returns the connector PID file used by the connector
 func GetConnectorPIDFile() string {
 	return filepath.Join(JumppadHome(), "connector.pid")
@@ -424,6 +531,17 @@ func GetLocalIPAndHostname() (string, string) {
 	return "127.0.0.1", "localhost"
 }
 
+// ContainerRuntime returns the runtime used to manage containers, docker
+// unless the environment variable JUMPPAD_CONTAINER_RUNTIME is set when it
+// returns this value
+func ContainerRuntime() string {
+	if r := os.Getenv("JUMPPAD_CONTAINER_RUNTIME"); r != "" {
+		return r
+	}
+
+	return "docker"
+}
+
 // ImageCacheADDR returns the default Image cache used by
 // Nomad and Kubernetes clusters unless the environment variable
 // IMAGE_CACHE_ADDR is set when it returns this value
@@ -432,6 +550,11 @@ func ImageCacheAddress() string {
 		return p
 	}
 
+	// each workspace has its own image cache
+	if Workspace() != DefaultWorkspace {
+		return fmt.Sprintf("http://%s:3128", FQDN("default", "", "image-cache"))
+	}
+
 	return jumppadProxyAddress
 }
 
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultWorkspace is the name of the workspace used when no other workspace
+// has been selected, the default workspace uses the same locations as versions
+// of jumppad that did not support workspaces
+const DefaultWorkspace = "default"
+
+// WorkspaceEnvVar is the environment variable that can be used to set the
+// workspace for a single command, it takes precedence over the workspace
+// selected with 'jumppad workspace select'
+const WorkspaceEnvVar = "JUMPPAD_WORKSPACE"
+
+var ErrInvalidWorkspaceName = fmt.Errorf("workspace name must start and end with a lower case letter or number and can only contain a-z, 0-9, or -, max length 63 characters")
+
+var workspaceNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-]{0,61}[a-z0-9])?$`)
+
+// ValidateWorkspaceName ensures that the workspace name can be used
+// as part of a DNS name
+func ValidateWorkspaceName(name string) error {
+	if !workspaceNameRegex.MatchString(name) {
+		return ErrInvalidWorkspaceName
+	}
+
+	return nil
+}
+
+// ValidateWorkspaceEnv returns an error when JUMPPAD_WORKSPACE is set to an
+// invalid workspace name
+func ValidateWorkspaceEnv() error {
+	ws := os.Getenv(WorkspaceEnvVar)
+	if ws == "" {
+		return nil
+	}
+
+	err := ValidateWorkspaceName(ws)
+	if err != nil {
+		return fmt.Errorf("invalid workspace '%s' set by %s: %w", ws, WorkspaceEnvVar, err)
+	}
+
+	return nil
+}
+
+// Workspace returns the name of the current workspace.
+// The workspace is selected in the following order:
+//
+//  1. the JUMPPAD_WORKSPACE environment variable
+//  2. the workspace selected with SelectWorkspace
+//  3. the default workspace
+//
+// An invalid name in JUMPPAD_WORKSPACE is ignored as it would be used in paths
+// and DNS names, ValidateWorkspaceEnv returns the error for the invalid name.
+func Workspace() string {
+	if ws := os.Getenv(WorkspaceEnvVar); ws != "" && ValidateWorkspaceName(ws) == nil {
+		return ws
+	}
+
+	d, err := os.ReadFile(selectedWorkspacePath())
+	if err == nil && len(strings.TrimSpace(string(d))) > 0 {
+		return strings.TrimSpace(string(d))
+	}
+
+	return DefaultWorkspace
+}
+
+// SelectWorkspace sets the workspace used by subsequent commands
+func SelectWorkspace(name string) error {
+	err := ValidateWorkspaceName(name)
+	if err != nil {
+		return err
+	}
+
+	if name == DefaultWorkspace {
+		err := os.Remove(selectedWorkspacePath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("unable to select workspace: %s", err)
+		}
+
+		return nil
+	}
+
+	// create the workspace folder so that it is returned by ListWorkspaces
+	err = os.MkdirAll(WorkspaceHome(name), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create workspace folder '%s': %s", WorkspaceHome(name), err)
+	}
+
+	err = os.WriteFile(selectedWorkspacePath(), []byte(name), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to select workspace: %s", err)
+	}
+
+	return nil
+}
+
+// ListWorkspaces returns the names of all the workspaces, the default
+// workspace is always returned
+func ListWorkspaces() ([]string, error) {
+	ws := []string{DefaultWorkspace}
+
+	entries, err := os.ReadDir(WorkspacesDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unable to list workspaces: %s", err)
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() && ValidateWorkspaceName(e.Name()) == nil && e.Name() != DefaultWorkspace {
+			names = append(names, e.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	return append(ws, names...), nil
+}
+
+// WorkspacesDir returns the location of the folder that contains the
+// non default workspaces, usually $HOME/.jumppad/workspaces
+func WorkspacesDir() string {
+	return filepath.Join(JumppadHome(), "/workspaces")
+}
+
+// WorkspaceHome returns the root folder for the state and data of the given
+// workspace, for the default workspace this is the jumppad home folder
+func WorkspaceHome(name string) string {
+	if name == "" || name == DefaultWorkspace {
+		return JumppadHome()
+	}
+
+	return filepath.Join(WorkspacesDir(), name)
+}
+
+// WorkspaceHasState returns true when the given workspace contains a state
+// file or a remote state backend, this indicates that the workspace has
+// resources that have not been destroyed
+func WorkspaceHasState(name string) bool {
+	for _, p := range []string{workspaceStatePath(name), workspaceStateBackendPath(name)} {
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OtherWorkspacesHaveState returns true when any workspace other than the
+// current workspace has state, resources shared by all workspaces such as the
+// connector must not be removed while other workspaces have resources
+func OtherWorkspacesHaveState() bool {
+	ws, err := ListWorkspaces()
+	if err != nil {
+		return true
+	}
+
+	for _, w := range ws {
+		if w != Workspace() && WorkspaceHasState(w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func workspaceStateDir(name string) string {
+	return filepath.Join(WorkspaceHome(name), "/state")
+}
+
+func workspaceStatePath(name string) string {
+	return filepath.Join(workspaceStateDir(name), "/state.json")
+}
+
+func workspaceStateBackendPath(name string) string {
+	return filepath.Join(workspaceStateDir(name), "/backend.json")
+}
+
+func selectedWorkspacePath() string {
+	return filepath.Join(JumppadHome(), "/workspace")
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupWorkspaceTests(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv(HomeEnvName(), home)
+	t.Setenv(WorkspaceEnvVar, "")
+	t.Setenv("IMAGE_CACHE_ADDR", "")
+
+	return home
+}
+
+func TestWorkspaceWithNoSelectionReturnsDefault(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	require.Equal(t, DefaultWorkspace, Workspace())
+}
+
+func TestWorkspaceReturnsEnvOverSelected(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	err := SelectWorkspace("nomad")
+	require.NoError(t, err)
+	require.Equal(t, "nomad", Workspace())
+
+	t.Setenv(WorkspaceEnvVar, "k8s")
+	require.Equal(t, "k8s", Workspace())
+}
+
+func TestWorkspaceIgnoresInvalidEnv(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	err := SelectWorkspace("nomad")
+	require.NoError(t, err)
+
+	t.Setenv(WorkspaceEnvVar, "../k8s")
+	require.Equal(t, "nomad", Workspace())
+
+	err = ValidateWorkspaceEnv()
+	require.ErrorIs(t, err, ErrInvalidWorkspaceName)
+}
+
+func TestSelectWorkspaceDefaultRemovesSelection(t *testing.T) {
+	home := setupWorkspaceTests(t)
+
+	err := SelectWorkspace("nomad")
+	require.NoError(t, err)
+
+	err = SelectWorkspace(DefaultWorkspace)
+	require.NoError(t, err)
+
+	require.Equal(t, DefaultWorkspace, Workspace())
+	require.NoFileExists(t, filepath.Join(home, ".jumppad", "workspace"))
+}
+
+func TestSelectWorkspaceWithInvalidNameReturnsError(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	err := SelectWorkspace("Nomad_Dev")
+	require.ErrorIs(t, err, ErrInvalidWorkspaceName)
+}
+
+func TestListWorkspacesReturnsDefaultFirst(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	SelectWorkspace("nomad")
+	SelectWorkspace("k8s")
+
+	ws, err := ListWorkspaces()
+	require.NoError(t, err)
+	require.Equal(t, []string{"default", "k8s", "nomad"}, ws)
+}
+
+func TestWorkspaceIsolatesStateAndData(t *testing.T) {
+	home := setupWorkspaceTests(t)
+	t.Setenv(WorkspaceEnvVar, "nomad")
+
+	require.Equal(t, filepath.Join(home, ".jumppad", "workspaces", "nomad", "state", "state.json"), StatePath())
+	require.Equal(t, filepath.Join(home, ".jumppad", "workspaces", "nomad", "data", "test"), DataFolder("test", os.ModePerm))
+}
+
+func TestWorkspaceAddsSuffixToFQDN(t *testing.T) {
+	setupWorkspaceTests(t)
+	t.Setenv(WorkspaceEnvVar, "nomad")
+
+	require.Equal(t, "test.type.nomad.local.jmpd.in", FQDN("test", "", "type"))
+	require.Equal(t, "test.mod.type.nomad.local.jmpd.in", FQDN("test", "mod", "type"))
+	require.Equal(t, "http://default.image-cache.nomad.local.jmpd.in:3128", ImageCacheAddress())
+}
+
+func TestWorkspaceHasStateReturnsTrueWhenStateExists(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	require.False(t, WorkspaceHasState("nomad"))
+
+	t.Setenv(WorkspaceEnvVar, "nomad")
+	os.MkdirAll(StateDir(), os.ModePerm)
+	os.WriteFile(StatePath(), []byte("{}"), os.ModePerm)
+
+	require.True(t, WorkspaceHasState("nomad"))
+}
+
+func TestOtherWorkspacesHaveStateIgnoresCurrentWorkspace(t *testing.T) {
+	setupWorkspaceTests(t)
+
+	t.Setenv(WorkspaceEnvVar, "nomad")
+	os.MkdirAll(StateDir(), os.ModePerm)
+	os.WriteFile(StatePath(), []byte("{}"), os.ModePerm)
+
+	require.False(t, OtherWorkspacesHaveState())
+
+	t.Setenv(WorkspaceEnvVar, "k8s")
+	require.True(t, OtherWorkspacesHaveState())
+}
+
+func TestWorkspaceScopesImageVolume(t *testing.T) {
+	setupWorkspaceTests(t)
+	require.Equal(t, "images", ImageVolumeName())
+
+	t.Setenv(WorkspaceEnvVar, "nomad")
+	require.Equal(t, "images.nomad", ImageVolumeName())
+}